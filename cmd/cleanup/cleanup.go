@@ -14,6 +14,9 @@ import (
 )
 
 type Command struct {
+	// Flags
+	DryRun bool // Show what would be deleted without deleting anything
+
 	Git   *git.Client
 	Stack *stack.Client
 	GH    *gh.Client
@@ -30,8 +33,12 @@ func (c *Command) Register(parent *cobra.Command) {
 The command will scan all stacks, identify candidates, and prompt for confirmation
 before deleting. Deleted stacks are archived to .git/stack/.archived/ for recovery.
 
+With --dry-run, the branches (local and remote) each candidate would lose and its
+archive path are shown, and nothing is deleted.
+
 Example:
-  stack cleanup`,
+  stack cleanup
+  stack cleanup --dry-run`,
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
 			c.Git, c.GH, c.Stack, err = common.InitClients()
@@ -42,6 +49,8 @@ Example:
 		},
 	}
 
+	command.Flags().BoolVar(&c.DryRun, "dry-run", false, "Show what would be deleted without deleting anything")
+
 	parent.AddCommand(command)
 }
 
@@ -61,6 +70,10 @@ func (c *Command) Run(ctx context.Context) error {
 
 	c.displayCandidates(candidates)
 
+	if c.DryRun {
+		return c.previewDeletion(candidates)
+	}
+
 	prompt := "Select stacks to clean up (e.g., '1,3,5' or 'all' or 'none'): "
 	selectedIndices := ui.PromptSelection(prompt, len(candidates))
 	if len(selectedIndices) == 0 {
@@ -84,6 +97,29 @@ func (c *Command) displayCandidates(candidates []stack.CleanupCandidate) {
 	}
 }
 
+// previewDeletion shows what deleting each candidate would do, without deleting anything
+func (c *Command) previewDeletion(candidates []stack.CleanupCandidate) error {
+	for _, candidate := range candidates {
+		plan, err := c.Stack.DeleteStack(candidate.StackCtx.StackName, stack.DeleteOptions{DryRun: true})
+		if err != nil {
+			return fmt.Errorf("failed to plan deletion of stack %s: %w", candidate.StackCtx.StackName, err)
+		}
+
+		ui.Printf("%s would:\n", ui.Bold(candidate.StackCtx.StackName))
+		ui.Printf("  Archive metadata to %s\n", plan.ArchivePath)
+		for _, branch := range plan.LocalBranches {
+			ui.Printf("  Delete local branch %s\n", branch)
+		}
+		for _, branch := range plan.RemoteBranches {
+			ui.Printf("  Delete remote branch %s\n", branch)
+		}
+		ui.Println("")
+	}
+
+	ui.Info("Dry run - nothing was deleted")
+	return nil
+}
+
 func (c *Command) formatReason(candidate stack.CleanupCandidate) string {
 	switch candidate.Reason {
 	case "empty":
@@ -99,19 +135,19 @@ func (c *Command) deleteSelected(candidates []stack.CleanupCandidate, indices []
 	successCount := 0
 	for _, idx := range indices {
 		candidate := candidates[idx]
-		stack := candidate.StackCtx.Stack
-		ui.Infof("Cleaning up stack: %s", stack.Name)
+		s := candidate.StackCtx.Stack
+		ui.Infof("Cleaning up stack: %s", s.Name)
 		ui.Println("")
 
 		if candidate.Reason == "all_merged" {
 			if err := c.Stack.SyncVisualizationComments(candidate.StackCtx); err != nil {
-				ui.Errorf("updating visualization comments for stack %s: %v", stack.Name, err)
+				ui.Errorf("updating visualization comments for stack %s: %v", s.Name, err)
 				continue
 			}
 		}
 
-		if err := c.Stack.DeleteStack(candidate.StackCtx.Stack.Name, true); err != nil {
-			ui.Errorf("cleaning up stack %s: %v", stack.Name, err)
+		if _, err := c.Stack.DeleteStack(s.Name, stack.DeleteOptions{}); err != nil {
+			ui.Errorf("cleaning up stack %s: %v", s.Name, err)
 			continue
 		}
 
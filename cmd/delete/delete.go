@@ -14,11 +14,14 @@ import (
 )
 
 type Command struct {
-	StackName string
-	Force     bool
-	Git       *git.Client
-	Stack     *stack.Client
-	GH        *gh.Client
+	StackName  string
+	Force      bool
+	ClosePRs   bool
+	KeepRemote bool
+	Exact      bool
+	Git        *git.Client
+	Stack      *stack.Client
+	GH         *gh.Client
 }
 
 func (c *Command) Register(parent *cobra.Command) {
@@ -32,10 +35,19 @@ All local and remote branches associated with the stack will be deleted.
 
 If no stack name is provided, deletes the current stack (if on a stack branch).
 
+Stacks with open PRs on GitHub are not deleted unless --force is given, since the
+PRs would be orphaned. Use --close-prs to close them as part of the deletion.
+
+Use --keep-remote to delete only the local branches, e.g. when handing the stack's
+PRs over to a teammate. The remote branches stay, so the PRs stay open and --force
+isn't needed.
+
 Example:
   stack delete                  # Delete current stack
   stack delete auth-refactor    # Delete specific stack
-  stack delete --force          # Skip confirmation prompt`,
+  stack delete --force          # Skip confirmation prompt and allow open PRs
+  stack delete --force --close-prs  # Also close the stack's open PRs
+  stack delete --keep-remote    # Keep the remote branches and open PRs`,
 		Args: cobra.MaximumNArgs(1),
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -50,7 +62,10 @@ Example:
 		},
 	}
 
-	command.Flags().BoolVarP(&c.Force, "force", "f", false, "Skip confirmation prompt")
+	command.Flags().BoolVarP(&c.Force, "force", "f", false, "Skip confirmation prompt and delete even if the stack has open PRs")
+	command.Flags().BoolVar(&c.ClosePRs, "close-prs", false, "Close the stack's open PRs on GitHub (requires --force)")
+	command.Flags().BoolVar(&c.KeepRemote, "keep-remote", false, "Keep the remote branches so the stack's PRs stay open")
+	command.Flags().BoolVar(&c.Exact, "exact", false, "Require an exact (case-sensitive) stack name match")
 	parent.AddCommand(command)
 }
 
@@ -72,6 +87,17 @@ func (c *Command) Run(ctx context.Context) error {
 
 	c.showDeletionSummary(stackCtx, branches)
 
+	if c.ClosePRs && !c.Force {
+		return fmt.Errorf("--close-prs requires --force")
+	}
+	if c.ClosePRs && c.KeepRemote {
+		return fmt.Errorf("--close-prs and --keep-remote cannot be used together")
+	}
+
+	if openPRs := stackCtx.OpenPRNumbers(); len(openPRs) > 0 && !c.Force && !c.KeepRemote {
+		return fmt.Errorf("stack '%s' has %d open PR(s): merge or close them first, or use --force to delete anyway", stackName, len(openPRs))
+	}
+
 	if !c.Force {
 		prompt := fmt.Sprintf("Type the stack name '%s' to confirm deletion: ", ui.Bold(stackName))
 		if !ui.Confirm(prompt, stackName) {
@@ -84,10 +110,21 @@ func (c *Command) Run(ctx context.Context) error {
 	ui.Info("Deleting stack...")
 	ui.Println("")
 
-	if err := c.Stack.DeleteStack(stackName, c.Force); err != nil {
+	orphaned, err := c.Stack.DeleteStackSafe(stackName, c.Force, stack.DeleteOptions{KeepRemote: c.KeepRemote})
+	if err != nil {
 		return fmt.Errorf("failed to delete stack: %w", err)
 	}
 
+	if c.ClosePRs {
+		for _, prNumber := range orphaned {
+			if err := c.GH.ClosePR(prNumber); err != nil {
+				ui.Warningf("Failed to close PR #%d: %v", prNumber, err)
+				continue
+			}
+			ui.Successf("Closed PR #%d", prNumber)
+		}
+	}
+
 	ui.Println("")
 	ui.Successf("Successfully deleted stack: %s", stackName)
 	return nil
@@ -95,10 +132,14 @@ func (c *Command) Run(ctx context.Context) error {
 
 func (c *Command) resolveStackName() (string, error) {
 	if c.StackName != "" {
-		if !c.Stack.StackExists(c.StackName) {
+		stackName, err := c.Stack.ResolveStackName(c.StackName, c.Exact)
+		if err != nil {
+			return "", err
+		}
+		if !c.Stack.StackExists(stackName) {
 			return "", fmt.Errorf("stack '%s' not found", c.StackName)
 		}
-		return c.StackName, nil
+		return stackName, nil
 	}
 
 	stackCtx, err := c.Stack.GetStackContext()
@@ -128,7 +169,11 @@ func (c *Command) showDeletionSummary(stackCtx *stack.StackContext, branches []s
 	ui.Printf("    Changes: %d total (%d open, %d merged)\n", len(stackCtx.AllChanges), openCount, mergedCount)
 	ui.Printf("    Branches: %d\n", len(branches))
 	if len(branches) > 0 {
-		ui.Printf("\n  Branches to be deleted:\n")
+		if c.KeepRemote {
+			ui.Printf("\n  Local branches to be deleted (remote branches are kept):\n")
+		} else {
+			ui.Printf("\n  Branches to be deleted:\n")
+		}
 		for _, branch := range branches {
 			ui.Printf("    - %s\n", branch)
 		}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command shows the diff of a change or the whole stack
+type Command struct {
+	// Arguments
+	Ref string
+
+	// Flags
+	Stat bool
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "diff [ref]",
+		Short: "Show the diff of a change or the whole stack",
+		Long: `Show the diff of a single change, or of the whole stack if no ref is given.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash). Its
+diff is against the change below it, so it matches what reviewers see in its PR.
+The stack diff runs from the stack's base to the TOP branch.
+
+Example:
+  stack diff
+  stack diff HEAD~1
+  stack diff --stat`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVar(&c.Stat, "stat", false, "Show a diffstat summary instead of the patch")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	var output string
+	if c.Ref == "" {
+		if c.Stat {
+			output, err = c.Stack.GetStackDiffStat(stackCtx)
+		} else {
+			output, err = c.Stack.GetStackDiff(stackCtx)
+		}
+	} else {
+		change, findErr := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+		if findErr != nil {
+			return findErr
+		}
+		if c.Stat {
+			output, err = c.Stack.GetChangeDiffStat(stackCtx, change.UUID)
+		} else {
+			output, err = c.Stack.GetChangeDiff(stackCtx, change.UUID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	ui.Printf("%s", output)
+	return nil
+}
@@ -0,0 +1,177 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command diagnoses (and optionally repairs) inconsistencies in the current stack's metadata
+type Command struct {
+	// Flags
+	Fix bool
+
+	// Clients
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the current stack for inconsistent metadata",
+		Long: `Check the current stack for metadata that no longer matches git.
+
+Checks:
+  - Recorded commit hashes that went stale after rebasing outside the tool
+  - Multiple changes referencing the same PR number
+  - Changes (or their PRs) not based on the previous change in the stack
+  - Commits missing their PR-UUID or PR-Stack trailers
+  - Commits sharing a PR-UUID trailer
+  - PR metadata for changes that are no longer in the stack
+  - UUID branches that don't point at their change's commit
+  - A base ref that isn't an ancestor of the TOP branch
+  - PRs merged out of order
+
+Run with --fix to repair the problems that were found. Trailer, branch, base ref and
+merge order issues are only reported; 'stack repair' fixes shared PR-UUIDs and UUID
+branches, so doctor exits with an error while any of them are reported as errors.
+
+Example:
+  stack doctor
+  stack doctor --fix`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVar(&c.Fix, "fix", false, "Repair the problems that were found")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackName, err := c.Stack.CurrentStackName()
+	if err != nil {
+		return err
+	}
+	if stackName == "" {
+		return fmt.Errorf("not on a stack branch. Use 'stack switch' to switch to a stack.")
+	}
+
+	// Doctor has to load the stacks that a normal load rejects, since it's what repairs them
+	stackCtx, err := c.Stack.GetStackContextForRepair(stackName)
+	if err != nil {
+		return err
+	}
+
+	issues, err := c.Stack.ValidateStackIntegrity(stackCtx.StackName)
+	if err != nil {
+		return err
+	}
+	// Errors are only reported, never fixed, so doctor fails once it's done if there were any
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == stack.IntegrityError {
+			errorCount++
+			ui.Error(issue.Message)
+		} else {
+			ui.Warning(issue.Message)
+		}
+	}
+	if len(stackCtx.DuplicateUUIDs) > 0 {
+		errorCount++
+		ui.Error((&stack.DuplicateUUIDError{Duplicates: stackCtx.DuplicateUUIDs}).Error())
+	}
+	checkErrors := func() error {
+		if errorCount > 0 {
+			return fmt.Errorf("found %d error(s) that 'stack doctor' can't fix", errorCount)
+		}
+		return nil
+	}
+
+	problems := 0
+
+	dupErr := c.Stack.ValidatePRNumbersUnique(stackCtx)
+	if dupErr != nil {
+		problems++
+		ui.Warning(dupErr.Error())
+	}
+
+	chainErr := c.Stack.VerifyDesiredBaseChain(stackCtx)
+	if chainErr != nil {
+		problems++
+		ui.Warning(chainErr.Error())
+	}
+
+	drift, err := c.Stack.FindHashDrift(stackCtx)
+	if err != nil {
+		return err
+	}
+	if len(drift) > 0 {
+		problems++
+		ui.Warningf("%d change(s) have stale recorded commit hashes:", len(drift))
+		for _, d := range drift {
+			ui.Printf("  %s %s (now at %s)\n", ui.Dim(fmt.Sprintf("#%d", d.Change.Position)), d.Change.Title, git.ShortHash(d.Actual))
+		}
+	}
+
+	if problems == 0 {
+		if len(issues) == 0 && len(stackCtx.DuplicateUUIDs) == 0 {
+			ui.Success("No problems found")
+		}
+		return checkErrors()
+	}
+
+	if !c.Fix {
+		ui.Println("")
+		ui.Info("Run 'stack doctor --fix' to repair")
+		return checkErrors()
+	}
+
+	ui.Println("")
+
+	if dupErr != nil {
+		cleared, err := c.Stack.FixDuplicatePRNumbers(stackCtx)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Cleared %d duplicate PR reference(s)", cleared)
+		if err := c.Stack.ValidatePRNumbersUnique(stackCtx); err != nil {
+			ui.Warning("Some duplicates could not be resolved automatically: no single change matches the PR's head commit")
+		}
+	}
+
+	if chainErr != nil {
+		fixed, err := c.Stack.FixDesiredBaseChain(stackCtx)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Re-pointed %d change base(s)", fixed)
+	}
+
+	if len(drift) > 0 {
+		count, err := c.Stack.ReconcileChangeHashes(stackCtx)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Corrected %d commit hash(es)", count)
+	}
+
+	return checkErrors()
+}
@@ -0,0 +1,44 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestDoctor(t *testing.T) {
+	setup := func(t *testing.T) *Command {
+		ghClient := &gh.MockGithubClient{}
+		ghClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		gitClient := testutil.NewTestGitClient(t)
+		stackClient := stack.NewTestStackWithClients(t, ghClient, gitClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+		return &Command{Git: gitClient, Stack: stackClient}
+	}
+
+	t.Run("HealthyStack", func(t *testing.T) {
+		cmd := setup(t)
+		require.NoError(t, cmd.Run(t.Context()))
+	})
+
+	t.Run("ErrorsFailWithoutOtherProblems", func(t *testing.T) {
+		cmd := setup(t)
+		// A commit without trailers is only reported, so nothing else counts as a problem
+		testutil.CreateCommitWithTrailers(t, cmd.Git, "Untracked change", "", nil)
+
+		err := cmd.Run(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found 1 error(s)")
+	})
+}
@@ -67,22 +67,17 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Sync metadata with GitHub (read-only, no git operations)
-	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to sync with GitHub: %w", err)
 	}
 
-	// Validate stack has active changes
-	if len(stackCtx.ActiveChanges) == 0 {
-		return fmt.Errorf("no active changes in stack: all changes are merged")
-	}
-
-	currentChange := stackCtx.FindChange(stackCtx.ChangeID())
-	if currentChange == nil {
-		return fmt.Errorf("current change is not a valid change in the stack")
+	targetChange, atBoundary, err := c.Stack.NavigateRelative(stackCtx, -1)
+	if err != nil {
+		return err
 	}
 
-	if currentChange.ActivePosition == 1 {
+	if atBoundary && targetChange.UUID == stackCtx.ChangeID() {
 		if len(stackCtx.ActiveChanges) == 1 {
 			ui.Warning("Only 1 active change in stack")
 		} else {
@@ -91,19 +86,13 @@ func (c *Command) Run(ctx context.Context) error {
 		return nil
 	}
 
-	currentActivePosition := currentChange.ActivePosition
-
-	// Calculate target position (move down by 1)
-	targetPosition := currentActivePosition - 1
-	targetChange := stackCtx.ActiveChanges[targetPosition-1]
-
 	// Validate UUID exists
 	if targetChange.UUID == "" {
-		return fmt.Errorf("cannot navigate to change #%d: commit missing PR-UUID trailer", targetPosition)
+		return fmt.Errorf("cannot navigate to change #%d: commit missing PR-UUID trailer", targetChange.ActivePosition)
 	}
 
 	// Checkout UUID branch for editing
-	_, err = c.Stack.CheckoutChangeForEditing(stackCtx, targetChange)
+	branch, err := c.Stack.CheckoutChangeForEditing(stackCtx, targetChange, stack.EditOptions{})
 	if err != nil {
 		return err
 	}
@@ -123,6 +112,7 @@ func (c *Command) Run(ctx context.Context) error {
 		Changes:     stackCtx.AllChanges,
 		CurrentUUID: targetChange.UUID,
 		IsEditing:   true,
+		OnTop:       branch == stackCtx.Stack.Branch,
 	}))
 
 	return nil
@@ -107,7 +107,7 @@ func TestDown(t *testing.T) {
 				// Move to bottom first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -207,7 +207,7 @@ func TestDown(t *testing.T) {
 				// Move to middle change (position 2)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -251,7 +251,7 @@ func TestDown(t *testing.T) {
 				// Move to third change (position 3)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[2])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[2], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -9,12 +9,17 @@ import (
 	"github.com/bjulian5/stack/internal/common"
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
 	"github.com/bjulian5/stack/internal/stack"
 	"github.com/bjulian5/stack/internal/ui"
 )
 
 // Command edits a change in the stack
 type Command struct {
+	// Arguments
+	Ref        string
+	StayOnUUID bool // Stay on the UUID branch even for the topmost change
+
 	// Clients (can be mocked in tests)
 	Git   *git.Client
 	Stack *stack.Client
@@ -23,53 +28,69 @@ type Command struct {
 
 func (c *Command) Register(parent *cobra.Command) {
 	command := &cobra.Command{
-		Use:   "edit",
+		Use:   "edit [ref]",
 		Short: "Edit a change in the stack",
 		Long: `Interactively select a change to edit using a fuzzy finder.
 
 Creates a UUID branch at the selected commit, allowing you to make changes.
 Use 'git commit --amend' to update the change, or create a new commit to insert after it.
 
+A git ref (HEAD, HEAD~2, a branch name or a commit hash) can be given instead of
+using the fuzzy finder. It is resolved to the change containing that commit.
+
+Editing the topmost change checks out the TOP branch so new commits extend the stack.
+Use --uuid-branch to stay on the change's UUID branch and amend it in isolation.
+
 Example:
-  stack edit`,
-		Args: cobra.NoArgs,
+  stack edit
+  stack edit HEAD~1
+  stack edit --uuid-branch HEAD`,
+		Args: cobra.MaximumNArgs(1),
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
 			c.Git, c.GH, c.Stack, err = common.InitClients()
 			return err
 		},
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
 			return c.Run(cobraCmd.Context())
 		},
 	}
 
+	command.Flags().BoolVar(&c.StayOnUUID, "uuid-branch", false, "Stay on the change's UUID branch even if it is the topmost change")
+
 	parent.AddCommand(command)
 }
 
 // Run executes the command
 func (c *Command) Run(ctx context.Context) error {
+	// Get current stack context
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
 	// Check for uncommitted changes before switching branches
-	hasUncommitted, err := c.Git.HasUncommittedChanges()
+	workTree, err := c.Stack.DescribeWorkingTree(stackCtx)
 	if err != nil {
 		return fmt.Errorf("failed to check working directory: %w", err)
 	}
-	if hasUncommitted {
+	if workTree.Dirty {
+		if description := workTree.Describe(); description != "" {
+			ui.Warning(description)
+		}
 		return fmt.Errorf("uncommitted changes detected: commit or stash your changes before editing a different change")
 	}
 
-	// Get current stack context
-	stackCtx, err := c.Stack.GetStackContext()
-	if err != nil {
-		return fmt.Errorf("failed to get stack context: %w", err)
-	}
-
 	// Validate we're in a stack
 	if !stackCtx.IsStack() {
 		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
 	}
 
 	// Sync metadata with GitHub (read-only, no git operations)
-	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to sync with GitHub: %w", err)
 	}
@@ -79,14 +100,22 @@ func (c *Command) Run(ctx context.Context) error {
 		return fmt.Errorf("no active changes to edit: all changes are merged")
 	}
 
-	// Use fuzzy finder to select a change
-	selectedChange, err := ui.SelectChange(stackCtx.ActiveChanges)
-	if err != nil {
-		return err
-	}
-	if selectedChange == nil {
-		// User cancelled
-		return nil
+	var selectedChange *model.Change
+	if c.Ref != "" {
+		selectedChange, err = c.Stack.FindChangeByRef(stackCtx, c.Ref)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Use fuzzy finder to select a change
+		selectedChange, err = ui.SelectChange(stackCtx.ActiveChanges)
+		if err != nil {
+			return err
+		}
+		if selectedChange == nil {
+			// User cancelled
+			return nil
+		}
 	}
 
 	// Error if trying to edit a merged change
@@ -103,7 +132,7 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Checkout UUID branch for editing
-	branchName, err := c.Stack.CheckoutChangeForEditing(stackCtx, selectedChange)
+	branchName, err := c.Stack.CheckoutChangeForEditing(stackCtx, selectedChange, stack.EditOptions{StayOnUUIDBranch: c.StayOnUUID})
 	if err != nil {
 		return err
 	}
@@ -115,6 +144,7 @@ func (c *Command) Run(ctx context.Context) error {
 		Changes:     stackCtx.AllChanges,
 		CurrentUUID: selectedChange.UUID,
 		IsEditing:   true,
+		OnTop:       branchName == stackCtx.Stack.Branch,
 	}))
 	return nil
 }
@@ -77,7 +77,7 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Sync metadata with GitHub (read-only, no git operations)
-	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to sync with GitHub: %w", err)
 	}
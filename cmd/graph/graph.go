@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command prints a stack's dependency graph as Mermaid or DOT
+type Command struct {
+	// Arguments
+	StackName string
+
+	// Flags
+	Format string
+	Exact  bool
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "graph [stack-name]",
+		Short: "Print the stack's dependency graph as Mermaid or DOT",
+		Long: `Print a stack's dependency graph to stdout, from the base branch up to the top
+change. Each change is labeled with its PR number and title and colored by status
+(merged, open, draft, closed or local).
+
+Mermaid output can be pasted into GitHub markdown inside a mermaid code block; DOT
+output can be rendered with Graphviz.
+
+If no stack name is provided, prints the current stack.
+
+Example:
+  stack graph
+  stack graph auth-refactor --format dot | dot -Tsvg > stack.svg`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				c.StackName = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().StringVar(&c.Format, "format", "mermaid", "Output format: mermaid or dot")
+	command.Flags().BoolVar(&c.Exact, "exact", false, "Require an exact (case-sensitive) stack name match")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	var render func(s *model.Stack, changes []*model.Change) string
+	switch c.Format {
+	case "mermaid":
+		render = ui.RenderStackMermaid
+	case "dot":
+		render = ui.RenderStackDOT
+	default:
+		return fmt.Errorf("unknown format '%s': use mermaid or dot", c.Format)
+	}
+
+	var stackCtx *stack.StackContext
+	var err error
+
+	if c.StackName == "" {
+		stackCtx, err = c.Stack.GetStackContext()
+		if err != nil || !stackCtx.IsStack() {
+			return fmt.Errorf("not on a stack branch: use 'stack graph <name>'")
+		}
+	} else {
+		stackName, err := c.Stack.ResolveStackName(c.StackName, c.Exact)
+		if err != nil {
+			return err
+		}
+		stackCtx, err = c.Stack.GetStackContextByName(stackName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if stackCtx.Stack == nil {
+		return fmt.Errorf("stack '%s' does not exist", stackCtx.StackName)
+	}
+
+	ui.Printf("%s", render(stackCtx.Stack, stackCtx.AllChanges))
+	return nil
+}
@@ -1,6 +1,9 @@
 package hook
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 
 	"github.com/bjulian5/stack/internal/gh"
@@ -24,13 +27,23 @@ func (c *Command) Register(parent *cobra.Command) {
 		return
 	}
 	ghClient := gh.NewClient()
-	c.Stack = stack.NewClient(c.Git, ghClient)
+	c.Stack, err = stack.NewClient(c.Git, ghClient)
+	// A stack client error is a broken config (e.g. an invalid STACK_USER) that the user has
+	// to fix, so the hooks report it when they run instead of being skipped without a word
+	clientErr := err
 
 	cmd := &cobra.Command{
 		Use:    "hook",
 		Short:  "Git hook commands (internal use)",
 		Long:   `Hook commands are called by git hooks and should not be run directly by users.`,
 		Hidden: true, // Hide from normal help output
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if clientErr != nil {
+				fmt.Fprintf(os.Stderr, "Error: stack hook: %v\n", clientErr)
+				return clientErr
+			}
+			return nil
+		},
 	}
 
 	// Register subcommands
@@ -53,6 +53,16 @@ func (c *PostCommitCommand) Run() error {
 		return nil
 	}
 
+	// 'stack insert' places its commits itself once the insert is finished
+	if c.Stack.InsertInProgress(ctx.StackName) {
+		return nil
+	}
+
+	// Neither does 'stack split', which turns the commits into changes on --continue
+	if c.Stack.SplitInProgress(ctx.StackName) {
+		return nil
+	}
+
 	// If not editing (i.e., on TOP branch), check if this is an amend
 	if !ctx.OnUUIDBranch() {
 		// Get the HEAD commit to check if it's an amend
@@ -0,0 +1,124 @@
+package insert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command inserts new changes in the middle of a stack
+type Command struct {
+	// Arguments
+	Ref string
+
+	// Flags
+	Continue bool
+	Abort    bool
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "insert [ref]",
+		Short: "Insert new changes above a change in the middle of the stack",
+		Long: `Insert new changes directly above an existing change.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash) and
+defaults to HEAD. Its branch is checked out so your next commits land on top of it.
+Make one or more commits, then run 'stack insert --continue' to add them to the
+stack, or 'stack insert --abort' to drop them.
+
+Each new commit becomes a new change that gets a PR on the next 'stack push'. The
+changes that were above the insertion point are rebased on top and keep their PRs.
+
+Example:
+  stack insert HEAD~2
+  git commit -m "Add validation helper"
+  stack insert --continue`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			c.Ref = "HEAD"
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVar(&c.Continue, "continue", false, "Add the commits made since 'stack insert' to the stack")
+	command.Flags().BoolVar(&c.Abort, "abort", false, "Abandon an insert and return to the stack")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	if c.Continue && c.Abort {
+		return fmt.Errorf("--continue and --abort cannot be used together")
+	}
+
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	if c.Abort {
+		if err := c.Stack.AbortInsert(stackCtx.StackName); err != nil {
+			return err
+		}
+		ui.Success("Insert aborted")
+		return nil
+	}
+
+	if c.Continue {
+		if err := c.Stack.FinalizeInsert(stackCtx.StackName); err != nil {
+			return err
+		}
+
+		stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+		if err != nil {
+			return fmt.Errorf("failed to reload stack context: %w", err)
+		}
+
+		ui.Print(ui.RenderNavigationSuccess(ui.NavigationSuccess{
+			Message: "Inserted the new changes into the stack",
+			Stack:   stackCtx.Stack,
+			Changes: stackCtx.AllChanges,
+		}))
+		return nil
+	}
+
+	change, err := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	branch, err := c.Stack.PrepareInsertAt(stackCtx, change.UUID)
+	if err != nil {
+		return err
+	}
+
+	ui.Infof("Checked out %s", branch)
+	ui.Infof("Commit the changes to insert above '%s', then run 'stack insert --continue' (or 'stack insert --abort')", change.Title)
+	return nil
+}
@@ -3,6 +3,7 @@ package list
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -16,6 +17,7 @@ import (
 
 type Command struct {
 	Table bool
+	JSON  bool
 	Git   *git.Client
 	Stack *stack.Client
 	GH    *gh.Client
@@ -32,7 +34,8 @@ The current stack is marked with an asterisk (*).
 
 Example:
   stack list
-  stack list --table`,
+  stack list --table
+  stack list --json`,
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
 			c.Git, c.GH, c.Stack, err = common.InitClients()
@@ -44,6 +47,7 @@ Example:
 	}
 
 	command.Flags().BoolVar(&c.Table, "table", false, "Display as table instead of tree")
+	command.Flags().BoolVar(&c.JSON, "json", false, "Print machine-readable JSON without styling")
 
 	parent.AddCommand(command)
 }
@@ -64,23 +68,48 @@ func (c *Command) Run(ctx context.Context) error {
 	for _, s := range stacks {
 		ctx, err := c.Stack.GetStackContextByName(s.Name)
 		if err != nil {
-			ui.Warningf("failed to load stack %s: %v", s.Name, err)
+			c.warnf("failed to load stack %s: %v", s.Name, err)
 			continue
 		}
 
 		if s.Name == currentStack {
-			ctx, err = c.Stack.MaybeRefreshStackMetadata(ctx)
+			refreshed, err := c.Stack.MaybeRefreshStackMetadata(ctx)
 			if err != nil {
-				ui.Warningf("failed to refresh stack %s: %v", s.Name, err)
+				c.warnf("failed to refresh stack %s: %v", s.Name, err)
+			} else {
+				ctx = refreshed
 			}
 		}
 
 		stackChanges[s.Name] = ctx.AllChanges
 	}
 
+	if c.JSON {
+		output, err := ui.RenderStackListJSON(stacks, stackChanges, currentStack)
+		if err != nil {
+			return err
+		}
+		ui.Print(output)
+		return nil
+	}
+
 	var output string
 	if c.Table {
-		output = ui.RenderStackListTable(stacks, stackChanges, currentStack)
+		needsRefresh := make(map[string]bool)
+		statuses, err := c.Stack.CheckSyncStatusAll()
+		if err != nil {
+			ui.Warningf("failed to check sync status: %v", err)
+		}
+		for name, status := range statuses {
+			needsRefresh[name] = status.NeedsSync
+		}
+		behind := make(map[string]int)
+		for _, s := range stacks {
+			if _, count, err := c.Stack.IsBaseBehindUpstream(s.Name); err == nil {
+				behind[s.Name] = count
+			}
+		}
+		output = ui.RenderStackListTable(stacks, stackChanges, currentStack, needsRefresh, behind)
 	} else {
 		output = ui.RenderStackList(stacks, currentStack, stackChanges)
 	}
@@ -88,3 +117,12 @@ func (c *Command) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// warnf prints a warning, or writes it to stderr with --json so stdout stays valid JSON
+func (c *Command) warnf(format string, args ...any) {
+	if c.JSON {
+		fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+		return
+	}
+	ui.Warningf(format, args...)
+}
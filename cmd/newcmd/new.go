@@ -18,7 +18,11 @@ type Command struct {
 	StackName string
 
 	// Flags
-	BaseBranch string
+	BaseBranch  string
+	Template    string
+	HoldReady   bool
+	Description string
+	From        string
 
 	// Clients (can be mocked in tests)
 	Git   *git.Client
@@ -32,14 +36,39 @@ func (c *Command) Register(parent *cobra.Command) {
 		Long: `Create a new stack for managing a set of stacked pull requests.
 
 This will:
-  1. Create a new branch (username/stack-<name>/TOP) from the current HEAD
+  1. Create a new branch (username/stack-<name>/TOP) at the base (default: the current HEAD)
   2. Store stack metadata in .git/stack/<name>/
   3. Set this as the current stack
   4. Checkout the stack branch
 
+The base can be any revision: a branch, a tag, a commit hash or HEAD~2. A branch or
+tag is recorded by name; other revisions are recorded as the commit's short hash.
+PRs can only target branches, so restack a stack based on a tag or commit onto a
+branch ('stack restack --onto <branch>') before pushing.
+
+With --template, the stack is scaffolded with an empty placeholder commit for each
+change listed in .git/stack/templates/<name>.json, e.g.:
+
+  {"changes": [{"title": "Add feature"}, {"title": "Add tests"}, {"title": "Add docs"}]}
+
+With --hold-ready, every PR is created as a draft until 'stack pr ready --all' marks the
+whole stack ready at once. Set "hold_ready_new_stacks": true in .git/stack/config.json to
+make this the default for new stacks.
+
+--description sets a one-line summary shown by 'stack status' and 'stack list'.
+
+With --from, an existing feature branch is adopted: its commits on top of --base become the
+stack's changes, each stamped with PR-UUID and PR-Stack trailers. The branch must be a linear
+series of commits on --base; it is left untouched, so delete it once you've checked the stack.
+
 Example:
   stack new auth-refactor
-  stack new feature-x --base develop`,
+  stack new feature-x --base develop
+  stack new hotfix --base v1.2.0
+  stack new feature-y --template feature
+  stack new feature-z --hold-ready
+  stack new auth --description "Auth refactor epic"
+  stack new login --from my-login-branch --base main`,
 		Args: cobra.ExactArgs(1),
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -52,7 +81,11 @@ Example:
 		},
 	}
 
-	command.Flags().StringVar(&c.BaseBranch, "base", "", "Base branch for the stack (default: current branch)")
+	command.Flags().StringVar(&c.BaseBranch, "base", "", "Base branch, tag or commit for the stack (default: current branch, or the current commit when detached)")
+	command.Flags().StringVar(&c.Template, "template", "", "Scaffold the stack from .git/stack/templates/<name>.json")
+	command.Flags().BoolVar(&c.HoldReady, "hold-ready", false, "Create all PRs as drafts until 'stack pr ready --all'")
+	command.Flags().StringVar(&c.Description, "description", "", "One-line summary of the stack")
+	command.Flags().StringVar(&c.From, "from", "", "Adopt the commits of an existing branch as the stack's changes (requires --base)")
 	parent.AddCommand(command)
 }
 
@@ -68,20 +101,52 @@ func (c *Command) Run(ctx context.Context) error {
 		return fmt.Errorf("stack is not installed in this repository\n\nRun 'stack install' first to set up hooks and configuration")
 	}
 
+	if c.From != "" {
+		if c.BaseBranch == "" {
+			return fmt.Errorf("--from requires --base to tell which commits belong to the branch")
+		}
+		if c.Template != "" {
+			return fmt.Errorf("--from and --template cannot be used together")
+		}
+	}
+
+	// Without --base, stack on the current branch, or on the current commit when HEAD is
+	// detached ("HEAD" resolves to the current branch when there is one)
 	baseBranch := c.BaseBranch
 	if baseBranch == "" {
-		baseBranch, err = c.Git.GetCurrentBranch()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
-		}
+		baseBranch = "HEAD"
 	}
 
 	// Create the stack
-	s, err := c.Stack.CreateStack(c.StackName, baseBranch)
+	switch {
+	case c.From != "":
+		_, err = c.Stack.AdoptBranch(c.From, c.StackName, baseBranch)
+	case c.Template != "":
+		err = c.Stack.CreateStackFromTemplate(c.StackName, baseBranch, c.Template)
+	default:
+		_, err = c.Stack.CreateStack(c.StackName, baseBranch)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create stack: %w", err)
 	}
 
+	if c.Description != "" {
+		if err := c.Stack.SetStackDescription(c.StackName, c.Description); err != nil {
+			return err
+		}
+	}
+
+	s, err := c.Stack.LoadStack(c.StackName)
+	if err != nil {
+		return err
+	}
+
+	if c.HoldReady && !s.HoldReady {
+		if err := c.Stack.SetHoldReady(s, true); err != nil {
+			return err
+		}
+	}
+
 	// Switch to the new stack
 	if err := c.Stack.SwitchStack(c.StackName); err != nil {
 		return fmt.Errorf("failed to switch to stack: %w", err)
@@ -91,7 +156,19 @@ func (c *Command) Run(ctx context.Context) error {
 	ui.Successf("Created stack '%s'", s.Name)
 	ui.Successf("Branch: %s", s.Branch)
 	ui.Successf("Base: %s", s.Base)
+	if s.Description != "" {
+		ui.Successf("Description: %s", s.Description)
+	}
 	ui.Success("Switched to stack branch")
+	if c.Template != "" {
+		ui.Infof("Scaffolded changes from template '%s' - use 'stack edit' to fill them in", c.Template)
+	}
+	if c.From != "" {
+		ui.Infof("Adopted the commits of '%s' - the branch itself was left as is", c.From)
+	}
+	if s.HoldReady {
+		ui.Info("PRs will be created as drafts until 'stack pr ready --all'")
+	}
 
 	return nil
 }
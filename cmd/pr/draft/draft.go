@@ -76,20 +76,24 @@ func (c *Command) Run(ctx context.Context) error {
 		changesToMark = []*model.Change{currentChange}
 	}
 
-	hasUnpushedChanges := false
+	var targets []*model.Change
 	for _, change := range changesToMark {
 		if change.UUID == "" {
 			ui.Warningf("Skipping change without UUID: %s", change.Title)
 			continue
 		}
+		targets = append(targets, change)
+	}
 
-		result, err := c.Stack.MarkChangeDraft(stackCtx, change)
-		if err != nil {
-			return fmt.Errorf("failed to mark change %s as draft: %w", change.Title, err)
-		}
+	results, err := c.Stack.MarkChangesDraft(stackCtx, targets)
+	if err != nil {
+		return fmt.Errorf("failed to mark changes as draft: %w", err)
+	}
 
-		if result.SyncedToGitHub {
-			ui.Successf("✓ Marked as draft on GitHub: %s (PR #%d)", change.Title, result.PRNumber)
+	hasUnpushedChanges := false
+	for i, change := range targets {
+		if results[i].SyncedToGitHub {
+			ui.Successf("✓ Marked as draft on GitHub: %s (PR #%d)", change.Title, results[i].PRNumber)
 		} else {
 			ui.Successf("✓ Marked as draft locally: %s", change.Title)
 			hasUnpushedChanges = true
@@ -0,0 +1,120 @@
+package merge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command merges the PRs at the bottom of the stack that are ready to merge
+type Command struct {
+	// Flags
+	Method string // squash, merge or rebase
+	DryRun bool   // Show which PRs would be merged without merging them
+	Yes    bool   // Skip the confirmation
+
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge the ready PRs at the bottom of the stack",
+		Long: `Merge the PRs at the bottom of the stack that are ready, in order.
+
+Starting from the bottom, PRs are merged while each one is open (not a draft),
+approved, and passing its checks. The first PR that isn't ready stops the run, so
+the stack is always merged bottom-up. Each PR after the first is retargeted at the
+stack's base before it is merged.
+
+Run 'stack refresh' afterwards to drop the merged commits from the stack.
+
+Example:
+  stack pr merge                   # Squash merge the ready PRs
+  stack pr merge --method rebase   # Rebase merge instead
+  stack pr merge --dry-run         # Show which PRs would be merged`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().StringVar(&c.Method, "method", "squash", fmt.Sprintf("Merge method (%s)", strings.Join(gh.MergeMethods, ", ")))
+	command.Flags().BoolVar(&c.DryRun, "dry-run", false, "Show which PRs would be merged without merging them")
+	command.Flags().BoolVarP(&c.Yes, "yes", "y", false, "Skip the confirmation")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return err
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch. Use 'stack switch' to switch to a stack.")
+	}
+
+	if _, err := c.Stack.SyncPRMetadata(stackCtx, nil, stack.RefreshOptions{}); err != nil {
+		return fmt.Errorf("failed to sync with GitHub: %w", err)
+	}
+
+	ui.Info("Checking which PRs are ready to merge...")
+	run, err := c.Stack.GetMergeableRun(stackCtx)
+	if err != nil {
+		return err
+	}
+	if len(run) == 0 {
+		ui.Info("No PRs are ready to merge: the bottom PR must be open, approved and passing its checks")
+		return nil
+	}
+
+	ui.Println("")
+	for _, change := range run {
+		ui.Printf("  #%d %s\n", change.PR.PRNumber, change.Title)
+	}
+	ui.Println("")
+
+	if c.DryRun {
+		ui.Infof("Would %s merge %d PR(s)", c.Method, len(run))
+		return nil
+	}
+
+	if !c.Yes {
+		prompt := fmt.Sprintf("%s merge %d PR(s) into %s? Type 'y' to continue: ", c.Method, len(run), stackCtx.Stack.Base)
+		if !ui.Confirm(prompt, "y") {
+			ui.Info("Merge cancelled")
+			return nil
+		}
+	}
+
+	// The run is checked again before merging, in case a PR changed while confirming
+	merged, err := c.Stack.MergeReadyChanges(stackCtx, c.Method)
+	for _, change := range merged {
+		ui.Successf("Merged #%d %s", change.PR.PRNumber, change.Title)
+	}
+	if err != nil {
+		return err
+	}
+
+	ui.Println("")
+	ui.Info("Run 'stack refresh' to drop the merged commits from the stack")
+	return nil
+}
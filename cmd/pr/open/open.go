@@ -16,6 +16,8 @@ import (
 
 type Command struct {
 	UseSelect bool
+	All       bool
+	Active    bool
 
 	Git   *git.Client
 	Stack *stack.Client
@@ -28,7 +30,8 @@ func (c *Command) Register(parent *cobra.Command) {
 		Short: "Open a PR in the browser",
 		Long: `Opens the PR for the current change in your browser.
 
-Use --select to interactively choose which PR to open.`,
+Use --select to interactively choose which PR to open, or --all to open every PR in the
+stack in its own tab (--active skips merged PRs). More than 8 tabs asks for confirmation.`,
 		Args: cobra.NoArgs,
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -41,6 +44,9 @@ Use --select to interactively choose which PR to open.`,
 	}
 
 	command.Flags().BoolVarP(&c.UseSelect, "select", "s", false, "Interactively select which PR to open")
+	command.Flags().BoolVar(&c.All, "all", false, "Open every PR in the stack")
+	command.Flags().BoolVar(&c.Active, "active", false, "Open every unmerged PR in the stack")
+	command.MarkFlagsMutuallyExclusive("select", "all", "active")
 
 	parent.AddCommand(command)
 }
@@ -55,6 +61,10 @@ func (c *Command) Run(ctx context.Context) error {
 		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
 	}
 
+	if c.All || c.Active {
+		return c.openStack(stackCtx)
+	}
+
 	var selectedChange *model.Change
 
 	if c.UseSelect {
@@ -100,3 +110,20 @@ func (c *Command) Run(ctx context.Context) error {
 
 	return nil
 }
+
+// openStack opens every PR in the stack, asking before opening a lot of tabs
+func (c *Command) openStack(stackCtx *stack.StackContext) error {
+	opened, err := c.Stack.OpenStack(stackCtx, c.Active, func(count int) bool {
+		return ui.Confirm(fmt.Sprintf("Open %d PRs in the browser? Type 'y' to continue: ", count), "y")
+	})
+	if err != nil {
+		return err
+	}
+	if len(opened) == 0 {
+		ui.Info("Cancelled.")
+		return nil
+	}
+
+	ui.Successf("Opening %d PR(s) from stack '%s'", len(opened), stackCtx.StackName)
+	return nil
+}
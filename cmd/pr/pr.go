@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bjulian5/stack/cmd/pr/draft"
+	"github.com/bjulian5/stack/cmd/pr/merge"
 	"github.com/bjulian5/stack/cmd/pr/open"
 	"github.com/bjulian5/stack/cmd/pr/ready"
 )
@@ -27,5 +28,8 @@ func (c *Command) Register(parent *cobra.Command) {
 	draftCmd := &draft.Command{}
 	draftCmd.Register(cmd)
 
+	mergeCmd := &merge.Command{}
+	mergeCmd.Register(cmd)
+
 	parent.AddCommand(cmd)
 }
@@ -30,6 +30,9 @@ When on a UUID branch: marks the current change as ready
 When on TOP branch: marks the top change as ready
 Use --all to mark all changes in the stack as ready
 
+If the stack was created with --hold-ready, only --all is allowed: it releases the
+hold and marks the whole stack ready together.
+
 If the PR already exists on GitHub, it will be marked as ready immediately.
 Otherwise, the ready/draft state is stored locally and applied during 'stack push'.
 
@@ -65,6 +68,16 @@ func (c *Command) Run(ctx context.Context) error {
 		return fmt.Errorf("no changes in stack")
 	}
 
+	if stackCtx.Stack.HoldReady {
+		if !c.All {
+			return fmt.Errorf("stack '%s' is holding PRs as drafts until the whole stack is ready: use 'stack pr ready --all'", stackCtx.StackName)
+		}
+		if err := c.Stack.SetHoldReady(stackCtx.Stack, false); err != nil {
+			return err
+		}
+		ui.Info("Released draft hold on stack")
+	}
+
 	var changesToMark []*model.Change
 	if c.All {
 		changesToMark = stackCtx.ActiveChanges
@@ -76,20 +89,24 @@ func (c *Command) Run(ctx context.Context) error {
 		changesToMark = []*model.Change{currentChange}
 	}
 
-	hasUnpushedChanges := false
+	var targets []*model.Change
 	for _, change := range changesToMark {
 		if change.UUID == "" {
 			ui.Warningf("Skipping change without UUID: %s", change.Title)
 			continue
 		}
+		targets = append(targets, change)
+	}
 
-		result, err := c.Stack.MarkChangeReady(stackCtx, change)
-		if err != nil {
-			return fmt.Errorf("failed to mark change %s as ready: %w", change.Title, err)
-		}
+	results, err := c.Stack.MarkChangesReady(stackCtx, targets)
+	if err != nil {
+		return fmt.Errorf("failed to mark changes as ready: %w", err)
+	}
 
-		if result.SyncedToGitHub {
-			ui.Successf("Marked as ready on GitHub: %s (PR #%d)", change.Title, result.PRNumber)
+	hasUnpushedChanges := false
+	for i, change := range targets {
+		if results[i].SyncedToGitHub {
+			ui.Successf("Marked as ready on GitHub: %s (PR #%d)", change.Title, results[i].PRNumber)
 		} else {
 			ui.Successf("Marked as ready locally: %s", change.Title)
 			hasUnpushedChanges = true
@@ -9,7 +9,7 @@ import (
 	"github.com/bjulian5/stack/internal/common"
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/git"
-	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/hooks"
 	"github.com/bjulian5/stack/internal/stack"
 	"github.com/bjulian5/stack/internal/ui"
 )
@@ -17,8 +17,10 @@ import (
 // Command pushes PRs to GitHub
 type Command struct {
 	// Flags
-	DryRun bool // Show what would happen without actually doing it
-	Force  bool // Force push all PRs (bypass diff check) and update visualizations
+	DryRun  bool // Show what would happen without actually doing it
+	Force   bool // Force push all PRs (bypass diff check) and update visualizations
+	Checks  bool // Include each PR's CI check status in the visualizations
+	Reviews bool // Fetch each PR's review decision and show it in the visualizations
 
 	Git   *git.Client
 	Stack *stack.Client
@@ -38,10 +40,20 @@ change a PR's state before pushing.
 By default, stack uses a diff-based approach and skips PRs that haven't changed.
 Use --force to bypass the diff check and push all PRs regardless.
 
+PR branches are force pushed with a lease on the last pushed commit, so a push fails
+instead of overwriting the branch if someone else updated it on the remote.
+
+Use --checks to add a CI checks column to the stack visualization comments. This
+queries GitHub once per PR, so it is off by default. --reviews likewise fetches each
+PR's review decision and marks it next to the PR status (👍 approved, ✋ changes
+requested, 👀 review required).
+
 Example:
   stack push              # Push all PRs (respects local draft/ready state)
   stack push --dry-run    # Show what would happen
-  stack push --force      # Force push all PRs even if unchanged`,
+  stack push --force      # Force push all PRs even if unchanged
+  stack push --checks     # Include CI check status in visualizations
+  stack push --reviews    # Include review decisions in visualizations`,
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
 			c.Git, c.GH, c.Stack, err = common.InitClients()
@@ -54,58 +66,12 @@ Example:
 
 	command.Flags().BoolVar(&c.DryRun, "dry-run", false, "Show what would happen without pushing")
 	command.Flags().BoolVar(&c.Force, "force", false, "Force push all PRs even if unchanged (bypass diff check)")
+	command.Flags().BoolVar(&c.Checks, "checks", false, "Show each PR's CI check status in the stack visualizations")
+	command.Flags().BoolVar(&c.Reviews, "reviews", false, "Show each PR's review decision in the stack visualizations")
 
 	parent.AddCommand(command)
 }
 
-// pushPR pushes a single PR to GitHub and returns PR number, URL, and whether it was newly created
-func (c *Command) pushPR(
-	stackCtx *stack.StackContext,
-	stackName string,
-	change model.Change,
-	prBranch string,
-	existingPRNumber int,
-) (prNumber int, url string, isNew bool, err error) {
-	if err := c.Git.UpdateRef(prBranch, change.CommitHash); err != nil {
-		return 0, "", false, fmt.Errorf("failed to update branch %s: %w", prBranch, err)
-	}
-
-	if err := c.Git.Push(prBranch, true); err != nil {
-		return 0, "", false, fmt.Errorf("failed to push branch %s: %w", prBranch, err)
-	}
-
-	spec := gh.PRSpec{
-		Number: existingPRNumber,
-		Title:  change.Title,
-		Body:   change.Description,
-		Base:   change.DesiredBase,
-		Head:   prBranch,
-		Draft:  change.GetDraftStatus(),
-	}
-
-	ghPR, err := c.GH.SyncPR(spec)
-	if err != nil {
-		return 0, "", false, fmt.Errorf("failed to sync PR for %s: %w", change.Title, err)
-	}
-
-	// Find the change in stackCtx to update it (since change is passed by value)
-	changeInCtx := stackCtx.FindChange(change.UUID)
-	if changeInCtx == nil {
-		return 0, "", false, fmt.Errorf("change %s not found in stack context", change.UUID)
-	}
-
-	// Update the change with the GitHub response
-	changeInCtx.UpdateFromPush(ghPR, prBranch)
-	changeInCtx.UpdateTitle(spec.Title, spec.Body, spec.Base)
-
-	// Persist to disk
-	if err := stackCtx.Save(); err != nil {
-		return 0, "", false, fmt.Errorf("failed to save stack context: %w", err)
-	}
-
-	return ghPR.Number, ghPR.URL, existingPRNumber == 0, nil
-}
-
 // Run executes the command
 func (c *Command) Run(ctx context.Context) error {
 	// Get stack context
@@ -132,7 +98,12 @@ func (c *Command) Run(ctx context.Context) error {
 		return nil
 	}
 
-	res, err := c.Stack.SyncPRMetadata(stackCtx)
+	// The bottom PR targets the stack's base, and GitHub PRs can only target branches
+	if !c.Git.IsLocalBranch(stackCtx.Stack.Base) {
+		return fmt.Errorf("stack base '%s' is not a branch, so the bottom PR has nothing to target: run 'stack restack --onto <branch>' first", stackCtx.Stack.Base)
+	}
+
+	res, err := c.Stack.SyncPRMetadata(stackCtx, nil, stack.RefreshOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to sync with GitHub: %w", err)
 	}
@@ -147,108 +118,74 @@ func (c *Command) Run(ctx context.Context) error {
 	if c.DryRun {
 		ui.Info("Dry run mode - no changes will be made")
 		ui.Println("")
-	}
-
-	var created, updated, skipped int
-
-	for _, change := range stackCtx.ActiveChanges {
-		prBranch := stackCtx.FormatUUIDBranch(change.UUID)
-
-		existingPRNumber := 0
-		var existingPR *model.PR
-
-		if change.PR != nil {
-			existingPRNumber = change.PR.PRNumber
-			existingPR = change.PR
-		}
-
-		if c.DryRun {
-			if existingPRNumber > 0 {
-				ui.Printf("Would update PR #%d: %s\n", existingPRNumber, change.Title)
+		for _, change := range stackCtx.ActiveChanges {
+			if change.PR != nil && change.PR.PRNumber > 0 {
+				ui.Printf("Would update PR #%d: %s\n", change.PR.PRNumber, change.Title)
 			} else {
 				ui.Printf("Would create PR: %s\n", change.Title)
 			}
-			continue
 		}
-
-		// Skip PRs that are closed on GitHub (not merged)
-		// GitHub doesn't allow updating the base branch of closed PRs
-		if existingPR != nil && existingPR.State == "closed" {
-			skipped++
-			ui.Print(ui.RenderPushProgress(ui.PushProgress{
-				Position: change.Position,
-				Total:    len(stackCtx.AllChanges),
-				Title:    change.Title,
-				PRNumber: existingPR.PRNumber,
-				URL:      existingPR.URL,
-				Action:   "skipped",
-				Reason:   "PR is closed on GitHub - reopen it or remove the commit from the stack",
-			}))
-			continue
-		}
-
-		var updateReason string
-		if existingPR != nil && !c.Force {
-			syncStatus := change.NeedsSyncToGitHub()
-
-			if !syncStatus.NeedsSync {
-				skipped++
-				ui.Print(ui.RenderPushProgress(ui.PushProgress{
-					Position: change.Position,
-					Total:    len(stackCtx.AllChanges),
-					Title:    change.Title,
-					PRNumber: existingPR.PRNumber,
-					URL:      existingPR.URL,
-					Action:   "skipped",
-				}))
-				continue
-			}
-
-			updateReason = syncStatus.Reason
-		}
-
-		prNumber, prURL, isNew, err := c.pushPR(stackCtx, stackCtx.StackName, *change, prBranch, existingPRNumber)
-		if err != nil {
-			return err
-		}
-
-		var action string
-		if isNew {
-			created++
-			action = "created"
-		} else {
-			updated++
-			action = "updated"
-		}
-
-		ui.Print(ui.RenderPushProgress(ui.PushProgress{
-			Position: change.Position,
-			Total:    len(stackCtx.AllChanges),
-			Title:    change.Title,
-			PRNumber: prNumber,
-			URL:      prURL,
-			Action:   action,
-			Reason:   updateReason,
-		}))
+		return nil
 	}
 
-	if c.DryRun {
-		return nil
+	result, err := c.Stack.PushStack(stackCtx, stack.PushOptions{Force: c.Force}, renderPushProgress)
+	if err != nil {
+		return err
 	}
 
-	ui.Print(ui.RenderPushSummary(created, updated, skipped))
+	ui.Print(ui.RenderPushSummary(result.Created, result.Updated, result.Skipped))
+
+	if stackCtx.Stack.HoldReady {
+		ui.Info("Stack is holding PRs as drafts - run 'stack pr ready --all' once the stack is complete")
+	}
 
-	if created > 0 || updated > 0 || c.Force {
+	if (result.Created > 0 || result.Updated > 0 || c.Force) && c.Stack.VisualizationCommentsEnabled() {
 		ui.Println("")
 		ui.Info("Updating stack visualizations...")
 
+		if c.Reviews {
+			if _, err := c.Stack.GetPRReviewStatus(stackCtx); err != nil {
+				return fmt.Errorf("failed to get review status: %w", err)
+			}
+		}
+
 		// stackCtx is already fresh after all pushPR calls saved their updates
-		if err := c.Stack.SyncVisualizationComments(stackCtx); err != nil {
+		syncVisualizations := c.Stack.SyncVisualizationComments
+		if c.Checks {
+			syncVisualizations = c.Stack.SyncVisualizationCommentsWithChecks
+		}
+		if err := syncVisualizations(stackCtx); err != nil {
 			return fmt.Errorf("failed to sync visualization comments: %w", err)
 		}
 
 		ui.Success("Stack visualizations updated")
 	}
 
+	pushed := []hooks.PRPayload{} // PRs created or updated, passed to the post-push hook
+	for _, p := range result.Pushed {
+		pushed = append(pushed, hooks.PRPayload{Number: p.PRNumber, URL: p.URL, UUID: p.Change.UUID, Title: p.Change.Title, Action: p.Action})
+	}
+	payload := hooks.Payload{Stack: stackCtx.StackName, Base: stackCtx.Stack.Base, PRs: pushed}
+	runner := hooks.NewRunner(c.Stack.UserHooksDir(), stackCtx.StackName, stackCtx.Stack.Base)
+	if err := runner.RunHook(hooks.PostPush, payload); err != nil {
+		ui.Warningf("%v", err)
+	}
+
 	return nil
 }
+
+// renderPushProgress prints a line for every PR that push finishes with
+func renderPushProgress(event stack.ProgressEvent) {
+	if event.Phase != stack.ProgressFinish {
+		return
+	}
+	ui.Print(ui.RenderPushProgress(ui.PushProgress{
+		Position: event.Position,
+		Total:    event.Total,
+		Title:    event.Change.Title,
+		PRNumber: event.PRNumber,
+		URL:      event.URL,
+		Action:   event.Action,
+		Reason:   event.Reason,
+	}))
+}
@@ -2,22 +2,31 @@ package refresh
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/spf13/cobra"
 
 	"github.com/bjulian5/stack/internal/common"
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/hooks"
+	"github.com/bjulian5/stack/internal/model"
 	"github.com/bjulian5/stack/internal/stack"
 	"github.com/bjulian5/stack/internal/ui"
 )
 
 // Command refreshes the stack by syncing with GitHub to detect merged PRs
 type Command struct {
-	Git   *git.Client
-	Stack *stack.Client
-	GH    *gh.Client
+	All             bool
+	Yes             bool
+	AutoStash       bool
+	Retarget        bool
+	AllowOutOfOrder bool
+	Git             *git.Client
+	Stack           *stack.Client
+	GH              *gh.Client
 }
 
 func (c *Command) Register(parent *cobra.Command) {
@@ -29,13 +38,40 @@ func (c *Command) Register(parent *cobra.Command) {
 This command:
   1. Fetches from remote
   2. Queries GitHub for each PR's merge status
-  3. Validates bottom-up merging (errors if out-of-order)
+  3. Validates bottom-up merging (errors if out-of-order, unless --allow-out-of-order)
   4. Saves merged changes to stack metadata
   5. Rebases remaining commits on the latest base branch
   6. Cleans up merged PR branches
 
+PRs that were closed without merging are listed, and you are asked which of
+their commits to drop from the stack.
+
+PRs whose base branch was changed on GitHub (e.g. retargeted in the web UI) are
+reported; the next 'stack push' points them back. Use --retarget to fix them
+during the refresh.
+
+Before merged commits are dropped from the TOP branch you are asked to confirm.
+Use --yes to skip the confirmation. When stdin isn't a terminal (CI, scripts) nothing
+is asked: merged commits are dropped and closed changes are kept.
+
+A PR merged above one that is still open (e.g. a hotfix merged from the middle of
+the stack) stops the refresh. With --allow-out-of-order it is recorded as merged
+with a warning instead, and you reconcile the stack by hand.
+
+Refresh needs a clean working tree. With --autostash, uncommitted changes are stashed
+before the rebase and reapplied afterwards, like git's rebase.autoStash.
+
+With --all, the PR metadata of every stack is synced with GitHub and a summary of
+merged and remaining PRs per stack is printed. Nothing is rebased; run 'stack refresh'
+on a stack to drop its merged commits.
+
 Example:
-  stack refresh`,
+  stack refresh
+  stack refresh --yes
+  stack refresh --autostash
+  stack refresh --retarget
+  stack refresh --allow-out-of-order
+  stack refresh --all`,
 		Args: cobra.NoArgs,
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -47,11 +83,25 @@ Example:
 		},
 	}
 
+	command.Flags().BoolVar(&c.All, "all", false, "Sync the PR metadata of every stack without rebasing")
+	command.Flags().BoolVarP(&c.Yes, "yes", "y", false, "Skip the confirmation before dropping merged commits (implied when stdin isn't a terminal)")
+	command.Flags().BoolVar(&c.AutoStash, "autostash", false, "Stash uncommitted changes before rebasing and reapply them afterwards")
+	command.Flags().BoolVar(&c.Retarget, "retarget", false, "Point PRs whose base was changed on GitHub back at their stack base")
+	command.Flags().BoolVar(&c.AllowOutOfOrder, "allow-out-of-order", false, "Record PRs merged out of order as merged with a warning instead of failing")
 	parent.AddCommand(command)
 }
 
+// refreshOptions returns the options for syncing PR metadata with GitHub
+func (c *Command) refreshOptions() stack.RefreshOptions {
+	return stack.RefreshOptions{AllowOutOfOrderMerges: c.AllowOutOfOrder}
+}
+
 // Run executes the command
 func (c *Command) Run(ctx context.Context) error {
+	if c.All {
+		return c.refreshAll()
+	}
+
 	// Get current stack context
 	stackCtx, err := c.Stack.GetStackContext()
 	if err != nil {
@@ -67,8 +117,8 @@ func (c *Command) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if hasChanges {
-		return fmt.Errorf("you have uncommitted changes. Commit or stash them before refreshing.")
+	if hasChanges && !c.AutoStash {
+		return fmt.Errorf("you have uncommitted changes. Commit or stash them before refreshing, or use --autostash.")
 	}
 
 	// If no active changes, nothing to refresh
@@ -79,34 +129,283 @@ func (c *Command) Run(ctx context.Context) error {
 
 	// Sync metadata with GitHub
 	ui.Info("Checking PR merge status on GitHub...")
-	result, err := c.Stack.SyncPRMetadata(stackCtx)
+	result, err := c.Stack.SyncPRMetadata(stackCtx, nil, c.refreshOptions())
 	if err != nil {
 		return err
 	}
 
-	// Display results if no merges
-	if result.StaleMergedCount == 0 {
-		ui.Success("No merged PRs found. Stack is up to date.")
-		return nil
+	if len(result.BaseDriftChanges) > 0 {
+		if err := c.handleBaseDrift(stackCtx, result); err != nil {
+			return err
+		}
+	}
+
+	if len(result.EditedChanges) > 0 {
+		ui.Println("")
+		ui.Warningf("%d PR(s) were edited on GitHub since the last push:", len(result.EditedChanges))
+		for _, change := range result.EditedChanges {
+			ui.Printf("  #%d %s %s\n", change.PR.PRNumber, change.Title, ui.Dim("("+editedFields(change)+")"))
+		}
+		ui.Info("'stack push' replaces these edits with the commit messages; use 'stack reword' to keep them")
+	}
+
+	if result.ClosedCount > 0 {
+		dropped, err := c.handleClosedChanges(stackCtx, result.ClosedChanges)
+		if err != nil {
+			return err
+		}
+		if dropped {
+			// Commits were rewritten - reload so the merged changes point at the new commits
+			stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+			if err != nil {
+				return fmt.Errorf("failed to reload stack context: %w", err)
+			}
+		}
+	}
+
+	inBase, err := c.Stack.ChangesInBase(stackCtx)
+	if err != nil {
+		ui.Warningf("could not check for changes already in %s: %v", stackCtx.Stack.Base, err)
+	} else if len(inBase) > 0 {
+		dropped, err := c.handleInBaseChanges(stackCtx, inBase)
+		if err != nil {
+			return err
+		}
+		if dropped {
+			stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+			if err != nil {
+				return fmt.Errorf("failed to reload stack context: %w", err)
+			}
+		}
 	}
 
-	if err := c.Stack.ApplyRefresh(stackCtx, result.StaleMergedChanges); err != nil {
+	rewrite, merged, err := c.Stack.RefreshWouldModifyHistory(stackCtx)
+	if err != nil {
 		return err
 	}
 
+	// Display results if no merges
+	if !rewrite {
+		ui.Success("No merged PRs found. Stack is up to date.")
+		c.runPostRefreshHook(stackCtx, nil)
+		return nil
+	}
+
 	// Display what was merged in table format
 	ui.Println("")
-	ui.Print(ui.RenderTitlef("Merged PRs (%d detected)", result.StaleMergedCount))
-	ui.Print(ui.RenderMergedPRsTable(result.StaleMergedChanges))
+	ui.Print(ui.RenderTitlef("Merged PRs (%d detected)", len(merged)))
+	ui.Print(ui.RenderMergedPRsTable(merged))
+
+	// Without a terminal to answer the prompt (CI, scripts), merged commits are dropped as
+	// if --yes was passed
+	if !c.Yes && ui.IsInteractive() {
+		ui.Println("")
+		prompt := fmt.Sprintf("Drop %d merged commit(s) and rebase the stack? Type 'y' to continue: ", len(merged))
+		if !ui.Confirm(prompt, "y") {
+			ui.Info("Refresh cancelled. PR metadata was updated; the stack branch is unchanged.")
+			return nil
+		}
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "refresh"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.ApplyRefresh(stackCtx, merged, c.AutoStash); err != nil {
+		var conflict *stack.ErrRebaseConflict
+		if errors.As(err, &conflict) {
+			ui.Println(conflict.Help())
+			return fmt.Errorf("refresh stopped on rebase conflicts: %w", conflict.Err)
+		}
+		return err
+	}
 
 	// Display summary
 	ui.Println("")
-	ui.Successf("Stack refreshed: %d merged, %d remaining", result.StaleMergedCount, result.RemainingCount)
+	ui.Successf("Stack refreshed: %d merged, %d remaining", len(merged), result.RemainingCount)
 
 	if result.RemainingCount > 0 {
 		ui.Println("")
 		ui.Info("Run 'stack push' to update PR base branches on GitHub")
 	}
 
+	c.runPostRefreshHook(stackCtx, merged)
+	return nil
+}
+
+// runPostRefreshHook runs the user's post-refresh hook with the PRs that were merged. A
+// failing hook is reported but doesn't fail the refresh.
+func (c *Command) runPostRefreshHook(stackCtx *stack.StackContext, merged []*model.Change) {
+	payload := hooks.Payload{Stack: stackCtx.StackName, Base: stackCtx.Stack.Base, PRs: make([]hooks.PRPayload, 0, len(merged))}
+	for _, change := range merged {
+		payload.PRs = append(payload.PRs, hooks.PRPayload{
+			Number: change.PR.PRNumber,
+			URL:    change.PR.URL,
+			UUID:   change.UUID,
+			Title:  change.Title,
+			Action: "merged",
+		})
+	}
+
+	runner := hooks.NewRunner(c.Stack.UserHooksDir(), stackCtx.StackName, stackCtx.Stack.Base)
+	if err := runner.RunHook(hooks.PostRefresh, payload); err != nil {
+		ui.Warningf("%v", err)
+	}
+}
+
+// editedFields describes which parts of a change's PR were edited on GitHub
+func editedFields(change *model.Change) string {
+	switch {
+	case change.HasTitleDrift() && change.HasBodyDrift():
+		return "title and description"
+	case change.HasTitleDrift():
+		return "title"
+	default:
+		return "description"
+	}
+}
+
+// handleBaseDrift lists the PRs whose base on GitHub differs from the one the stack wants and,
+// with --retarget, points them back. Retargeting waits for 'stack push' when merged commits are
+// about to be dropped, since that changes the bases again.
+func (c *Command) handleBaseDrift(stackCtx *stack.StackContext, result *stack.RefreshResult) error {
+	ui.Println("")
+	ui.Warningf("%d PR(s) have a different base on GitHub:", len(result.BaseDriftChanges))
+	for _, change := range result.BaseDriftChanges {
+		ui.Printf("  #%d %s %s\n", change.PR.PRNumber, change.Title,
+			ui.Dim(fmt.Sprintf("(targets %s, expected %s)", change.PR.RemoteBase, change.DesiredBase)))
+	}
+
+	if !c.Retarget {
+		ui.Info("Run 'stack push' or 'stack refresh --retarget' to point them back")
+		return nil
+	}
+	if result.StaleMergedCount > 0 {
+		ui.Info("Merged PRs change the stack's bases; run 'stack push' after the refresh to retarget them")
+		return nil
+	}
+
+	fixed, err := c.Stack.FixDesiredBaseChain(stackCtx)
+	if err != nil {
+		return err
+	}
+	ui.Successf("Retargeted %d PR(s)", fixed)
+	return nil
+}
+
+// handleClosedChanges lists the changes whose PRs were closed without merging and asks
+// which of their commits to drop from the stack. Returns whether any were dropped.
+func (c *Command) handleClosedChanges(stackCtx *stack.StackContext, closed []*model.Change) (bool, error) {
+	ui.Println("")
+	ui.Warningf("%d PR(s) were closed on GitHub without merging:", len(closed))
+	for i, change := range closed {
+		ui.Printf("  %d. #%d %s\n", i+1, change.PR.PRNumber, change.Title)
+	}
+	ui.Println("")
+
+	if !ui.IsInteractive() {
+		ui.Info("Keeping closed changes - run 'stack refresh' in a terminal to choose which to drop")
+		return false, nil
+	}
+	selected := ui.PromptSelection("Drop which commits from the stack? [all/none/1,2,...] (default none): ", len(closed))
+	if len(selected) == 0 {
+		ui.Info("Keeping closed changes - reopen their PRs or drop the commits later")
+		return false, nil
+	}
+
+	toDrop := make([]*model.Change, len(selected))
+	for i, idx := range selected {
+		toDrop[i] = closed[idx]
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "drop"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.DropChanges(stackCtx, toDrop); err != nil {
+		return false, fmt.Errorf("failed to drop closed changes: %w", err)
+	}
+	ui.Successf("Dropped %d closed change(s) from the stack", len(toDrop))
+	return true, nil
+}
+
+// handleInBaseChanges lists the changes whose patch is already in the base branch although
+// their PR isn't merged, and asks which of their commits to drop. Returns whether any were dropped.
+func (c *Command) handleInBaseChanges(stackCtx *stack.StackContext, inBase []*model.Change) (bool, error) {
+	ui.Println("")
+	ui.Warningf("%d change(s) are already in %s without a merged PR:", len(inBase), stackCtx.Stack.Base)
+	for i, change := range inBase {
+		pr := ui.Dim("(no PR)")
+		if !change.IsLocal() {
+			pr = fmt.Sprintf("#%d", change.PR.PRNumber)
+		}
+		ui.Printf("  %d. %s %s\n", i+1, pr, change.Title)
+	}
+	ui.Println("")
+
+	if !ui.IsInteractive() {
+		ui.Info("Keeping them - run 'stack refresh' in a terminal to choose which to drop")
+		return false, nil
+	}
+	selected := ui.PromptSelection("Drop which commits from the stack? [all/none/1,2,...] (default none): ", len(inBase))
+	if len(selected) == 0 {
+		ui.Info("Keeping them - drop the commits later if they're no longer needed")
+		return false, nil
+	}
+
+	toDrop := make([]*model.Change, len(selected))
+	for i, idx := range selected {
+		toDrop[i] = inBase[idx]
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "drop"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.DropChanges(stackCtx, toDrop); err != nil {
+		return false, fmt.Errorf("failed to drop changes already in %s: %w", stackCtx.Stack.Base, err)
+	}
+	for _, change := range toDrop {
+		if !change.IsLocal() {
+			ui.Warningf("PR #%d for '%s' is no longer part of the stack - close it on GitHub", change.PR.PRNumber, change.Title)
+		}
+	}
+	ui.Successf("Dropped %d change(s) already in %s", len(toDrop), stackCtx.Stack.Base)
+	return true, nil
+}
+
+// refreshAll syncs every stack's PR metadata and prints what's merged and left per stack
+func (c *Command) refreshAll() error {
+	ui.Info("Checking PR status on GitHub for all stacks...")
+	results, err := c.Stack.RefreshAll(c.refreshOptions())
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	if len(names) > 0 {
+		rows := make([][]string, len(names))
+		for i, name := range names {
+			result := results[name]
+			rows[i] = []string{
+				name,
+				fmt.Sprintf("%d", result.StaleMergedCount),
+				fmt.Sprintf("%d", result.ClosedCount),
+				fmt.Sprintf("%d", result.RemainingCount),
+			}
+		}
+		t := ui.NewStackTable().
+			Headers("STACK", "NEWLY MERGED", "CLOSED", "REMAINING").
+			Rows(rows...)
+		ui.Print("\n" + t.String() + "\n")
+	}
+
+	if err != nil {
+		return fmt.Errorf("some stacks failed to sync:\n%w", err)
+	}
+	if len(names) == 0 {
+		ui.Info("No stacks to refresh.")
+		return nil
+	}
+	ui.Successf("Synced %d stack(s). Run 'stack refresh' on a stack to drop its merged commits.", len(names))
 	return nil
 }
@@ -0,0 +1,81 @@
+package rename
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command renames a stack
+type Command struct {
+	// Arguments
+	OldName string
+	NewName string
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "rename [old-name] <new-name>",
+		Short: "Rename a stack",
+		Long: `Rename a stack along with its TOP and UUID branches.
+
+With a single argument, the current stack is renamed. PR metadata is kept.
+Existing commits are not rewritten: their PR-Stack trailers keep the old name,
+which is remembered so those commits still belong to the stack.
+
+Stacks with open PRs cannot be renamed, since GitHub can't change a PR's head branch.
+
+Example:
+  stack rename auth-v2
+  stack rename auth-refactor auth-v2`,
+		Args: cobra.RangeArgs(1, 2),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) == 2 {
+				c.OldName = args[0]
+			}
+			c.NewName = args[len(args)-1]
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	oldName := c.OldName
+	if oldName == "" {
+		stackCtx, err := c.Stack.GetStackContext()
+		if err != nil {
+			return fmt.Errorf("failed to get stack context: %w", err)
+		}
+		if !stackCtx.IsStack() {
+			return fmt.Errorf("not on a stack branch. Specify stack name: stack rename <old-name> <new-name>")
+		}
+		oldName = stackCtx.StackName
+	}
+
+	if err := c.Stack.RenameStack(oldName, c.NewName); err != nil {
+		return fmt.Errorf("failed to rename stack: %w", err)
+	}
+
+	ui.Successf("Renamed stack '%s' to '%s'", oldName, c.NewName)
+	return nil
+}
@@ -0,0 +1,101 @@
+package reorder
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command moves a change to a new position within the stack
+type Command struct {
+	// Arguments
+	Ref      string
+	Position int
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "reorder <ref> <position>",
+		Short: "Move a change to a new position in the stack",
+		Long: `Move a change up or down within the stack.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash) and
+the position counts active changes from the bottom of the stack, starting at 1.
+The TOP branch is rebuilt by cherry-picking its commits in the new order, and the
+UUID branches are moved to match.
+
+Changes cannot be moved across merged changes that are still on the TOP branch.
+If a cherry-pick conflicts, resolve it and run 'stack restack --recover'.
+
+Example:
+  stack reorder HEAD 1
+  stack reorder HEAD~2 3`,
+		Args: cobra.ExactArgs(2),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			c.Ref = args[0]
+			position, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid position %q: must be a number", args[1])
+			}
+			c.Position = position
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	change, err := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "reorder"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.ReorderChange(stackCtx, change.UUID, c.Position); err != nil {
+		return err
+	}
+
+	stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to reload stack context: %w", err)
+	}
+
+	ui.Print(ui.RenderNavigationSuccess(ui.NavigationSuccess{
+		Message:     fmt.Sprintf("Moved '%s' to position %d", change.Title, c.Position),
+		Stack:       stackCtx.Stack,
+		Changes:     stackCtx.AllChanges,
+		CurrentUUID: change.UUID,
+	}))
+	return nil
+}
@@ -0,0 +1,126 @@
+package repair
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command resyncs a stack's UUID branches with its TOP branch
+type Command struct {
+	// Arguments
+	StackName string
+
+	// Flags
+	Exact bool
+
+	// Clients
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "repair [stack-name]",
+		Short: "Resync a stack's UUID branches with its commits",
+		Long: `Resync the UUID branches of a stack after manual git surgery.
+
+Creates missing UUID branches for active changes, moves branches that point at the
+wrong commit, and deletes branches whose change is no longer in the stack.
+
+The TOP branch and its commits are only touched when several commits carry the same
+PR-UUID trailer (e.g. after a botched cherry-pick). One of them keeps it, preferring
+the commit its PR was pushed from, and the others get new PR-UUIDs and become new
+changes. This needs the TOP branch checked out and no uncommitted changes.
+
+If no stack name is provided, repairs the current stack.
+
+Example:
+  stack repair
+  stack repair auth-refactor`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				c.StackName = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVar(&c.Exact, "exact", false, "Require an exact (case-sensitive) stack name match")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackName := c.StackName
+	if stackName == "" {
+		// The current stack may not load normally, which is what this repairs
+		current, err := c.Stack.CurrentStackName()
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return fmt.Errorf("not on a stack branch: use 'stack repair <name>'")
+		}
+		stackName = current
+	} else {
+		resolved, err := c.Stack.ResolveStackName(stackName, c.Exact)
+		if err != nil {
+			return err
+		}
+		stackName = resolved
+	}
+
+	report, err := c.Stack.RepairStack(stackName)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range report.Reassigned {
+		ui.Printf("  gave '%s' PR-UUID %s (shared %s)\n", r.Title, r.NewUUID, r.OldUUID)
+	}
+	if len(report.Reassigned) > 0 {
+		ui.Successf("Gave %d commit(s) a new PR-UUID", len(report.Reassigned))
+	}
+
+	if len(report.Actions) == 0 {
+		ui.Success("UUID branches are in sync")
+		return nil
+	}
+
+	for _, action := range report.Actions {
+		switch action.Action {
+		case "created":
+			ui.Printf("  created %s at %s\n", action.Branch, git.ShortHash(action.To))
+		case "moved":
+			ui.Printf("  moved   %s from %s to %s\n", action.Branch, git.ShortHash(action.From), git.ShortHash(action.To))
+		case "deleted":
+			ui.Printf("  deleted %s (was at %s)\n", action.Branch, git.ShortHash(action.From))
+		case "skipped":
+			ui.Printf("  skipped %s: %s\n", action.Branch, action.Reason)
+		}
+	}
+
+	ui.Println("")
+	ui.Successf("Repaired stack '%s': %d created, %d moved, %d deleted",
+		stackName, report.Count("created"), report.Count("moved"), report.Count("deleted"))
+	if skipped := report.Count("skipped"); skipped > 0 {
+		ui.Warningf("%d branch(es) skipped", skipped)
+	}
+	return nil
+}
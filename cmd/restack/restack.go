@@ -2,6 +2,7 @@ package restack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -18,10 +19,13 @@ type Command struct {
 	Stack *stack.Client
 
 	// Flags
-	Fetch   bool
-	Onto    string
-	Recover bool
-	Retry   bool
+	Fetch     bool
+	Onto      string
+	Recover   bool
+	Retry     bool
+	Continue  bool
+	Abort     bool
+	AutoStash bool
 }
 
 func (c *Command) Register(parent *cobra.Command) {
@@ -37,6 +41,16 @@ changes before pushing your stack.
 Use --onto to move your stack to a different base branch (e.g., from main to develop).
 When using --onto, fetching is NOT automatic - add --fetch if needed.
 
+If the rebase stops on conflicts, resolve them, 'git add' the files and run
+'stack restack --continue' to finish the rebase and update the stack's base.
+
+Use --abort to give up on a rebase that stopped on conflicts: the rebase is aborted
+and the stack's TOP branch is reset to where it was before the operation.
+
+Restack needs a clean working tree. With --autostash, uncommitted changes are stashed
+before the rebase and reapplied when the restack finishes (including after --continue
+or --abort), like git's rebase.autoStash.
+
 Use --recover to complete a rebase after resolving conflicts or to recover from an
 aborted rebase. Use --recover --retry to automatically retry a failed rebase.
 
@@ -50,10 +64,15 @@ Examples:
   # Fetch first, then move to different base
   stack restack --onto develop --fetch
 
-  # After resolving rebase conflicts
+  # Restack without committing work in progress
+  stack restack --autostash
+
+  # After resolving restack conflicts
   git add resolved-file.txt
-  git rebase --continue
-  stack restack --recover
+  stack restack --continue
+
+  # Give up on a conflicting rebase
+  stack restack --abort
 
   # After aborting a rebase, retry it
   git rebase --abort
@@ -73,11 +92,24 @@ Examples:
 	command.Flags().StringVar(&c.Onto, "onto", "", "Rebase stack onto a different base branch")
 	command.Flags().BoolVar(&c.Recover, "recover", false, "Recover from a failed or aborted rebase")
 	command.Flags().BoolVar(&c.Retry, "retry", false, "Retry the rebase (only valid with --recover)")
+	command.Flags().BoolVar(&c.Continue, "continue", false, "Finish a restack that stopped on conflicts")
+	command.Flags().BoolVar(&c.Abort, "abort", false, "Abort a rebase that stopped on conflicts and restore the stack")
+	command.Flags().BoolVar(&c.AutoStash, "autostash", false, "Stash uncommitted changes before rebasing and reapply them afterwards")
 
 	parent.AddCommand(command)
 }
 
 func (c *Command) Run(ctx context.Context) error {
+	if c.Continue && c.Abort {
+		return fmt.Errorf("--continue and --abort cannot be used together")
+	}
+	if c.Continue {
+		return c.runContinue()
+	}
+	if c.Abort {
+		return c.runAbort()
+	}
+
 	// Handle recovery mode
 	if c.Recover {
 		return c.runRecover()
@@ -106,8 +138,8 @@ func (c *Command) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if hasChanges {
-		return fmt.Errorf("you have uncommitted changes. Commit or stash them before restacking.")
+	if hasChanges && !c.AutoStash {
+		return fmt.Errorf("you have uncommitted changes. Commit or stash them before restacking, or use --autostash.")
 	}
 
 	// Determine target base: use --onto if specified, otherwise current base
@@ -120,22 +152,83 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	ui.Info("Checking PR merge status on GitHub...")
-	if _, err := c.Stack.SyncPRMetadata(stackCtx); err != nil {
+	if _, err := c.Stack.SyncPRMetadata(stackCtx, nil, stack.RefreshOptions{}); err != nil {
 		return fmt.Errorf("failed to sync PR metadata: %w", err)
 	}
 
 	opts := stack.RestackOptions{
-		Onto:  targetBase,
-		Fetch: fetch,
+		Onto:      targetBase,
+		Fetch:     fetch,
+		AutoStash: c.AutoStash,
+	}
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "restack"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
 	}
 	if err := c.Stack.Restack(stackCtx, opts); err != nil {
-		return err
+		return conflictHelp(err)
 	}
 
 	ui.Successf("Restacked on %s", targetBase)
 	return nil
 }
 
+func (c *Command) runContinue() error {
+	// HEAD is usually detached mid-rebase, so find the stack from the saved restack state
+	stacks, err := c.Stack.ListStacks()
+	if err != nil {
+		return err
+	}
+	var stackName string
+	for _, s := range stacks {
+		if !c.Stack.HasRebaseState(s.Name) {
+			continue
+		}
+		state, err := c.Stack.LoadRebaseState(s.Name)
+		if err == nil && state.TargetBase != "" {
+			stackName = s.Name
+			break
+		}
+	}
+	if stackName == "" {
+		return fmt.Errorf("no restack in progress")
+	}
+
+	if err := c.Stack.ContinueRestack(stackName); err != nil {
+		return conflictHelp(err)
+	}
+
+	restacked, err := c.Stack.LoadStack(stackName)
+	if err != nil {
+		return err
+	}
+	ui.Successf("Restacked on %s", restacked.Base)
+	return nil
+}
+
+func (c *Command) runAbort() error {
+	// HEAD is usually detached mid-rebase, so find the stack from its saved rebase state
+	stacks, err := c.Stack.ListStacks()
+	if err != nil {
+		return err
+	}
+	var stackName string
+	for _, s := range stacks {
+		if c.Stack.HasRebaseState(s.Name) {
+			stackName = s.Name
+			break
+		}
+	}
+	if stackName == "" {
+		return fmt.Errorf("no stack operation to abort")
+	}
+
+	if err := c.Stack.AbortStackOperation(stackName); err != nil {
+		return err
+	}
+	ui.Successf("Aborted; stack '%s' is back where it started", stackName)
+	return nil
+}
+
 func (c *Command) runRecover() error {
 	// Check if rebase is still in progress
 	if c.Git.IsRebaseInProgress() {
@@ -172,6 +265,15 @@ func (c *Command) runRecover() error {
 		return fmt.Errorf("failed to load rebase state: %w", err)
 	}
 
+	// A restack that stopped on conflicts is finished the same way as with --continue
+	if rebaseState.TargetBase != "" {
+		if err := c.Stack.ContinueRestack(stackName); err != nil {
+			return err
+		}
+		ui.Successf("Restacked on %s", rebaseState.TargetBase)
+		return nil
+	}
+
 	// Determine what scenario we're in based on current state
 	currentBranch, err := c.Git.GetCurrentBranch()
 	if err != nil {
@@ -180,7 +282,7 @@ func (c *Command) runRecover() error {
 	}
 
 	// We're on a branch - check if it's the stack branch
-	if !stack.IsStackBranch(currentBranch) {
+	if !c.Stack.IsStackBranch(currentBranch) {
 		return fmt.Errorf("not on stack branch (on %s)", currentBranch)
 	}
 
@@ -348,3 +450,14 @@ func (c *Command) updateUUIDBranches(stackName string) error {
 
 	return nil
 }
+
+// conflictHelp prints the conflict help panel when err is a rebase conflict, and returns a
+// short error in place of the conflict's full instructions
+func conflictHelp(err error) error {
+	var conflict *stack.ErrRebaseConflict
+	if !errors.As(err, &conflict) {
+		return err
+	}
+	ui.Println(conflict.Help())
+	return fmt.Errorf("restack stopped on rebase conflicts: %w", conflict.Err)
+}
@@ -0,0 +1,103 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command restores a deleted stack from its archived metadata
+type Command struct {
+	// Arguments
+	Name string
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "restore [archive-name | stack-name]",
+		Short: "Restore a deleted stack",
+		Long: `Restore a stack deleted with 'stack delete' from its archived metadata.
+
+Deleted stacks are archived in .git/stack/.archived/<name>-<timestamp>. Pass the
+archive name, or a stack name to restore its most recent archive. With no
+arguments, the archived stacks are listed.
+
+The stack's PR mapping is restored. If its TOP branch was deleted, it is
+recreated at the stack's base; the stack's commits are not recovered.
+
+Example:
+  stack restore
+  stack restore auth-refactor
+  stack restore auth-refactor-20250101-120000`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				c.Name = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	archived, err := c.Stack.ListArchivedStacks()
+	if err != nil {
+		return err
+	}
+
+	if c.Name == "" {
+		if len(archived) == 0 {
+			ui.Info("No archived stacks.")
+			return nil
+		}
+		ui.Print(ui.RenderTitle("Archived stacks"))
+		for _, a := range archived {
+			ui.Printf("  %s  %s\n", ui.Bold(a.ArchiveName), ui.Dim("deleted "+a.ArchivedAt.Format("2006-01-02 15:04")))
+		}
+		ui.Println("")
+		ui.Info("Run 'stack restore <archive-name>' to restore one")
+		return nil
+	}
+
+	archiveName := ""
+	for _, a := range archived {
+		if a.ArchiveName == c.Name {
+			archiveName = a.ArchiveName
+			break
+		}
+		// Archives are sorted newest first, so the first match is the latest
+		if archiveName == "" && a.StackName == c.Name {
+			archiveName = a.ArchiveName
+		}
+	}
+	if archiveName == "" {
+		return fmt.Errorf("no archived stack named '%s'. Run 'stack restore' to list archived stacks", c.Name)
+	}
+
+	if err := c.Stack.RestoreStack(archiveName); err != nil {
+		return fmt.Errorf("failed to restore stack: %w", err)
+	}
+
+	ui.Successf("Restored stack from %s", archiveName)
+	return nil
+}
@@ -0,0 +1,110 @@
+package reword
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command rewrites a change's commit message without checking the change out
+type Command struct {
+	// Arguments
+	Ref string
+
+	// Flags
+	Title   string
+	Body    string
+	SetBody bool // Whether --body was given; otherwise the existing description is kept
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "reword [ref]",
+		Short: "Change a change's commit title and description",
+		Long: `Rewrite the commit message of a change without checking it out.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash) and
+defaults to HEAD. The commit keeps its files and its PR-UUID and PR-Stack trailers,
+and later commits are rebased onto it. Run 'stack push' to update the PR.
+
+Without --body the existing description is kept.
+
+Example:
+  stack reword HEAD~1 --title "Add token refresh"
+  stack reword HEAD --title "Fix login" --body "Handles expired sessions"`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			c.Ref = "HEAD"
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
+			c.SetBody = cobraCmd.Flags().Changed("body")
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().StringVarP(&c.Title, "title", "t", "", "New commit title (required)")
+	command.Flags().StringVarP(&c.Body, "body", "b", "", "New commit description")
+	_ = command.MarkFlagRequired("title")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	change, err := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	body := change.Description
+	if c.SetBody {
+		body = c.Body
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "reword"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.UpdateChangeMessage(stackCtx, change.UUID, c.Title, body); err != nil {
+		return err
+	}
+
+	stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to reload stack context: %w", err)
+	}
+
+	ui.Print(ui.RenderNavigationSuccess(ui.NavigationSuccess{
+		Message:     fmt.Sprintf("Reworded change #%d: %s", change.Position, c.Title),
+		Stack:       stackCtx.Stack,
+		Changes:     stackCtx.AllChanges,
+		CurrentUUID: change.UUID,
+	}))
+	return nil
+}
@@ -9,20 +9,32 @@ import (
 	"github.com/bjulian5/stack/cmd/bottom"
 	"github.com/bjulian5/stack/cmd/cleanup"
 	"github.com/bjulian5/stack/cmd/delete"
+	"github.com/bjulian5/stack/cmd/diff"
+	"github.com/bjulian5/stack/cmd/doctor"
 	"github.com/bjulian5/stack/cmd/down"
 	"github.com/bjulian5/stack/cmd/edit"
 	"github.com/bjulian5/stack/cmd/fixup"
+	"github.com/bjulian5/stack/cmd/graph"
 	"github.com/bjulian5/stack/cmd/hook"
+	"github.com/bjulian5/stack/cmd/insert"
 	"github.com/bjulian5/stack/cmd/install"
 	"github.com/bjulian5/stack/cmd/list"
 	"github.com/bjulian5/stack/cmd/newcmd"
 	"github.com/bjulian5/stack/cmd/pr"
 	"github.com/bjulian5/stack/cmd/push"
 	"github.com/bjulian5/stack/cmd/refresh"
+	"github.com/bjulian5/stack/cmd/rename"
+	"github.com/bjulian5/stack/cmd/reorder"
+	"github.com/bjulian5/stack/cmd/repair"
 	"github.com/bjulian5/stack/cmd/restack"
+	"github.com/bjulian5/stack/cmd/restore"
+	"github.com/bjulian5/stack/cmd/reword"
+	"github.com/bjulian5/stack/cmd/split"
+	"github.com/bjulian5/stack/cmd/squash"
 	"github.com/bjulian5/stack/cmd/status"
 	switchcmd "github.com/bjulian5/stack/cmd/switch"
 	"github.com/bjulian5/stack/cmd/top"
+	"github.com/bjulian5/stack/cmd/undo"
 	"github.com/bjulian5/stack/cmd/up"
 )
 
@@ -51,18 +63,30 @@ func init() {
 		&newcmd.Command{},
 		&list.Command{},
 		&status.Command{},
+		&graph.Command{},
+		&diff.Command{},
 		&edit.Command{},
 		&fixup.Command{},
+		&reorder.Command{},
+		&squash.Command{},
+		&reword.Command{},
+		&split.Command{},
+		&insert.Command{},
 		&up.Command{},
 		&down.Command{},
 		&top.Command{},
 		&bottom.Command{},
 		&switchcmd.Command{},
+		&rename.Command{},
 		&push.Command{},
 		&refresh.Command{},
 		&restack.Command{},
+		&undo.Command{},
 		&delete.Command{},
+		&restore.Command{},
 		&cleanup.Command{},
+		&doctor.Command{},
+		&repair.Command{},
 		&pr.Command{},
 		&hook.Command{},
 	}
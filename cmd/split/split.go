@@ -0,0 +1,146 @@
+package split
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command splits a change into two changes
+type Command struct {
+	// Arguments
+	Ref string
+
+	// Flags
+	Continue    bool
+	Abort       bool
+	Paths       []string
+	FirstTitle  string
+	SecondTitle string
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "split [ref]",
+		Short: "Split a change into two changes",
+		Long: `Split an active change into two changes.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash) and
+defaults to HEAD. By default the change's branch is checked out at its parent with
+its changes staged. Make two commits, then run 'stack split --continue' to
+replace the change with them, or 'stack split --abort' to leave it as it was.
+
+With --paths the split happens without interaction: the first change gets the
+edits to the given paths and the second gets the rest.
+
+The first change keeps the original change's PR; the second becomes a new change
+that gets a PR on the next 'stack push'. Later commits are rebased on top.
+
+Example:
+  stack split HEAD~1
+  stack split --continue
+  stack split --paths api/ --second-title "Add UI for the new endpoint"`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			c.Ref = "HEAD"
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVar(&c.Continue, "continue", false, "Finish a split after making the two commits")
+	command.Flags().BoolVar(&c.Abort, "abort", false, "Abandon a split and return to the stack")
+	command.Flags().StringSliceVar(&c.Paths, "paths", nil, "Split without interaction, moving these paths into the first change")
+	command.Flags().StringVar(&c.FirstTitle, "first-title", "", "Title of the first change with --paths (default: the original title)")
+	command.Flags().StringVar(&c.SecondTitle, "second-title", "", "Title of the second change with --paths")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	if c.Continue && c.Abort {
+		return fmt.Errorf("--continue and --abort cannot be used together")
+	}
+	if c.Continue {
+		if err := c.Stack.ContinueSplit(); err != nil {
+			return err
+		}
+		ui.Success("Split the change into two changes")
+		return nil
+	}
+	if c.Abort {
+		if err := c.Stack.AbortSplit(); err != nil {
+			return err
+		}
+		ui.Success("Split aborted")
+		return nil
+	}
+
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	change, err := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Paths) == 0 {
+		if err := c.Stack.SplitChange(stackCtx, change.UUID); err != nil {
+			return err
+		}
+		ui.Infof("The changes of '%s' are staged on its branch", change.Title)
+		ui.Info("Make two commits, then run 'stack split --continue' (or 'stack split --abort')")
+		return nil
+	}
+
+	if c.SecondTitle == "" {
+		return fmt.Errorf("--second-title is required with --paths")
+	}
+	firstTitle := c.FirstTitle
+	if firstTitle == "" {
+		firstTitle = change.Title
+	}
+
+	if err := c.Stack.SplitChangeByPaths(stackCtx, change.UUID, c.Paths, [2]string{firstTitle, c.SecondTitle}); err != nil {
+		return err
+	}
+
+	stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to reload stack context: %w", err)
+	}
+
+	ui.Print(ui.RenderNavigationSuccess(ui.NavigationSuccess{
+		Message:     fmt.Sprintf("Split '%s' into two changes", change.Title),
+		Stack:       stackCtx.Stack,
+		Changes:     stackCtx.AllChanges,
+		CurrentUUID: change.UUID,
+	}))
+	return nil
+}
@@ -0,0 +1,98 @@
+package squash
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command squashes a change into the change below it
+type Command struct {
+	// Arguments
+	Ref string
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "squash [ref]",
+		Short: "Squash a change into the change below it",
+		Long: `Fold a change into the active change directly below it.
+
+The change is given as a git ref (HEAD, HEAD~2, a branch name or a commit hash) and
+defaults to HEAD. The combined commit keeps the lower change's title and PR, and
+the squashed change's title and description are appended to its body. Later
+commits are rebased onto the combined commit.
+
+If the squashed change already had a PR, it is no longer part of the stack and
+should be closed on GitHub.
+
+Example:
+  stack squash
+  stack squash HEAD~1`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			c.Ref = "HEAD"
+			if len(args) > 0 {
+				c.Ref = args[0]
+			}
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	change, err := c.Stack.FindChangeByRef(stackCtx, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Stack.RecordCheckpoint(stackCtx.StackName, "squash"); err != nil {
+		ui.Warningf("failed to record undo checkpoint: %v", err)
+	}
+	if err := c.Stack.SquashChanges(stackCtx, change.UUID); err != nil {
+		return err
+	}
+	below := stackCtx.ActiveChanges[change.ActivePosition-2]
+
+	stackCtx, err = c.Stack.GetStackContextByName(stackCtx.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to reload stack context: %w", err)
+	}
+
+	ui.Print(ui.RenderNavigationSuccess(ui.NavigationSuccess{
+		Message:     fmt.Sprintf("Squashed '%s' into '%s'", change.Title, below.Title),
+		Stack:       stackCtx.Stack,
+		Changes:     stackCtx.AllChanges,
+		CurrentUUID: below.UUID,
+	}))
+	return nil
+}
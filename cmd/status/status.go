@@ -16,6 +16,12 @@ import (
 type Command struct {
 	StackName string
 	Table     bool
+	Stat      bool
+	Size      bool
+	Remote    bool
+	Author    bool
+	JSON      bool
+	Exact     bool
 	Git       *git.Client
 	Stack     *stack.Client
 	GH        *gh.Client
@@ -32,7 +38,13 @@ If no stack name is provided, shows the current stack.
 Example:
   stack status
   stack status auth-refactor
-  stack status --table`,
+  stack status --table
+  stack status --stat
+  stack status --size
+  stack status --stat --size
+  stack status --remote
+  stack status --author
+  stack status --json`,
 		Args: cobra.MaximumNArgs(1),
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -48,6 +60,12 @@ Example:
 	}
 
 	command.Flags().BoolVar(&c.Table, "table", false, "Display as table instead of tree")
+	command.Flags().BoolVar(&c.Stat, "stat", false, "Show additions/deletions per change (implies --table)")
+	command.Flags().BoolVar(&c.Size, "size", false, "Show the stack's commit count and total additions/deletions")
+	command.Flags().BoolVar(&c.Remote, "remote", false, "Show whether each remote PR branch is in sync (implies --table)")
+	command.Flags().BoolVar(&c.Author, "author", false, "Show the author of each change (implies --table)")
+	command.Flags().BoolVar(&c.JSON, "json", false, "Print machine-readable JSON without styling")
+	command.Flags().BoolVar(&c.Exact, "exact", false, "Require an exact (case-sensitive) stack name match")
 
 	parent.AddCommand(command)
 }
@@ -62,7 +80,11 @@ func (c *Command) Run(ctx context.Context) error {
 			return fmt.Errorf("not on a stack branch: use 'stack status <name>'")
 		}
 	} else {
-		stackCtx, err = c.Stack.GetStackContextByName(c.StackName)
+		stackName, err := c.Stack.ResolveStackName(c.StackName, c.Exact)
+		if err != nil {
+			return err
+		}
+		stackCtx, err = c.Stack.GetStackContextByName(stackName)
 		if err != nil {
 			return err
 		}
@@ -79,13 +101,78 @@ func (c *Command) Run(ctx context.Context) error {
 
 	currentUUID := stackCtx.ChangeID()
 
+	if c.JSON {
+		output, err := ui.RenderStackDetailsJSON(stackCtx.Stack, stackCtx.AllChanges, currentUUID)
+		if err != nil {
+			return err
+		}
+		ui.Print(output)
+		return nil
+	}
+
+	if c.Remote {
+		if err := c.Stack.AnnotateRemoteState(stackCtx); err != nil {
+			return fmt.Errorf("failed to check remote branches: %w", err)
+		}
+	}
+
+	if c.Author {
+		display := ui.GetDisplayConfig()
+		display.ShowAuthor = true
+		ui.SetDisplayConfig(display)
+	}
+
+	// --size adds a line to whichever view is shown
+	var size *stack.StackStats
+	if c.Size {
+		stats, err := c.Stack.ComputeStackStats(stackCtx)
+		if err != nil {
+			return fmt.Errorf("failed to compute stack size: %w", err)
+		}
+		size = &stats
+	}
+
 	var output string
-	if c.Table {
-		output = ui.RenderStackDetailsTable(stackCtx.Stack, stackCtx.AllChanges, currentUUID)
+	if c.Stat || c.Table || c.Remote || c.Author {
+		var byUUID map[string]git.DiffStat
+		var total git.DiffStat
+		if c.Stat {
+			stats, err := c.Stack.GetChangeCommitStats(stackCtx)
+			if err != nil {
+				return fmt.Errorf("failed to compute diff stats: %w", err)
+			}
+			byUUID, total = stats.ByUUID, stats.Total
+		}
+		switch {
+		case size != nil:
+			output = ui.RenderStackDetailsTableWithSize(stackCtx.Stack, stackCtx.AllChanges, currentUUID, byUUID, total, size.Commits, size.Total)
+		case c.Stat:
+			output = ui.RenderStackDetailsTableWithStats(stackCtx.Stack, stackCtx.AllChanges, currentUUID, byUUID, total)
+		default:
+			output = ui.RenderStackDetailsTable(stackCtx.Stack, stackCtx.AllChanges, currentUUID)
+		}
+	} else if size != nil {
+		output = ui.RenderStackDetailsWithSize(stackCtx.Stack, stackCtx.AllChanges, currentUUID, stackCtx.OnTopBranch(), size.Commits, size.Total)
 	} else {
-		output = ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID)
+		output = ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID, stackCtx.OnTopBranch())
 	}
 	ui.Print(output)
 
+	if workTree, err := c.Stack.DescribeWorkingTree(stackCtx); err == nil && workTree.Describe() != "" {
+		ui.Println("")
+		ui.Infof("%s (%d staged, %d unstaged, %d untracked)", workTree.Describe(),
+			workTree.StagedFiles, workTree.UnstagedFiles, workTree.UntrackedFiles)
+	}
+
+	if hint, err := c.Stack.BaseMovedHint(stackCtx.Stack); err == nil && hint != "" {
+		ui.Println("")
+		ui.Warning(hint)
+	}
+
+	if behind, count, err := c.Stack.IsBaseBehindUpstream(stackCtx.StackName); err == nil && behind {
+		ui.Println("")
+		ui.Warningf("%s - run 'stack restack --fetch' to rebase onto it", ui.FormatBaseBehind(count, stackCtx.Stack.Base))
+	}
+
 	return nil
 }
@@ -0,0 +1,81 @@
+package status
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/testutil"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// captureOutput runs fn and returns what it wrote to stdout and stderr
+func captureOutput(t *testing.T, fn func()) (stdout string, stderr string) {
+	read := func(r *os.File) string {
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	outR, outW, err := os.Pipe()
+	require.NoError(t, err)
+	errR, errW, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outW, errW
+	defer func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+	}()
+
+	fn()
+	require.NoError(t, outW.Close())
+	require.NoError(t, errW.Close())
+	return read(outR), read(errR)
+}
+
+func TestStatusJSON(t *testing.T) {
+	t.Run("WarningsStayOutOfJSON", func(t *testing.T) {
+		ghClient := &gh.MockGithubClient{}
+		ghClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		gitClient := testutil.NewTestGitClient(t)
+		stackClient := stack.NewTestStackWithClients(t, ghClient, gitClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		stackCtx.ActiveChanges[0].PR = &model.PR{PRNumber: 7, State: "open"}
+		require.NoError(t, stackCtx.Save())
+
+		// The PR is gone from GitHub, so the sync before rendering warns about it
+		ghClient.On("BatchGetPRs", "test-owner", "test-repo", []int{7}).
+			Return(&gh.BatchPRsResult{PRStates: map[int]*gh.PRState{}}, nil)
+
+		cmd := Command{StackName: "test-stack", JSON: true, Git: gitClient, Stack: stackClient}
+		var runErr error
+		stdout, stderr := captureOutput(t, func() {
+			runErr = cmd.Run(t.Context())
+		})
+		require.NoError(t, runErr)
+
+		var details ui.StackJSON
+		require.NoError(t, json.Unmarshal([]byte(stdout), &details), "stdout should be only JSON: %q", stdout)
+		assert.Equal(t, "test-stack", details.Name)
+		require.Len(t, details.Changes, 1)
+		assert.Equal(t, 7, details.Changes[0].PRNumber)
+		assert.Contains(t, stderr, "PR(s) #7 not found on GitHub")
+		ghClient.AssertExpectations(t)
+	})
+}
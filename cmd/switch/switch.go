@@ -20,6 +20,9 @@ type Command struct {
 	// Arguments
 	StackName string
 
+	// Flags
+	Exact bool
+
 	// Clients (can be mocked in tests)
 	Git   *git.Client
 	Stack *stack.Client
@@ -36,7 +39,10 @@ After switching, displays the full stack details.
 
 Example:
   stack switch                  # Interactive fuzzy finder
-  stack switch auth-refactor    # Direct switch`,
+  stack switch auth-refactor    # Direct switch
+
+Stack names are matched case-insensitively when there is no exact match.
+Use --exact to require the exact name.`,
 		Args: cobra.MaximumNArgs(1),
 		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
 			var err error
@@ -51,6 +57,8 @@ Example:
 		},
 	}
 
+	command.Flags().BoolVar(&c.Exact, "exact", false, "Require an exact (case-sensitive) stack name match")
+
 	parent.AddCommand(command)
 }
 
@@ -86,9 +94,14 @@ func (c *Command) Run(ctx context.Context) error {
 	var selectedStack *model.Stack
 
 	if c.StackName != "" {
+		stackName, err := c.Stack.ResolveStackName(c.StackName, c.Exact)
+		if err != nil {
+			return err
+		}
+
 		found := false
 		for _, s := range stacks {
-			if s.Name == c.StackName {
+			if s.Name == stackName {
 				selectedStack = s
 				found = true
 				break
@@ -145,7 +158,7 @@ func (c *Command) Run(ctx context.Context) error {
 		}
 
 		// Sync latest metadata from GitHub
-		stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+		stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 		if err != nil {
 			// Log warning but continue with cached data
 			ui.Warningf("Failed to refresh stack: %v", err)
@@ -154,7 +167,7 @@ func (c *Command) Run(ctx context.Context) error {
 		// Get current position (we're on this stack, so arrow will show)
 		currentUUID := stackCtx.ChangeID()
 
-		ui.Print(ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID))
+		ui.Print(ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID, stackCtx.OnTopBranch()))
 		return nil
 	}
 
@@ -174,7 +187,7 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Sync latest metadata from GitHub
-	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 	if err != nil {
 		// Log warning but continue with cached data
 		ui.Warningf("Failed to refresh stack: %v", err)
@@ -183,7 +196,7 @@ func (c *Command) Run(ctx context.Context) error {
 	// Get current position (we're on TOP branch, arrow will show at last change)
 	currentUUID := stackCtx.ChangeID()
 
-	ui.Print(ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID))
+	ui.Print(ui.RenderStackDetails(stackCtx.Stack, stackCtx.AllChanges, currentUUID, stackCtx.OnTopBranch()))
 
 	return nil
 }
@@ -66,7 +66,7 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Sync metadata with GitHub (read-only, no git operations)
-	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx)
+	stackCtx, err = c.Stack.RefreshStackMetadata(stackCtx, stack.RefreshOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to sync with GitHub: %w", err)
 	}
@@ -84,7 +84,7 @@ func (c *Command) Run(ctx context.Context) error {
 	}
 
 	// Checkout UUID branch for editing
-	_, err = c.Stack.CheckoutChangeForEditing(stackCtx, topActiveChange)
+	branch, err := c.Stack.CheckoutChangeForEditing(stackCtx, topActiveChange, stack.EditOptions{})
 	if err != nil {
 		return err
 	}
@@ -104,6 +104,7 @@ func (c *Command) Run(ctx context.Context) error {
 		Changes:     stackCtx.AllChanges,
 		CurrentUUID: topActiveChange.UUID,
 		IsEditing:   false,
+		OnTop:       branch == stackCtx.Stack.Branch,
 	}))
 
 	return nil
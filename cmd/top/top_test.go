@@ -135,7 +135,7 @@ func TestTop(t *testing.T) {
 				// Move to top first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[len(stackCtx.ActiveChanges)-1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[len(stackCtx.ActiveChanges)-1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -174,7 +174,7 @@ func TestTop(t *testing.T) {
 				// Move to middle change
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -213,7 +213,7 @@ func TestTop(t *testing.T) {
 				// Move to bottom change
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -0,0 +1,97 @@
+package undo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bjulian5/stack/internal/common"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/stack"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// Command undoes the last history-rewriting operation on the current stack
+type Command struct {
+	// Flags
+	Yes bool // Skip the confirmation
+
+	// Clients (can be mocked in tests)
+	Git   *git.Client
+	Stack *stack.Client
+	GH    *gh.Client
+}
+
+func (c *Command) Register(parent *cobra.Command) {
+	command := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last refresh, restack, reorder or squash",
+		Long: `Put the stack back the way it was before its last history-rewriting operation.
+
+Before 'stack refresh', 'stack restack', 'stack reorder' and 'stack squash' rewrite
+the stack, the TOP branch's commit and the stack's base are recorded in
+.git/stack/<name>/journal.jsonl. Undo resets the TOP branch to the recorded commit,
+restores the base, and moves the change branches back. Running it again steps
+further back; the last 20 operations are kept.
+
+PRs on GitHub aren't touched: run 'stack push' afterwards to update them. To
+recover a deleted stack, use 'stack restore'.
+
+Example:
+  stack undo
+  stack undo --yes`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cobraCmd *cobra.Command, args []string) error {
+			var err error
+			c.Git, c.GH, c.Stack, err = common.InitClients()
+			return err
+		},
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return c.Run(cobraCmd.Context())
+		},
+	}
+
+	command.Flags().BoolVarP(&c.Yes, "yes", "y", false, "Skip the confirmation")
+
+	parent.AddCommand(command)
+}
+
+// Run executes the command
+func (c *Command) Run(ctx context.Context) error {
+	stackCtx, err := c.Stack.GetStackContext()
+	if err != nil {
+		return fmt.Errorf("failed to get stack context: %w", err)
+	}
+
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch: switch to a stack first or use 'stack switch'")
+	}
+
+	entry, err := c.Stack.LastCheckpoint(stackCtx.StackName)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		ui.Infof("Nothing to undo for stack '%s'", stackCtx.StackName)
+		return nil
+	}
+
+	description := fmt.Sprintf("'%s' from %s", entry.Label, entry.Timestamp.Format("2006-01-02 15:04"))
+	if !c.Yes {
+		prompt := fmt.Sprintf("Undo %s and reset %s to %s? Type 'y' to continue: ", description, stackCtx.Stack.Branch, git.ShortHash(entry.Head))
+		if !ui.Confirm(prompt, "y") {
+			ui.Info("Undo cancelled")
+			return nil
+		}
+	}
+
+	if err := c.Stack.UndoLast(stackCtx.StackName); err != nil {
+		return err
+	}
+
+	ui.Successf("Undid %s", description)
+	ui.Info("Run 'stack push' to update the PRs on GitHub")
+	return nil
+}
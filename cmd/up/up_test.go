@@ -58,7 +58,7 @@ func TestUp(t *testing.T) {
 				// Move to the change first (since up requires being on a UUID branch)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 
 				// Create uncommitted changes
@@ -85,7 +85,7 @@ func TestUp(t *testing.T) {
 				// Move to the change first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -120,7 +120,7 @@ func TestUp(t *testing.T) {
 				// Move to top change first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[len(stackCtx.ActiveChanges)-1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[len(stackCtx.ActiveChanges)-1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -155,7 +155,7 @@ func TestUp(t *testing.T) {
 				// Move to bottom first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -195,7 +195,7 @@ func TestUp(t *testing.T) {
 				// Move to bottom first
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -234,7 +234,7 @@ func TestUp(t *testing.T) {
 				// Move to middle change (position 2)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -279,7 +279,7 @@ func TestUp(t *testing.T) {
 				// Move to second change (position 2)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[1], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
@@ -323,7 +323,7 @@ func TestUp(t *testing.T) {
 				// Move to third change (position 3)
 				stackCtx, err := stackClient.GetStackContext()
 				require.NoError(t, err)
-				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[2])
+				_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[2], stack.EditOptions{})
 				require.NoError(t, err)
 			},
 			verify: func(t *testing.T, ghClient *gh.MockGithubClient, gitClient *git.Client) {
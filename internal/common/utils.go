@@ -2,9 +2,6 @@ package common
 
 import (
 	"fmt"
-	"strings"
-
-	"github.com/google/uuid"
 
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/git"
@@ -14,9 +11,7 @@ import (
 
 // GenerateUUID generates a 16-character hex UUID for PR identification
 func GenerateUUID() string {
-	u := uuid.New()
-	hexStr := strings.ReplaceAll(u.String(), "-", "")
-	return hexStr[:16]
+	return stack.GenerateUUID()
 }
 
 // InitClients initializes git, GitHub, and stack clients
@@ -28,6 +23,20 @@ func InitClients() (*git.Client, *gh.Client, *stack.Client, error) {
 		return nil, nil, nil, fmt.Errorf("git client initialization failed: %w", err)
 	}
 	ghClient := gh.NewClient()
-	stackClient := stack.NewClient(gitClient, ghClient)
+	stackClient, err := stack.NewClient(gitClient, ghClient)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("stack client initialization failed: %w", err)
+	}
+	gitClient.SetDefaultRemote(stackClient.DefaultRemote())
+	gitClient.SetPushRemote(stackClient.PushRemote())
+	ghClient.SetBatchSize(stackClient.PRBatchSize())
+	ghClient.SetHost(stackClient.GitHubHost())
+	// Stacks created against another GitHub host keep using it
+	stackClient.SetGithubClientFactory(func(host string) stack.GithubClient {
+		hostClient := gh.NewClient()
+		hostClient.SetBatchSize(stackClient.PRBatchSize())
+		hostClient.SetHost(host)
+		return hostClient
+	})
 	return gitClient, ghClient, stackClient, nil
 }
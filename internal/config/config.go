@@ -0,0 +1,224 @@
+// Package config reads the repository-level stack configuration stored in .git/stack/config.json.
+//
+// Load layers the environment variable overrides on top of the file and fills in defaults, so
+// callers read every setting from one place. A repository without a config file behaves exactly
+// like one with an empty config.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Config is the repository-level stack configuration
+type Config struct {
+	HooksInstalled bool      `json:"hooks_installed"`
+	HooksVersion   string    `json:"hooks_version"`   // Version of hooks for future compatibility
+	GitConfigured  bool      `json:"git_configured"`  // Whether git settings have been configured
+	InstalledAt    time.Time `json:"installed_at"`    // When stack was first installed
+	LastUpdatedAt  time.Time `json:"last_updated_at"` // Last time config was updated
+
+	// SyncThreshold overrides DefaultSyncThreshold (a time.ParseDuration string, e.g. "30m").
+	// "0" disables time-based staleness so only new commits trigger a sync.
+	SyncThreshold string `json:"sync_threshold,omitempty"`
+
+	// HoldReadyNewStacks makes new stacks hold their PRs as drafts until the whole
+	// stack is marked ready (see model.Stack.HoldReady).
+	HoldReadyNewStacks bool `json:"hold_ready_new_stacks,omitempty"`
+
+	// Remote is the git remote used for branches without a configured branch.<name>.remote.
+	// Empty means origin, or the first remote. STACK_REMOTE takes precedence.
+	Remote string `json:"remote,omitempty"`
+
+	// PushRemote is the git remote PR branches are pushed to, for contributors who push to
+	// a fork but open PRs against the upstream repository. Empty means the fetch remote.
+	PushRemote string `json:"push_remote,omitempty"`
+
+	// HeadRepo is the owner of the fork PR branches are pushed to. New PRs are opened with
+	// "<head_repo>:<branch>" as their head. Empty means the upstream repository itself.
+	HeadRepo string `json:"head_repo,omitempty"`
+
+	// User overrides the OS username in branch names (<user>/stack-<name>/...), e.g. on CI
+	// runners where every user is "runner" or "root". STACK_USER takes precedence.
+	User string `json:"user,omitempty"`
+
+	// PRLabels are added to every PR stack creates or updates (e.g. "stacked-pr")
+	PRLabels []string `json:"pr_labels,omitempty"`
+
+	// PRReviewers are requested on every PR stack creates or updates
+	PRReviewers []string `json:"pr_reviewers,omitempty"`
+
+	// PRAutoMerge enables auto-merge with this merge method ("squash", "merge" or "rebase")
+	// on every ready PR stack creates or updates. "off" turns auto-merge off; empty leaves it
+	// to be set on GitHub.
+	PRAutoMerge string `json:"pr_auto_merge,omitempty"`
+
+	// DisableMaintainerEdit stops maintainers of the upstream repository from pushing to the
+	// branches of PRs opened from a fork (see HeadRepo)
+	DisableMaintainerEdit bool `json:"disable_maintainer_edit,omitempty"`
+
+	// PropagateTrailers are commit trailers (e.g. "Reviewed-on") copied from a change to the
+	// commits derived from it: the second commit of a split, and the change a squashed change
+	// is folded into if it doesn't have the trailer. Other trailers stay on their own commit.
+	PropagateTrailers []string `json:"propagate_trailers,omitempty"`
+
+	// DisableVizComments turns off the stack visualization comment on PRs
+	DisableVizComments bool `json:"disable_visualization_comments,omitempty"`
+
+	// VizCommentMarker is the hidden marker used to find visualization comments. "{stack}" is
+	// replaced with the stack name. Defaults to DefaultVizCommentMarker.
+	VizCommentMarker string `json:"visualization_marker,omitempty"`
+
+	// ClosedVizComments is what happens to the visualization comment of a merged or closed PR:
+	// "update" keeps it up to date like any other, "note" replaces it with a short note pointing
+	// at the rest of the stack and "delete" removes it. Defaults to "update".
+	ClosedVizComments string `json:"closed_visualization_comments,omitempty"`
+
+	// PRBatchSize is how many PRs are fetched per GitHub GraphQL query when syncing PR
+	// metadata. Lower it if large syncs hit GitHub's query limits. Defaults to gh.DefaultBatchSize.
+	PRBatchSize int `json:"pr_batch_size,omitempty"`
+
+	// Host is the GitHub Enterprise hostname (e.g. "github.example.com") gh is pointed at.
+	// GH_HOST takes precedence. Empty lets gh pick the host from the git remote.
+	Host string `json:"host,omitempty"`
+}
+
+// Environment variables that override the config file
+const (
+	SyncThresholdEnvVar = "STACK_SYNC_THRESHOLD" // Overrides SyncThreshold
+	RemoteEnvVar        = "STACK_REMOTE"         // Overrides Remote
+	UserEnvVar          = "STACK_USER"           // Overrides User
+	HostEnvVar          = "GH_HOST"              // gh's own variable for the GitHub host; overrides Host
+)
+
+// DefaultSyncThreshold is the time threshold after which a stack is considered stale
+// and needs to be refreshed to check for merged PRs on GitHub
+const DefaultSyncThreshold = 5 * time.Minute
+
+// DefaultVizCommentMarker is the hidden marker that identifies stack visualization comments
+const DefaultVizCommentMarker = "<!-- stack-visualization: {stack} -->"
+
+// Values of Config.ClosedVizComments
+const (
+	ClosedVizCommentsUpdate = "update"
+	ClosedVizCommentsNote   = "note"
+	ClosedVizCommentsDelete = "delete"
+)
+
+// usernameRules describes a valid username in error messages
+const usernameRules = "only letters, digits, '.', '_' and '-' are allowed, without a leading or trailing dot, '..' or a '.lock' suffix"
+
+// validUsernameRegex matches the characters allowed in a username
+var validUsernameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// validUsername reports whether name is safe to use as a branch name component. Like stack
+// names, it can't start or end with a dot, contain ".." or end with ".lock", which git
+// refuses in ref names.
+func validUsername(name string) bool {
+	return validUsernameRegex.MatchString(name) &&
+		!strings.HasPrefix(name, ".") && !strings.HasSuffix(name, ".") &&
+		!strings.Contains(name, "..") && !strings.HasSuffix(name, ".lock")
+}
+
+// Path returns the path of the config file in the given git directory. Worktrees share the
+// config, so gitDir is the repository's common git directory.
+func Path(gitDir string) string {
+	return filepath.Join(gitDir, "stack", "config.json")
+}
+
+// Load reads the config file, applies the environment variable overrides and fills in the
+// defaults. Returns the defaults if the file doesn't exist. The result is meant for reading
+// settings; use ReadFile to change the file, so overrides and defaults aren't written back.
+func Load(gitDir string) (*Config, error) {
+	cfg, err := ReadFile(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := os.LookupEnv(SyncThresholdEnvVar); ok {
+		cfg.SyncThreshold = value
+	}
+	if value := os.Getenv(RemoteEnvVar); value != "" {
+		cfg.Remote = value
+	}
+	if value := os.Getenv(HostEnvVar); value != "" {
+		cfg.Host = value
+	}
+	if value := os.Getenv(UserEnvVar); value != "" {
+		if !validUsername(value) {
+			return nil, fmt.Errorf("invalid %s '%s': %s", UserEnvVar, value, usernameRules)
+		}
+		cfg.User = value
+	} else if cfg.User != "" && !validUsername(cfg.User) {
+		return nil, fmt.Errorf("invalid user '%s' in %s: %s", cfg.User, Path(gitDir), usernameRules)
+	}
+
+	if cfg.VizCommentMarker == "" {
+		cfg.VizCommentMarker = DefaultVizCommentMarker
+	}
+	switch cfg.ClosedVizComments {
+	case ClosedVizCommentsNote, ClosedVizCommentsDelete:
+	default:
+		cfg.ClosedVizComments = ClosedVizCommentsUpdate
+	}
+	if cfg.PRBatchSize < 0 {
+		cfg.PRBatchSize = 0
+	}
+
+	return cfg, nil
+}
+
+// ReadFile reads the config file as stored, without environment overrides or defaults.
+// Returns an empty config if the file doesn't exist.
+func ReadFile(gitDir string) (*Config, error) {
+	data, err := os.ReadFile(Path(gitDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse repository config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Save writes the config file, stamping LastUpdatedAt
+func Save(gitDir string, cfg *Config) error {
+	path := Path(gitDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create stack directory: %w", err)
+	}
+
+	cfg.LastUpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// SyncThresholdDuration returns how long a sync with GitHub stays fresh. Empty, malformed or
+// negative values fall back to DefaultSyncThreshold. Zero means never stale based on time.
+func (c *Config) SyncThresholdDuration() time.Duration {
+	threshold, err := time.ParseDuration(c.SyncThreshold)
+	if err != nil || threshold < 0 {
+		return DefaultSyncThreshold
+	}
+	return threshold
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv unsets the override env vars so the outer environment doesn't leak into a test
+func clearEnv(t *testing.T) {
+	for _, name := range []string{SyncThresholdEnvVar, RemoteEnvVar, UserEnvVar, HostEnvVar} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeConfig(t *testing.T, gitDir string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(gitDir, "stack"), 0755))
+	require.NoError(t, os.WriteFile(Path(gitDir), []byte(content), 0644))
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, err := Load(t.TempDir())
+	require.NoError(t, err)
+
+	assert.Equal(t, &Config{
+		VizCommentMarker:  DefaultVizCommentMarker,
+		ClosedVizComments: ClosedVizCommentsUpdate,
+	}, cfg)
+	assert.Equal(t, DefaultSyncThreshold, cfg.SyncThresholdDuration())
+}
+
+func TestLoad_PartialFile(t *testing.T) {
+	clearEnv(t)
+	gitDir := t.TempDir()
+	writeConfig(t, gitDir, `{
+  "hooks_installed": true,
+  "sync_threshold": "30m",
+  "remote": "upstream",
+  "pr_labels": ["stacked-pr"],
+  "closed_visualization_comments": "delete",
+  "pr_batch_size": -1
+}`)
+
+	cfg, err := Load(gitDir)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.HooksInstalled)
+	assert.Equal(t, 30*time.Minute, cfg.SyncThresholdDuration())
+	assert.Equal(t, "upstream", cfg.Remote)
+	assert.Equal(t, []string{"stacked-pr"}, cfg.PRLabels)
+	assert.Equal(t, ClosedVizCommentsDelete, cfg.ClosedVizComments)
+	assert.Equal(t, 0, cfg.PRBatchSize, "negative batch size falls back to the gh default")
+
+	// Settings missing from the file keep their defaults
+	assert.Equal(t, DefaultVizCommentMarker, cfg.VizCommentMarker)
+	assert.Empty(t, cfg.Host)
+	assert.Empty(t, cfg.User)
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	clearEnv(t)
+	gitDir := t.TempDir()
+	writeConfig(t, gitDir, `{"sync_threshold": "10m", "remote": "upstream", "user": "ci-bot", "host": "github.example.com"}`)
+
+	t.Setenv(SyncThresholdEnvVar, "0")
+	t.Setenv(RemoteEnvVar, "fork")
+	t.Setenv(UserEnvVar, "jane")
+	t.Setenv(HostEnvVar, "ghe.corp.example")
+
+	cfg, err := Load(gitDir)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), cfg.SyncThresholdDuration())
+	assert.Equal(t, "fork", cfg.Remote)
+	assert.Equal(t, "jane", cfg.User)
+	assert.Equal(t, "ghe.corp.example", cfg.Host)
+
+	// ReadFile returns the file as stored, so saving it doesn't persist the overrides
+	stored, err := ReadFile(gitDir)
+	require.NoError(t, err)
+	assert.Equal(t, "10m", stored.SyncThreshold)
+	assert.Equal(t, "upstream", stored.Remote)
+	assert.Equal(t, "ci-bot", stored.User)
+	assert.Equal(t, "github.example.com", stored.Host)
+}
+
+func TestLoad_InvalidUser(t *testing.T) {
+	clearEnv(t)
+	gitDir := t.TempDir()
+	writeConfig(t, gitDir, `{"user": "ci~bot"}`)
+
+	_, err := Load(gitDir)
+	assert.ErrorContains(t, err, "invalid user 'ci~bot' in "+Path(gitDir))
+
+	// A valid env override wins over the invalid file value
+	t.Setenv(UserEnvVar, "jane")
+	cfg, err := Load(gitDir)
+	require.NoError(t, err)
+	assert.Equal(t, "jane", cfg.User)
+
+	t.Setenv(UserEnvVar, "team/jane")
+	_, err = Load(gitDir)
+	assert.ErrorContains(t, err, "invalid STACK_USER 'team/jane'")
+}
+
+func TestLoad_MalformedFile(t *testing.T) {
+	clearEnv(t)
+	gitDir := t.TempDir()
+	writeConfig(t, gitDir, "invalid json{")
+
+	_, err := Load(gitDir)
+	assert.ErrorContains(t, err, "failed to parse repository config")
+}
+
+func TestSave(t *testing.T) {
+	gitDir := t.TempDir()
+
+	require.NoError(t, Save(gitDir, &Config{Remote: "upstream"}))
+
+	cfg, err := ReadFile(gitDir)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream", cfg.Remote)
+	assert.False(t, cfg.LastUpdatedAt.IsZero())
+}
+
+func TestSyncThresholdDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", DefaultSyncThreshold},
+		{"30m", 30 * time.Minute},
+		{"0", 0},
+		{"soon", DefaultSyncThreshold},
+		{"-5m", DefaultSyncThreshold},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{SyncThreshold: tt.value}
+		assert.Equal(t, tt.expected, cfg.SyncThresholdDuration(), "sync_threshold %q", tt.value)
+	}
+}
@@ -3,19 +3,86 @@ package gh
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
-type Client struct{}
+const (
+	// defaultRetryAttempts is how many times a transient gh failure is retried
+	defaultRetryAttempts = 3
+	// defaultRetryBaseDelay is the delay before the first retry; it doubles on each retry
+	defaultRetryBaseDelay = time.Second
+	// DefaultBatchSize is how many PRs BatchGetPRs fetches per GraphQL query, keeping each
+	// query well under GitHub's node and complexity limits
+	DefaultBatchSize = 50
+)
+
+// retriableGHErrors are stderr fragments (lowercased) of transient GitHub failures
+var retriableGHErrors = []string{
+	"http 500", "http 502", "http 503", "http 504",
+	"bad gateway", "service unavailable", "gateway timeout",
+	"timeout", "timed out",
+	"connection reset", "connection refused",
+}
+
+// permanentGHErrors are stderr fragments (lowercased) of failures that retrying can't fix
+var permanentGHErrors = []string{
+	"http 401", "http 403", "http 404", "http 422",
+	"not found", "could not resolve",
+	"authentication", "gh auth login",
+}
+
+// repoNotFoundGHErrors are stderr fragments (lowercased) of queries against a repository that
+// doesn't exist under the given owner/name, e.g. because it was renamed or transferred
+var repoNotFoundGHErrors = []string{
+	"could not resolve to a repository",
+	"repository not found",
+}
+
+type Client struct {
+	retryAttempts  int           // Retries after the first attempt for transient failures
+	retryBaseDelay time.Duration // Delay before the first retry
+	batchSize      int           // PRs per BatchGetPRs query; 0 means DefaultBatchSize
+	host           string        // GitHub host passed to gh as GH_HOST; empty lets gh choose
+
+	// The repository can't change during a run, so loadRepoInfo caches its first success
+	repoInfoMu    sync.Mutex
+	repoOwner     string
+	repoName      string
+	defaultBranch string
+}
 
 func NewClient() *Client {
-	return &Client{}
+	return &Client{
+		retryAttempts:  defaultRetryAttempts,
+		retryBaseDelay: defaultRetryBaseDelay,
+		batchSize:      DefaultBatchSize,
+	}
+}
+
+// SetHost points every gh invocation at host (e.g. a GitHub Enterprise server) by setting
+// GH_HOST. An empty host leaves gh's own host resolution in place.
+func (c *Client) SetHost(host string) {
+	c.host = host
+}
+
+// SetBatchSize sets how many PRs BatchGetPRs fetches per GraphQL query. Zero or a negative
+// size restores DefaultBatchSize.
+func (c *Client) SetBatchSize(size int) {
+	c.batchSize = size
 }
 
 func (c *Client) SyncPR(spec PRSpec) (*PR, error) {
+	if err := validateAutoMerge(spec.AutoMerge); err != nil {
+		return nil, err
+	}
+
 	var existingPR *PR
 	var err error
 
@@ -47,34 +114,112 @@ func (c *Client) SyncPR(spec PRSpec) (*PR, error) {
 }
 
 func (c *Client) createPR(spec PRSpec) (*PR, error) {
+	output, err := c.execGHOnce(createPRArgs(spec)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+
+	prNumber, err := extractPRNumber(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract PR number from output: %w", err)
+	}
+
+	pr, err := c.getPRByNumber(prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch created PR details: %w", err)
+	}
+
+	if args := autoMergeArgs(pr.Number, spec, pr.AutoMerge); args != nil {
+		if _, err := c.execGH(args...); err != nil {
+			return nil, fmt.Errorf("failed to update auto-merge of PR #%d: %w", pr.Number, err)
+		}
+		pr.AutoMerge = desiredAutoMerge(spec, pr.AutoMerge)
+	}
+
+	return pr, nil
+}
+
+// createPRArgs builds the gh arguments that create the PR described by spec
+func createPRArgs(spec PRSpec) []string {
+	head := spec.Head
+	if spec.HeadRepo != "" {
+		head = spec.HeadRepo + ":" + spec.Head
+	}
+
 	args := []string{
 		"pr", "create",
 		"--title", spec.Title,
 		"--body", spec.Body,
 		"--base", spec.Base,
-		"--head", spec.Head,
+		"--head", head,
 	}
 
 	if spec.Draft {
 		args = append(args, "--draft")
 	}
+	if spec.HeadRepo != "" && !spec.AllowMaintainerEdit {
+		args = append(args, "--no-maintainer-edit")
+	}
+	for _, label := range spec.Labels {
+		args = append(args, "--label", label)
+	}
+	for _, reviewer := range spec.Reviewers {
+		args = append(args, "--reviewer", reviewer)
+	}
+	return args
+}
 
-	output, err := c.execGH(args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create PR: %w", err)
+// editPRArgs builds the `gh pr edit` arguments for spec. Labels and reviewers are added to
+// the ones already on the PR, never removed.
+func editPRArgs(spec PRSpec) []string {
+	args := []string{
+		"pr", "edit", fmt.Sprintf("%d", spec.Number),
+		"--title", spec.Title,
+		"--body", spec.Body,
+		"--base", spec.Base,
 	}
 
-	prNumber, err := extractPRNumber(string(output))
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract PR number from output: %w", err)
+	for _, label := range spec.Labels {
+		args = append(args, "--add-label", label)
+	}
+	for _, reviewer := range spec.Reviewers {
+		args = append(args, "--add-reviewer", reviewer)
 	}
+	return args
+}
 
-	pr, err := c.getPRByNumber(prNumber)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch created PR details: %w", err)
+// validateAutoMerge checks a PRSpec.AutoMerge value
+func validateAutoMerge(autoMerge string) error {
+	if autoMerge == "" || autoMerge == AutoMergeOff || slices.Contains(MergeMethods, autoMerge) {
+		return nil
 	}
+	return fmt.Errorf("invalid auto-merge setting '%s': must be one of %s or %s", autoMerge, strings.Join(MergeMethods, ", "), AutoMergeOff)
+}
 
-	return pr, nil
+// desiredAutoMerge returns the merge method a PR should auto-merge with after syncing spec,
+// given the one it currently has ("" when auto-merge is off)
+func desiredAutoMerge(spec PRSpec, current string) string {
+	switch {
+	case spec.AutoMerge == AutoMergeOff:
+		return ""
+	case spec.AutoMerge == "" || spec.Draft:
+		return current
+	default:
+		return spec.AutoMerge
+	}
+}
+
+// autoMergeArgs builds the `gh pr merge` arguments that bring the auto-merge of a PR from
+// current to what spec asks for, or returns nil when there's nothing to change
+func autoMergeArgs(prNumber int, spec PRSpec, current string) []string {
+	desired := desiredAutoMerge(spec, current)
+	if desired == current {
+		return nil
+	}
+	if desired == "" {
+		return []string{"pr", "merge", fmt.Sprintf("%d", prNumber), "--disable-auto"}
+	}
+	return []string{"pr", "merge", fmt.Sprintf("%d", prNumber), "--auto", "--" + desired}
 }
 
 func extractPRNumber(output string) (int, error) {
@@ -96,14 +241,7 @@ func extractPRNumber(output string) (int, error) {
 func (c *Client) updatePR(spec PRSpec, currentPR *PR) (*PR, error) {
 	prNumber := fmt.Sprintf("%d", spec.Number)
 
-	editArgs := []string{
-		"pr", "edit", prNumber,
-		"--title", spec.Title,
-		"--body", spec.Body,
-		"--base", spec.Base,
-	}
-
-	if _, err := c.execGH(editArgs...); err != nil {
+	if _, err := c.execGH(editPRArgs(spec)...); err != nil {
 		return nil, fmt.Errorf("failed to update PR: %w", err)
 	}
 
@@ -117,11 +255,18 @@ func (c *Client) updatePR(spec PRSpec, currentPR *PR) (*PR, error) {
 		}
 	}
 
+	if args := autoMergeArgs(spec.Number, spec, currentPR.AutoMerge); args != nil {
+		if _, err := c.execGH(args...); err != nil {
+			return nil, fmt.Errorf("failed to update auto-merge of PR #%d: %w", spec.Number, err)
+		}
+	}
+
 	return &PR{
 		Number:    spec.Number,
 		URL:       currentPR.URL,
 		State:     normalizeState("OPEN", spec.Draft),
 		IsDraft:   spec.Draft,
+		AutoMerge: desiredAutoMerge(spec, currentPR.AutoMerge),
 		CreatedAt: currentPR.CreatedAt,
 		UpdatedAt: time.Now(),
 	}, nil
@@ -134,14 +279,23 @@ type prJSON struct {
 	IsDraft   bool      `json:"isDraft"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	AutoMergeRequest *struct {
+		MergeMethod string `json:"mergeMethod"`
+	} `json:"autoMergeRequest"`
 }
 
 func (p *prJSON) toPR() *PR {
+	autoMerge := ""
+	if p.AutoMergeRequest != nil {
+		autoMerge = strings.ToLower(p.AutoMergeRequest.MergeMethod)
+	}
 	return &PR{
 		Number:    p.Number,
 		URL:       p.URL,
 		State:     normalizeState(p.State, p.IsDraft),
 		IsDraft:   p.IsDraft,
+		AutoMerge: autoMerge,
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
 	}
@@ -156,8 +310,18 @@ func (c *Client) parsePRJSON(data []byte) (*PR, error) {
 }
 
 func (c *Client) execGH(args ...string) ([]byte, error) {
-	cmd := exec.Command("gh", args...)
-	output, err := cmd.Output()
+	return c.execGHWithRetries(c.retryAttempts, args...)
+}
+
+// execGHOnce runs gh like execGH but never retries. Use it for calls that aren't safe to
+// repeat: a timeout can hit after GitHub already handled the request, and retrying would then
+// create a second PR or comment.
+func (c *Client) execGHOnce(args ...string) ([]byte, error) {
+	return c.execGHWithRetries(0, args...)
+}
+
+func (c *Client) execGHWithRetries(retries int, args ...string) ([]byte, error) {
+	output, err := c.runGH(retries, args...)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return nil, fmt.Errorf("gh CLI error: %s", string(exitErr.Stderr))
@@ -167,11 +331,71 @@ func (c *Client) execGH(args ...string) ([]byte, error) {
 	return output, nil
 }
 
+// runGH runs gh with args, retrying up to retries times with exponential backoff while it fails
+// with a transient error (rate limits, 5xx responses, timeouts). Returns stdout and the error
+// of the last attempt.
+func (c *Client) runGH(retries int, args ...string) ([]byte, error) {
+	delay := c.retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		cmd := exec.Command("gh", args...)
+		if c.host != "" {
+			cmd.Env = append(os.Environ(), "GH_HOST="+c.host)
+		}
+		output, err := cmd.Output()
+		if err == nil || attempt >= retries {
+			return output, err
+		}
+
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || !isRetriableGHError(string(exitErr.Stderr)) {
+			return output, err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isRetriableGHError reports whether gh's stderr describes a transient failure worth retrying.
+// Rate limits are retried even when GitHub reports them as a 403.
+func isRetriableGHError(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	if strings.Contains(stderr, "rate limit") {
+		return true
+	}
+	for _, fragment := range permanentGHErrors {
+		if strings.Contains(stderr, fragment) {
+			return false
+		}
+	}
+	for _, fragment := range retriableGHErrors {
+		if strings.Contains(stderr, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRepoNotFound reports whether err is GitHub failing to find the queried repository. Cached
+// owner/repo coordinates go stale this way when the repository is renamed or transferred.
+func IsRepoNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range repoNotFoundGHErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Client) getPRByHead(head string) (*PR, error) {
 	output, err := c.execGH(
 		"pr", "list",
 		"--head", head,
-		"--json", "number,url,state,isDraft,createdAt,updatedAt",
+		"--json", "number,url,state,isDraft,createdAt,updatedAt,autoMergeRequest",
 		"--limit", "1",
 	)
 	if err != nil {
@@ -193,7 +417,7 @@ func (c *Client) getPRByHead(head string) (*PR, error) {
 func (c *Client) getPRByNumber(number int) (*PR, error) {
 	output, err := c.execGH(
 		"pr", "view", fmt.Sprintf("%d", number),
-		"--json", "number,url,state,isDraft,createdAt,updatedAt",
+		"--json", "number,url,state,isDraft,createdAt,updatedAt,autoMergeRequest",
 	)
 	if err != nil {
 		return nil, err
@@ -218,11 +442,14 @@ func (c *Client) OpenPR(prNumber int) error {
 
 // PRState contains the merge state of a pull request
 type PRState struct {
-	Number   int       // PR number
-	State    string    // "OPEN", "CLOSED", "MERGED"
-	IsMerged bool      // True if PR is merged
-	MergedAt time.Time // When PR was merged (zero if not merged)
-	IsDraft  bool      // True if PR is a draft
+	Number      int       // PR number
+	State       string    // "OPEN", "CLOSED", "MERGED"
+	IsMerged    bool      // True if PR is merged
+	MergedAt    time.Time // When PR was merged (zero if not merged)
+	IsDraft     bool      // True if PR is a draft
+	BaseRefName string    // Base branch the PR targets on GitHub (only set by BatchGetPRs)
+	Title       string    // PR title on GitHub (only set by BatchGetPRs)
+	Body        string    // PR description on GitHub (only set by BatchGetPRs)
 }
 
 // GetPRState queries the merge state of a pull request from GitHub
@@ -256,24 +483,196 @@ func (c *Client) GetPRState(prNumber int) (*PRState, error) {
 	return state, nil
 }
 
-// GetRepoInfo fetches the repository owner and name from GitHub
+// PRDiffStat summarizes the size of a pull request's diff as reported by GitHub
+type PRDiffStat struct {
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	ChangedFiles int `json:"changedFiles"`
+}
+
+// GetPRDiffStat queries the number of additions, deletions and changed files of a pull request.
+// Useful when the PR's commits are no longer available locally (e.g. after it was merged).
+func (c *Client) GetPRDiffStat(prNumber int) (*PRDiffStat, error) {
+	output, err := c.execGH(
+		"pr", "view", fmt.Sprintf("%d", prNumber),
+		"--json", "additions,deletions,changedFiles",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR diff stat: %w", err)
+	}
+
+	var stat PRDiffStat
+	if err := json.Unmarshal(output, &stat); err != nil {
+		return nil, fmt.Errorf("failed to parse PR diff stat: %w", err)
+	}
+
+	return &stat, nil
+}
+
+// ChecksSummary counts a pull request's CI checks by outcome. Skipped checks are not
+// included in Total.
+type ChecksSummary struct {
+	Passed  int
+	Failed  int
+	Pending int
+	Total   int
+}
+
+// GetPRChecks queries the CI checks of a pull request and summarizes them by outcome.
+// A PR without any checks returns an empty summary.
+func (c *Client) GetPRChecks(prNumber int) (*ChecksSummary, error) {
+	// gh exits non-zero when checks are failing (1) or pending (8) but still prints the JSON,
+	// so the output is parsed regardless of the exit status.
+	output, err := c.runGH(c.retryAttempts, "pr", "checks", fmt.Sprintf("%d", prNumber), "--json", "bucket")
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, fmt.Errorf("failed to execute gh: %w", err)
+		}
+		if strings.Contains(string(exitErr.Stderr), "no checks reported") {
+			return &ChecksSummary{}, nil
+		}
+		if len(output) == 0 {
+			return nil, fmt.Errorf("failed to get PR checks: gh CLI error: %s", string(exitErr.Stderr))
+		}
+	}
+
+	var checks []struct {
+		Bucket string `json:"bucket"` // "pass", "fail", "pending", "skipping", "cancel"
+	}
+	if err := json.Unmarshal(output, &checks); err != nil {
+		return nil, fmt.Errorf("failed to parse PR checks: %w", err)
+	}
+
+	summary := &ChecksSummary{}
+	for _, check := range checks {
+		switch check.Bucket {
+		case "pass":
+			summary.Passed++
+		case "fail", "cancel":
+			summary.Failed++
+		case "pending":
+			summary.Pending++
+		default:
+			continue
+		}
+		summary.Total++
+	}
+
+	return summary, nil
+}
+
+// ReviewState is a pull request's review decision
+type ReviewState string
+
+const (
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewRequired         ReviewState = "REVIEW_REQUIRED"
+	ReviewNone             ReviewState = "" // No review required and none submitted
+)
+
+// GetPRReview queries the review decision of a pull request
+func (c *Client) GetPRReview(prNumber int) (ReviewState, error) {
+	output, err := c.execGH("pr", "view", fmt.Sprintf("%d", prNumber), "--json", "reviewDecision,reviews")
+	if err != nil {
+		return ReviewNone, fmt.Errorf("failed to get PR reviews: %w", err)
+	}
+	return parseReviewDecision(output)
+}
+
+// parseReviewDecision parses `gh pr view --json reviewDecision,reviews` output. GitHub only
+// reports a reviewDecision when the base branch requires reviews, so without one the decision
+// is derived from each reviewer's latest approving or blocking review.
+func parseReviewDecision(output []byte) (ReviewState, error) {
+	var result struct {
+		ReviewDecision string `json:"reviewDecision"`
+		Reviews        []struct {
+			Author struct {
+				Login string `json:"login"`
+			} `json:"author"`
+			State string `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED, PENDING
+		} `json:"reviews"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return ReviewNone, fmt.Errorf("failed to parse PR reviews: %w", err)
+	}
+
+	switch state := ReviewState(result.ReviewDecision); state {
+	case ReviewApproved, ReviewChangesRequested, ReviewRequired:
+		return state, nil
+	}
+
+	// Reviews are listed oldest first, so later reviews replace earlier ones
+	latest := make(map[string]ReviewState)
+	for _, review := range result.Reviews {
+		switch review.State {
+		case string(ReviewApproved), string(ReviewChangesRequested):
+			latest[review.Author.Login] = ReviewState(review.State)
+		case "DISMISSED":
+			delete(latest, review.Author.Login)
+		}
+	}
+
+	decision := ReviewNone
+	for _, state := range latest {
+		if state == ReviewChangesRequested {
+			return ReviewChangesRequested, nil
+		}
+		decision = ReviewApproved
+	}
+	return decision, nil
+}
+
+// GetRepoInfo returns the owner and name of the current repository. The result is cached
+// for the lifetime of the client; failures are not cached so a later call can retry.
 func (c *Client) GetRepoInfo() (owner, repoName string, err error) {
-	output, err := c.execGH("repo", "view", "--json", "owner,name")
+	if err := c.loadRepoInfo(); err != nil {
+		return "", "", err
+	}
+	return c.repoOwner, c.repoName, nil
+}
+
+// GetDefaultBranch returns the name of the repository's default branch (e.g. "main")
+func (c *Client) GetDefaultBranch() (string, error) {
+	if err := c.loadRepoInfo(); err != nil {
+		return "", err
+	}
+	if c.defaultBranch == "" {
+		return "", fmt.Errorf("repository %s/%s has no default branch", c.repoOwner, c.repoName)
+	}
+	return c.defaultBranch, nil
+}
+
+// loadRepoInfo fetches the repository's owner, name and default branch with one query
+func (c *Client) loadRepoInfo() error {
+	c.repoInfoMu.Lock()
+	defer c.repoInfoMu.Unlock()
+
+	if c.repoOwner != "" {
+		return nil
+	}
+
+	output, err := c.execGH("repo", "view", "--json", "owner,name,defaultBranchRef")
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get repo info: %w", err)
+		return fmt.Errorf("failed to get repo info: %w", err)
 	}
 
 	var repo struct {
 		Owner struct {
 			Login string `json:"login"`
 		} `json:"owner"`
-		Name string `json:"name"`
+		Name             string `json:"name"`
+		DefaultBranchRef struct {
+			Name string `json:"name"`
+		} `json:"defaultBranchRef"`
 	}
 	if err := json.Unmarshal(output, &repo); err != nil {
-		return "", "", fmt.Errorf("failed to parse repo info: %w", err)
+		return fmt.Errorf("failed to parse repo info: %w", err)
 	}
 
-	return repo.Owner.Login, repo.Name, nil
+	c.repoOwner, c.repoName, c.defaultBranch = repo.Owner.Login, repo.Name, repo.DefaultBranchRef.Name
+	return nil
 }
 
 // BatchPRsResult contains results from bulk PR query
@@ -281,13 +680,30 @@ type BatchPRsResult struct {
 	PRStates map[int]*PRState // Map of PR number to state
 }
 
-// BatchGetPRs fetches states for multiple PRs in a single GraphQL query.
-// This is much more efficient than querying each PR individually.
+// BatchGetPRs fetches states for multiple PRs with GraphQL. The PRs are fetched in batches of
+// the client's batch size, one query per batch, so large stacks stay under GitHub's query limits.
+// PRs that don't exist are left out of the result.
 func (c *Client) BatchGetPRs(owner, repoName string, prNumbers []int) (*BatchPRsResult, error) {
-	if len(prNumbers) == 0 {
-		return &BatchPRsResult{PRStates: make(map[int]*PRState)}, nil
+	result := &BatchPRsResult{PRStates: make(map[int]*PRState)}
+
+	batchSize := c.batchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	for batch := range slices.Chunk(prNumbers, batchSize) {
+		batchResult, err := c.batchGetPRs(owner, repoName, batch)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(result.PRStates, batchResult.PRStates)
 	}
 
+	return result, nil
+}
+
+// batchGetPRs fetches states for prNumbers in a single GraphQL query
+func (c *Client) batchGetPRs(owner, repoName string, prNumbers []int) (*BatchPRsResult, error) {
 	// Build dynamic GraphQL query
 	query := c.buildBatchPRQuery(prNumbers)
 
@@ -324,6 +740,9 @@ func (c *Client) buildBatchPRQuery(prNumbers []int) string {
       merged
       mergedAt
 			isDraft
+      baseRefName
+      title
+      body
     }
 `
 
@@ -362,11 +781,14 @@ func (c *Client) parseBatchPRResponse(data []byte, prNumbers []int) (*BatchPRsRe
 		}
 
 		var pr struct {
-			Number   int       `json:"number"`
-			State    string    `json:"state"` // "OPEN", "CLOSED", "MERGED"
-			Merged   bool      `json:"merged"`
-			MergedAt time.Time `json:"mergedAt"`
-			IsDraft  bool      `json:"isDraft"`
+			Number      int       `json:"number"`
+			State       string    `json:"state"` // "OPEN", "CLOSED", "MERGED"
+			Merged      bool      `json:"merged"`
+			MergedAt    time.Time `json:"mergedAt"`
+			IsDraft     bool      `json:"isDraft"`
+			BaseRefName string    `json:"baseRefName"`
+			Title       string    `json:"title"`
+			Body        string    `json:"body"`
 		}
 
 		if err := json.Unmarshal(prData, &pr); err != nil {
@@ -375,11 +797,14 @@ func (c *Client) parseBatchPRResponse(data []byte, prNumbers []int) (*BatchPRsRe
 		}
 
 		prStates[prNum] = &PRState{
-			Number:   pr.Number,
-			State:    pr.State,
-			IsMerged: pr.Merged,
-			MergedAt: pr.MergedAt,
-			IsDraft:  pr.IsDraft,
+			Number:      pr.Number,
+			State:       pr.State,
+			IsMerged:    pr.Merged,
+			MergedAt:    pr.MergedAt,
+			IsDraft:     pr.IsDraft,
+			BaseRefName: pr.BaseRefName,
+			Title:       pr.Title,
+			Body:        pr.Body,
 		}
 	}
 
@@ -413,7 +838,7 @@ func (c *Client) ListPRComments(prNumber int) ([]Comment, error) {
 }
 
 func (c *Client) CreatePRComment(prNumber int, body string) (string, error) {
-	_, err := c.execGH(
+	_, err := c.execGHOnce(
 		"pr", "comment", fmt.Sprintf("%d", prNumber),
 		"--body", body,
 	)
@@ -457,6 +882,65 @@ func (c *Client) UpdatePRComment(commentID string, body string) error {
 	return nil
 }
 
+// DeletePRComment deletes a PR comment by its node ID
+func (c *Client) DeletePRComment(commentID string) error {
+	query := fmt.Sprintf(`
+		mutation {
+			deleteIssueComment(input: {id: "%s"}) {
+				clientMutationId
+			}
+		}
+	`, commentID)
+
+	_, err := c.execGH("api", "graphql", "-f", "query="+query)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePRBase changes the base branch of a PR
+func (c *Client) UpdatePRBase(prNumber int, base string) error {
+	_, err := c.execGH("pr", "edit", fmt.Sprintf("%d", prNumber), "--base", base)
+	if err != nil {
+		return fmt.Errorf("failed to update PR base: %w", err)
+	}
+	return nil
+}
+
+// MergeMethods are the ways a PR can be merged, matching gh's --squash, --merge and --rebase
+var MergeMethods = []string{"squash", "merge", "rebase"}
+
+// MergePR merges a PR on GitHub with the given method (one of MergeMethods)
+func (c *Client) MergePR(prNumber int, method string) error {
+	args, err := mergePRArgs(prNumber, method)
+	if err != nil {
+		return err
+	}
+	if _, err := c.execGHOnce(args...); err != nil {
+		return fmt.Errorf("failed to merge PR: %w", err)
+	}
+	return nil
+}
+
+// mergePRArgs builds the gh arguments that merge a PR with method
+func mergePRArgs(prNumber int, method string) ([]string, error) {
+	if !slices.Contains(MergeMethods, method) {
+		return nil, fmt.Errorf("invalid merge method '%s': must be one of %s", method, strings.Join(MergeMethods, ", "))
+	}
+	return []string{"pr", "merge", fmt.Sprintf("%d", prNumber), "--" + method}, nil
+}
+
+// ClosePR closes a PR without merging it
+func (c *Client) ClosePR(prNumber int) error {
+	_, err := c.execGH("pr", "close", fmt.Sprintf("%d", prNumber))
+	if err != nil {
+		return fmt.Errorf("failed to close PR: %w", err)
+	}
+	return nil
+}
+
 // MarkPRReady marks a PR as ready for review (not draft)
 func (c *Client) MarkPRReady(prNumber int) error {
 	_, err := c.execGH("pr", "ready", fmt.Sprintf("%d", prNumber))
@@ -0,0 +1,500 @@
+package gh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installFakeGH puts a fake gh on PATH that fails with stderr on its first `failures` calls
+// and prints stdout afterwards. Returns a function reporting how many times gh was called.
+func installFakeGH(t *testing.T, failures int, stderr string, stdout string) func() int {
+	dir := t.TempDir()
+	countFile := filepath.Join(dir, "count")
+	script := fmt.Sprintf(`#!/bin/sh
+count=$(cat %[1]q 2>/dev/null || echo 0)
+count=$((count + 1))
+echo "$count" > %[1]q
+if [ "$count" -le %[2]d ]; then
+	echo %[3]q >&2
+	exit 1
+fi
+echo %[4]q
+`, countFile, failures, stderr, stdout)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() int {
+		data, err := os.ReadFile(countFile)
+		require.NoError(t, err)
+		count, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		require.NoError(t, err)
+		return count
+	}
+}
+
+func newTestClient() *Client {
+	return &Client{retryAttempts: 3, retryBaseDelay: 0}
+}
+
+func TestSetHost(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"host=$GH_HOST\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GH_HOST", "")
+
+	client := newTestClient()
+	output, err := client.execGH("api", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "host=", strings.TrimSpace(string(output)), "gh picks the host itself by default")
+
+	client.SetHost("github.example.com")
+	output, err = client.execGH("api", "user")
+	require.NoError(t, err)
+	assert.Equal(t, "host=github.example.com", strings.TrimSpace(string(output)))
+}
+
+func TestExecGH_RetriesTransientErrors(t *testing.T) {
+	calls := installFakeGH(t, 2, "HTTP 502: Bad Gateway", `{"owner":{"login":"test-owner"},"name":"test-repo"}`)
+
+	owner, repoName, err := newTestClient().GetRepoInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "test-owner", owner)
+	assert.Equal(t, "test-repo", repoName)
+	assert.Equal(t, 3, calls())
+}
+
+func TestExecGH_GivesUpAfterRetries(t *testing.T) {
+	calls := installFakeGH(t, 10, "API rate limit exceeded", "")
+
+	_, _, err := newTestClient().GetRepoInfo()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "API rate limit exceeded")
+	assert.Equal(t, 4, calls())
+}
+
+func TestExecGH_DoesNotRetryPermanentErrors(t *testing.T) {
+	calls := installFakeGH(t, 1, "HTTP 404: Not Found", "")
+
+	_, _, err := newTestClient().GetRepoInfo()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "HTTP 404")
+	assert.Equal(t, 1, calls())
+}
+
+func TestExecGH_DoesNotRetryCreates(t *testing.T) {
+	// The request may have gone through before the timeout, so a retry could duplicate it
+	calls := installFakeGH(t, 1, "request timed out", "")
+
+	_, err := newTestClient().CreatePRComment(101, "Stack visualization")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "request timed out")
+	assert.Equal(t, 1, calls())
+}
+
+func TestGetRepoInfo_CachesResult(t *testing.T) {
+	calls := installFakeGH(t, 0, "", `{"owner":{"login":"test-owner"},"name":"test-repo"}`)
+
+	client := newTestClient()
+	for range 3 {
+		owner, repoName, err := client.GetRepoInfo()
+		require.NoError(t, err)
+		assert.Equal(t, "test-owner", owner)
+		assert.Equal(t, "test-repo", repoName)
+	}
+	assert.Equal(t, 1, calls())
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	calls := installFakeGH(t, 0, "", `{"owner":{"login":"test-owner"},"name":"test-repo","defaultBranchRef":{"name":"develop"}}`)
+
+	client := newTestClient()
+	defaultBranch, err := client.GetDefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "develop", defaultBranch)
+
+	// Cached with the repo info, so neither call queries gh again
+	owner, _, err := client.GetRepoInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "test-owner", owner)
+	defaultBranch, err = client.GetDefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "develop", defaultBranch)
+	assert.Equal(t, 1, calls())
+}
+
+func TestGetDefaultBranch_Empty(t *testing.T) {
+	installFakeGH(t, 0, "", `{"owner":{"login":"test-owner"},"name":"test-repo","defaultBranchRef":{"name":""}}`)
+
+	_, err := newTestClient().GetDefaultBranch()
+	assert.ErrorContains(t, err, "repository test-owner/test-repo has no default branch")
+}
+
+func TestGetRepoInfo_DoesNotCacheFailures(t *testing.T) {
+	calls := installFakeGH(t, 1, "HTTP 404: Not Found", `{"owner":{"login":"test-owner"},"name":"test-repo"}`)
+
+	client := newTestClient()
+	_, _, err := client.GetRepoInfo()
+	require.Error(t, err)
+
+	owner, _, err := client.GetRepoInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "test-owner", owner)
+	assert.Equal(t, 2, calls())
+}
+
+func TestIsRetriableGHError(t *testing.T) {
+	tests := []struct {
+		stderr   string
+		expected bool
+	}{
+		{"HTTP 502: Bad Gateway (https://api.github.com/graphql)", true},
+		{"HTTP 503: Service Unavailable", true},
+		{"HTTP 403: You have exceeded a secondary rate limit", true},
+		{"API rate limit exceeded for user", true},
+		{"dial tcp: i/o timeout", true},
+		{"HTTP 401: Bad credentials", false},
+		{"To get started with GitHub CLI, please run:  gh auth login", false},
+		{"HTTP 404: Not Found", false},
+		{"no pull requests found for branch \"feature\"", false},
+		{"a pull request for branch \"feature\" already exists", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stderr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isRetriableGHError(tt.stderr))
+		})
+	}
+}
+
+func TestIsRepoNotFound(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{fmt.Errorf("gh CLI error: GraphQL: Could not resolve to a Repository with the name 'owner/repo'. (repository)"), true},
+		{fmt.Errorf("gh CLI error: HTTP 404: Repository not found"), true},
+		{fmt.Errorf("gh CLI error: HTTP 401: Bad credentials"), false},
+		{fmt.Errorf("gh CLI error: HTTP 502: Bad Gateway"), false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.err), func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRepoNotFound(tt.err))
+		})
+	}
+}
+
+func TestCreatePRArgs(t *testing.T) {
+	spec := PRSpec{
+		Title: "Add feature",
+		Body:  "Description",
+		Base:  "main",
+		Head:  "user/stack-feature/1111111111111111",
+	}
+
+	t.Run("SameRepo", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"pr", "create",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+			"--head", "user/stack-feature/1111111111111111",
+		}, createPRArgs(spec))
+	})
+
+	t.Run("ForkDraft", func(t *testing.T) {
+		forkSpec := spec
+		forkSpec.HeadRepo = "contributor"
+		forkSpec.Draft = true
+		forkSpec.AllowMaintainerEdit = true
+		assert.Equal(t, []string{
+			"pr", "create",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+			"--head", "contributor:user/stack-feature/1111111111111111",
+			"--draft",
+		}, createPRArgs(forkSpec))
+	})
+
+	t.Run("ForkWithoutMaintainerEdit", func(t *testing.T) {
+		forkSpec := spec
+		forkSpec.HeadRepo = "contributor"
+		assert.Equal(t, []string{
+			"pr", "create",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+			"--head", "contributor:user/stack-feature/1111111111111111",
+			"--no-maintainer-edit",
+		}, createPRArgs(forkSpec))
+
+		// Maintainer edits only apply to fork PRs
+		assert.NotContains(t, createPRArgs(spec), "--no-maintainer-edit")
+	})
+
+	t.Run("LabelsAndReviewers", func(t *testing.T) {
+		labeledSpec := spec
+		labeledSpec.Labels = []string{"stacked", "needs-review"}
+		labeledSpec.Reviewers = []string{"alice"}
+		assert.Equal(t, []string{
+			"pr", "create",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+			"--head", "user/stack-feature/1111111111111111",
+			"--label", "stacked",
+			"--label", "needs-review",
+			"--reviewer", "alice",
+		}, createPRArgs(labeledSpec))
+	})
+}
+
+func TestEditPRArgs(t *testing.T) {
+	spec := PRSpec{
+		Number: 42,
+		Title:  "Add feature",
+		Body:   "Description",
+		Base:   "main",
+	}
+
+	t.Run("NoLabelsOrReviewers", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"pr", "edit", "42",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+		}, editPRArgs(spec))
+	})
+
+	t.Run("LabelsAndReviewers", func(t *testing.T) {
+		labeledSpec := spec
+		labeledSpec.Labels = []string{"stacked"}
+		labeledSpec.Reviewers = []string{"alice", "bob"}
+		assert.Equal(t, []string{
+			"pr", "edit", "42",
+			"--title", "Add feature",
+			"--body", "Description",
+			"--base", "main",
+			"--add-label", "stacked",
+			"--add-reviewer", "alice",
+			"--add-reviewer", "bob",
+		}, editPRArgs(labeledSpec))
+	})
+}
+
+func TestAutoMergeArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		autoMerge string
+		draft     bool
+		current   string
+		expected  []string
+	}{
+		{"EnableOnNewPR", "squash", false, "", []string{"pr", "merge", "42", "--auto", "--squash"}},
+		{"AlreadyEnabled", "squash", false, "squash", nil},
+		{"ChangeMethod", "rebase", false, "merge", []string{"pr", "merge", "42", "--auto", "--rebase"}},
+		{"NotOnDrafts", "squash", true, "", nil},
+		{"Disable", AutoMergeOff, false, "squash", []string{"pr", "merge", "42", "--disable-auto"}},
+		{"DisableOnDraft", AutoMergeOff, true, "merge", []string{"pr", "merge", "42", "--disable-auto"}},
+		{"AlreadyDisabled", AutoMergeOff, false, "", nil},
+		{"Unmanaged", "", false, "squash", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := PRSpec{Number: 42, AutoMerge: tt.autoMerge, Draft: tt.draft}
+			assert.Equal(t, tt.expected, autoMergeArgs(42, spec, tt.current))
+		})
+	}
+}
+
+func TestValidateAutoMerge(t *testing.T) {
+	for _, value := range []string{"", "squash", "merge", "rebase", AutoMergeOff} {
+		assert.NoError(t, validateAutoMerge(value), value)
+	}
+	assert.ErrorContains(t, validateAutoMerge("fast-forward"), "invalid auto-merge setting 'fast-forward'")
+}
+
+func TestPRJSON_AutoMerge(t *testing.T) {
+	c := newTestClient()
+
+	pr, err := c.parsePRJSON([]byte(`{"number": 42, "state": "OPEN", "autoMergeRequest": {"mergeMethod": "SQUASH"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "squash", pr.AutoMerge)
+
+	pr, err = c.parsePRJSON([]byte(`{"number": 42, "state": "OPEN", "autoMergeRequest": null}`))
+	require.NoError(t, err)
+	assert.Empty(t, pr.AutoMerge)
+}
+
+func TestParseReviewDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected ReviewState
+	}{
+		{"Approved", `{"reviewDecision":"APPROVED","reviews":[]}`, ReviewApproved},
+		{"ChangesRequested", `{"reviewDecision":"CHANGES_REQUESTED","reviews":[]}`, ReviewChangesRequested},
+		{"ReviewRequired", `{"reviewDecision":"REVIEW_REQUIRED","reviews":[]}`, ReviewRequired},
+		{"NoDecisionNoReviews", `{"reviewDecision":"","reviews":[]}`, ReviewNone},
+		{
+			"NoDecisionApprovedReview",
+			`{"reviewDecision":"","reviews":[{"author":{"login":"alice"},"state":"COMMENTED"},{"author":{"login":"alice"},"state":"APPROVED"}]}`,
+			ReviewApproved,
+		},
+		{
+			"NoDecisionChangesRequestedWins",
+			`{"reviewDecision":"","reviews":[{"author":{"login":"alice"},"state":"APPROVED"},{"author":{"login":"bob"},"state":"CHANGES_REQUESTED"}]}`,
+			ReviewChangesRequested,
+		},
+		{
+			"NoDecisionLatestReviewWins",
+			`{"reviewDecision":"","reviews":[{"author":{"login":"bob"},"state":"CHANGES_REQUESTED"},{"author":{"login":"bob"},"state":"APPROVED"}]}`,
+			ReviewApproved,
+		},
+		{
+			"NoDecisionDismissedReview",
+			`{"reviewDecision":"","reviews":[{"author":{"login":"bob"},"state":"CHANGES_REQUESTED"},{"author":{"login":"bob"},"state":"DISMISSED"}]}`,
+			ReviewNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := parseReviewDecision([]byte(tt.output))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, decision)
+		})
+	}
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := parseReviewDecision([]byte("not json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestMergePRArgs(t *testing.T) {
+	for _, method := range MergeMethods {
+		args, err := mergePRArgs(42, method)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pr", "merge", "42", "--" + method}, args)
+	}
+
+	_, err := mergePRArgs(42, "fast-forward")
+	assert.ErrorContains(t, err, "invalid merge method")
+}
+
+// installFakeGraphQL puts a fake gh on PATH that answers batch PR queries with an open PR against
+// main, titled "PR <number>", for every aliased number. Returns a function reporting the PR numbers of each query, in order.
+func installFakeGraphQL(t *testing.T) func() [][]int {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "queries")
+	script := fmt.Sprintf(`#!/bin/sh
+for arg in "$@"; do
+	case "$arg" in
+	query=*) query="${arg#query=}" ;;
+	esac
+done
+numbers=$(echo "$query" | grep -o 'pullRequest(number: [0-9]*' | grep -o '[0-9]*$')
+echo $numbers >> %[1]q
+printf '{"data":{"repository":{'
+sep=""
+for n in $numbers; do
+	printf '%%s"pr%%s":{"number":%%s,"state":"OPEN","merged":false,"isDraft":false,"baseRefName":"main","title":"PR %%s","body":"Line one\\r\\nLine two"}' "$sep" "$n" "$n" "$n"
+	sep=","
+done
+printf '}}}\n'
+`, logFile)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() [][]int {
+		data, err := os.ReadFile(logFile)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		require.NoError(t, err)
+		var queries [][]int
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var numbers []int
+			for _, field := range strings.Fields(line) {
+				n, err := strconv.Atoi(field)
+				require.NoError(t, err)
+				numbers = append(numbers, n)
+			}
+			queries = append(queries, numbers)
+		}
+		return queries
+	}
+}
+
+func TestBatchGetPRs_Chunks(t *testing.T) {
+	prNumbers := make([]int, 120)
+	for i := range prNumbers {
+		prNumbers[i] = i + 1
+	}
+
+	t.Run("DefaultBatchSize", func(t *testing.T) {
+		queries := installFakeGraphQL(t)
+
+		client := newTestClient()
+		client.SetBatchSize(DefaultBatchSize)
+		result, err := client.BatchGetPRs("test-owner", "test-repo", prNumbers)
+		require.NoError(t, err)
+
+		require.Len(t, result.PRStates, 120)
+		for _, n := range prNumbers {
+			require.Contains(t, result.PRStates, n)
+			assert.Equal(t, n, result.PRStates[n].Number)
+			assert.Equal(t, "OPEN", result.PRStates[n].State)
+			assert.Equal(t, "main", result.PRStates[n].BaseRefName)
+			assert.Equal(t, fmt.Sprintf("PR %d", n), result.PRStates[n].Title)
+			assert.Equal(t, "Line one\r\nLine two", result.PRStates[n].Body)
+		}
+
+		got := queries()
+		require.Len(t, got, 3)
+		assert.Equal(t, prNumbers[:50], got[0])
+		assert.Equal(t, prNumbers[50:100], got[1])
+		assert.Equal(t, prNumbers[100:], got[2])
+	})
+
+	t.Run("SingleQueryForSmallInput", func(t *testing.T) {
+		queries := installFakeGraphQL(t)
+
+		result, err := newTestClient().BatchGetPRs("test-owner", "test-repo", prNumbers[:DefaultBatchSize])
+		require.NoError(t, err)
+		assert.Len(t, result.PRStates, DefaultBatchSize)
+		assert.Len(t, queries(), 1)
+	})
+
+	t.Run("CustomBatchSize", func(t *testing.T) {
+		queries := installFakeGraphQL(t)
+
+		client := newTestClient()
+		client.SetBatchSize(40)
+		result, err := client.BatchGetPRs("test-owner", "test-repo", prNumbers)
+		require.NoError(t, err)
+		assert.Len(t, result.PRStates, 120)
+		assert.Len(t, queries(), 3)
+	})
+
+	t.Run("EmptyInput", func(t *testing.T) {
+		queries := installFakeGraphQL(t)
+
+		result, err := newTestClient().BatchGetPRs("test-owner", "test-repo", nil)
+		require.NoError(t, err)
+		assert.Empty(t, result.PRStates)
+		assert.Empty(t, queries())
+	})
+}
@@ -21,6 +21,42 @@ func (m *MockGithubClient) CreatePRComment(prNumber int, body string) (string, e
 	return args.String(0), args.Error(1)
 }
 
+// DeletePRComment implements GithubClient.
+func (m *MockGithubClient) DeletePRComment(commentID string) error {
+	args := m.Called(commentID)
+	return args.Error(0)
+}
+
+// GetPRDiffStat implements GithubClient.
+func (m *MockGithubClient) GetPRDiffStat(prNumber int) (*PRDiffStat, error) {
+	args := m.Called(prNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PRDiffStat), args.Error(1)
+}
+
+// GetPRChecks implements GithubClient.
+func (m *MockGithubClient) GetPRChecks(prNumber int) (*ChecksSummary, error) {
+	args := m.Called(prNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ChecksSummary), args.Error(1)
+}
+
+// GetPRReview implements GithubClient.
+func (m *MockGithubClient) GetPRReview(prNumber int) (ReviewState, error) {
+	args := m.Called(prNumber)
+	return args.Get(0).(ReviewState), args.Error(1)
+}
+
+// GetDefaultBranch implements GithubClient.
+func (m *MockGithubClient) GetDefaultBranch() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 // GetRepoInfo implements GithubClient.
 func (m *MockGithubClient) GetRepoInfo() (owner string, repoName string, err error) {
 	args := m.Called()
@@ -36,6 +72,12 @@ func (m *MockGithubClient) ListPRComments(prNumber int) ([]Comment, error) {
 	return args.Get(0).([]Comment), args.Error(1)
 }
 
+// MergePR implements GithubClient.
+func (m *MockGithubClient) MergePR(prNumber int, method string) error {
+	args := m.Called(prNumber, method)
+	return args.Error(0)
+}
+
 // MarkPRDraft implements GithubClient.
 func (m *MockGithubClient) MarkPRDraft(prNumber int) error {
 	args := m.Called(prNumber)
@@ -48,6 +90,27 @@ func (m *MockGithubClient) MarkPRReady(prNumber int) error {
 	return args.Error(0)
 }
 
+// OpenPR implements GithubClient.
+func (m *MockGithubClient) OpenPR(prNumber int) error {
+	args := m.Called(prNumber)
+	return args.Error(0)
+}
+
+// SyncPR implements GithubClient.
+func (m *MockGithubClient) SyncPR(spec PRSpec) (*PR, error) {
+	args := m.Called(spec)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*PR), args.Error(1)
+}
+
+// UpdatePRBase implements GithubClient.
+func (m *MockGithubClient) UpdatePRBase(prNumber int, base string) error {
+	args := m.Called(prNumber, base)
+	return args.Error(0)
+}
+
 // UpdatePRComment implements GithubClient.
 func (m *MockGithubClient) UpdatePRComment(commentID string, body string) error {
 	args := m.Called(commentID, body)
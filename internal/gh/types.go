@@ -4,20 +4,37 @@ import "time"
 
 // PRSpec defines all parameters for creating/updating a PR
 type PRSpec struct {
-	Number int    // 0 for new PR, >0 to update existing
-	Title  string // PR title
-	Body   string // PR description
-	Base   string // base branch name
-	Head   string // head branch name
-	Draft  bool   // whether PR should be a draft
+	Number   int    // 0 for new PR, >0 to update existing
+	Title    string // PR title
+	Body     string // PR description
+	Base     string // base branch name
+	Head     string // head branch name
+	HeadRepo string // owner of the fork the head branch is pushed to ("" when it's the same repo)
+	Draft    bool   // whether PR should be a draft
+
+	Labels    []string // labels to add to the PR
+	Reviewers []string // reviewers to request on the PR
+
+	// AllowMaintainerEdit lets maintainers of the base repository push to the head branch.
+	// Only applies to new PRs from a fork (HeadRepo set); gh allows it unless told otherwise.
+	AllowMaintainerEdit bool
+
+	// AutoMerge is the merge method (one of MergeMethods) to enable auto-merge with,
+	// AutoMergeOff to turn auto-merge off, or "" to leave it as it is. Draft PRs can't
+	// auto-merge, so it's only enabled once the PR is ready for review.
+	AutoMerge string
 }
 
+// AutoMergeOff is the PRSpec.AutoMerge value that turns auto-merge off
+const AutoMergeOff = "off"
+
 // PR contains GitHub PR information returned from gh CLI
 type PR struct {
 	Number    int       // PR number
 	URL       string    // PR URL
 	State     string    // "open", "closed", "merged"
 	IsDraft   bool      // draft status
+	AutoMerge string    // merge method auto-merge is enabled with, "" when it's off
 	CreatedAt time.Time // when PR was created
 	UpdatedAt time.Time // when PR was last updated
 }
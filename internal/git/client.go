@@ -1,16 +1,22 @@
 package git
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Client provides git operations for a repository
 type Client struct {
-	gitRoot string
+	gitRoot       string
+	pushRemote    string // Remote branches are pushed to; empty means the fetch remote
+	defaultRemote string // Remote used for branches without a configured remote; empty means origin or the first remote
 }
 
 // NewClient creates a new git client for the current directory
@@ -26,11 +32,108 @@ func NewClientAt(path string) (*Client, error) {
 	return &Client{gitRoot: path}, nil
 }
 
+// SetPushRemote sets the remote that branches are pushed to and deleted from, e.g. a fork
+// when PRs are opened against the upstream repository. An empty remote restores the default
+// of pushing to the fetch remote (GetRemoteName).
+func (c *Client) SetPushRemote(remote string) {
+	c.pushRemote = remote
+}
+
+// SetDefaultRemote sets the remote used for branches that have no branch.<name>.remote
+// configured. An empty remote restores the default of origin, or the first remote.
+func (c *Client) SetDefaultRemote(remote string) {
+	c.defaultRemote = remote
+}
+
+// GetPushRemoteName returns the remote branches are pushed to
+func (c *Client) GetPushRemoteName() (string, error) {
+	return c.pushRemoteFor("")
+}
+
+// pushRemoteFor returns the remote branch is pushed to: the push remote when one is set,
+// otherwise the remote resolved for branch
+func (c *Client) pushRemoteFor(branch string) (string, error) {
+	if c.pushRemote != "" {
+		return c.pushRemote, nil
+	}
+	return c.ResolveRemote(branch)
+}
+
+// ResolveRemote returns the remote to use for branch. It prefers the branch's configured
+// branch.<name>.remote, then the default remote (SetDefaultRemote), then origin if it
+// exists, then the first remote. An empty branch skips the branch lookup.
+func (c *Client) ResolveRemote(branch string) (string, error) {
+	if branch != "" {
+		cmd := exec.Command("git", "config", "--get", fmt.Sprintf("branch.%s.remote", branch))
+		cmd.Dir = c.gitRoot
+		// A missing key exits non-zero; fall through to the defaults
+		if output, err := cmd.Output(); err == nil {
+			// "." means the branch tracks another local branch, which can't be fetched or pushed
+			if remote := strings.TrimSpace(string(output)); remote != "" && remote != "." {
+				return remote, nil
+			}
+		}
+	}
+
+	if c.defaultRemote != "" {
+		return c.defaultRemote, nil
+	}
+
+	remotes, err := c.listRemotes()
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 0 {
+		return "", fmt.Errorf("no git remote configured")
+	}
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote, nil
+		}
+	}
+	return remotes[0], nil
+}
+
+// listRemotes returns the names of the configured remotes
+func (c *Client) listRemotes() ([]string, error) {
+	cmd := exec.Command("git", "remote")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	var remotes []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes, nil
+}
+
 // GitRoot returns the root directory of the git repository
 func (c *Client) GitRoot() string {
 	return c.gitRoot
 }
 
+// GitCommonDir returns the absolute path of the git directory shared by all worktrees.
+// In a regular checkout this is <root>/.git; in a linked worktree, where .git is a file,
+// it is the main repository's git directory.
+func (c *Client) GitCommonDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common dir: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.gitRoot, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
 func (c *Client) GetCurrentBranch() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = c.gitRoot
@@ -50,6 +153,16 @@ func (c *Client) CheckoutBranch(name string) error {
 	return nil
 }
 
+// CheckoutDetached checks out ref with a detached HEAD
+func (c *Client) CheckoutDetached(ref string) error {
+	cmd := exec.Command("git", "checkout", "--detach", ref)
+	cmd.Dir = c.gitRoot
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", ref, err)
+	}
+	return nil
+}
+
 func (c *Client) CreateAndCheckoutBranch(name string) error {
 	cmd := exec.Command("git", "checkout", "-b", name)
 	cmd.Dir = c.gitRoot
@@ -77,12 +190,93 @@ func (c *Client) CreateAndCheckoutBranchAt(name string, commitHash string) error
 	return nil
 }
 
+// EnsureBranchAt checks out branch name pointing at commitHash: it's created at the commit if
+// it doesn't exist, moved to the commit if it points elsewhere, and just checked out otherwise.
+// Uses 'git checkout -B', so uncommitted changes are carried over rather than discarded.
+func (c *Client) EnsureBranchAt(name string, commitHash string) error {
+	cmd := exec.Command("git", "checkout", "-B", name, commitHash)
+	cmd.Dir = c.gitRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out branch %s at %s: %w\nOutput: %s", name, commitHash, err, string(output))
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch. If it is checked out, HEAD follows the new name.
+func (c *Client) RenameBranch(oldName string, newName string) error {
+	cmd := exec.Command("git", "branch", "-m", oldName, newName)
+	cmd.Dir = c.gitRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to rename branch %s to %s: %w\nOutput: %s", oldName, newName, err, string(output))
+	}
+	return nil
+}
+
 func (c *Client) BranchExists(name string) bool {
 	cmd := exec.Command("git", "rev-parse", "--verify", name)
 	cmd.Dir = c.gitRoot
 	return cmd.Run() == nil
 }
 
+// IsLocalBranch reports whether name is a local branch (refs/heads/<name>). Unlike
+// BranchExists, tags and commit hashes don't count.
+func (c *Client) IsLocalBranch(name string) bool {
+	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	cmd.Dir = c.gitRoot
+	return cmd.Run() == nil
+}
+
+// ListBranches lists the local branches matching the given pattern (e.g. "user/stack-foo/*").
+// Returns an empty slice if no branch matches.
+func (c *Client) ListBranches(pattern string) ([]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)", "refs/heads/"+pattern)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	branchesStr := strings.TrimSpace(string(output))
+	if branchesStr == "" {
+		return []string{}, nil
+	}
+	return strings.Split(branchesStr, "\n"), nil
+}
+
+// ResolveCommit resolves any revision git understands (branch, tag, commit hash, HEAD~2) to
+// a full commit hash. Revisions that don't name a commit, or that name several refs (e.g. a
+// branch and a tag with the same name), are rejected.
+func (c *Client) ResolveCommit(rev string) (string, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision '%s'", rev)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--verify", rev+"^{commit}")
+	cmd.Dir = c.gitRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if strings.Contains(stderr.String(), "ambiguous") {
+		return "", fmt.Errorf("revision '%s' is ambiguous: %s", rev, strings.TrimSpace(stderr.String()))
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve '%s' to a commit", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SymbolicFullName returns the full ref name rev refers to (e.g. refs/heads/main or
+// refs/tags/v1.0), or an empty string when rev isn't a ref (a commit hash or HEAD~2)
+func (c *Client) SymbolicFullName(rev string) string {
+	cmd := exec.Command("git", "rev-parse", "--symbolic-full-name", rev)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
 func (c *Client) GetCommitHash(ref string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", ref)
 	cmd.Dir = c.gitRoot
@@ -94,7 +288,19 @@ func (c *Client) GetCommitHash(ref string) (string, error) {
 }
 
 func (c *Client) GetCommits(branch string, base string) ([]Commit, error) {
-	cmd := exec.Command("git", "rev-list", "--reverse", fmt.Sprintf("%s..%s", base, branch))
+	return c.revListCommits("rev-list", "--reverse", fmt.Sprintf("%s..%s", base, branch))
+}
+
+// GetCommitsWithPaths returns the commits in base..branch that touch any of the given paths,
+// oldest first. With no paths it returns the same commits as GetCommits.
+func (c *Client) GetCommitsWithPaths(branch string, base string, paths []string) ([]Commit, error) {
+	args := []string{"rev-list", "--reverse", fmt.Sprintf("%s..%s", base, branch), "--"}
+	return c.revListCommits(append(args, paths...)...)
+}
+
+// revListCommits runs 'git rev-list' with the given args and loads each listed commit
+func (c *Client) revListCommits(args ...string) ([]Commit, error) {
+	cmd := exec.Command("git", args...)
 	cmd.Dir = c.gitRoot
 	output, err := cmd.Output()
 	if err != nil {
@@ -118,23 +324,136 @@ func (c *Client) GetCommits(branch string, base string) ([]Commit, error) {
 	return commits, nil
 }
 
+// CountCommitsBetween returns the number of commits reachable from head but not from base
+// (base..head). It returns 0 when they point at the same commit and an error if either ref
+// is unknown.
+func (c *Client) CountCommitsBetween(base, head string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", base+".."+head)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits between %s and %s: %w", base, head, err)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse commit count: %w", err)
+	}
+	return count, nil
+}
+
+// PatchesInUpstream returns the commits in limit..head whose patch is already in upstream
+// (same patch-id, e.g. cherry-picked or merged through another branch), using 'git cherry'
+func (c *Client) PatchesInUpstream(upstream, head, limit string) (map[string]bool, error) {
+	cmd := exec.Command("git", "cherry", upstream, head, limit)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %w", head, upstream, err)
+	}
+
+	result := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if hash, ok := strings.CutPrefix(line, "- "); ok {
+			result[hash] = true
+		}
+	}
+	return result, nil
+}
+
+// AheadBehind returns how many commits a has that b doesn't (ahead) and how many b has that
+// a doesn't (behind), using 'git rev-list --left-right --count a...b'
+func (c *Client) AheadBehind(a, b string) (ahead, behind int, err error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", a+"..."+b)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare %s and %s: %w", a, b, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", strings.TrimSpace(string(output)))
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse ahead count: %w", err)
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse behind count: %w", err)
+	}
+	return ahead, behind, nil
+}
+
 func (c *Client) GetCommit(hash string) (Commit, error) {
 	actualHash, err := c.GetCommitHash(hash)
 	if err != nil {
 		return Commit{}, fmt.Errorf("failed to resolve %s: %w", hash, err)
 	}
 
-	cmd := exec.Command("git", "log", "--format=%B", "-n", "1", actualHash)
+	// Idents can't contain a NUL, so the message is everything after the metadata fields
+	cmd := exec.Command("git", "log", "--format="+commitMetaFormat+"%x00%B", "-n", "1", actualHash)
 	cmd.Dir = c.gitRoot
 	output, err := cmd.Output()
 	if err != nil {
 		return Commit{}, fmt.Errorf("failed to get commit %s: %w", actualHash, err)
 	}
 
-	messageStr := string(output)
+	fields := strings.SplitN(string(output), "\x00", commitMetaFields+1)
+	if len(fields) != commitMetaFields+1 {
+		return Commit{}, fmt.Errorf("unexpected format for commit %s: %q", actualHash, string(output))
+	}
+	meta, err := parseCommitMeta(fields[:commitMetaFields])
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed to parse commit %s: %w", actualHash, err)
+	}
 	return Commit{
 		Hash:    actualHash,
-		Message: ParseCommitMessage(messageStr),
+		Meta:    meta,
+		Message: ParseCommitMessage(fields[commitMetaFields]),
+	}, nil
+}
+
+// commitMetaFormat is the pretty format read by parseCommitMeta: author name, email and date,
+// then the committer date, separated by NULs
+const commitMetaFormat = "%an%x00%ae%x00%aI%x00%cI"
+
+const commitMetaFields = 4
+
+// GetCommitMeta returns the author and commit date of the given commit
+func (c *Client) GetCommitMeta(hash string) (CommitMeta, error) {
+	cmd := exec.Command("git", "show", "-s", "--format="+commitMetaFormat, hash)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return CommitMeta{}, fmt.Errorf("failed to get metadata of commit %s: %w", hash, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(output)), "\x00")
+	if len(fields) != commitMetaFields {
+		return CommitMeta{}, fmt.Errorf("unexpected metadata format for commit %s: %q", hash, string(output))
+	}
+	meta, err := parseCommitMeta(fields)
+	if err != nil {
+		return CommitMeta{}, fmt.Errorf("failed to parse metadata of commit %s: %w", hash, err)
+	}
+	return meta, nil
+}
+
+// parseCommitMeta parses the fields printed by commitMetaFormat
+func parseCommitMeta(fields []string) (CommitMeta, error) {
+	authorDate, err := time.Parse(time.RFC3339, fields[2])
+	if err != nil {
+		return CommitMeta{}, fmt.Errorf("failed to parse author date: %w", err)
+	}
+	date, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return CommitMeta{}, fmt.Errorf("failed to parse commit date: %w", err)
+	}
+	return CommitMeta{
+		AuthorName:  fields[0],
+		AuthorEmail: fields[1],
+		AuthorDate:  authorDate,
+		Date:        date,
 	}, nil
 }
 
@@ -166,6 +485,17 @@ func (c *Client) ResetHard(ref string) error {
 	return nil
 }
 
+// ResetSoft moves the current branch (or detached HEAD) to ref, keeping the index and working
+// tree so the changes between ref and the old HEAD end up staged
+func (c *Client) ResetSoft(ref string) error {
+	cmd := exec.Command("git", "reset", "--soft", ref)
+	cmd.Dir = c.gitRoot
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", ref, err)
+	}
+	return nil
+}
+
 func (c *Client) AmendCommitMessage(message string) error {
 	cmd := exec.Command("git", "commit", "--amend", "-m", message)
 	cmd.Dir = c.gitRoot
@@ -206,8 +536,27 @@ func (c *Client) GetCommitTree(commitHash string) (string, error) {
 }
 
 func (c *Client) CommitTree(treeHash string, parentHash string, message string) (string, error) {
+	return c.commitTree(treeHash, parentHash, message, nil)
+}
+
+// CommitTreeAs is CommitTree with the author name, email and date taken from author instead of
+// the current user and time, so recreating a commit (e.g. to rewrite its message) keeps its
+// authorship. The committer is still the current user.
+func (c *Client) CommitTreeAs(treeHash string, parentHash string, message string, author CommitMeta) (string, error) {
+	return c.commitTree(treeHash, parentHash, message, []string{
+		"GIT_AUTHOR_NAME=" + author.AuthorName,
+		"GIT_AUTHOR_EMAIL=" + author.AuthorEmail,
+		"GIT_AUTHOR_DATE=" + author.AuthorDate.Format(time.RFC3339),
+	})
+}
+
+// commitTree runs 'git commit-tree' with env added to the environment
+func (c *Client) commitTree(treeHash string, parentHash string, message string, env []string) (string, error) {
 	cmd := exec.Command("git", "commit-tree", treeHash, "-p", parentHash, "-m", message)
 	cmd.Dir = c.gitRoot
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to commit tree: %w", err)
@@ -215,6 +564,23 @@ func (c *Client) CommitTree(treeHash string, parentHash string, message string)
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ConflictedFiles returns the paths with unresolved merge conflicts in the index, e.g. while a
+// rebase or cherry-pick is stopped on conflicts. Returns an empty list when there are none.
+func (c *Client) ConflictedFiles() ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	filesStr := strings.TrimSpace(string(output))
+	if filesStr == "" {
+		return []string{}, nil
+	}
+	return strings.Split(filesStr, "\n"), nil
+}
+
 func (c *Client) IsRebaseInProgress() bool {
 	rebaseMerge := filepath.Join(c.gitRoot, ".git", "rebase-merge")
 	rebaseApply := filepath.Join(c.gitRoot, ".git", "rebase-apply")
@@ -271,6 +637,17 @@ func (c *Client) CommitFixup(commitHash string) error {
 	return nil
 }
 
+// CommitEmpty creates a commit with no file changes using the given message.
+func (c *Client) CommitEmpty(message string) error {
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", message)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create empty commit: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
 // RebaseInteractiveAutosquash runs an interactive rebase with autosquash from the specified commit.
 // Uses GIT_SEQUENCE_EDITOR=true to automatically apply the rebase plan without user interaction.
 func (c *Client) RebaseInteractiveAutosquash(fromCommit string) error {
@@ -284,17 +661,19 @@ func (c *Client) RebaseInteractiveAutosquash(fromCommit string) error {
 	return nil
 }
 
+// Push pushes branch to the remote. When force is true the push uses --force-with-lease,
+// so it is rejected if the remote branch moved since it was last fetched.
 func (c *Client) Push(branch string, force bool) error {
 	args := []string{"push"}
 
-	remote, err := c.GetRemoteName()
+	remote, err := c.pushRemoteFor(branch)
 	if err != nil {
 		return err
 	}
 	args = append(args, remote, branch)
 
 	if force {
-		args = append(args, "--force")
+		args = append(args, "--force-with-lease")
 	}
 
 	cmd := exec.Command("git", args...)
@@ -306,23 +685,58 @@ func (c *Client) Push(branch string, force bool) error {
 	return nil
 }
 
-func (c *Client) GetRemoteName() (string, error) {
-	cmd := exec.Command("git", "remote")
-	cmd.Dir = c.gitRoot
-	output, err := cmd.Output()
+// PushWithLease force pushes branch only if the remote branch still points at expected.
+// If someone else updated the remote branch the push is rejected rather than overwriting
+// their commits. If the remote branch was deleted (e.g. by GitHub after a merge) there is
+// nothing to overwrite, so it is recreated with a plain push.
+func (c *Client) PushWithLease(branch string, expected string) error {
+	remote, err := c.pushRemoteFor(branch)
 	if err != nil {
-		return "", fmt.Errorf("failed to get remote: %w", err)
+		return err
 	}
 
-	remotes := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(remotes) == 0 {
-		return "", fmt.Errorf("no git remote configured")
+	lease := fmt.Sprintf("--force-with-lease=%s:%s", branch, expected)
+	cmd := exec.Command("git", "push", remote, branch, lease)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// A lease on a missing ref always fails; a plain push still refuses to overwrite a
+		// branch someone created in the meantime
+		if heads, lsErr := c.LsRemoteHeads(remote, branch); lsErr == nil {
+			if _, exists := heads[branch]; !exists {
+				cmd := exec.Command("git", "push", remote, branch)
+				cmd.Dir = c.gitRoot
+				if output, err := cmd.CombinedOutput(); err != nil {
+					return fmt.Errorf("failed to push branch %s: %w\nOutput: %s", branch, err, string(output))
+				}
+				return nil
+			}
+		}
+		if strings.Contains(string(output), "stale info") {
+			return fmt.Errorf("remote branch %s has moved since it was last pushed (expected %s): "+
+				"someone else may have updated it - fetch and inspect it before pushing again", branch, ShortHash(expected))
+		}
+		return fmt.Errorf("failed to push branch %s: %w\nOutput: %s", branch, err, string(output))
 	}
+	return nil
+}
 
-	return remotes[0], nil
+// GetRemoteName returns the remote used when no branch is involved: the default remote,
+// then origin, then the first remote
+func (c *Client) GetRemoteName() (string, error) {
+	return c.ResolveRemote("")
 }
 
+// Fetch fetches from remote. An empty remote fetches from GetRemoteName.
 func (c *Client) Fetch(remote string) error {
+	if remote == "" {
+		resolved, err := c.GetRemoteName()
+		if err != nil {
+			return err
+		}
+		remote = resolved
+	}
+
 	cmd := exec.Command("git", "fetch", remote)
 	cmd.Dir = c.gitRoot
 	output, err := cmd.CombinedOutput()
@@ -352,6 +766,88 @@ func (c *Client) Rebase(onto string) error {
 	return nil
 }
 
+// RebaseContinue continues an in-progress rebase after conflicts were resolved, keeping the
+// existing commit messages
+func (c *Client) RebaseContinue() error {
+	cmd := exec.Command("git", "rebase", "--continue")
+	cmd.Dir = c.gitRoot
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("rebase --continue failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// AbortRebase aborts the in-progress rebase, returning HEAD to where the rebase started.
+// Returns an error if no rebase is in progress.
+func (c *Client) AbortRebase() error {
+	if !c.IsRebaseInProgress() {
+		return fmt.Errorf("no rebase in progress")
+	}
+	cmd := exec.Command("git", "rebase", "--abort")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("rebase --abort failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// autoStashMessage labels stashes made by Stash so they can be told apart in 'git stash list'
+const autoStashMessage = "stack autostash"
+
+// Stash stashes uncommitted changes, including untracked files, so an operation needing a
+// clean working tree can run. Returns the stash's commit hash for StashPop, or "" if there was
+// nothing to stash.
+func (c *Client) Stash() (string, error) {
+	hasChanges, err := c.HasUncommittedChanges()
+	if err != nil {
+		return "", err
+	}
+	if !hasChanges {
+		return "", nil
+	}
+
+	cmd := exec.Command("git", "stash", "push", "--include-untracked", "-m", autoStashMessage)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git stash failed: %w\nOutput: %s", err, string(output))
+	}
+
+	hash, err := c.GetCommitHash("stash@{0}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the new stash: %w", err)
+	}
+	return hash, nil
+}
+
+// StashPop reapplies and drops the stash with the given commit hash, wherever it now is in
+// 'git stash list' (other stashes may have been pushed on top since). If reapplying conflicts,
+// the conflicts are left in the working tree and the stash is kept.
+func (c *Client) StashPop(hash string) error {
+	cmd := exec.Command("git", "stash", "list", "--format=%H")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+
+	index := slices.Index(strings.Fields(string(output)), hash)
+	if index < 0 {
+		return fmt.Errorf("stash %s is no longer in 'git stash list'", ShortHash(hash))
+	}
+
+	cmd = exec.Command("git", "stash", "pop", fmt.Sprintf("stash@{%d}", index))
+	cmd.Dir = c.gitRoot
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git stash pop failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
 func (c *Client) DeleteBranch(branchName string, force bool) error {
 	args := []string{"branch"}
 	if force {
@@ -371,7 +867,7 @@ func (c *Client) DeleteBranch(branchName string, force bool) error {
 }
 
 func (c *Client) DeleteRemoteBranch(branchName string) error {
-	remote, err := c.GetRemoteName()
+	remote, err := c.pushRemoteFor(branchName)
 	if err != nil {
 		return err
 	}
@@ -397,10 +893,57 @@ func (c *Client) SetConfig(key string, value string) error {
 	return nil
 }
 
-// StripComments removes git comment lines from a message using git stripspace.
-// Respects the configured comment character (core.commentChar).
+// autoCommentChars are the characters git picks from, in order, when core.commentChar is
+// "auto": the first one no line of the message starts with
+const autoCommentChars = "#;@!$%^&|:"
+
+// CommentChar returns the effective comment character for message. It is core.commentChar,
+// defaulting to "#". With core.commentChar=auto, git chose the character when it wrote the
+// message, and 'git stripspace' would still strip "#" lines, so the character is recovered
+// from the comment block git appends: the first character of the last non-blank line, if it
+// is one git picks from.
+func (c *Client) CommentChar(message string) string {
+	cmd := exec.Command("git", "config", "--get", "core.commentChar")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		// Unset (exit status 1) means the default
+		return "#"
+	}
+
+	char := strings.TrimSpace(string(output))
+	switch char {
+	case "":
+		return "#"
+	case "auto":
+		return detectCommentChar(message)
+	default:
+		return char
+	}
+}
+
+// detectCommentChar guesses the comment character git picked for message under
+// core.commentChar=auto, falling back to "#"
+func detectCommentChar(message string) string {
+	lines := strings.Split(strings.TrimRight(message, "\n\t "), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last != "" && strings.ContainsRune(autoCommentChars, rune(last[0])) {
+		return last[:1]
+	}
+	return "#"
+}
+
+// StripComments removes git comment lines from a message using git stripspace. Lines are
+// comments when they start with the effective comment character (see CommentChar); the
+// character elsewhere in a line is kept.
 func (c *Client) StripComments(message string) (string, error) {
-	cmd := exec.Command("git", "stripspace", "--strip-comments")
+	return c.StripCommentsWithChar(message, c.CommentChar(message))
+}
+
+// StripCommentsWithChar removes the lines of message that start with char, regardless of
+// core.commentChar, and cleans up whitespace like git stripspace
+func (c *Client) StripCommentsWithChar(message string, char string) (string, error) {
+	cmd := exec.Command("git", "-c", "core.commentChar="+char, "stripspace", "--strip-comments")
 	cmd.Dir = c.gitRoot
 	cmd.Stdin = strings.NewReader(message)
 	output, err := cmd.Output()
@@ -421,3 +964,83 @@ func (c *Client) GetUpstreamBranch(branch string) (string, error) {
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// LsRemoteHeads lists the branches on a remote matching the given pattern (e.g. "user/stack-foo/*")
+// using a single `git ls-remote` call. Returns a map of branch name to commit hash.
+func (c *Client) LsRemoteHeads(remote string, pattern string) (map[string]string, error) {
+	cmd := exec.Command("git", "ls-remote", "--heads", remote, pattern)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	return ParseLsRemote(string(output)), nil
+}
+
+// ParseLsRemote parses `git ls-remote --heads` output ("<hash>\trefs/heads/<branch>" per line)
+// into a map of branch name to commit hash.
+func ParseLsRemote(output string) map[string]string {
+	heads := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		hash, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		heads[strings.TrimPrefix(ref, "refs/heads/")] = hash
+	}
+	return heads
+}
+
+// IsAncestor returns true if ancestor is reachable from descendant.
+// Returns false if either commit is unknown locally.
+func (c *Client) IsAncestor(ancestor, descendant string) bool {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd.Dir = c.gitRoot
+	return cmd.Run() == nil
+}
+
+// ContainsChanges reports whether base already contains the changes made between from and to,
+// for example because they were merged into base by a merge, squash or rebase merge. The diff
+// from..to is reverse-applied to base's tree in a temporary index: if that applies cleanly,
+// base has the changes. Later edits in base to the same lines make it report false.
+func (c *Client) ContainsChanges(base string, from string, to string) (bool, error) {
+	if c.IsAncestor(to, base) {
+		return true, nil
+	}
+
+	indexDir, err := os.MkdirTemp("", "stack-index-")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(indexDir)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(indexDir, "index"))
+
+	readTree := exec.Command("git", "read-tree", base)
+	readTree.Dir = c.gitRoot
+	readTree.Env = env
+	if output, err := readTree.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to read tree of %s: %s: %w", base, strings.TrimSpace(string(output)), err)
+	}
+
+	diffCmd := exec.Command("git", "diff", "--binary", "--no-renames", from, to)
+	diffCmd.Dir = c.gitRoot
+	diff, err := diffCmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+	if len(diff) == 0 {
+		return true, nil
+	}
+
+	apply := exec.Command("git", "apply", "--cached", "--reverse", "--check")
+	apply.Dir = c.gitRoot
+	apply.Env = env
+	apply.Stdin = bytes.NewReader(diff)
+	if err := apply.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to run git apply: %w", err)
+	}
+	return true, nil
+}
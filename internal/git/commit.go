@@ -2,22 +2,44 @@ package git
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 )
 
 // CommitMessage represents a parsed git commit message with its components
 type CommitMessage struct {
 	Title    string
 	Body     string
-	Trailers map[string]string
+	Trailers map[string]string // Last value of each trailer key
+
+	// trailerLines are the trailers in the order they appear in the message, including
+	// repeated keys (e.g. several Co-authored-by lines) that Trailers can only hold once.
+	// String writes them back so rewriting a commit doesn't reorder or drop trailers.
+	trailerLines []Trailer
+}
+
+// Trailer is a single "Key: value" line in a commit message's trailer block
+type Trailer struct {
+	Key   string
+	Value string
 }
 
 // Commit represents a git commit with its hash and parsed message
 type Commit struct {
 	Hash    string
+	Meta    CommitMeta
 	Message CommitMessage
 }
 
+// CommitMeta holds the authorship of a commit
+type CommitMeta struct {
+	AuthorName  string
+	AuthorEmail string
+	AuthorDate  time.Time
+	Date        time.Time // Committer date
+}
+
 const ShortHashLength = 7
 
 func (c *Commit) ShortHash() string {
@@ -31,9 +53,14 @@ func ShortHash(hash string) string {
 	return hash[:ShortHashLength]
 }
 
-// ParseCommitMessage parses a commit message string into its components
+// ParseCommitMessage parses a commit message string into its components. CRLF line endings
+// are normalized and trailing whitespace is trimmed from every line, so messages written by
+// Windows editors don't leak "\r" into titles and bodies.
 func ParseCommitMessage(message string) CommitMessage {
-	lines := strings.Split(message, "\n")
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
 
 	commitMsg := CommitMessage{
 		Trailers: make(map[string]string),
@@ -84,6 +111,7 @@ func ParseCommitMessage(message string) CommitMessage {
 			key := strings.TrimSpace(parts[0])
 			value := strings.TrimSpace(parts[1])
 			commitMsg.Trailers[key] = value
+			commitMsg.trailerLines = append(commitMsg.trailerLines, Trailer{Key: key, Value: value})
 		}
 	}
 
@@ -100,9 +128,53 @@ func ParseCommitMessage(message string) CommitMessage {
 
 // AddTrailer adds a trailer to the commit message
 func (c *CommitMessage) AddTrailer(key string, value string) {
+	if _, ok := c.Trailers[key]; !ok {
+		// Clip so a copy of the message sharing trailerLines isn't modified
+		c.trailerLines = append(slices.Clip(c.trailerLines), Trailer{Key: key, Value: value})
+	}
 	c.Trailers[key] = value
 }
 
+// OrderedTrailers returns the message's trailers in the order String writes them: parsed
+// trailers keep their original order and repeated keys, unless their value was changed or
+// removed through Trailers, and trailers only set in Trailers follow sorted by key.
+func (c *CommitMessage) OrderedTrailers() []Trailer {
+	result := make([]Trailer, 0, len(c.Trailers))
+	written := make(map[string]bool, len(c.Trailers))
+	for _, line := range c.trailerLines {
+		value, ok := c.Trailers[line.Key]
+		if !ok || written[line.Key] {
+			continue
+		}
+		written[line.Key] = true
+
+		var lines []Trailer
+		for _, other := range c.trailerLines {
+			if other.Key == line.Key {
+				lines = append(lines, other)
+			}
+		}
+		// The map holds the last parsed value; anything else means the trailer was replaced
+		if lines[len(lines)-1].Value == value {
+			result = append(result, lines...)
+		} else {
+			result = append(result, Trailer{Key: line.Key, Value: value})
+		}
+	}
+
+	var added []string
+	for key := range c.Trailers {
+		if !written[key] {
+			added = append(added, key)
+		}
+	}
+	slices.Sort(added)
+	for _, key := range added {
+		result = append(result, Trailer{Key: key, Value: c.Trailers[key]})
+	}
+	return result
+}
+
 // String converts the CommitMessage back to a formatted string
 func (c *CommitMessage) String() string {
 	var result strings.Builder
@@ -118,8 +190,8 @@ func (c *CommitMessage) String() string {
 
 	if len(c.Trailers) > 0 {
 		result.WriteString("\n")
-		for key, value := range c.Trailers {
-			result.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+		for _, trailer := range c.OrderedTrailers() {
+			result.WriteString(fmt.Sprintf("%s: %s\n", trailer.Key, trailer.Value))
 		}
 	}
 
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DiffStat summarizes the size of a diff
+type DiffStat struct {
+	Files     int
+	Additions int
+	Deletions int
+}
+
+// Add returns the sum of two diff stats
+func (d DiffStat) Add(other DiffStat) DiffStat {
+	return DiffStat{
+		Files:     d.Files + other.Files,
+		Additions: d.Additions + other.Additions,
+		Deletions: d.Deletions + other.Deletions,
+	}
+}
+
+// DiffStat returns the number of files changed, lines added and lines deleted by a single commit.
+func (c *Client) DiffStat(commitHash string) (DiffStat, error) {
+	cmd := exec.Command("git", "show", "--numstat", "--format=", commitHash)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("failed to get diff stat for %s: %w", ShortHash(commitHash), err)
+	}
+
+	return parseNumstat(string(output)), nil
+}
+
+// DiffStatBetween returns the number of files changed, lines added and lines deleted between
+// two commits. Unlike summing per-commit stats, a file touched by several commits counts once.
+func (c *Client) DiffStatBetween(from string, to string) (DiffStat, error) {
+	output, err := c.diff("--numstat", from, to)
+	if err != nil {
+		return DiffStat{}, err
+	}
+	return parseNumstat(output), nil
+}
+
+// parseNumstat parses `git diff --numstat` output. Binary files ("-\t-\tpath") count as a
+// changed file without line counts.
+func parseNumstat(output string) DiffStat {
+	var stat DiffStat
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		stat.Files++
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			stat.Additions += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			stat.Deletions += n
+		}
+	}
+	return stat
+}
+
+// Diff returns the patch between two commits
+func (c *Client) Diff(from string, to string) (string, error) {
+	return c.diff("", from, to)
+}
+
+// DiffSummary returns the `git diff --stat` summary between two commits
+func (c *Client) DiffSummary(from string, to string) (string, error) {
+	return c.diff("--stat", from, to)
+}
+
+func (c *Client) diff(format string, from string, to string) (string, error) {
+	args := []string{"diff", "--no-ext-diff"}
+	if format != "" {
+		args = append(args, format)
+	}
+	args = append(args, from, to, "--")
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s..%s: %w", ShortHash(from), ShortHash(to), err)
+	}
+	return string(output), nil
+}
@@ -1,6 +1,9 @@
 package git
 
-import "fmt"
+import (
+	"fmt"
+	"os/exec"
+)
 
 // RebaseSubsequentCommits rebases commits that come after oldCommitHash onto newCommitHash
 // This is a common operation when updating a commit in the middle of a stack.
@@ -24,6 +27,8 @@ func (c *Client) RebaseSubsequentCommits(stackBranch string, oldCommitHash strin
 			"To abort and retry:\n"+
 			"  1. git rebase --abort\n"+
 			"  2. stack restack --recover --retry\n\n"+
+			"To abort and put the stack back as it was:\n"+
+			"  stack restack --abort\n\n"+
 			"Error: %w", err)
 	}
 
@@ -53,3 +58,48 @@ func (c *Client) RebaseSubsequentCommits(stackBranch string, oldCommitHash strin
 
 	return len(commits), nil
 }
+
+// ReplayCommits rebuilds stackBranch by cherry-picking commitHashes, in the given order,
+// onto onto. The picks happen on a detached HEAD so the stack branch is only moved once
+// every commit has been applied; on conflict the branch is left untouched. With no commits
+// the stack branch is simply moved to onto.
+func (c *Client) ReplayCommits(stackBranch string, onto string, commitHashes []string) error {
+	if err := c.CheckoutDetached(onto); err != nil {
+		return err
+	}
+
+	if len(commitHashes) == 0 {
+		if err := c.UpdateRef(stackBranch, onto); err != nil {
+			return fmt.Errorf("failed to update stack branch: %w", err)
+		}
+		return c.CheckoutBranch(stackBranch)
+	}
+
+	args := append([]string{"cherry-pick", "--allow-empty"}, commitHashes...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.gitRoot
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cherry-pick conflicts detected.\n\n"+
+			"To resolve:\n"+
+			"  1. Resolve conflicts in your files\n"+
+			"  2. git add <resolved-files>\n"+
+			"  3. git cherry-pick --continue\n"+
+			"  4. stack restack --recover\n\n"+
+			"To abort and restore the original order:\n"+
+			"  1. git cherry-pick --abort\n"+
+			"  2. git checkout %s\n"+
+			"  3. stack restack --recover\n\n"+
+			"Error: %w\nOutput: %s", stackBranch, err, string(output))
+	}
+
+	newStackHead, err := c.GetCommitHash("HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD after cherry-pick: %w", err)
+	}
+
+	if err := c.UpdateRef(stackBranch, newStackHead); err != nil {
+		return fmt.Errorf("failed to update stack branch: %w", err)
+	}
+
+	return c.CheckoutBranch(stackBranch)
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// StatusSummary counts the files in each state reported by `git status`
+type StatusSummary struct {
+	Staged    int // Files with changes in the index
+	Unstaged  int // Tracked files with changes in the working tree
+	Untracked int
+}
+
+// Status summarizes the working tree and index.
+func (c *Client) Status() (StatusSummary, error) {
+	cmd := exec.Command("git", "status", "--porcelain")
+	cmd.Dir = c.gitRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return StatusSummary{}, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	return parsePorcelainStatus(string(output)), nil
+}
+
+// parsePorcelainStatus parses `git status --porcelain` output. Each line starts with a two
+// character XY code: X is the index state and Y the working tree state. A file that is both
+// staged and modified again counts in both Staged and Unstaged.
+func parsePorcelainStatus(output string) StatusSummary {
+	var summary StatusSummary
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+
+		if strings.HasPrefix(line, "??") {
+			summary.Untracked++
+			continue
+		}
+		if line[0] != ' ' {
+			summary.Staged++
+		}
+		if line[1] != ' ' {
+			summary.Unstaged++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TreeWithPaths writes a tree that matches base except that files under paths are taken from
+// source: files added or modified in source are copied and files deleted in source are removed.
+// A temporary index is used, so the working tree and the real index are untouched. Returns the
+// hash of the new tree.
+func (c *Client) TreeWithPaths(base string, source string, paths []string) (string, error) {
+	indexDir, err := os.MkdirTemp("", "stack-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.RemoveAll(indexDir)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(indexDir, "index"))
+
+	run := func(stdin string, args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = c.gitRoot
+		cmd.Env = env
+		if stdin != "" {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return string(output), nil
+	}
+
+	if _, err := run("", "read-tree", base); err != nil {
+		return "", err
+	}
+
+	diffArgs := append([]string{"diff", "--name-only", "--no-renames", "-z", base, source, "--"}, paths...)
+	changed, err := run("", diffArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	var removed []string
+	var entries strings.Builder
+	for _, path := range strings.Split(changed, "\x00") {
+		if path == "" {
+			continue
+		}
+		entry, err := run("", "ls-tree", "-z", source, "--", path)
+		if err != nil {
+			return "", err
+		}
+		if entry == "" {
+			removed = append(removed, path)
+			continue
+		}
+		entries.WriteString(entry)
+	}
+
+	if entries.Len() > 0 {
+		if _, err := run(entries.String(), "update-index", "-z", "--index-info"); err != nil {
+			return "", err
+		}
+	}
+	if len(removed) > 0 {
+		if _, err := run("", append([]string{"update-index", "--force-remove", "--"}, removed...)...); err != nil {
+			return "", err
+		}
+	}
+
+	tree, err := run("", "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(tree), nil
+}
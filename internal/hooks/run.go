@@ -0,0 +1,85 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// User hooks run from .git/stack/hooks after stack operations
+const (
+	PostPush    = "post-push"
+	PostRefresh = "post-refresh"
+)
+
+// Environment variables describing the stack, set for every user hook
+const (
+	StackNameEnvVar = "STACK_NAME"
+	StackBaseEnvVar = "STACK_BASE"
+)
+
+// PRPayload describes a PR affected by the operation that triggered a hook
+type PRPayload struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	UUID   string `json:"uuid"`
+	Title  string `json:"title"`
+	Action string `json:"action"` // "created" or "updated" (post-push), "merged" (post-refresh)
+}
+
+// Payload is the JSON written to a user hook's stdin
+type Payload struct {
+	Stack string      `json:"stack"`
+	Base  string      `json:"base"`
+	PRs   []PRPayload `json:"prs"`
+}
+
+// Runner runs the user hooks in a directory
+type Runner struct {
+	Dir string   // Directory containing the hook executables
+	Env []string // Extra KEY=VALUE pairs added to each hook's environment
+}
+
+// NewRunner creates a runner for the hooks in dir that describes the stack to them through
+// STACK_NAME and STACK_BASE
+func NewRunner(dir string, stackName string, base string) *Runner {
+	return &Runner{
+		Dir: dir,
+		Env: []string{StackNameEnvVar + "=" + stackName, StackBaseEnvVar + "=" + base},
+	}
+}
+
+// RunHook runs the named hook with payload encoded as JSON on its stdin. It does nothing if
+// the hook doesn't exist or isn't executable. The hook's output is passed through.
+func (r *Runner) RunHook(name string, payload any) error {
+	hookPath := filepath.Join(r.Dir, name)
+
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s hook: %w", name, err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0111 == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s hook payload: %w", name, err)
+	}
+
+	cmd := exec.Command(hookPath)
+	cmd.Env = append(os.Environ(), r.Env...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHook writes a shell script hook to dir with the given permissions
+func writeHook(t *testing.T, dir string, name string, script string, perm os.FileMode) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), perm))
+}
+
+func TestRunHook(t *testing.T) {
+	payload := Payload{
+		Stack: "auth-refactor",
+		Base:  "main",
+		PRs: []PRPayload{
+			{Number: 123, URL: "https://github.com/o/r/pull/123", UUID: "1111111111111111", Title: "Add JWT auth", Action: "created"},
+			{Number: 124, URL: "https://github.com/o/r/pull/124", UUID: "2222222222222222", Title: "Refresh tokens", Action: "updated"},
+		},
+	}
+
+	t.Run("WritesPayloadToStdin", func(t *testing.T) {
+		dir := t.TempDir()
+		out := filepath.Join(t.TempDir(), "payload.json")
+		writeHook(t, dir, PostPush, `cat > "`+out+`"`+"\n", 0755)
+
+		require.NoError(t, NewRunner(dir, "auth-refactor", "main").RunHook(PostPush, payload))
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		var decoded Payload
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, payload, decoded)
+	})
+
+	t.Run("SetsStackEnv", func(t *testing.T) {
+		dir := t.TempDir()
+		out := filepath.Join(t.TempDir(), "env.txt")
+		writeHook(t, dir, PostRefresh, `echo "$STACK_NAME $STACK_BASE" > "`+out+`"`+"\n", 0755)
+
+		require.NoError(t, NewRunner(dir, "auth-refactor", "develop").RunHook(PostRefresh, payload))
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "auth-refactor develop", strings.TrimSpace(string(data)))
+	})
+
+	t.Run("MissingHookIsNoop", func(t *testing.T) {
+		assert.NoError(t, NewRunner(t.TempDir(), "auth-refactor", "main").RunHook(PostPush, payload))
+	})
+
+	t.Run("MissingDirIsNoop", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "does-not-exist")
+		assert.NoError(t, NewRunner(dir, "auth-refactor", "main").RunHook(PostPush, payload))
+	})
+
+	t.Run("NonExecutableHookIsSkipped", func(t *testing.T) {
+		dir := t.TempDir()
+		out := filepath.Join(t.TempDir(), "payload.json")
+		writeHook(t, dir, PostPush, `cat > "`+out+`"`+"\n", 0644)
+
+		require.NoError(t, NewRunner(dir, "auth-refactor", "main").RunHook(PostPush, payload))
+		assert.NoFileExists(t, out)
+	})
+
+	t.Run("FailingHookReturnsError", func(t *testing.T) {
+		dir := t.TempDir()
+		writeHook(t, dir, PostPush, "exit 3\n", 0755)
+
+		err := NewRunner(dir, "auth-refactor", "main").RunHook(PostPush, payload)
+		assert.ErrorContains(t, err, "post-push hook failed")
+	})
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"github.com/bjulian5/stack/internal/gh"
@@ -17,8 +18,28 @@ type Change struct {
 	PR             *PR
 	MergedAt       time.Time `json:"merged_at"`
 	DesiredBase    string
+	Author         string // Commit author name
+
+	// Trailers are the commit's trailers other than PR-UUID and PR-Stack (e.g. Change-Id,
+	// Signed-off-by). Rewrites keep them on the commit; see Config.PropagateTrailers.
+	Trailers map[string]string `json:"trailers,omitempty"`
+
+	// RemoteState is the state of the remote PR branch relative to the local one.
+	// Only populated on demand (see stack.Client.AnnotateRemoteState); never persisted.
+	RemoteState RemoteState `json:"-"`
 }
 
+// RemoteState describes how a change's remote branch compares to its local commit
+type RemoteState string
+
+const (
+	RemoteInSync   RemoteState = "in-sync"  // Remote branch points at the local commit
+	RemoteAhead    RemoteState = "ahead"    // Local has commits the remote doesn't (needs push)
+	RemoteBehind   RemoteState = "behind"   // Remote has commits the local branch doesn't
+	RemoteDiverged RemoteState = "diverged" // Both sides have commits the other doesn't
+	RemoteMissing  RemoteState = "missing"  // No remote branch exists
+)
+
 func (c *Change) IsLocal() bool {
 	return c.PR == nil || c.PR.PRNumber == 0
 }
@@ -60,6 +81,18 @@ func (c *Change) NeedsSyncToGitHub() ChangeSyncStatus {
 		return ChangeSyncStatus{NeedsSync: true, Reason: "base changed"}
 	}
 
+	if c.HasBaseDrift() {
+		return ChangeSyncStatus{NeedsSync: true, Reason: "base changed on GitHub"}
+	}
+
+	if c.HasTitleDrift() {
+		return ChangeSyncStatus{NeedsSync: true, Reason: "title edited on GitHub"}
+	}
+
+	if c.HasBodyDrift() {
+		return ChangeSyncStatus{NeedsSync: true, Reason: "description edited on GitHub"}
+	}
+
 	if c.PR.LocalDraftStatus != c.PR.RemoteDraftStatus {
 		return ChangeSyncStatus{NeedsSync: true, Reason: "draft status changed"}
 	}
@@ -99,6 +132,39 @@ func (c *Change) UpdateTitle(title, description, base string) {
 	}
 }
 
+// HasBaseDrift reports whether the change's open PR targets a different base on GitHub than
+// the stack wants, e.g. because the PR was retargeted in the GitHub UI. Pushing the change
+// retargets it back.
+func (c *Change) HasBaseDrift() bool {
+	if c.IsLocal() || c.PR.IsMerged() || c.PR.State == "closed" {
+		return false
+	}
+	return c.DesiredBase != "" && c.PR.RemoteBase != "" && c.PR.RemoteBase != c.DesiredBase
+}
+
+// HasTitleDrift reports whether the change's open PR title was edited on GitHub since it was
+// last pushed. Pushing the change replaces the edit with the commit title.
+func (c *Change) HasTitleDrift() bool {
+	if c.IsLocal() || c.PR.IsMerged() || c.PR.State == "closed" {
+		return false
+	}
+	return c.PR.Title != "" && c.PR.RemoteTitle != "" && c.PR.RemoteTitle != c.PR.Title
+}
+
+// HasBodyDrift reports whether the change's open PR description was edited on GitHub since it
+// was last pushed. Line endings are ignored, since GitHub stores descriptions edited in its UI
+// with CRLF. Pushing the change replaces the edit with the rendered commit description.
+func (c *Change) HasBodyDrift() bool {
+	if c.IsLocal() || c.PR.IsMerged() || c.PR.State == "closed" || c.PR.PushedBody == "" {
+		return false
+	}
+	return normalizeNewlines(c.PR.RemoteBody) != normalizeNewlines(c.PR.PushedBody)
+}
+
+func normalizeNewlines(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
 // StackChanges contains the various categories of changes in a stack.
 type StackChanges struct {
 	// All includes merged + active changes (deduplicated by UUID).
@@ -255,6 +255,108 @@ func TestChange_NeedsSyncToGitHub(t *testing.T) {
 			},
 			expected: ChangeSyncStatus{NeedsSync: true, Reason: "base changed"},
 		},
+		{
+			name: "base changed on GitHub",
+			change: &Change{
+				UUID:        "test-uuid",
+				Title:       "Test PR",
+				Description: "Test description",
+				CommitHash:  "abc123",
+				DesiredBase: "main",
+				PR: &PR{
+					PRNumber:   123,
+					State:      "open",
+					Title:      "Test PR",
+					Body:       "Test description",
+					Base:       "main",
+					RemoteBase: "release",
+					CommitHash: "abc123",
+				},
+			},
+			expected: ChangeSyncStatus{NeedsSync: true, Reason: "base changed on GitHub"},
+		},
+		{
+			name: "title edited on GitHub",
+			change: &Change{
+				UUID:        "test-uuid",
+				Title:       "Test PR",
+				Description: "Test description",
+				CommitHash:  "abc123",
+				PR: &PR{
+					PRNumber:    123,
+					State:       "open",
+					Title:       "Test PR",
+					Body:        "Test description",
+					Base:        "main",
+					CommitHash:  "abc123",
+					RemoteTitle: "Renamed in the UI",
+				},
+			},
+			expected: ChangeSyncStatus{NeedsSync: true, Reason: "title edited on GitHub"},
+		},
+		{
+			name: "description edited on GitHub",
+			change: &Change{
+				UUID:        "test-uuid",
+				Title:       "Test PR",
+				Description: "Test description",
+				CommitHash:  "abc123",
+				PR: &PR{
+					PRNumber:    123,
+					State:       "open",
+					Title:       "Test PR",
+					Body:        "Test description",
+					Base:        "main",
+					CommitHash:  "abc123",
+					RemoteTitle: "Test PR",
+					RemoteBody:  "Rewritten in the UI",
+					PushedBody:  "## Summary\nTest description",
+				},
+			},
+			expected: ChangeSyncStatus{NeedsSync: true, Reason: "description edited on GitHub"},
+		},
+		{
+			name: "description with CRLF line endings on GitHub is not an edit",
+			change: &Change{
+				UUID:        "test-uuid",
+				Title:       "Test PR",
+				Description: "Test description",
+				CommitHash:  "abc123",
+				PR: &PR{
+					PRNumber:    123,
+					State:       "open",
+					Title:       "Test PR",
+					Body:        "Test description",
+					Base:        "main",
+					CommitHash:  "abc123",
+					RemoteTitle: "Test PR",
+					RemoteBody:  "## Summary\r\nTest description\r\n",
+					PushedBody:  "## Summary\nTest description",
+				},
+			},
+			expected: ChangeSyncStatus{NeedsSync: false},
+		},
+		{
+			name: "edits on GitHub ignored for merged PR",
+			change: &Change{
+				UUID:        "test-uuid",
+				Title:       "Test PR",
+				Description: "Test description",
+				CommitHash:  "abc123",
+				PR: &PR{
+					PRNumber:    123,
+					State:       "merged",
+					Title:       "Test PR",
+					Body:        "Test description",
+					Base:        "main",
+					CommitHash:  "abc123",
+					RemoteTitle: "Renamed in the UI",
+					RemoteBody:  "Rewritten in the UI",
+					PushedBody:  "Test description",
+				},
+			},
+			expected: ChangeSyncStatus{NeedsSync: false},
+		},
 		{
 			name: "base not changed when desired base is empty",
 			change: &Change{
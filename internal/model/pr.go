@@ -1,6 +1,8 @@
 package model
 
 import (
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/bjulian5/stack/internal/gh"
@@ -17,7 +19,8 @@ type PR struct {
 	VizCommentID string    `json:"viz_comment_id,omitempty"` // GitHub comment ID for stack visualization
 	CreatedAt    time.Time `json:"created_at"`
 	LastPushed   time.Time `json:"last_pushed"`
-	State        string    `json:"state"` // open, draft, closed, merged (actual GitHub state)
+	State        string    `json:"state"`               // open, draft, closed, merged (actual GitHub state)
+	MergedAt     time.Time `json:"merged_at,omitempty"` // When the PR was merged on GitHub (zero if not merged)
 
 	// Cached PR metadata for diff-based updates (avoids redundant API calls)
 	Title string `json:"title,omitempty"` // Last pushed PR title
@@ -33,6 +36,38 @@ type PR struct {
 	// This is synced from GitHub API during SyncPRMetadata.
 	// When LocalDraftStatus differs from RemoteDraftStatus, the PR needs to be synced.
 	RemoteDraftStatus bool `json:"remote_draft_status"`
+
+	// RemoteBase is the PR's base branch on GitHub, synced during SyncPRMetadata.
+	// It differs from Base when the PR was retargeted outside of stack (e.g. in the GitHub UI).
+	RemoteBase string `json:"remote_base,omitempty"`
+
+	// RemoteTitle and RemoteBody are the PR's title and description on GitHub, synced during
+	// SyncPRMetadata. PushedBody is the description last pushed (Body caches the commit
+	// description, which differs when a PR template is used). They differ from Title and
+	// PushedBody when the PR was edited outside of stack (e.g. in the GitHub UI).
+	RemoteTitle string `json:"remote_title,omitempty"`
+	RemoteBody  string `json:"remote_body,omitempty"`
+	PushedBody  string `json:"pushed_body,omitempty"`
+
+	// ReviewDecision is the PR's review state on GitHub as of the last review fetch
+	ReviewDecision gh.ReviewState `json:"review_decision,omitempty"`
+
+	// Labels and reviewers stack has already applied to the PR, so pushes only add new ones
+	Labels    []string `json:"labels,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// SetBase records that the PR's base branch was set to base on GitHub
+func (p *PR) SetBase(base string) {
+	p.Base = base
+	p.RemoteBase = base
+}
+
+// SetPushedContent records that the PR's title and description were set on GitHub
+func (p *PR) SetPushedContent(title, body string) {
+	p.RemoteTitle = title
+	p.RemoteBody = body
+	p.PushedBody = body
 }
 
 func (p *PR) IsMerged() bool {
@@ -42,6 +77,45 @@ func (p *PR) IsMerged() bool {
 	return p.State == "merged"
 }
 
+// LabelsToAdd returns the labels that haven't been applied to the PR yet
+func (p *PR) LabelsToAdd(labels []string) []string {
+	if p == nil {
+		return missingNames(labels, nil)
+	}
+	return missingNames(labels, p.Labels)
+}
+
+// ReviewersToRequest returns the reviewers that haven't been requested on the PR yet
+func (p *PR) ReviewersToRequest(reviewers []string) []string {
+	if p == nil {
+		return missingNames(reviewers, nil)
+	}
+	return missingNames(reviewers, p.Reviewers)
+}
+
+// RecordApplied remembers labels and reviewers that were applied to the PR
+func (p *PR) RecordApplied(labels []string, reviewers []string) {
+	p.Labels = append(p.Labels, missingNames(labels, p.Labels)...)
+	p.Reviewers = append(p.Reviewers, missingNames(reviewers, p.Reviewers)...)
+}
+
+// missingNames returns the names in want that aren't in have. GitHub labels and logins are
+// case-insensitive, so they're compared that way.
+func missingNames(want []string, have []string) []string {
+	var missing []string
+	for _, name := range want {
+		if name == "" {
+			continue
+		}
+		found := slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, name) }) ||
+			slices.ContainsFunc(missing, func(m string) bool { return strings.EqualFold(m, name) })
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // PRSyncData contains all data needed to sync a PR to local storage
 type PRSyncData struct {
 	StackName         string
@@ -82,3 +82,53 @@ func TestPR_IsMerged(t *testing.T) {
 		})
 	}
 }
+
+func TestPR_LabelsToAdd(t *testing.T) {
+	tests := []struct {
+		name     string
+		pr       *PR
+		want     []string
+		expected []string
+	}{
+		{
+			name:     "nil PR needs every label",
+			pr:       nil,
+			want:     []string{"stacked", "backend"},
+			expected: []string{"stacked", "backend"},
+		},
+		{
+			name:     "labels already present are not re-requested",
+			pr:       &PR{Labels: []string{"stacked"}},
+			want:     []string{"stacked", "backend"},
+			expected: []string{"backend"},
+		},
+		{
+			name:     "comparison ignores case",
+			pr:       &PR{Labels: []string{"Stacked"}},
+			want:     []string{"stacked"},
+			expected: nil,
+		},
+		{
+			name:     "duplicates and empty names are dropped",
+			pr:       &PR{},
+			want:     []string{"stacked", "", "stacked"},
+			expected: []string{"stacked"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.pr.LabelsToAdd(tt.want))
+		})
+	}
+}
+
+func TestPR_RecordApplied(t *testing.T) {
+	pr := &PR{Labels: []string{"stacked"}}
+
+	pr.RecordApplied([]string{"backend", "Stacked"}, []string{"alice"})
+
+	assert.Equal(t, []string{"stacked", "backend"}, pr.Labels)
+	assert.Equal(t, []string{"alice"}, pr.Reviewers)
+	assert.Empty(t, pr.ReviewersToRequest([]string{"alice"}))
+}
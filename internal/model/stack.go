@@ -1,17 +1,30 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Stack represents a PR stack
 type Stack struct {
 	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"` // Short summary shown by 'stack status' and 'stack list'
 	Branch        string    `json:"branch"`
 	Base          string    `json:"base"`
-	Owner         string    `json:"owner"`     // GitHub repo owner (cached)
-	RepoName      string    `json:"repo_name"` // GitHub repo name (cached)
+	Owner         string    `json:"owner"`          // GitHub repo owner (cached)
+	RepoName      string    `json:"repo_name"`      // GitHub repo name (cached)
+	Host          string    `json:"host,omitempty"` // GitHub Enterprise host the PRs live on (empty for gh's default host)
 	Created       time.Time `json:"created"`
-	LastSynced    time.Time `json:"last_synced"`    // When we last checked GitHub for merged PRs
-	SyncHash      string    `json:"sync_hash"`      // TOP branch commit hash at last sync
-	BaseRef       string    `json:"base_ref"`       // Git ref of the base branch at stack creation
-	MergedChanges []Change  `json:"merged_changes"` // PRs that have been merged on GitHub
+	LastSynced    time.Time `json:"last_synced"`              // When we last checked GitHub for merged PRs
+	SyncHash      string    `json:"sync_hash"`                // TOP branch commit hash at last sync
+	BaseRef       string    `json:"base_ref"`                 // Git ref of the base branch at stack creation
+	MergedChanges []Change  `json:"merged_changes"`           // PRs that have been merged on GitHub
+	HoldReady     bool      `json:"hold_ready,omitempty"`     // Create new PRs as drafts until released with 'stack pr ready --all'
+	PreviousNames []string  `json:"previous_names,omitempty"` // Names from before 'stack rename'; commits keep them in PR-Stack
+}
+
+// HasStackTrailer reports whether a commit's PR-Stack trailer belongs to this stack.
+// Commits made before a rename still carry one of the previous names.
+func (s *Stack) HasStackTrailer(name string) bool {
+	return name == s.Name || slices.Contains(s.PreviousNames, name)
 }
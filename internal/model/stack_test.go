@@ -54,6 +54,15 @@ func TestStack_JSONRoundTrip(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "stack with description",
+			stack: Stack{
+				Name:        "test-stack",
+				Description: "Auth refactor epic",
+				Branch:      "user/stack-test-stack/TOP",
+				Base:        "main",
+			},
+		},
 		{
 			name: "stack with nil merged changes",
 			stack: Stack{
@@ -81,3 +90,16 @@ func TestStack_JSONRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestStack_WithoutDescription(t *testing.T) {
+	// Stacks saved before descriptions existed load with an empty one
+	var stack Stack
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"test-stack","branch":"user/stack-test-stack/TOP","base":"main"}`), &stack))
+	assert.Equal(t, "test-stack", stack.Name)
+	assert.Empty(t, stack.Description)
+
+	// and an empty description isn't written out
+	data, err := json.Marshal(stack)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "description")
+}
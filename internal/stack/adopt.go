@@ -0,0 +1,103 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// AdoptBranch turns an existing feature branch into a stack. The commits in baseBranch..branchName
+// become the stack's changes: each is recreated with PR-UUID and PR-Stack trailers and the new
+// stack's TOP branch is created at the result and checked out. Commits that already carry a
+// unique PR-UUID keep it, and leading commits whose trailers already match are left as they are.
+// The original branch is left untouched. The branch must be a linear series of commits on top
+// of baseBranch.
+func (c *Client) AdoptBranch(branchName, stackName, baseBranch string) (*model.Stack, error) {
+	if err := validateStackName(stackName); err != nil {
+		return nil, err
+	}
+	if c.StackExists(stackName) {
+		return nil, fmt.Errorf("stack '%s' already exists", stackName)
+	}
+	if baseBranch == "" {
+		return nil, fmt.Errorf("base branch is required")
+	}
+	if !c.git.IsLocalBranch(branchName) {
+		return nil, fmt.Errorf("branch '%s' does not exist", branchName)
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return nil, fmt.Errorf("cannot adopt a branch with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	baseRef, err := c.git.ResolveCommit(baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base: %w", err)
+	}
+	if !c.git.IsAncestor(baseRef, branchName) {
+		return nil, fmt.Errorf("branch '%s' is not based on %s: rebase it onto %s first", branchName, baseBranch, baseBranch)
+	}
+
+	commits, err := c.git.GetCommits(branchName, baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("branch '%s' has no commits on top of %s", branchName, baseBranch)
+	}
+
+	// Recreate the commits with stack trailers, oldest first
+	parent := baseRef
+	previous := baseRef
+	rewritten := false
+	seen := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		// Merge commits bring in side branches whose commits don't chain onto each other
+		commitParent, err := c.git.GetParentCommit(commit.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if commitParent != previous {
+			return nil, fmt.Errorf("branch '%s' has merge commits: rebase it onto %s to make it linear first", branchName, baseBranch)
+		}
+		previous = commit.Hash
+
+		uuid := commit.Message.Trailers["PR-UUID"]
+		if uuid == "" || seen[uuid] {
+			uuid = GenerateUUID()
+		}
+		seen[uuid] = true
+
+		if !rewritten && uuid == commit.Message.Trailers["PR-UUID"] && commit.Message.Trailers["PR-Stack"] == stackName {
+			parent = commit.Hash
+			continue
+		}
+
+		msg := commit.Message
+		msg.Trailers = withStackTrailers(msg.Trailers, uuid, stackName)
+		tree, err := c.git.GetCommitTree(commit.Hash)
+		if err != nil {
+			return nil, err
+		}
+		parent, err = c.git.CommitTreeAs(tree, parent, msg.String(), commit.Meta)
+		if err != nil {
+			return nil, err
+		}
+		rewritten = true
+	}
+
+	s, err := c.CreateStack(stackName, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	// CreateStack leaves the new TOP branch checked out at the base
+	if err := c.git.ResetHard(parent); err != nil {
+		return nil, fmt.Errorf("failed to move TOP branch to the adopted commits: %w", err)
+	}
+
+	return s, nil
+}
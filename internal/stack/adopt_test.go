@@ -0,0 +1,128 @@
+package stack
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestAdoptBranch(t *testing.T) {
+	setup := func(t *testing.T) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		mainHash, err := gitClient.GetCommitHash("main")
+		require.NoError(t, err)
+		require.NoError(t, gitClient.CreateAndCheckoutBranchAt("feature", mainHash))
+		return stackClient, gitClient
+	}
+
+	t.Run("TwoCommits", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		first := testutil.CreateCommitWithTrailers(t, gitClient, "First", "First body", nil)
+		second := testutil.CreateCommitWithTrailers(t, gitClient, "Second", "", nil)
+		featureTree, err := gitClient.GetCommitTree("feature")
+		require.NoError(t, err)
+
+		s, err := stackClient.AdoptBranch("feature", "adopted", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "test-user/stack-adopted/TOP", s.Branch)
+		assert.Equal(t, "main", s.Base)
+
+		stackCtx, err := stackClient.GetStackContextByName("adopted")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 2)
+		assert.Equal(t, "First", stackCtx.ActiveChanges[0].Title)
+		assert.Equal(t, "First body", stackCtx.ActiveChanges[0].Description)
+		assert.Equal(t, "Second", stackCtx.ActiveChanges[1].Title)
+
+		uuidPattern := regexp.MustCompile(`^[0-9a-f]{16}$`)
+		for _, change := range stackCtx.ActiveChanges {
+			assert.Regexp(t, uuidPattern, change.UUID)
+			commit, err := gitClient.GetCommit(change.CommitHash)
+			require.NoError(t, err)
+			assert.Equal(t, "adopted", commit.Message.Trailers["PR-Stack"])
+		}
+		assert.NotEqual(t, stackCtx.ActiveChanges[0].UUID, stackCtx.ActiveChanges[1].UUID)
+
+		// The stack has the same content and sits on TOP; the original branch is untouched
+		topTree, err := gitClient.GetCommitTree(s.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, featureTree, topTree)
+		featureHash, err := gitClient.GetCommitHash("feature")
+		require.NoError(t, err)
+		assert.Equal(t, second, featureHash)
+		assert.NotEqual(t, first, stackCtx.ActiveChanges[0].CommitHash)
+
+		currentBranch, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, s.Branch, currentBranch)
+	})
+
+	t.Run("KeepsExistingTrailers", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		first := testutil.CreateCommitWithTrailers(t, gitClient, "First", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "adopted",
+		})
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Second", "", map[string]string{"PR-UUID": "2222222222222222"})
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Duplicate", "", map[string]string{"PR-UUID": "2222222222222222"})
+
+		_, err := stackClient.AdoptBranch("feature", "adopted", "main")
+		require.NoError(t, err)
+
+		stackCtx, err := stackClient.GetStackContextByName("adopted")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 3)
+		assert.Equal(t, first, stackCtx.ActiveChanges[0].CommitHash, "commit with matching trailers is kept as is")
+		assert.Equal(t, "1111111111111111", stackCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, "2222222222222222", stackCtx.ActiveChanges[1].UUID)
+		assert.NotEqual(t, "2222222222222222", stackCtx.ActiveChanges[2].UUID, "duplicate UUID is replaced")
+	})
+
+	t.Run("KeepsAuthors", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		_ = createCommitByOtherAuthor(t, gitClient, "Teammate's change", "", nil)
+
+		_, err := stackClient.AdoptBranch("feature", "adopted", "main")
+		require.NoError(t, err)
+
+		stackCtx, err := stackClient.GetStackContextByName("adopted")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 1)
+		assertOtherAuthor(t, gitClient, stackCtx.ActiveChanges[0].CommitHash)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+
+		_, err := stackClient.AdoptBranch("feature", "adopted", "main")
+		assert.ErrorContains(t, err, "branch 'feature' has no commits on top of main")
+
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "First", "", nil)
+		_, err = stackClient.AdoptBranch("missing", "adopted", "main")
+		assert.ErrorContains(t, err, "branch 'missing' does not exist")
+		_, err = stackClient.AdoptBranch("feature", "adopted", "")
+		assert.ErrorContains(t, err, "base branch is required")
+
+		// A base that moved on after the branch was created
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Main moved", "", nil)
+		require.NoError(t, gitClient.CheckoutBranch("feature"))
+		_, err = stackClient.AdoptBranch("feature", "adopted", "main")
+		assert.ErrorContains(t, err, "branch 'feature' is not based on main")
+
+		testutil.WriteFile(t, gitClient.GitRoot(), "dirty.txt", "uncommitted")
+		_, err = stackClient.AdoptBranch("feature", "adopted", "main")
+		assert.ErrorIs(t, err, ErrUncommittedChanges)
+		assert.False(t, stackClient.StackExists("adopted"))
+	})
+}
@@ -0,0 +1,123 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// archiveTimestampFormat is the suffix appended to archived stack directories: <name>-<timestamp>
+const archiveTimestampFormat = "20060102-150405"
+
+// ArchivedStack is a deleted stack whose metadata was kept in .git/stack/.archived
+type ArchivedStack struct {
+	ArchiveName string    // Directory name under .archived, used to restore the stack
+	StackName   string    // Name of the stack when it was deleted
+	ArchivedAt  time.Time // When the stack was deleted
+}
+
+func (c *Client) getArchiveRootDir() string {
+	return filepath.Join(c.getStacksRootDir(), ".archived")
+}
+
+// parseArchiveName splits an archive directory name into the stack name and archive time
+func parseArchiveName(archiveName string) (string, time.Time, bool) {
+	sep := len(archiveName) - len(archiveTimestampFormat) - 1
+	if sep <= 0 || archiveName[sep] != '-' {
+		return "", time.Time{}, false
+	}
+	archivedAt, err := time.ParseInLocation(archiveTimestampFormat, archiveName[sep+1:], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return archiveName[:sep], archivedAt, true
+}
+
+// ListArchivedStacks returns the archived stacks, most recently deleted first
+func (c *Client) ListArchivedStacks() ([]ArchivedStack, error) {
+	entries, err := os.ReadDir(c.getArchiveRootDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ArchivedStack{}, nil
+		}
+		return nil, fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	archived := []ArchivedStack{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		stackName, archivedAt, ok := parseArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+		archived = append(archived, ArchivedStack{
+			ArchiveName: entry.Name(),
+			StackName:   stackName,
+			ArchivedAt:  archivedAt,
+		})
+	}
+
+	slices.SortStableFunc(archived, func(a, b ArchivedStack) int {
+		return b.ArchivedAt.Compare(a.ArchivedAt)
+	})
+	return archived, nil
+}
+
+// RestoreStack moves an archived stack's metadata back to .git/stack/<name>. If the TOP
+// branch was deleted along with the stack, it is recreated at the stack's recorded BaseRef;
+// the stack's commits are not recovered. Refuses if a stack with the same name exists.
+func (c *Client) RestoreStack(archiveName string) error {
+	if archiveName == "" || archiveName == "." || archiveName == ".." || archiveName != filepath.Base(archiveName) {
+		return fmt.Errorf("invalid archive name '%s'", archiveName)
+	}
+
+	archivePath := filepath.Join(c.getArchiveRootDir(), archiveName)
+	data, err := os.ReadFile(filepath.Join(archivePath, "config.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("archived stack '%s' not found", archiveName)
+		}
+		return fmt.Errorf("failed to read archived stack config: %w", err)
+	}
+
+	var stack model.Stack
+	if err := json.Unmarshal(data, &stack); err != nil {
+		return fmt.Errorf("failed to parse archived stack config: %w", err)
+	}
+	if stack.Name == "" {
+		return fmt.Errorf("archived stack '%s' has no name", archiveName)
+	}
+
+	if c.StackExists(stack.Name) {
+		return fmt.Errorf("stack '%s' already exists: delete or rename it before restoring", stack.Name)
+	}
+
+	if !c.git.BranchExists(stack.Branch) {
+		baseRef := stack.BaseRef
+		if baseRef == "" {
+			baseRef = stack.Base
+		}
+		if err := c.git.CreateBranchAt(stack.Branch, baseRef); err != nil {
+			return fmt.Errorf("failed to recreate branch %s: %w", stack.Branch, err)
+		}
+		ui.Warningf("Recreated %s at the stack's base; its commits were not restored", stack.Branch)
+	}
+
+	if err := os.MkdirAll(c.getStacksRootDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create stacks directory: %w", err)
+	}
+	c.InvalidateContext(stack.Name)
+	if err := os.Rename(archivePath, c.getStackDir(stack.Name)); err != nil {
+		return fmt.Errorf("failed to restore stack metadata: %w", err)
+	}
+
+	return nil
+}
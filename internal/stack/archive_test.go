@@ -0,0 +1,93 @@
+package stack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestRestoreStack(t *testing.T) {
+	setup := func(t *testing.T) (*Client, *model.Stack) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		uuid := "1111111111111111"
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Test change", "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs:     map[string]*model.PR{uuid: {PRNumber: 101, State: "closed"}},
+		}))
+
+		_, err = stackClient.DeleteStack("test-stack", DeleteOptions{})
+		require.NoError(t, err)
+		return stackClient, stack
+	}
+
+	t.Run("ArchiveThenRestore", func(t *testing.T) {
+		stackClient, stack := setup(t)
+
+		archived, err := stackClient.ListArchivedStacks()
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		assert.Equal(t, "test-stack", archived[0].StackName)
+		assert.WithinDuration(t, time.Now(), archived[0].ArchivedAt, time.Minute)
+		assert.False(t, stackClient.git.BranchExists(stack.Branch))
+
+		require.NoError(t, stackClient.RestoreStack(archived[0].ArchiveName))
+
+		restored, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, stack.Branch, restored.Branch)
+
+		// TOP is recreated at the recorded base
+		assert.True(t, stackClient.git.BranchExists(stack.Branch))
+		topHash, err := stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, stack.BaseRef, topHash)
+
+		prs, err := stackClient.LoadPRs("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, 101, prs.PRs["1111111111111111"].PRNumber)
+
+		archived, err = stackClient.ListArchivedStacks()
+		require.NoError(t, err)
+		assert.Empty(t, archived)
+	})
+
+	t.Run("RefuseWhenStackExists", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		archived, err := stackClient.ListArchivedStacks()
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+
+		err = stackClient.RestoreStack(archived[0].ArchiveName)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "stack 'test-stack' already exists")
+	})
+
+	t.Run("UnknownArchive", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		err := stackClient.RestoreStack("missing-20200101-000000")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "archived stack 'missing-20200101-000000' not found")
+	})
+}
@@ -4,57 +4,112 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+
+	"github.com/bjulian5/stack/internal/config"
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/git"
 	"github.com/bjulian5/stack/internal/model"
 	"github.com/bjulian5/stack/internal/ui"
 )
 
-// DefaultSyncThreshold is the time threshold after which a stack is considered stale
-// and needs to be refreshed to check for merged PRs on GitHub
-const DefaultSyncThreshold = 5 * time.Minute
-
 var validStackNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
 
+// maxStackNameLength keeps <user>/stack-<name>/<uuid> branch names well within git's ref
+// name limits
+const maxStackNameLength = 100
+
+// reservedStackNames are entries of .git/stack that a stack's directory would collide with
+var reservedStackNames = []string{".archived", ".lock", "hooks", "templates", "config.json", "split-state.json", "pr_template.md"}
+
 // GitClient defines the git operations needed by Stack Client
 type GitClient interface {
 	GetCurrentBranch() (string, error)
 	BranchExists(name string) bool
 	CreateAndCheckoutBranch(name string) error
 	CheckoutBranch(name string) error
+	CheckoutDetached(ref string) error
 	GetCommits(branch, base string) ([]git.Commit, error)
 	GetCommitHash(ref string) (string, error)
+	ResolveCommit(rev string) (string, error)
+	SymbolicFullName(rev string) string
+	IsLocalBranch(name string) bool
+	ListBranches(pattern string) ([]string, error)
+	GetCommit(hash string) (git.Commit, error)
+	GetCommitMeta(hash string) (git.CommitMeta, error)
+	GetParentCommit(commitHash string) (string, error)
+	GetCommitTree(commitHash string) (string, error)
+	CommitTreeAs(treeHash string, parentHash string, message string, author git.CommitMeta) (string, error)
+	TreeWithPaths(base string, source string, paths []string) (string, error)
 	GitRoot() string
+	GitCommonDir() (string, error)
 	GetRemoteName() (string, error)
+	ResolveRemote(branch string) (string, error)
+	GetPushRemoteName() (string, error)
 	Fetch(remote string) error
 	Rebase(onto string) error
+	RebaseOnto(newBase string, upstream string, branch string) error
+	RebaseContinue() error
+	IsRebaseInProgress() bool
+	ConflictedFiles() ([]string, error)
+	AbortRebase() error
+	Stash() (string, error)
+	StashPop(hash string) error
 	DeleteBranch(branchName string, force bool) error
 	DeleteRemoteBranch(branchName string) error
+	CherryPick(commitHash string) error
 	ResetHard(ref string) error
+	ResetSoft(ref string) error
 	CreateAndCheckoutBranchAt(name string, commitHash string) error
+	EnsureBranchAt(name string, commitHash string) error
 	GetUpstreamBranch(branch string) (string, error)
+	CountCommitsBetween(base, head string) (int, error)
+	PatchesInUpstream(upstream, head, limit string) (map[string]bool, error)
+	AheadBehind(a, b string) (ahead, behind int, err error)
 	CreateBranchAt(branchName string, ref string) error
 	UpdateRef(branchName string, commitHash string) error
 	HasUncommittedChanges() (bool, error)
+	CommitEmpty(message string) error
+	DiffStat(commitHash string) (git.DiffStat, error)
+	DiffStatBetween(from string, to string) (git.DiffStat, error)
+	Diff(from string, to string) (string, error)
+	DiffSummary(from string, to string) (string, error)
+	LsRemoteHeads(remote string, pattern string) (map[string]string, error)
+	IsAncestor(ancestor, descendant string) bool
+	ContainsChanges(base string, from string, to string) (bool, error)
+	ReplayCommits(stackBranch string, onto string, commitHashes []string) error
+	Push(branch string, force bool) error
+	PushWithLease(branch string, expected string) error
+	RenameBranch(oldName string, newName string) error
+	Status() (git.StatusSummary, error)
 }
 
 // GithubClient defines the GitHub operations needed by Stack Client
 type GithubClient interface {
 	GetRepoInfo() (owner string, repoName string, err error)
+	GetDefaultBranch() (string, error)
 	MarkPRDraft(prNumber int) error
 	MarkPRReady(prNumber int) error
 	BatchGetPRs(owner, repoName string, prNumbers []int) (*gh.BatchPRsResult, error)
 	UpdatePRComment(commentID string, body string) error
 	ListPRComments(prNumber int) ([]gh.Comment, error)
 	CreatePRComment(prNumber int, body string) (string, error)
+	DeletePRComment(commentID string) error
+	GetPRDiffStat(prNumber int) (*gh.PRDiffStat, error)
+	GetPRChecks(prNumber int) (*gh.ChecksSummary, error)
+	GetPRReview(prNumber int) (gh.ReviewState, error)
+	UpdatePRBase(prNumber int, base string) error
+	MergePR(prNumber int, method string) error
+	SyncPR(spec gh.PRSpec) (*gh.PR, error)
+	OpenPR(prNumber int) error
 }
 
 // Client provides stack operations
@@ -62,22 +117,44 @@ type Client struct {
 	git      GitClient
 	gh       GithubClient
 	gitRoot  string
+	gitDir   string // Common git directory; stack metadata lives under <gitDir>/stack
 	username string
+	config   *config.Config // Repository settings, loaded by NewClient (see loadConfig)
+
+	// contexts caches loaded stack contexts by stack name (see cachedStackContext). Stacks
+	// are loaded concurrently (e.g. GetCleanupCandidates), so access holds contextsMu.
+	contexts   map[string]*cachedContext
+	contextsMu sync.Mutex
+
+	// newGithubClient creates the GitHub client for a stack created against another host than
+	// the one gh is currently pointed at (see ghFor). hostClients caches them by host.
+	newGithubClient func(host string) GithubClient
+	hostClients     map[string]GithubClient
+	hostClientsMu   sync.Mutex
 }
 
 // NewClient creates a new stack client
-func NewClient(gitOps GitClient, ghClient GithubClient) *Client {
-	// TODO: update callsites to handle error
-	username, err := getUsername()
+func NewClient(gitOps GitClient, ghClient GithubClient) (*Client, error) {
+	gitRoot := gitOps.GitRoot()
+	gitDir, err := gitOps.GitCommonDir()
 	if err != nil {
-		panic(fmt.Sprintf("failed to get username: %v", err))
+		gitDir = filepath.Join(gitRoot, ".git")
+	}
+	c := &Client{
+		git:     gitOps,
+		gh:      ghClient,
+		gitRoot: gitRoot,
+		gitDir:  gitDir,
+	}
+
+	if err := c.loadConfig(); err != nil {
+		return nil, err
 	}
-	return &Client{
-		git:      gitOps,
-		gh:       ghClient,
-		gitRoot:  gitOps.GitRoot(),
-		username: username,
+	c.username, err = c.resolveUsername()
+	if err != nil {
+		return nil, err
 	}
+	return c, nil
 }
 
 // SetUsernameForTesting sets the username for testing purposes
@@ -85,20 +162,68 @@ func (c *Client) SetUsernameForTesting(username string) {
 	c.username = username
 }
 
+// ResolveStackName returns the on-disk name of the stack called name. Exact matches are
+// returned as-is; otherwise, unless exact is set, the existing stacks are searched
+// case-insensitively. A single match is used with a warning about the case difference, and
+// multiple matches are an error. If nothing matches, name is returned unchanged so the caller
+// reports the missing stack. With exact, a name that doesn't exist is an ErrStackNotFound
+// error, so loading the returned name never falls back to a case-insensitive match.
+func (c *Client) ResolveStackName(name string, exact bool) (string, error) {
+	if c.StackExists(name) {
+		return name, nil
+	}
+	if exact {
+		return "", fmt.Errorf("%w: '%s'", ErrStackNotFound, name)
+	}
+
+	entries, err := os.ReadDir(c.getStacksRootDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return name, nil
+		}
+		return "", fmt.Errorf("failed to read stacks directory: %w", err)
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if entry.IsDir() && strings.EqualFold(entry.Name(), name) && c.StackExists(entry.Name()) {
+			matches = append(matches, entry.Name())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		ui.Warningf("stack '%s' not found, using '%s'", name, matches[0])
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("stack name '%s' is ambiguous: matches %s (use the exact name)", name, strings.Join(matches, ", "))
+	}
+}
+
 func (c *Client) getStackDir(stackName string) string {
-	return filepath.Join(c.gitRoot, ".git", "stack", stackName)
+	return filepath.Join(c.gitDir, "stack", stackName)
 }
 
 func (c *Client) getStacksRootDir() string {
-	return filepath.Join(c.gitRoot, ".git", "stack")
+	return filepath.Join(c.gitDir, "stack")
 }
 
 func (c *Client) LoadStack(name string) (*model.Stack, error) {
+	name, err := c.ResolveStackName(name, false)
+	if err != nil {
+		return nil, err
+	}
+
 	stackDir := c.getStackDir(name)
 	configPath := filepath.Join(stackDir, "config.json")
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: '%s'", ErrStackNotFound, name)
+		}
 		return nil, fmt.Errorf("failed to read stack config: %w", err)
 	}
 
@@ -108,7 +233,7 @@ func (c *Client) LoadStack(name string) (*model.Stack, error) {
 	}
 
 	if stack.Owner == "" || stack.RepoName == "" {
-		if owner, repoName, err := c.gh.GetRepoInfo(); err == nil {
+		if owner, repoName, err := c.ghFor(&stack).GetRepoInfo(); err == nil {
 			stack.Owner = owner
 			stack.RepoName = repoName
 			_ = c.SaveStack(&stack)
@@ -119,6 +244,7 @@ func (c *Client) LoadStack(name string) (*model.Stack, error) {
 }
 
 func (c *Client) SaveStack(stack *model.Stack) error {
+	c.InvalidateContext(stack.Name)
 	stackDir := c.getStackDir(stack.Name)
 
 	if err := os.MkdirAll(stackDir, 0755); err != nil {
@@ -150,12 +276,54 @@ func (c *Client) CheckSyncStatus(stackName string) (*SyncStatus, error) {
 		return nil, fmt.Errorf("failed to load stack: %w", err)
 	}
 
+	return c.syncStatusFor(stack, c.SyncThreshold()), nil
+}
+
+// CheckSyncStatusAll returns the sync status of every stack, keyed by stack name. It only uses
+// local data (LastSynced and SyncHash against the current branch) and never calls GitHub, so
+// it is cheap enough for 'stack list'. A stack that fails to load is reported as
+// hash_check_failed instead of failing the whole report.
+func (c *Client) CheckSyncStatusAll() (map[string]*SyncStatus, error) {
+	statuses := make(map[string]*SyncStatus)
+
+	entries, err := os.ReadDir(c.getStacksRootDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return statuses, nil
+		}
+		return nil, fmt.Errorf("failed to read stacks directory: %w", err)
+	}
+
+	threshold := c.SyncThreshold()
+	for _, entry := range entries {
+		if !entry.IsDir() || !c.StackExists(entry.Name()) {
+			continue
+		}
+
+		stack, err := c.LoadStack(entry.Name())
+		if err != nil {
+			statuses[entry.Name()] = &SyncStatus{
+				NeedsSync: true,
+				Reason:    "hash_check_failed",
+				Warning:   fmt.Sprintf("Could not load stack: %v", err),
+			}
+			continue
+		}
+		statuses[stack.Name] = c.syncStatusFor(stack, threshold)
+	}
+
+	return statuses, nil
+}
+
+// syncStatusFor computes a stack's sync status from local data, treating syncs older than
+// threshold as stale (0 disables the time check)
+func (c *Client) syncStatusFor(stack *model.Stack, threshold time.Duration) *SyncStatus {
 	if stack.LastSynced.IsZero() {
 		return &SyncStatus{
 			NeedsSync: true,
 			Reason:    "never_synced",
 			Warning:   "Stack has never been synced with GitHub. Run 'stack refresh' to check for merged PRs.",
-		}, nil
+		}
 	}
 
 	currentHash, err := c.git.GetCommitHash(stack.Branch)
@@ -164,7 +332,7 @@ func (c *Client) CheckSyncStatus(stackName string) (*SyncStatus, error) {
 			NeedsSync: true,
 			Reason:    "hash_check_failed",
 			Warning:   "Could not verify stack sync status. Run 'stack refresh' to ensure consistency.",
-		}, nil
+		}
 	}
 
 	if currentHash != stack.SyncHash {
@@ -172,18 +340,77 @@ func (c *Client) CheckSyncStatus(stackName string) (*SyncStatus, error) {
 			NeedsSync: true,
 			Reason:    "commits_changed",
 			Warning:   "Stack has new commits since last sync. Run 'stack refresh' to ensure consistency with GitHub.",
-		}, nil
+		}
 	}
 
-	if time.Since(stack.LastSynced) > DefaultSyncThreshold {
+	if threshold > 0 && time.Since(stack.LastSynced) > threshold {
 		return &SyncStatus{
 			NeedsSync: true,
 			Reason:    "stale",
 			Warning:   "Stack sync is stale. Run 'stack refresh' to check for merged PRs.",
-		}, nil
+		}
+	}
+
+	return &SyncStatus{NeedsSync: false}
+}
+
+// BaseMovedHint returns a hint when the local base branch has moved away from the stored
+// BaseRef (e.g. after pulling the base without restacking). Positions and desired bases are
+// computed against BaseRef, so the hint explains why they may look out of date.
+// Returns an empty string when the base has not moved.
+func (c *Client) BaseMovedHint(stack *model.Stack) (string, error) {
+	if stack.BaseRef == "" {
+		return "", nil
+	}
+
+	baseHash, err := c.git.GetCommitHash(stack.Base)
+	if err != nil {
+		return "", fmt.Errorf("failed to get base branch hash: %w", err)
+	}
+
+	if baseHash == stack.BaseRef {
+		return "", nil
+	}
+
+	return fmt.Sprintf("local %s has moved since last restack - positions are computed against %s (run 'stack restack' to update)",
+		stack.Base, git.ShortHash(stack.BaseRef)), nil
+}
+
+// IsBaseBehindUpstream reports whether the upstream of the stack's base branch has commits
+// the stack isn't based on yet, and how many. The stack's recorded BaseRef (or the local base
+// when none is recorded) is compared with the upstream as of the last fetch; nothing is
+// fetched. A base without an upstream, or that isn't a local branch, is never behind.
+func (c *Client) IsBaseBehindUpstream(stackName string) (behind bool, count int, err error) {
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to load stack: %w", err)
+	}
+	if !c.git.IsLocalBranch(stack.Base) {
+		return false, 0, nil
+	}
+
+	upstream, err := c.git.GetUpstreamBranch(stack.Base)
+	if err != nil {
+		return false, 0, err
+	}
+	if upstream == "" {
+		return false, 0, nil
+	}
+
+	baseRef := stack.BaseRef
+	if baseRef == "" {
+		baseRef = stack.Base
+	}
+	upstreamHash, err := c.git.GetCommitHash(upstream)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get upstream hash: %w", err)
 	}
 
-	return &SyncStatus{NeedsSync: false}, nil
+	count, err = c.git.CountCommitsBetween(baseRef, upstreamHash)
+	if err != nil {
+		return false, 0, err
+	}
+	return count > 0, count, nil
 }
 
 func (c *Client) StackExists(name string) bool {
@@ -225,14 +452,25 @@ func (c *Client) GetStackContext() (*StackContext, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
-	stackName := extractStackName(currentBranch)
-	if stackName != "" {
+	if stackName, _, ok := c.parseStackBranch(currentBranch); ok {
 		return c.GetStackContextByName(stackName)
 	}
 
 	return &StackContext{}, nil
 }
 
+// CurrentStackName returns the name of the stack whose TOP or UUID branch is checked out, or
+// "" when the current branch isn't a stack branch. Unlike GetStackContext it doesn't load the
+// stack, so it also works for stacks that are too broken to load.
+func (c *Client) CurrentStackName() (string, error) {
+	currentBranch, err := c.git.GetCurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	stackName, _, _ := c.parseStackBranch(currentBranch)
+	return stackName, nil
+}
+
 // GetStackContextByName loads stack context for a specific stack by name.
 // This is useful for commands that operate on a stack without being on a stack branch.
 func (c *Client) GetStackContextByName(name string) (*StackContext, error) {
@@ -240,22 +478,43 @@ func (c *Client) GetStackContextByName(name string) (*StackContext, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
 	}
-	return c.getStackContextByName(name, currentBranch)
+	return c.getStackContextByName(name, currentBranch, false)
+}
+
+// GetStackContextForRepair loads a stack like GetStackContextByName, but doesn't reject the
+// problems that 'stack doctor --fix' and 'stack repair' exist to fix: duplicate PR numbers in
+// prs.json and commits sharing a PR-UUID (listed in StackContext.DuplicateUUIDs). Use it only
+// in the commands that report and fix those problems; the context isn't cached.
+func (c *Client) GetStackContextForRepair(name string) (*StackContext, error) {
+	currentBranch, err := c.git.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return c.getStackContextByName(name, currentBranch, true)
 }
 
-func (c *Client) getStackContextByName(name string, currentBranch string) (*StackContext, error) {
+// getStackContextByName loads the stack called name. A lenient load skips the validation that
+// GetStackContextForRepair documents and bypasses the context cache.
+func (c *Client) getStackContextByName(name string, currentBranch string, lenient bool) (*StackContext, error) {
 	if name == "" {
 		return nil, fmt.Errorf("stack name is required")
 	}
+	if !lenient {
+		if cached := c.cachedStackContext(name, currentBranch); cached != nil {
+			return cached, nil
+		}
+	}
 
 	// Load stack metadata
 	stack, err := c.LoadStack(name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load stack '%s': %w", name, err)
 	}
+	// The stack may have been matched case-insensitively
+	name = stack.Name
 
 	// Load all changes (merged + active + stale merged)
-	changes, err := c.getChangesForStack(stack)
+	changes, err := c.getChangesForStack(stack, lenient)
 	if err != nil {
 		return nil, err
 	}
@@ -276,20 +535,15 @@ func (c *Client) getStackContextByName(name string, currentBranch string) (*Stac
 		AllChanges:         changes.All,
 		ActiveChanges:      changes.Active,
 		StaleMergedChanges: changes.StaleMerged,
+		DuplicateUUIDs:     changes.DuplicateUUIDs,
 		username:           c.username,
 	}
 
-	if isUUIDBranch(currentBranch) {
-		currentStackName, uuid := extractUUIDFromBranch(currentBranch)
+	if currentStackName, suffix, ok := c.parseStackBranch(currentBranch); ok && suffix != "TOP" {
 		res.stackActive = currentStackName == name
-		res.currentUUID = uuid
+		res.currentUUID = suffix
 		res.onUUIDBranch = true
-		return res, nil
-	} else if IsStackBranch(currentBranch) {
-		currentStackName, uuid := extractUUIDFromBranch(currentBranch)
-		if uuid != "TOP" {
-			return nil, fmt.Errorf("unexpected stack branch format: %s", currentBranch)
-		}
+	} else if ok {
 		res.stackActive = currentStackName == name
 
 		if len(changes.All) > 0 {
@@ -297,6 +551,9 @@ func (c *Client) getStackContextByName(name string, currentBranch string) (*Stac
 			res.currentUUID = lastChange.UUID
 		}
 	}
+	if !lenient {
+		c.cacheStackContext(res, currentBranch)
+	}
 	return res, nil
 }
 
@@ -317,21 +574,34 @@ func (c *Client) SwitchStack(name string) error {
 	return nil
 }
 
-// CreateStack creates a new stack with the given name and base branch
+// CreateStack creates a new stack with the given name on top of baseBranch, which can be any
+// revision git resolves: a branch, a tag, a commit hash or HEAD~2. The TOP branch starts at
+// the resolved commit, which is recorded as BaseRef; Base is a readable label for it (see
+// baseLabel). An empty baseBranch means the repository's default branch on GitHub.
 func (c *Client) CreateStack(name string, baseBranch string) (*model.Stack, error) {
 	// Check if stack already exists
 	if c.StackExists(name) {
 		return nil, fmt.Errorf("stack '%s' already exists", name)
 	}
 
-	// Get current branch as base if not specified
+	if err := validateStackName(name); err != nil {
+		return nil, err
+	}
+
+	// Without a base, stack on the repository's default branch
 	if baseBranch == "" {
-		return nil, fmt.Errorf("base branch is required")
+		defaultBranch, err := c.gh.GetDefaultBranch()
+		if err != nil {
+			return nil, fmt.Errorf("no base branch given and failed to get the default branch: %w", err)
+		}
+		baseBranch = defaultBranch
 	}
 
-	if err := validateStackName(name); err != nil {
-		return nil, err
+	baseRef, err := c.git.ResolveCommit(baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base: %w", err)
 	}
+	base := c.baseLabel(baseBranch, baseRef)
 
 	// Format branch name
 	branchName := formatStackBranch(c.username, name)
@@ -342,7 +612,7 @@ func (c *Client) CreateStack(name string, baseBranch string) (*model.Stack, erro
 	}
 
 	// Create stack branch
-	if err := c.git.CreateAndCheckoutBranch(branchName); err != nil {
+	if err := c.git.CreateAndCheckoutBranchAt(branchName, baseRef); err != nil {
 		return nil, fmt.Errorf("failed to create stack branch: %w", err)
 	}
 
@@ -352,23 +622,20 @@ func (c *Client) CreateStack(name string, baseBranch string) (*model.Stack, erro
 		return nil, fmt.Errorf("failed to get repo info: %w", err)
 	}
 
-	baseRef, err := c.git.GetCommitHash(baseBranch)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get base branch hash: %w", err)
-	}
-
 	// Create stack metadata
 	s := &model.Stack{
 		Name:          name,
 		Branch:        branchName,
-		Base:          baseBranch,
+		Base:          base,
 		Owner:         owner,
 		RepoName:      repoName,
+		Host:          c.GitHubHost(),
 		Created:       time.Now(),
 		BaseRef:       baseRef,
 		MergedChanges: []model.Change{},
 		LastSynced:    time.Time{},
 		SyncHash:      baseRef,
+		HoldReady:     c.holdReadyNewStacks(),
 	}
 
 	if err := c.SaveStack(s); err != nil {
@@ -378,10 +645,42 @@ func (c *Client) CreateStack(name string, baseBranch string) (*model.Stack, erro
 	return s, nil
 }
 
+// baseLabel returns the name to record as a stack's base for rev, which resolved to hash:
+// branch, tag and remote branch names are kept (HEAD becomes the current branch), while
+// other revisions like HEAD~2 or a commit hash are recorded as the short hash so the label
+// doesn't drift as HEAD moves.
+func (c *Client) baseLabel(rev string, hash string) string {
+	fullName := c.git.SymbolicFullName(rev)
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
+		if name, ok := strings.CutPrefix(fullName, prefix); ok {
+			return name
+		}
+	}
+	return git.ShortHash(hash)
+}
+
+// validateStackName checks that name can be used as a stack's directory under .git/stack and
+// in its branch names
 func validateStackName(name string) error {
+	if name == "" {
+		return fmt.Errorf("invalid stack name: the name can't be empty")
+	}
+	if len(name) > maxStackNameLength {
+		return fmt.Errorf("invalid stack name '%s': names can be at most %d characters (got %d)", name, maxStackNameLength, len(name))
+	}
 	if !validStackNameRegex.MatchString(name) {
 		return fmt.Errorf("invalid stack name '%s': only letters, numbers, dots, underscores, and hyphens are allowed", name)
 	}
+	if slices.Contains(reservedStackNames, name) {
+		return fmt.Errorf("invalid stack name '%s': the name is reserved", name)
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid stack name '%s': names can't start or end with a dot", name)
+	}
+	// Git ref names can't contain ".." or end in ".lock"
+	if strings.Contains(name, "..") || strings.HasSuffix(name, ".lock") {
+		return fmt.Errorf("invalid stack name '%s': names can't contain '..' or end with '.lock'", name)
+	}
 	return nil
 }
 
@@ -391,14 +690,20 @@ type stackChanges struct {
 	All []*model.Change
 	// Active includes only unmerged changes currently on the stack branch.
 	Active []*model.Change
+	// Merged includes the changes recorded as merged in the stack metadata (Stack.MergedChanges).
+	Merged []*model.Change
 	// StaleMerged includes active changes that are merged on GitHub but still on the TOP branch (need refresh).
 	StaleMerged []*model.Change
+	// DuplicateUUIDs maps PR-UUIDs carried by more than one active commit to the commits' hashes
+	// (only set by a lenient load).
+	DuplicateUUIDs map[string][]string
 }
 
-// getChangesForStack loads all changes for a stack
-func (c *Client) getChangesForStack(s *model.Stack) (*stackChanges, error) {
+// getChangesForStack loads all changes for a stack. lenient skips the PR metadata validation
+// (see GetStackContextForRepair).
+func (c *Client) getChangesForStack(s *model.Stack, lenient bool) (*stackChanges, error) {
 	// Load PR tracking data
-	prData, err := c.LoadPRs(s.Name)
+	prData, err := c.loadPRs(s.Name, lenient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load PRs: %w", err)
 	}
@@ -424,6 +729,9 @@ func (c *Client) getChangesForStack(s *model.Stack) (*stackChanges, error) {
 		if mergedChanges[i].UUID != "" {
 			if pr, ok := prData.PRs[mergedChanges[i].UUID]; ok {
 				mergedChanges[i].PR = pr
+				if !pr.MergedAt.IsZero() {
+					mergedChanges[i].MergedAt = pr.MergedAt
+				}
 			}
 		}
 	}
@@ -441,12 +749,19 @@ func (c *Client) getChangesForStack(s *model.Stack) (*stackChanges, error) {
 	// Filter commits to only include those belonging to this stack
 	filteredCommits := make([]git.Commit, 0, len(activeCommits))
 	for _, commit := range activeCommits {
-		stackName := commit.Message.Trailers["PR-Stack"]
-		if stackName == s.Name {
+		if s.HasStackTrailer(commit.Message.Trailers["PR-Stack"]) {
 			filteredCommits = append(filteredCommits, commit)
 		}
 	}
 
+	var duplicates map[string][]string
+	if found := findDuplicateUUIDs(filteredCommits, prData); len(found) > 0 {
+		if !lenient {
+			return nil, &DuplicateUUIDError{Duplicates: found}
+		}
+		duplicates = found
+	}
+
 	for _, change := range c.commitsToChanges(filteredCommits, prData) {
 		if change.UUID == "" {
 			continue
@@ -501,7 +816,7 @@ func (c *Client) getChangesForStack(s *model.Stack) (*stackChanges, error) {
 		} else {
 			// Subsequent active changes: base off the previous active change's PR branch
 			prevChange := activeChanges[i-1]
-			desiredBase = fmt.Sprintf("%s/stack-%s/%s", c.username, s.Name, prevChange.UUID)
+			desiredBase = c.FormatUUIDBranch(s.Name, prevChange.UUID)
 		}
 
 		activeChanges[i].DesiredBase = desiredBase
@@ -538,12 +853,30 @@ func (c *Client) getChangesForStack(s *model.Stack) (*stackChanges, error) {
 	}
 
 	return &stackChanges{
-		All:         allChanges,
-		Active:      activeChanges,
-		StaleMerged: staleMergedChanges,
+		All:            allChanges,
+		Active:         activeChanges,
+		Merged:         mergedChanges,
+		StaleMerged:    staleMergedChanges,
+		DuplicateUUIDs: duplicates,
 	}, nil
 }
 
+// ClassifyChanges loads the changes of the stack called stackName, classified like a
+// StackContext: active changes on the TOP branch, merged changes recorded in the stack
+// metadata, and stale merged changes that are merged on GitHub but still on the TOP branch.
+// Unlike GetStackContextByName it doesn't depend on the current branch.
+func (c *Client) ClassifyChanges(stackName string) (active, merged, staleMerged []*model.Change, err error) {
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load stack '%s': %w", stackName, err)
+	}
+	changes, err := c.getChangesForStack(stack, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return changes.Active, changes.Merged, changes.StaleMerged, nil
+}
+
 // commitsToChanges converts git commits to Changes with the specified merged status
 func (c *Client) commitsToChanges(commits []git.Commit, prData *model.PRData) []*model.Change {
 	changes := make([]*model.Change, len(commits))
@@ -563,14 +896,26 @@ func (c *Client) commitsToChanges(commits []git.Commit, prData *model.PRData) []
 			CommitHash:  commit.Hash,
 			UUID:        uuid,
 			PR:          pr,
+			Trailers:    customTrailers(commit.Message.Trailers),
+			Author:      commit.Meta.AuthorName,
+		}
+		if pr != nil && pr.IsMerged() {
+			changes[i].MergedAt = pr.MergedAt
 		}
 	}
 
 	return changes
 }
 
-// LoadPRs loads PR tracking data for a stack
+// LoadPRs loads PR tracking data for a stack. Returns a *DuplicatePRError if two changes
+// reference the same PR number.
 func (c *Client) LoadPRs(stackName string) (*model.PRData, error) {
+	return c.loadPRs(stackName, false)
+}
+
+// loadPRs reads a stack's prs.json. Unless lenient, it rejects PR data that references a PR
+// number from more than one change.
+func (c *Client) loadPRs(stackName string, lenient bool) (*model.PRData, error) {
 	stackDir := c.getStackDir(stackName)
 	prsPath := filepath.Join(stackDir, "prs.json")
 
@@ -599,11 +944,18 @@ func (c *Client) LoadPRs(stackName string) (*model.PRData, error) {
 		prData.PRs = make(map[string]*model.PR)
 	}
 
+	if !lenient {
+		if err := validatePRNumbersUnique(&prData); err != nil {
+			return nil, err
+		}
+	}
+
 	return &prData, nil
 }
 
 // savePRs saves PR tracking data for a stack
 func (c *Client) savePRs(stackName string, prData *model.PRData) error {
+	c.InvalidateContext(stackName)
 	stackDir := c.getStackDir(stackName)
 
 	// Ensure version is set before saving
@@ -642,16 +994,67 @@ func (c *Client) MarkChangeReady(stackCtx *StackContext, change *model.Change) (
 	return c.markChangeStatus(stackCtx, change, false)
 }
 
+// MarkChangesDraft marks a batch of changes as draft. Visualization comments are synced once
+// for the whole batch rather than once per change.
+func (c *Client) MarkChangesDraft(stackCtx *StackContext, changes []*model.Change) ([]*MarkChangeStatusResult, error) {
+	return c.markChangesStatus(stackCtx, changes, true)
+}
+
+// MarkChangesReady marks a batch of changes as ready for review. Visualization comments are
+// synced once for the whole batch rather than once per change.
+func (c *Client) MarkChangesReady(stackCtx *StackContext, changes []*model.Change) ([]*MarkChangeStatusResult, error) {
+	return c.markChangesStatus(stackCtx, changes, false)
+}
+
 func (c *Client) markChangeStatus(stackCtx *StackContext, change *model.Change, isDraft bool) (*MarkChangeStatusResult, error) {
+	results, err := c.markChangesStatus(stackCtx, []*model.Change{change}, isDraft)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (c *Client) markChangesStatus(stackCtx *StackContext, changes []*model.Change, isDraft bool) ([]*MarkChangeStatusResult, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	results := make([]*MarkChangeStatusResult, 0, len(changes))
+	for _, change := range changes {
+		result, err := c.applyChangeStatus(c.ghFor(stackCtx.Stack), change, isDraft)
+		if err != nil {
+			// Persist the toggles that already reached GitHub before bailing out
+			if len(results) > 0 {
+				_ = stackCtx.Save()
+			}
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := stackCtx.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save stack context: %w", err)
+	}
+
+	if err := c.SyncVisualizationComments(stackCtx); err != nil {
+		return nil, fmt.Errorf("failed to sync visualization comments: %w", err)
+	}
+
+	return results, nil
+}
+
+// applyChangeStatus updates the draft status of a single change, marking the PR on GitHub if it
+// exists. It does not persist the change or sync visualization comments.
+func (c *Client) applyChangeStatus(github GithubClient, change *model.Change, isDraft bool) (*MarkChangeStatusResult, error) {
 	result := &MarkChangeStatusResult{}
 
 	if !change.IsLocal() && (change.PR.State == "open" || change.PR.State == "draft") {
 		if change.PR.LocalDraftStatus == change.PR.RemoteDraftStatus {
 			var err error
 			if isDraft {
-				err = c.gh.MarkPRDraft(change.PR.PRNumber)
+				err = github.MarkPRDraft(change.PR.PRNumber)
 			} else {
-				err = c.gh.MarkPRReady(change.PR.PRNumber)
+				err = github.MarkPRReady(change.PR.PRNumber)
 			}
 			if err != nil {
 				status := "ready"
@@ -679,14 +1082,6 @@ func (c *Client) markChangeStatus(stackCtx *StackContext, change *model.Change,
 		result.SyncedToGitHub = false
 	}
 
-	if err := stackCtx.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save stack context: %w", err)
-	}
-
-	if err := c.SyncVisualizationComments(stackCtx); err != nil {
-		return nil, fmt.Errorf("failed to sync visualization comments: %w", err)
-	}
-
 	return result, nil
 }
 
@@ -695,12 +1090,63 @@ type RefreshResult struct {
 	StaleMergedCount   int             // Number of PRs that were merged on GitHub but still on TOP (stale)
 	RemainingCount     int             // Number of PRs still active
 	StaleMergedChanges []*model.Change // The changes that were merged on GitHub but still on TOP (stale)
+	ClosedCount        int             // Number of active changes whose PR was closed without merging
+	ClosedChanges      []*model.Change // The changes whose PR was closed without merging (still on TOP)
+	BaseDriftChanges   []*model.Change // Open changes whose PR targets a different base on GitHub than DesiredBase
+	EditedChanges      []*model.Change // Open changes whose PR title or description was edited on GitHub since the last push
+	MissingPRs         []int           // PRs that were queried but not returned by GitHub (e.g. deleted); their metadata wasn't updated
+	Warnings           []string        // Problems that were tolerated because of RefreshOptions (e.g. an out-of-order merge)
+}
+
+// RefreshOptions controls how strictly a metadata refresh validates what it finds on GitHub
+type RefreshOptions struct {
+	// AllowOutOfOrderMerges records PRs merged above an unmerged change as merged, reporting
+	// the out-of-order merge in RefreshResult.Warnings instead of failing. Use it to get past a
+	// stack that was partially merged out of band and reconcile it by hand.
+	AllowOutOfOrderMerges bool
+}
+
+// batchGetPRs queries prNumbers in the stack's repository. The owner/repo cached in the stack
+// config go stale when the repository is renamed or transferred on GitHub, so when the
+// repository isn't found they're re-fetched from GitHub, saved, and the query retried once.
+func (c *Client) batchGetPRs(stack *model.Stack, prNumbers []int) (*gh.BatchPRsResult, error) {
+	github := c.ghFor(stack)
+	result, err := github.BatchGetPRs(stack.Owner, stack.RepoName, prNumbers)
+	if err == nil {
+		return result, nil
+	}
+	if !gh.IsRepoNotFound(err) {
+		return nil, fmt.Errorf("failed to batch query PRs: %w", err)
+	}
+
+	owner, repoName, infoErr := github.GetRepoInfo()
+	if infoErr != nil {
+		return nil, fmt.Errorf("repository %s/%s not found on GitHub and failed to look up the current repository: %w", stack.Owner, stack.RepoName, infoErr)
+	}
+	if owner == stack.Owner && repoName == stack.RepoName {
+		return nil, fmt.Errorf("repository %s/%s not found on GitHub: check that it exists and that 'gh' can access it: %w", owner, repoName, err)
+	}
+
+	ui.Warningf("Repository %s/%s moved to %s/%s, updating stack '%s'", stack.Owner, stack.RepoName, owner, repoName, stack.Name)
+	stack.Owner = owner
+	stack.RepoName = repoName
+	if err := c.SaveStack(stack); err != nil {
+		return nil, fmt.Errorf("failed to save stack config: %w", err)
+	}
+
+	result, err = github.BatchGetPRs(owner, repoName, prNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to batch query PRs in %s/%s: %w", owner, repoName, err)
+	}
+	return result, nil
 }
 
 // SyncPRMetadata queries GitHub and updates local metadata without modifying git state.
 // This is safe to call from any branch with any working tree state.
-// Returns info about what changed (merged PRs, etc).
-func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error) {
+// Returns info about what changed (merged PRs, etc). If progress is non-nil, it receives a
+// start event for every PR before GitHub is queried and a finish event as each PR is synced.
+// Problems tolerated because of opts are printed and returned in RefreshResult.Warnings.
+func (c *Client) SyncPRMetadata(stackCtx *StackContext, progress ProgressFunc, opts RefreshOptions) (*RefreshResult, error) {
 	if len(stackCtx.AllChanges) == 0 {
 		// Update sync metadata in Stack
 		commitHash, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
@@ -750,12 +1196,19 @@ func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error)
 		}, nil
 	}
 
-	result, err := c.gh.BatchGetPRs(stackCtx.Stack.Owner, stackCtx.Stack.RepoName, prNumbers)
+	for _, change := range stackCtx.AllChanges {
+		if !change.IsLocal() {
+			progress.Emit(NewProgressEvent(stackCtx, ProgressStart, change))
+		}
+	}
+
+	result, err := c.batchGetPRs(stackCtx.Stack, prNumbers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to batch query PRs: %w", err)
+		return nil, err
 	}
 
 	// Update ALL PR metadata from GitHub (not just merged ones)
+	var missingPRs []int
 	for _, change := range stackCtx.AllChanges {
 		if change.IsLocal() {
 			continue
@@ -763,7 +1216,12 @@ func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error)
 
 		prState, found := result.PRStates[change.PR.PRNumber]
 		if !found {
-			// PR was deleted or not found - skip
+			// PR was deleted or not found - skip, keeping its last known state
+			missingPRs = append(missingPRs, change.PR.PRNumber)
+			event := NewProgressEvent(stackCtx, ProgressFinish, change)
+			event.Action = "skipped"
+			event.Reason = "PR not found on GitHub"
+			progress.Emit(event)
 			continue
 		}
 
@@ -774,11 +1232,30 @@ func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error)
 			// merged from closed. Use the IsMerged flag to set the canonical state.
 			if prState.IsMerged {
 				change.PR.State = "merged"
+				if !prState.MergedAt.IsZero() {
+					change.PR.MergedAt = prState.MergedAt
+				}
+				change.MergedAt = change.PR.MergedAt
 			} else {
 				change.PR.State = strings.ToLower(prState.State)
 			}
 			change.PR.RemoteDraftStatus = prState.IsDraft
+			if prState.BaseRefName != "" {
+				change.PR.RemoteBase = prState.BaseRefName
+			}
+			// A PR always has a title, so an empty one means title and body weren't fetched
+			if prState.Title != "" {
+				change.PR.RemoteTitle = prState.Title
+				change.PR.RemoteBody = prState.Body
+			}
 		}
+
+		event := NewProgressEvent(stackCtx, ProgressFinish, change)
+		event.Action = change.PR.State
+		progress.Emit(event)
+	}
+	if len(missingPRs) > 0 {
+		ui.Warningf("PR(s) %s not found on GitHub (maybe deleted); keeping their last known state", formatPRNumbers(missingPRs))
 	}
 
 	// Calculate all merged changes
@@ -794,8 +1271,16 @@ func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error)
 	for _, change := range mergedChanges {
 		mergedPRNumbers[change.PR.PRNumber] = true
 	}
+	var warnings []string
 	if err := validateBottomUpMerges(stackCtx.AllChanges, mergedPRNumbers); err != nil {
-		return nil, err
+		if !opts.AllowOutOfOrderMerges {
+			return nil, err
+		}
+		// Keep only the first line of the error; its fix instructions assume a hard failure
+		summary, _, _ := strings.Cut(err.Error(), "\n")
+		warning := summary + "; recording it as merged anyway"
+		ui.Warningf("%s", warning)
+		warnings = append(warnings, warning)
 	}
 
 	// Update merged changes in Stack
@@ -829,36 +1314,84 @@ func (c *Client) SyncPRMetadata(stackCtx *StackContext) (*RefreshResult, error)
 	}
 	stackCtx.StaleMergedChanges = freshStaleMerged
 
+	// Closed-but-not-merged PRs stay on TOP; report them so the caller can decide
+	// whether to drop their commits.
+	var closedChanges []*model.Change
+	for _, change := range stackCtx.ActiveChanges {
+		if change.PR != nil && change.PR.State == "closed" {
+			closedChanges = append(closedChanges, change)
+		}
+	}
+
+	// PRs retargeted outside of stack (e.g. in the GitHub UI) keep their wrong base until the
+	// next push, so report them.
+	var baseDriftChanges []*model.Change
+	for _, change := range stackCtx.ActiveChanges {
+		if change.HasBaseDrift() {
+			baseDriftChanges = append(baseDriftChanges, change)
+		}
+	}
+
+	// PRs edited outside of stack keep the edits until the next push replaces them, so report them.
+	var editedChanges []*model.Change
+	for _, change := range stackCtx.ActiveChanges {
+		if change.HasTitleDrift() || change.HasBodyDrift() {
+			editedChanges = append(editedChanges, change)
+		}
+	}
+
 	remainingCount := len(stackCtx.ActiveChanges) - len(freshStaleMerged)
 	return &RefreshResult{
 		StaleMergedCount:   len(freshStaleMerged),
 		RemainingCount:     remainingCount,
 		StaleMergedChanges: freshStaleMerged,
+		ClosedCount:        len(closedChanges),
+		ClosedChanges:      closedChanges,
+		BaseDriftChanges:   baseDriftChanges,
+		EditedChanges:      editedChanges,
+		MissingPRs:         missingPRs,
+		Warnings:           warnings,
 	}, nil
 }
 
+// RefreshWouldModifyHistory reports whether applying a refresh would rewrite the TOP branch.
+// Returns true along with the stale merged changes whose commits would be dropped, or false
+// when a refresh would only update metadata. Call after SyncPRMetadata so the merge state is
+// current. Does not modify git or metadata.
+func (c *Client) RefreshWouldModifyHistory(stackCtx *StackContext) (bool, []*model.Change, error) {
+	if !stackCtx.IsStack() {
+		return false, nil, fmt.Errorf("not on a stack branch")
+	}
+	if len(stackCtx.StaleMergedChanges) == 0 {
+		return false, nil, nil
+	}
+	return true, stackCtx.StaleMergedChanges, nil
+}
+
 // ApplyRefresh applies a refresh by rebasing the TOP branch onto the latest base.
-// Requires: current branch is TOP, no uncommitted changes.
+// Requires: current branch is TOP, and no uncommitted changes unless autoStash is set, in
+// which case they are stashed around the rebase (see RestackOptions.AutoStash).
 // This performs the git operations to actually apply merged PR removals.
-func (c *Client) ApplyRefresh(stackCtx *StackContext, merged []*model.Change) error {
+func (c *Client) ApplyRefresh(stackCtx *StackContext, merged []*model.Change, autoStash bool) error {
 	// Validate on TOP branch (not editing a specific change)
 	if !stackCtx.IsStack() || stackCtx.OnUUIDBranch() {
 		currentBranch, _ := c.git.GetCurrentBranch()
-		return fmt.Errorf("must be on TOP branch to apply refresh, currently on %s", currentBranch)
+		return fmt.Errorf("%w to apply refresh, currently on %s", ErrNotOnTopBranch, currentBranch)
 	}
 
 	hasChanges, err := c.git.HasUncommittedChanges()
 	if err != nil {
 		return fmt.Errorf("failed to check working tree: %w", err)
 	}
-	if hasChanges {
-		return fmt.Errorf("cannot apply refresh with uncommitted changes - commit or stash first")
+	if hasChanges && !autoStash {
+		return fmt.Errorf("cannot apply refresh with %w - commit or stash first, or use --autostash", ErrUncommittedChanges)
 	}
 
 	// Rebase TOP branch using Restack (handles fetch + update-ref + rebase)
 	if err := c.Restack(stackCtx, RestackOptions{
-		Onto:  stackCtx.Stack.Base,
-		Fetch: true,
+		Onto:      stackCtx.Stack.Base,
+		Fetch:     true,
+		AutoStash: autoStash,
 	}); err != nil {
 		return fmt.Errorf("failed to rebase TOP: %w", err)
 	}
@@ -866,333 +1399,1420 @@ func (c *Client) ApplyRefresh(stackCtx *StackContext, merged []*model.Change) er
 	return nil
 }
 
-// RefreshStackMetadata syncs metadata from GitHub without staleness threshold.
-// IMPORTANT: This is read-only - never performs git operations.
-// Use for commands that need fresh state (edit, navigation, switch).
-// Returns fresh context with updated metadata.
-func (c *Client) RefreshStackMetadata(stackCtx *StackContext) (*StackContext, error) {
-	// Always sync metadata (no staleness check)
-	// This updates stackCtx in place and persists via stackCtx.Save()
-	_, err := c.SyncPRMetadata(stackCtx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sync with GitHub: %w", err)
-	}
+// PruneMergedChanges drops the stale merged commits from the bottom of the TOP branch without
+// fetching, by replaying the rest of the stack onto the local base with
+// 'git rebase --onto <base> <last merged commit> <TOP>'. The local base is assumed to already
+// contain the merges. Requires: current branch is TOP, no uncommitted changes, merged changes
+// at the bottom of the stack. Returns the number of commits dropped.
+func (c *Client) PruneMergedChanges(stackCtx *StackContext) (int, error) {
+	return c.dropMergedChanges(stackCtx, "prune", false)
+}
 
-	// stackCtx is already fresh - no need to reload
-	return stackCtx, nil
+// CollapseMergedChanges is PruneMergedChanges for when the base is expected to already contain
+// the merged changes: it first verifies that the local base has the stale merged commits'
+// changes (however they were merged) and refuses otherwise, so nothing is lost. Nothing is
+// fetched. The stack's BaseRef and UUID branches are updated. Returns the number of commits
+// dropped.
+func (c *Client) CollapseMergedChanges(stackCtx *StackContext) (int, error) {
+	return c.dropMergedChanges(stackCtx, "collapse", true)
 }
 
-// MaybeRefreshStackMetadata syncs metadata from GitHub only if staleness threshold exceeded.
-// IMPORTANT: This is read-only - never performs git operations.
-// Use for read-only display commands (list, status) where slight staleness is acceptable.
-// Returns fresh context with updated metadata (or existing context if still fresh).
-func (c *Client) MaybeRefreshStackMetadata(stackCtx *StackContext) (*StackContext, error) {
-	// Quick check: do we need sync?
-	syncStatus, err := c.CheckSyncStatus(stackCtx.StackName)
-	if err == nil && !syncStatus.NeedsSync {
-		// Already fresh, no action needed
-		return stackCtx, nil
+// dropMergedChanges drops the stale merged commits from the bottom of the TOP branch for
+// PruneMergedChanges and CollapseMergedChanges. op names the operation in errors. With
+// verifyBase, it refuses unless the local base already contains the merged changes.
+func (c *Client) dropMergedChanges(stackCtx *StackContext, op string, verifyBase bool) (int, error) {
+	if !stackCtx.IsStack() || stackCtx.OnUUIDBranch() {
+		currentBranch, _ := c.git.GetCurrentBranch()
+		return 0, fmt.Errorf("%w to %s merged changes, currently on %s", ErrNotOnTopBranch, op, currentBranch)
 	}
 
-	// Sync metadata (no git operations)
-	// This updates stackCtx in place and persists via stackCtx.Save()
-	_, err = c.SyncPRMetadata(stackCtx)
+	merged := stackCtx.StaleMergedChanges
+	if len(merged) == 0 {
+		return 0, nil
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
 	if err != nil {
-		return nil, fmt.Errorf("failed to sync with GitHub: %w", err)
+		return 0, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return 0, fmt.Errorf("cannot %s merged changes with %w; commit or stash them first", op, ErrUncommittedChanges)
 	}
 
-	// stackCtx is already fresh - no need to reload
-	return stackCtx, nil
-}
+	// Stale merged changes aren't in ActiveChanges, so rebuild the TOP branch's order from git
+	baseRef := stackCtx.Stack.BaseRef
+	if baseRef == "" {
+		baseRef = stackCtx.Stack.Base
+	}
+	commits, err := c.git.GetCommits(stackCtx.Stack.Branch, baseRef)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commits: %w", err)
+	}
+	onTop := make([]*model.Change, 0, len(commits))
+	for _, commit := range commits {
+		change := stackCtx.FindChange(commit.Message.Trailers["PR-UUID"])
+		if change == nil {
+			change = &model.Change{CommitHash: commit.Hash}
+		}
+		onTop = append(onTop, change)
+	}
+
+	mergedPRNumbers := make(map[int]bool, len(merged))
+	for _, change := range merged {
+		mergedPRNumbers[change.PR.PRNumber] = true
+	}
+	if err := validateBottomUpMerges(onTop, mergedPRNumbers); err != nil {
+		return 0, err
+	}
+
+	// Bottom-up merges mean the merged changes are the first len(merged) commits on TOP
+	lastMerged := onTop[len(merged)-1]
+
+	base := stackCtx.Stack.Base
+	ref, err := c.git.GetCommitHash(base)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get base hash: %w", err)
+	}
+
+	if verifyBase {
+		below, err := c.git.GetParentCommit(onTop[0].CommitHash)
+		if err != nil {
+			return 0, err
+		}
+		contained, err := c.git.ContainsChanges(ref, below, lastMerged.CommitHash)
+		if err != nil {
+			return 0, fmt.Errorf("failed to check base for merged changes: %w", err)
+		}
+		if !contained {
+			return 0, fmt.Errorf("%s doesn't contain the %d merged change(s) yet: run 'stack refresh' to fetch it and rebase", base, len(merged))
+		}
+	}
+
+	if err := c.git.RebaseOnto(base, lastMerged.CommitHash, stackCtx.Stack.Branch); err != nil {
+		if c.git.IsRebaseInProgress() {
+			return 0, fmt.Errorf("%w\n\n%sResolve the conflicts and run 'git rebase --continue', or run 'git rebase --abort' to leave the stack unchanged", err, formatConflictedFiles(c.conflictedFiles()))
+		}
+		return 0, err
+	}
+
+	// The dropped commits are no longer on TOP, so the stack config is now the only record of them
+	for _, change := range merged {
+		recorded := slices.ContainsFunc(stackCtx.Stack.MergedChanges, func(m model.Change) bool { return m.UUID == change.UUID })
+		if !recorded {
+			stackCtx.Stack.MergedChanges = append(stackCtx.Stack.MergedChanges, *change)
+		}
+	}
+
+	if err := c.finishRestack(stackCtx.Stack, base, ref); err != nil {
+		return 0, err
+	}
+	return len(merged), nil
+}
+
+// RefreshStackMetadata syncs metadata from GitHub without staleness threshold.
+// IMPORTANT: This is read-only - never performs git operations.
+// Use for commands that need fresh state (edit, navigation, switch).
+// Returns fresh context with updated metadata; warnings allowed by opts are printed by
+// SyncPRMetadata.
+func (c *Client) RefreshStackMetadata(stackCtx *StackContext, opts RefreshOptions) (*StackContext, error) {
+	// Always sync metadata (no staleness check)
+	// This updates stackCtx in place and persists via stackCtx.Save()
+	_, err := c.SyncPRMetadata(stackCtx, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync with GitHub: %w", err)
+	}
+
+	// stackCtx is already fresh - no need to reload
+	return stackCtx, nil
+}
+
+// MaybeRefreshStackMetadata syncs metadata from GitHub only if staleness threshold exceeded.
+// IMPORTANT: This is read-only - never performs git operations.
+// Use for read-only display commands (list, status) where slight staleness is acceptable.
+// Returns fresh context with updated metadata (or existing context if still fresh).
+func (c *Client) MaybeRefreshStackMetadata(stackCtx *StackContext) (*StackContext, error) {
+	// Quick check: do we need sync?
+	syncStatus, err := c.CheckSyncStatus(stackCtx.StackName)
+	if err == nil && !syncStatus.NeedsSync {
+		// Already fresh, no action needed
+		return stackCtx, nil
+	}
+
+	// Sync metadata (no git operations)
+	// This updates stackCtx in place and persists via stackCtx.Save()
+	_, err = c.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync with GitHub: %w", err)
+	}
+
+	// stackCtx is already fresh - no need to reload
+	return stackCtx, nil
+}
 
 // IsChangeMerged returns true if a change has been merged on GitHub
 func (c *Client) IsChangeMerged(change *model.Change) bool {
 	return !change.IsLocal() && change.PR != nil && strings.ToLower(change.PR.State) == "merged"
 }
 
-// fetchRemote fetches from the remote repository
-func (c *Client) fetchRemote() error {
-	remote, err := c.git.GetRemoteName()
-	if err != nil {
-		return err
+// fetchRemote fetches from the remote that branch resolves to
+func (c *Client) fetchRemote(branch string) error {
+	remote, err := c.git.ResolveRemote(branch)
+	if err != nil {
+		return err
+	}
+
+	if err := c.git.Fetch(remote); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type RestackOptions struct {
+	// Onto specifies the base branch to rebase onto (required)
+	Onto string
+
+	// Fetch from remote before rebasing
+	Fetch bool
+
+	// AutoStash stashes uncommitted changes before rebasing and reapplies them afterwards,
+	// like git's rebase.autoStash
+	AutoStash bool
+}
+
+// ErrRebaseConflict is returned by Restack and ContinueRestack when the rebase stops on
+// conflicts. The repository is left mid-rebase and the restack is recorded in the stack's
+// rebase state so ContinueRestack can finish it.
+type ErrRebaseConflict struct {
+	StackName  string
+	TargetBase string
+	Files      []string // Files with unresolved conflicts, if they could be listed
+	Err        error    // Error from git
+}
+
+func (e *ErrRebaseConflict) Error() string {
+	return fmt.Sprintf("rebase conflicts while restacking '%s' onto %s.\n\n"+
+		"%s"+
+		"To resolve:\n"+
+		"  1. Resolve conflicts in your files\n"+
+		"  2. git add <resolved-files>\n"+
+		"  3. stack restack --continue\n\n"+
+		"To give up and keep the stack as it was:\n"+
+		"  stack restack --abort\n\n"+
+		"Error: %v", e.StackName, e.TargetBase, formatConflictedFiles(e.Files), e.Err)
+}
+
+// formatConflictedFiles lists conflicted files for an error message, followed by a blank
+// line, or returns "" when there are none
+func formatConflictedFiles(files []string) string {
+	if len(files) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("Conflicted files:\n")
+	for _, file := range files {
+		sb.WriteString("  " + file + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// conflictedFiles returns the files left conflicted by a stopped rebase. Listing them is only
+// for the error message, so a failure to list them is ignored.
+func (c *Client) conflictedFiles() []string {
+	files, err := c.git.ConflictedFiles()
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+// Help renders the conflicted files and the commands to resolve or abort the restack as a
+// panel, for commands that print it in place of Error's plain text
+func (e *ErrRebaseConflict) Help() string {
+	operation := fmt.Sprintf("Restacking '%s' onto %s", e.StackName, e.TargetBase)
+	return ui.RenderConflictHelp(operation, e.Files, "stack restack --continue", "stack restack --abort")
+}
+
+func (e *ErrRebaseConflict) Unwrap() error {
+	return e.Err
+}
+
+// Restack rebases the stack on top of the specified base branch.
+// Always updates stack metadata with the new base (idempotent if unchanged).
+// If opts.Fetch is true, fetches from remote and updates local base ref before rebasing.
+// If the rebase stops on conflicts, the restack is saved and *ErrRebaseConflict is returned.
+// With opts.AutoStash, uncommitted changes are stashed before the rebase and reapplied once the
+// restack finishes, or once it is continued or aborted after conflicts.
+func (c *Client) Restack(stackCtx *StackContext, opts RestackOptions) error {
+	targetBase := opts.Onto
+
+	if opts.Fetch {
+		ui.Info("Fetching from remote...")
+		if err := c.fetchRemote(targetBase); err != nil {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+
+		// Tags, commits and remote branches have no local branch to fast-forward
+		if c.git.IsLocalBranch(targetBase) {
+			if err := c.UpdateLocalBaseRef(targetBase); err != nil {
+				// Non-fatal: show warning and continue
+				ui.Warningf("could not update local base ref: %v", err)
+			}
+		}
+	}
+
+	if err := c.validateRestackTarget(stackCtx, targetBase); err != nil {
+		return err
+	}
+
+	ref, err := c.git.GetCommitHash(targetBase)
+	if err != nil {
+		return fmt.Errorf("failed to get target base hash: %w", err)
+	}
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+
+	var stash string
+	if opts.AutoStash {
+		if stash, err = c.git.Stash(); err != nil {
+			return fmt.Errorf("failed to stash uncommitted changes: %w", err)
+		}
+	}
+
+	if err := c.git.Rebase(targetBase); err != nil {
+		if !c.git.IsRebaseInProgress() {
+			if stash != "" {
+				if popErr := c.popAutoStash(stash); popErr != nil {
+					return fmt.Errorf("%w (and %v)", err, popErr)
+				}
+			}
+			return err
+		}
+		if saveErr := c.SaveRebaseState(stackCtx.StackName, RebaseState{
+			OriginalStackHead: originalHead,
+			StackBranch:       stackCtx.Stack.Branch,
+			TargetBase:        targetBase,
+			TargetBaseRef:     ref,
+			AutoStash:         stash,
+		}); saveErr != nil {
+			return fmt.Errorf("%w (and failed to save rebase state: %v)", err, saveErr)
+		}
+		return &ErrRebaseConflict{StackName: stackCtx.StackName, TargetBase: targetBase, Files: c.conflictedFiles(), Err: err}
+	}
+
+	if err := c.finishRestack(stackCtx.Stack, targetBase, ref); err != nil {
+		// The rebase itself went through, so the changes can go back on the rebased stack
+		if stash != "" {
+			if popErr := c.popAutoStash(stash); popErr != nil {
+				return fmt.Errorf("%w (and %v)", err, popErr)
+			}
+		}
+		return err
+	}
+	if stash != "" {
+		return c.popAutoStash(stash)
+	}
+	return nil
+}
+
+// popAutoStash reapplies the stash with the given commit hash made by an autostash restack.
+// If it conflicts with the restacked commits, the conflicts are left in the working tree and
+// the stash is kept.
+func (c *Client) popAutoStash(hash string) error {
+	if err := c.git.StashPop(hash); err != nil {
+		return fmt.Errorf("your stashed changes could not be reapplied after the restack: resolve any conflicts, then run 'git stash drop' (the changes are still in 'git stash list' as %s): %w", git.ShortHash(hash), err)
+	}
+	return nil
+}
+
+// validateRestackTarget checks that the stack can be rebased onto targetBase. The target must
+// exist, and the base can't change while changes merged into the old base are still on the
+// stack branch, since only a refresh against the old base can drop them. A warning is shown
+// if the target doesn't contain the current base, as the old base's extra commits would be
+// rebased along with the stack.
+func (c *Client) validateRestackTarget(stackCtx *StackContext, targetBase string) error {
+	if targetBase == "" {
+		return fmt.Errorf("no base branch to restack onto")
+	}
+	if !c.git.BranchExists(targetBase) {
+		return fmt.Errorf("cannot restack onto '%s': branch does not exist", targetBase)
+	}
+
+	oldBase := stackCtx.Stack.Base
+	if targetBase != oldBase && len(stackCtx.StaleMergedChanges) > 0 {
+		return fmt.Errorf("cannot move stack '%s' from %s to %s: %d change(s) merged into %s are still on the stack branch; run 'stack refresh' first",
+			stackCtx.StackName, oldBase, targetBase, len(stackCtx.StaleMergedChanges), oldBase)
+	}
+
+	if baseRef := stackCtx.Stack.BaseRef; baseRef != "" && !c.git.IsAncestor(baseRef, targetBase) {
+		if targetBase == oldBase {
+			ui.Warningf("%s no longer contains the stack's recorded base %s (was it force-pushed?)", targetBase, git.ShortHash(baseRef))
+		} else {
+			ui.Warningf("%s doesn't contain the stack's current base %s; commits from %s that aren't in %s will be rebased along with the stack",
+				targetBase, git.ShortHash(baseRef), oldBase, targetBase)
+		}
+	}
+	return nil
+}
+
+// ContinueRestack finishes a restack that stopped on conflicts. If the rebase is still in
+// progress it is continued with 'git rebase --continue'; once it completes, the stack's base
+// and UUID branches are updated. If the rebase was aborted, the saved restack is discarded
+// and the stack keeps its old base. Returns *ErrRebaseConflict if the rebase stops again.
+func (c *Client) ContinueRestack(stackName string) error {
+	state, err := c.LoadRebaseState(stackName)
+	if err != nil {
+		return err
+	}
+	if state.TargetBase == "" {
+		return fmt.Errorf("no restack in progress for stack '%s'", stackName)
+	}
+
+	if c.git.IsRebaseInProgress() {
+		if err := c.git.RebaseContinue(); err != nil {
+			if c.git.IsRebaseInProgress() {
+				return &ErrRebaseConflict{StackName: stackName, TargetBase: state.TargetBase, Files: c.conflictedFiles(), Err: err}
+			}
+			return err
+		}
+	}
+
+	head, err := c.git.GetCommitHash(state.StackBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+	if !c.git.IsAncestor(state.TargetBaseRef, head) {
+		// The rebase was aborted and the stack branch is back where it started
+		if err := c.ClearRebaseState(stackName); err != nil {
+			return err
+		}
+		if state.AutoStash != "" {
+			if err := c.popAutoStash(state.AutoStash); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("the restack onto %s was aborted; the stack was left unchanged", state.TargetBase)
+	}
+
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	if err := c.finishRestack(stack, state.TargetBase, state.TargetBaseRef); err != nil {
+		return err
+	}
+	if err := c.ClearRebaseState(stackName); err != nil {
+		return err
+	}
+	if state.AutoStash != "" {
+		return c.popAutoStash(state.AutoStash)
+	}
+	return nil
+}
+
+// AbortStackOperation gives up on a stack operation that stopped on rebase conflicts: the
+// in-progress rebase is aborted, the TOP branch is reset to the head recorded in the stack's
+// rebase state, the UUID branches are moved back, and the rebase state is cleared. An
+// amend that triggered the rebase is discarded along with it.
+func (c *Client) AbortStackOperation(stackName string) error {
+	state, err := c.LoadRebaseState(stackName)
+	if err != nil {
+		return err
+	}
+	if state.OriginalStackHead == "" || state.StackBranch == "" {
+		return fmt.Errorf("rebase state for stack '%s' doesn't record the original stack head", stackName)
+	}
+
+	if c.git.IsRebaseInProgress() {
+		if err := c.git.AbortRebase(); err != nil {
+			return err
+		}
+	}
+
+	currentBranch, err := c.git.GetCurrentBranch()
+	if err == nil && currentBranch == state.StackBranch {
+		err = c.git.ResetHard(state.OriginalStackHead)
+	} else {
+		// Usually detached after aborting a rebase of the commits above an amended change
+		if err = c.git.UpdateRef(state.StackBranch, state.OriginalStackHead); err == nil {
+			err = c.git.CheckoutBranch(state.StackBranch)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", state.StackBranch, err)
+	}
+
+	if _, err := c.UpdateUUIDBranches(stackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+	if err := c.ClearRebaseState(stackName); err != nil {
+		return err
+	}
+	if state.AutoStash != "" {
+		return c.popAutoStash(state.AutoStash)
+	}
+	return nil
+}
+
+// finishRestack records the new base of a rebased stack and moves its UUID branches to the
+// rebased commits
+func (c *Client) finishRestack(stack *model.Stack, targetBase string, ref string) error {
+	stack.BaseRef = ref
+	stack.Base = targetBase
+	if err := c.SaveStack(stack); err != nil {
+		return fmt.Errorf("failed to update stack metadata: %w", err)
+	}
+
+	// Point existing UUID branches at the rebased commits so editing a change afterwards
+	// doesn't find them stale
+	if _, err := c.UpdateUUIDBranches(stack.Name); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+	return nil
+}
+
+// UpdateLocalBaseRef updates the local base branch ref to match its upstream
+func (c *Client) UpdateLocalBaseRef(baseBranch string) error {
+	upstream, err := c.git.GetUpstreamBranch(baseBranch)
+	if err != nil {
+		return err
+	}
+	if upstream == "" {
+		return fmt.Errorf("no upstream tracking branch configured for %s", baseBranch)
+	}
+
+	upstreamHash, err := c.git.GetCommitHash(upstream)
+	if err != nil {
+		return err
+	}
+
+	// Get current hash of local base (may not exist, that's ok)
+	currentHash, err := c.git.GetCommitHash(baseBranch)
+	if err != nil {
+		// Branch doesn't exist locally - create it at upstream's commit
+		if err := c.git.CreateBranchAt(baseBranch, upstreamHash); err != nil {
+			return err
+		}
+		ui.Infof("Created local branch %s at %s", baseBranch, upstreamHash[:7])
+		return nil
+	}
+
+	if upstreamHash != currentHash {
+		if err := c.git.UpdateRef(baseBranch, upstreamHash); err != nil {
+			return err
+		}
+		ui.Infof("Updating %s: %s..%s", baseBranch, currentHash[:7], upstreamHash[:7])
+	}
+	return nil
+}
+
+// NavigateRelative returns the active change delta positions away from the current change
+// (positive moves up toward TOP, negative moves down toward the base). Merged changes are
+// skipped since only ActiveChanges are considered. When the move would go past the top or
+// bottom, the nearest active change is returned with atBoundary set instead of an error, so
+// callers can report "already at top" themselves. A current change that is not active (a
+// merged change still on the TOP branch) sits below the first active change: moving up from
+// it starts at the first active change, and moving down is refused since only merged changes
+// are below it.
+func (c *Client) NavigateRelative(stackCtx *StackContext, delta int) (target *model.Change, atBoundary bool, err error) {
+	if len(stackCtx.ActiveChanges) == 0 {
+		return nil, false, fmt.Errorf("no active changes in stack: all changes are merged")
+	}
+
+	current := stackCtx.FindChange(stackCtx.ChangeID())
+	if current == nil {
+		return nil, false, fmt.Errorf("current change is not a valid change in the stack")
+	}
+	if current.ActivePosition == 0 && delta < 0 {
+		return nil, false, fmt.Errorf("change #%d is merged but still on the stack branch, so there's no active change below it: run 'stack refresh' first", current.Position)
+	}
+
+	position := current.ActivePosition + delta
+	if position < 1 {
+		position, atBoundary = 1, true
+	} else if position > len(stackCtx.ActiveChanges) {
+		position, atBoundary = len(stackCtx.ActiveChanges), true
+	}
+
+	return stackCtx.ActiveChanges[position-1], atBoundary, nil
+}
+
+// EditOptions configures CheckoutChangeForEditing
+type EditOptions struct {
+	// StayOnUUIDBranch keeps the user on the UUID branch for the topmost change instead of
+	// switching to the TOP branch, so the change can be amended in isolation
+	StayOnUUIDBranch bool
+}
+
+// CheckoutChangeForEditing checks out a UUID branch for the given change, creating it if needed.
+// If the branch already exists but points to a different commit, it syncs it to the current commit.
+// Returns the branch name that was checked out. It refuses to run with uncommitted changes,
+// which 'git checkout -B' would otherwise carry over onto the change.
+func (c *Client) CheckoutChangeForEditing(stackCtx *StackContext, change *model.Change, opts EditOptions) (string, error) {
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return "", fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return "", fmt.Errorf("cannot check out change #%d with %w; commit or stash them first", change.Position, ErrUncommittedChanges)
+	}
+
+	// Format UUID branch name
+	branchName := stackCtx.FormatUUIDBranch(change.UUID)
+
+	// A UUID branch left at an older commit (e.g. after an amend) is moved to the change
+	previousHash, _ := c.git.GetCommitHash(branchName)
+	if err := c.git.EnsureBranchAt(branchName, change.CommitHash); err != nil {
+		return "", err
+	}
+	if previousHash != "" && previousHash != change.CommitHash {
+		ui.Warningf("Synced branch to current commit (was at %s, now at %s)",
+			git.ShortHash(previousHash), git.ShortHash(change.CommitHash))
+	}
+
+	// If this is the topmost change, checkout the TOP branch instead of staying on UUID branch
+	// This allows users to work on the entire stack when at the top position
+	if change.Position == len(stackCtx.AllChanges) && !opts.StayOnUUIDBranch {
+		if err := c.git.CheckoutBranch(stackCtx.Stack.Branch); err != nil {
+			return "", fmt.Errorf("failed to checkout TOP branch: %w", err)
+		}
+		// Return the TOP branch name to indicate where we ended up
+		return stackCtx.Stack.Branch, nil
+	}
+
+	return branchName, nil
+}
+
+// FindChangeByRef resolves any git ref (HEAD, a branch name, HEAD~2, a hash) to the stack change
+// containing that commit. Commits are matched by hash first, then by their PR-UUID trailer so that
+// amended or otherwise rewritten copies of a change still resolve.
+func (c *Client) FindChangeByRef(stackCtx *StackContext, ref string) (*model.Change, error) {
+	hash, err := c.git.GetCommitHash(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	for _, change := range stackCtx.AllChanges {
+		if change.CommitHash == hash {
+			return change, nil
+		}
+	}
+
+	commit, err := c.git.GetCommit(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", git.ShortHash(hash), err)
+	}
+
+	if stackCtx.Stack.HasStackTrailer(commit.Message.Trailers["PR-Stack"]) {
+		if change := stackCtx.FindChange(commit.Message.Trailers["PR-UUID"]); change != nil {
+			return change, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s (%s) does not point to a change in stack '%s'", ref, git.ShortHash(hash), stackCtx.StackName)
+}
+
+// UpdateUUIDBranches reloads stack context and updates all UUID branches to point to their new commit locations
+// Returns the number of branches that were actually updated
+func (c *Client) UpdateUUIDBranches(stackName string) (int, error) {
+	ctx, err := c.GetStackContextByName(stackName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reload stack context: %w", err)
+	}
+
+	updatedCount := 0
+	for _, change := range ctx.ActiveChanges {
+		if change.UUID == "" {
+			continue
+		}
+
+		branchName := ctx.FormatUUIDBranch(change.UUID)
+
+		if !c.git.BranchExists(branchName) {
+			continue
+		}
+
+		currentHash, err := c.git.GetCommitHash(branchName)
+		if err == nil && currentHash == change.CommitHash {
+			continue
+		}
+
+		if err := c.git.UpdateRef(branchName, change.CommitHash); err != nil {
+			return updatedCount, fmt.Errorf("failed to update branch %s: %w", branchName, err)
+		}
+		updatedCount++
+	}
+
+	return updatedCount, nil
+}
+
+// HashDrift describes a change whose stored PR commit hash no longer matches the TOP branch
+type HashDrift struct {
+	Change *model.Change
+	Actual string // Commit hash on the TOP branch carrying the change's PR-UUID
+}
+
+// FindHashDrift re-reads the TOP branch and returns the pushed changes whose stored
+// PR.CommitHash is out of date: it differs from the change's commit on TOP while the PR
+// branch already points at that commit (e.g. the PR branch was rebased and pushed outside the
+// tool). A stored hash that differs while the PR branch is still at the old commit is a
+// change that needs a push, not drift. This is a pure read.
+func (c *Client) FindHashDrift(stackCtx *StackContext) ([]HashDrift, error) {
+	baseRef := stackCtx.Stack.BaseRef
+	if baseRef == "" {
+		baseRef = stackCtx.Stack.Base
+	}
+
+	commits, err := c.git.GetCommits(stackCtx.Stack.Branch, baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	actualHashes := make(map[string]string, len(commits))
+	for _, commit := range commits {
+		if uuid := commit.Message.Trailers["PR-UUID"]; uuid != "" {
+			actualHashes[uuid] = commit.Hash
+		}
+	}
+
+	var drift []HashDrift
+	for _, change := range stackCtx.ActiveChanges {
+		actual, ok := actualHashes[change.UUID]
+		if !ok {
+			continue
+		}
+
+		if c.prBranchMovedTo(stackCtx, change, actual) {
+			drift = append(drift, HashDrift{Change: change, Actual: actual})
+		}
+	}
+
+	return drift, nil
+}
+
+// prBranchMovedTo reports whether the stored PR hash is stale because the local PR branch
+// was moved to the given commit outside the tool.
+func (c *Client) prBranchMovedTo(stackCtx *StackContext, change *model.Change, hash string) bool {
+	if change.IsLocal() || change.PR.CommitHash == hash {
+		return false
+	}
+
+	branch := change.PR.Branch
+	if branch == "" {
+		branch = stackCtx.FormatUUIDBranch(change.UUID)
+	}
+	if !c.git.BranchExists(branch) {
+		return false
+	}
+
+	branchHash, err := c.git.GetCommitHash(branch)
+	return err == nil && branchHash == hash
+}
+
+// ReconcileChangeHashes corrects stored PR commit hashes that went stale after the PR
+// branches were rebased outside the tool (see FindHashDrift), and saves the stack context if
+// anything changed. Returns the number of changes that were corrected.
+func (c *Client) ReconcileChangeHashes(stackCtx *StackContext) (int, error) {
+	drift, err := c.FindHashDrift(stackCtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(drift) == 0 {
+		return 0, nil
+	}
+
+	for _, d := range drift {
+		d.Change.PR.CommitHash = d.Actual
+	}
+
+	if err := stackCtx.Save(); err != nil {
+		return 0, fmt.Errorf("failed to save stack context: %w", err)
+	}
+
+	return len(drift), nil
+}
+
+// DuplicatePRError reports changes in a stack that reference the same PR number
+type DuplicatePRError struct {
+	Duplicates map[int][]string // PR number -> UUIDs of the changes referencing it
+}
+
+func (e *DuplicatePRError) Error() string {
+	numbers := make([]int, 0, len(e.Duplicates))
+	for number := range e.Duplicates {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		parts[i] = fmt.Sprintf("PR #%d is referenced by changes %s", number, strings.Join(e.Duplicates[number], ", "))
+	}
+	return fmt.Sprintf("duplicate PR numbers in stack: %s (run 'stack doctor --fix' to repair)", strings.Join(parts, "; "))
+}
+
+// DuplicateUUIDError reports active commits in a stack that carry the same PR-UUID trailer,
+// e.g. after a botched cherry-pick. The changes can't be told apart until one is fixed.
+type DuplicateUUIDError struct {
+	Duplicates map[string][]string // PR-UUID -> hashes of the commits carrying it
+}
+
+func (e *DuplicateUUIDError) Error() string {
+	uuids := make([]string, 0, len(e.Duplicates))
+	for uuid := range e.Duplicates {
+		uuids = append(uuids, uuid)
+	}
+	slices.Sort(uuids)
+
+	parts := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		hashes := make([]string, len(e.Duplicates[uuid]))
+		for j, hash := range e.Duplicates[uuid] {
+			hashes[j] = git.ShortHash(hash)
+		}
+		parts[i] = fmt.Sprintf("PR-UUID %s is used by commits %s", uuid, strings.Join(hashes, ", "))
+	}
+	return fmt.Sprintf("duplicate PR-UUID trailers in stack: %s (run 'stack repair' to give all but one of each a new PR-UUID)",
+		strings.Join(parts, "; "))
+}
+
+// findDuplicateUUIDs returns the PR-UUIDs carried by more than one commit, mapped to the
+// commits' hashes. Duplicates of a merged PR are stale commits that the next refresh drops,
+// so they are ignored.
+func findDuplicateUUIDs(commits []git.Commit, prData *model.PRData) map[string][]string {
+	byUUID := make(map[string][]string)
+	for _, commit := range commits {
+		uuid := commit.Message.Trailers["PR-UUID"]
+		if uuid == "" {
+			continue
+		}
+		if pr, ok := prData.PRs[uuid]; ok && pr.IsMerged() {
+			continue
+		}
+		byUUID[uuid] = append(byUUID[uuid], commit.Hash)
+	}
+
+	for uuid, hashes := range byUUID {
+		if len(hashes) < 2 {
+			delete(byUUID, uuid)
+		}
+	}
+	return byUUID
+}
+
+// findDuplicatePRNumbers groups the UUIDs in prData by PR number and returns the groups
+// with more than one UUID, each sorted.
+func findDuplicatePRNumbers(prData *model.PRData) map[int][]string {
+	byNumber := make(map[int][]string)
+	for uuid, pr := range prData.PRs {
+		if pr == nil || pr.PRNumber == 0 {
+			continue
+		}
+		byNumber[pr.PRNumber] = append(byNumber[pr.PRNumber], uuid)
+	}
+
+	for number, uuids := range byNumber {
+		if len(uuids) < 2 {
+			delete(byNumber, number)
+			continue
+		}
+		slices.Sort(uuids)
+	}
+	return byNumber
+}
+
+// validatePRNumbersUnique returns a *DuplicatePRError if prData references a PR number from
+// more than one change
+func validatePRNumbersUnique(prData *model.PRData) error {
+	duplicates := findDuplicatePRNumbers(prData)
+	if len(duplicates) == 0 {
+		return nil
+	}
+	return &DuplicatePRError{Duplicates: duplicates}
+}
+
+// ValidatePRNumbersUnique returns a *DuplicatePRError if two changes in the stack's stored PR
+// data reference the same PR number (e.g. after a bad import of prs.json). Such a stack only
+// loads with GetStackContextForRepair.
+func (c *Client) ValidatePRNumbersUnique(stackCtx *StackContext) error {
+	prData, err := c.loadPRs(stackCtx.StackName, true)
+	if err != nil {
+		return err
+	}
+	return validatePRNumbersUnique(prData)
+}
+
+// FixDuplicatePRNumbers clears the PR from changes that wrongly share a PR number with another
+// change. The change whose commit matches the PR's recorded head keeps the PR. Groups where no
+// single change matches are left untouched. stackCtx should come from GetStackContextForRepair.
+// Returns the number of changes that were cleared.
+func (c *Client) FixDuplicatePRNumbers(stackCtx *StackContext) (int, error) {
+	prData, err := c.loadPRs(stackCtx.StackName, true)
+	if err != nil {
+		return 0, err
+	}
+
+	cleared := 0
+	for _, uuids := range findDuplicatePRNumbers(prData) {
+		var owners []*model.Change
+		for _, uuid := range uuids {
+			if change := stackCtx.FindChange(uuid); change != nil && change.PR != nil && change.CommitHash == change.PR.CommitHash {
+				owners = append(owners, change)
+			}
+		}
+		if len(owners) != 1 {
+			continue
+		}
+
+		// Entries for commits that are no longer in the stack aren't saved back
+		for _, uuid := range uuids {
+			if uuid == owners[0].UUID {
+				continue
+			}
+			if change := stackCtx.FindChange(uuid); change != nil {
+				change.PR = nil
+			}
+			cleared++
+		}
+	}
+
+	if cleared == 0 {
+		return 0, nil
+	}
+
+	if err := stackCtx.Save(); err != nil {
+		return 0, fmt.Errorf("failed to save stack context: %w", err)
+	}
+	return cleared, nil
+}
+
+// BaseMismatch describes a change whose base doesn't follow the stack's chain
+type BaseMismatch struct {
+	UUID     string
+	Position int
+	Got      string // The DesiredBase or pushed PR base that is out of place
+	Want     string // The previous active change's UUID branch, or the stack base for the first change
+}
+
+// BaseChainError reports changes whose bases don't form a chain
+type BaseChainError struct {
+	Mismatches []BaseMismatch
+}
+
+func (e *BaseChainError) Error() string {
+	parts := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		parts[i] = fmt.Sprintf("change #%d is based on %s, expected %s", m.Position, m.Got, m.Want)
+	}
+	return fmt.Sprintf("inconsistent base chain: %s (run 'stack doctor --fix' to repair)", strings.Join(parts, "; "))
+}
+
+// expectedBase returns the base an active change should have: the stack base for the first
+// active change, otherwise the previous active change's UUID branch.
+func expectedBase(stackCtx *StackContext, activeIndex int) string {
+	if activeIndex == 0 {
+		return stackCtx.Stack.Base
+	}
+	return stackCtx.FormatUUIDBranch(stackCtx.ActiveChanges[activeIndex-1].UUID)
+}
+
+// prBase returns the base of the change's PR on GitHub: the base seen by the last sync, or
+// the last pushed base if the PR hasn't been synced since
+func prBase(change *model.Change) string {
+	if change.PR.RemoteBase != "" {
+		return change.PR.RemoteBase
+	}
+	return change.PR.Base
+}
+
+// VerifyDesiredBaseChain confirms that every active change's DesiredBase, and the base of its
+// open PR on GitHub, point at the previous active change's UUID branch (or the stack base for
+// the first change). Returns a *BaseChainError listing the discrepancies.
+func (c *Client) VerifyDesiredBaseChain(stackCtx *StackContext) error {
+	var mismatches []BaseMismatch
+	for i, change := range stackCtx.ActiveChanges {
+		want := expectedBase(stackCtx, i)
+
+		got := change.DesiredBase
+		if got == want && hasOpenPR(change) && prBase(change) != "" {
+			got = prBase(change)
+		}
+		if got != want {
+			mismatches = append(mismatches, BaseMismatch{UUID: change.UUID, Position: change.Position, Got: got, Want: want})
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &BaseChainError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// FixDesiredBaseChain recomputes DesiredBase for every active change and re-points open PRs on
+// GitHub whose base doesn't match. Returns the number of changes that were corrected.
+func (c *Client) FixDesiredBaseChain(stackCtx *StackContext) (int, error) {
+	fixed := 0
+	for i, change := range stackCtx.ActiveChanges {
+		want := expectedBase(stackCtx, i)
+		corrected := false
+
+		if change.DesiredBase != want {
+			change.DesiredBase = want
+			corrected = true
+		}
+
+		if hasOpenPR(change) && prBase(change) != "" && prBase(change) != want {
+			if err := c.ghFor(stackCtx.Stack).UpdatePRBase(change.PR.PRNumber, want); err != nil {
+				return fixed, fmt.Errorf("failed to update base of PR #%d: %w", change.PR.PRNumber, err)
+			}
+			change.PR.SetBase(want)
+			corrected = true
+		}
+
+		if corrected {
+			fixed++
+		}
+	}
+
+	if fixed == 0 {
+		return 0, nil
+	}
+
+	if err := stackCtx.Save(); err != nil {
+		return fixed, fmt.Errorf("failed to save stack context: %w", err)
+	}
+	return fixed, nil
+}
+
+func hasOpenPR(change *model.Change) bool {
+	return !change.IsLocal() && (change.PR.State == "open" || change.PR.State == "draft")
+}
+
+// RebaseParams contains parameters for rebasing subsequent commits with recovery
+type RebaseParams struct {
+	StackName         string
+	StackBranch       string
+	OldCommitHash     string
+	NewCommitHash     string
+	OriginalStackHead string
+}
+
+// RebaseSubsequentCommitsWithRecovery rebases commits with automatic state save/clear for recovery
+func (c *Client) RebaseSubsequentCommitsWithRecovery(params RebaseParams) (int, error) {
+	rebaseState := RebaseState{
+		OriginalStackHead: params.OriginalStackHead,
+		NewCommitHash:     params.NewCommitHash,
+		OldCommitHash:     params.OldCommitHash,
+		StackBranch:       params.StackBranch,
+	}
+	if err := c.SaveRebaseState(params.StackName, rebaseState); err != nil {
+		ui.Warningf("failed to save rebase state: %v", err)
+	}
+
+	gitClient, ok := c.git.(*git.Client)
+	if !ok {
+		return 0, fmt.Errorf("git client type assertion failed")
+	}
+
+	rebasedCount, err := gitClient.RebaseSubsequentCommits(
+		params.StackBranch,
+		params.OldCommitHash,
+		params.NewCommitHash,
+		params.OriginalStackHead,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.ClearRebaseState(params.StackName); err != nil {
+		ui.Warningf("failed to clear rebase state: %v", err)
+	}
+
+	return rebasedCount, nil
+}
+
+// SetHoldReady turns the stack's hold-ready gate on or off. While held, PRs are created as
+// drafts regardless of each change's local draft status; releasing the hold lets
+// 'stack pr ready --all' mark the whole stack ready together.
+func (c *Client) SetHoldReady(stack *model.Stack, hold bool) error {
+	stack.HoldReady = hold
+	if err := c.SaveStack(stack); err != nil {
+		return fmt.Errorf("failed to save stack: %w", err)
+	}
+	return nil
+}
+
+// SetStackDescription sets the stack's one-line description. Surrounding whitespace is
+// trimmed; an empty description clears it.
+func (c *Client) SetStackDescription(name string, description string) error {
+	description = strings.TrimSpace(description)
+	if strings.ContainsAny(description, "\r\n") {
+		return fmt.Errorf("stack description must be a single line")
+	}
+
+	stack, err := c.LoadStack(name)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	stack.Description = description
+	if err := c.SaveStack(stack); err != nil {
+		return fmt.Errorf("failed to save stack: %w", err)
+	}
+	return nil
+}
+
+// PushDraftStatus returns the draft status to push for a change. New PRs in a stack that is
+// holding ready are always created as drafts; otherwise the change's local draft status wins.
+func (c *Client) PushDraftStatus(stackCtx *StackContext, change *model.Change) bool {
+	if stackCtx.Stack.HoldReady && change.IsLocal() {
+		return true
+	}
+	return change.GetDraftStatus()
+}
+
+// PushChangeBranch force pushes the PR branch for a change. A change that has been pushed
+// before is pushed with a lease on its last pushed commit (PR.CommitHash), so the push fails
+// instead of overwriting the branch if someone else updated it in the meantime.
+func (c *Client) PushChangeBranch(change *model.Change, prBranch string) error {
+	if change.PR != nil && change.PR.CommitHash != "" {
+		return c.git.PushWithLease(prBranch, change.PR.CommitHash)
+	}
+	return c.git.Push(prBranch, true)
+}
+
+// ReorderChange moves the active change with the given UUID to newActivePosition (1-indexed)
+// by cherry-picking the TOP branch commits in the new order onto the stack base. Merged
+// changes still on the TOP branch keep their place, and a change cannot be moved across
+// one since active changes must stay above merged ones. DesiredBase is derived from commit
+// order, so reloading the stack context afterwards picks up the new base relationships.
+//
+// Rebase state is saved before the commits are replayed so a conflicting cherry-pick can be
+// completed with 'stack restack --recover'. On success the UUID branches are moved to the
+// reordered commits.
+func (c *Client) ReorderChange(stackCtx *StackContext, uuid string, newActivePosition int) error {
+	if !stackCtx.IsStack() {
+		return fmt.Errorf("not on a stack branch")
+	}
+
+	change := stackCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+
+	if newActivePosition < 1 || newActivePosition > len(stackCtx.ActiveChanges) {
+		return fmt.Errorf("position %d is out of range (stack has %d active changes)", newActivePosition, len(stackCtx.ActiveChanges))
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("cannot reorder with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	baseRef := stackCtx.Stack.BaseRef
+	if baseRef == "" {
+		baseRef = stackCtx.Stack.Base
+	}
+
+	commits, err := c.git.GetCommits(stackCtx.Stack.Branch, baseRef)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	merged := make(map[string]*model.Change, len(stackCtx.StaleMergedChanges))
+	for _, staleChange := range stackCtx.StaleMergedChanges {
+		merged[staleChange.UUID] = staleChange
+	}
+
+	from := -1
+	var activeIndexes []int
+	for i, commit := range commits {
+		commitUUID := commit.Message.Trailers["PR-UUID"]
+		if commitUUID == uuid {
+			from = i
+		}
+		if stackCtx.FindChangeInActive(commitUUID) != nil {
+			activeIndexes = append(activeIndexes, i)
+		}
+	}
+	if from == -1 || newActivePosition > len(activeIndexes) {
+		return fmt.Errorf("TOP branch does not match the stack metadata (run 'stack doctor')")
+	}
+
+	to := activeIndexes[newActivePosition-1]
+	if from == to {
+		return nil
+	}
+
+	for i := min(from, to); i <= max(from, to); i++ {
+		if mergedChange, ok := merged[commits[i].Message.Trailers["PR-UUID"]]; ok {
+			return fmt.Errorf("cannot move change across merged change #%d: active changes must stay above merged changes", mergedChange.PR.PRNumber)
+		}
 	}
 
-	if err := c.git.Fetch(remote); err != nil {
-		return err
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		hashes = append(hashes, commit.Hash)
 	}
+	moved := hashes[from]
+	hashes = slices.Delete(hashes, from, from+1)
+	hashes = slices.Insert(hashes, to, moved)
 
-	return nil
+	return c.replayStack(stackCtx, baseRef, hashes)
 }
 
-type RestackOptions struct {
-	// Onto specifies the base branch to rebase onto (required)
-	Onto string
-
-	// Fetch from remote before rebasing
-	Fetch bool
-}
+// SquashChanges folds the active change with the given UUID into the active change directly
+// below it. The combined commit keeps the lower change's title and trailers, so the lower
+// change's PR survives, and appends the upper change's title and description to its body.
+// The upper change's propagated trailers (see PropagateTrailers) are added if the lower
+// change doesn't have them.
+// Subsequent commits are rebased onto the combined commit and the UUID branches are updated;
+// the squashed change's UUID branch is deleted. Both changes must be unmerged.
+func (c *Client) SquashChanges(stackCtx *StackContext, uuid string) error {
+	if !stackCtx.IsStack() || stackCtx.OnUUIDBranch() {
+		return fmt.Errorf("%w to squash changes", ErrNotOnTopBranch)
+	}
 
-// Restack rebases the stack on top of the specified base branch.
-// Always updates stack metadata with the new base (idempotent if unchanged).
-// If opts.Fetch is true, fetches from remote and updates local base ref before rebasing.
-func (c *Client) Restack(stackCtx *StackContext, opts RestackOptions) error {
-	targetBase := opts.Onto
+	upper := stackCtx.FindChangeInActive(uuid)
+	if upper == nil {
+		return fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+	if upper.ActivePosition <= 1 {
+		return fmt.Errorf("cannot squash '%s': it is the bottom change of the stack", upper.Title)
+	}
+	lower := stackCtx.ActiveChanges[upper.ActivePosition-2]
 
-	if opts.Fetch {
-		ui.Info("Fetching from remote...")
-		if err := c.fetchRemote(); err != nil {
-			return fmt.Errorf("failed to fetch: %w", err)
+	for _, change := range []*model.Change{lower, upper} {
+		if change.PR.IsMerged() {
+			return fmt.Errorf("cannot squash merged change #%d", change.PR.PRNumber)
 		}
+	}
 
-		if err := c.UpdateLocalBaseRef(targetBase); err != nil {
-			// Non-fatal: show warning and continue
-			ui.Warningf("could not update local base ref: %v", err)
-		}
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("cannot squash with %w; commit or stash them first", ErrUncommittedChanges)
 	}
 
-	if err := c.git.Rebase(targetBase); err != nil {
+	lowerCommit, err := c.git.GetCommit(lower.CommitHash)
+	if err != nil {
 		return err
 	}
-
-	ref, err := c.git.GetCommitHash(targetBase)
+	upperCommit, err := c.git.GetCommit(upper.CommitHash)
 	if err != nil {
-		return fmt.Errorf("failed to get target base hash: %w", err)
+		return err
 	}
 
-	stackCtx.Stack.BaseRef = ref
-	stackCtx.Stack.Base = targetBase
-	if err := c.SaveStack(stackCtx.Stack); err != nil {
-		return fmt.Errorf("failed to update stack metadata: %w", err)
+	msg := lowerCommit.Message
+	msg.Trailers = withPropagatedTrailers(msg.Trailers, upperCommit.Message.Trailers, c.PropagateTrailers())
+	squashed := upperCommit.Message.Title
+	if upperCommit.Message.Body != "" {
+		squashed += "\n\n" + upperCommit.Message.Body
+	}
+	if msg.Body != "" {
+		msg.Body += "\n\n" + squashed
+	} else {
+		msg.Body = squashed
 	}
 
-	// if _, err := c.UpdateUUIDBranches(stackCtx.StackName); err != nil {
-	// 	return fmt.Errorf("failed to update UUID branches: %w", err)
-	// }
-	return nil
-}
-
-// UpdateLocalBaseRef updates the local base branch ref to match its upstream
-func (c *Client) UpdateLocalBaseRef(baseBranch string) error {
-	upstream, err := c.git.GetUpstreamBranch(baseBranch)
+	parent, err := c.git.GetParentCommit(lower.CommitHash)
 	if err != nil {
 		return err
 	}
-	if upstream == "" {
-		return fmt.Errorf("no upstream tracking branch configured for %s", baseBranch)
+	// The upper commit's tree already contains both changes
+	tree, err := c.git.GetCommitTree(upper.CommitHash)
+	if err != nil {
+		return err
 	}
-
-	upstreamHash, err := c.git.GetCommitHash(upstream)
+	// The squashed commit is still the lower change, so it keeps that change's author
+	newHash, err := c.git.CommitTreeAs(tree, parent, msg.String(), lowerCommit.Meta)
 	if err != nil {
 		return err
 	}
 
-	// Get current hash of local base (may not exist, that's ok)
-	currentHash, err := c.git.GetCommitHash(baseBranch)
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
 	if err != nil {
-		// Branch doesn't exist locally - create it at upstream's commit
-		if err := c.git.CreateBranchAt(baseBranch, upstreamHash); err != nil {
-			return err
-		}
-		ui.Infof("Created local branch %s at %s", baseBranch, upstreamHash[:7])
-		return nil
+		return fmt.Errorf("failed to get stack head: %w", err)
 	}
 
-	if upstreamHash != currentHash {
-		if err := c.git.UpdateRef(baseBranch, upstreamHash); err != nil {
-			return err
-		}
-		ui.Infof("Updating %s: %s..%s", baseBranch, currentHash[:7], upstreamHash[:7])
+	if _, err := c.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+		StackName:         stackCtx.StackName,
+		StackBranch:       stackCtx.Stack.Branch,
+		OldCommitHash:     upper.CommitHash,
+		NewCommitHash:     newHash,
+		OriginalStackHead: originalHead,
+	}); err != nil {
+		return err
 	}
-	return nil
-}
-
-// CheckoutChangeForEditing checks out a UUID branch for the given change, creating it if needed.
-// If the branch already exists but points to a different commit, it syncs it to the current commit.
-// Returns the branch name that was checked out.
-func (c *Client) CheckoutChangeForEditing(stackCtx *StackContext, change *model.Change) (string, error) {
 
-	// Format UUID branch name
-	branchName := stackCtx.FormatUUIDBranch(change.UUID)
-
-	// Check if UUID branch already exists
-	if c.git.BranchExists(branchName) {
-		// Get the commit hash the existing branch points to
-		existingHash, err := c.git.GetCommitHash(branchName)
-		if err != nil {
-			return "", fmt.Errorf("failed to get branch commit: %w", err)
-		}
-
-		// Checkout the branch first
-		if err := c.git.CheckoutBranch(branchName); err != nil {
-			return "", fmt.Errorf("failed to checkout branch: %w", err)
+	if upperBranch := stackCtx.FormatUUIDBranch(upper.UUID); c.git.BranchExists(upperBranch) {
+		if err := c.git.DeleteBranch(upperBranch, true); err != nil {
+			ui.Warningf("failed to delete branch %s: %v", upperBranch, err)
 		}
+	}
 
-		// If branch is at wrong commit, sync it to the current commit location
-		if existingHash != change.CommitHash {
-			if err := c.git.ResetHard(change.CommitHash); err != nil {
-				return "", fmt.Errorf("failed to sync branch to current commit: %w", err)
-			}
-			// TODO:(this is probably unexpected)
-			ui.Warningf("Synced branch to current commit (was at %s, now at %s)",
-				git.ShortHash(existingHash), git.ShortHash(change.CommitHash))
-		}
-	} else {
-		// Create and checkout new branch at the commit
-		if err := c.git.CreateAndCheckoutBranchAt(branchName, change.CommitHash); err != nil {
-			return "", fmt.Errorf("failed to create branch: %w", err)
-		}
+	if _, err := c.UpdateUUIDBranches(stackCtx.StackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
 	}
 
-	// If this is the topmost change, checkout the TOP branch instead of staying on UUID branch
-	// This allows users to work on the entire stack when at the top position
-	if change.Position == len(stackCtx.AllChanges) {
-		if err := c.git.CheckoutBranch(stackCtx.Stack.Branch); err != nil {
-			return "", fmt.Errorf("failed to checkout TOP branch: %w", err)
-		}
-		// Return the TOP branch name to indicate where we ended up
-		return stackCtx.Stack.Branch, nil
+	if hasOpenPR(upper) {
+		ui.Warningf("PR #%d for '%s' is no longer part of the stack - close it on GitHub", upper.PR.PRNumber, upper.Title)
 	}
 
-	return branchName, nil
+	return nil
 }
 
-// IsStackBranch checks if a branch name matches the stack branch pattern
-func IsStackBranch(branch string) bool {
-	// Stack branches follow pattern: username/stack-<name>/TOP
-	parts := strings.Split(branch, "/")
-	if len(parts) != 3 {
-		return false
+// DropChanges removes the commits for the given changes from the TOP branch by replaying
+// the remaining commits onto the stack base. Their PR metadata is left alone. Like
+// ReorderChange, rebase state is saved so a conflict can be finished with
+// 'stack restack --recover'.
+func (c *Client) DropChanges(stackCtx *StackContext, changes []*model.Change) error {
+	if len(changes) == 0 {
+		return nil
 	}
 
-	// Check second part starts with "stack-" and third part is "TOP"
-	return strings.HasPrefix(parts[1], "stack-") && (parts[2] == "TOP" || validUUID(parts[2]))
-}
-
-// UpdateUUIDBranches reloads stack context and updates all UUID branches to point to their new commit locations
-// Returns the number of branches that were actually updated
-func (c *Client) UpdateUUIDBranches(stackName string) (int, error) {
-	ctx, err := c.GetStackContextByName(stackName)
+	hasChanges, err := c.git.HasUncommittedChanges()
 	if err != nil {
-		return 0, fmt.Errorf("failed to reload stack context: %w", err)
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("cannot drop changes with %w; commit or stash them first", ErrUncommittedChanges)
 	}
 
-	updatedCount := 0
-	for _, change := range ctx.ActiveChanges {
-		if change.UUID == "" {
-			continue
-		}
-
-		branchName := ctx.FormatUUIDBranch(change.UUID)
+	drop := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		drop[change.UUID] = true
+	}
 
-		if !c.git.BranchExists(branchName) {
-			continue
-		}
+	baseRef := stackCtx.Stack.BaseRef
+	if baseRef == "" {
+		baseRef = stackCtx.Stack.Base
+	}
 
-		currentHash, err := c.git.GetCommitHash(branchName)
-		if err == nil && currentHash == change.CommitHash {
-			continue
-		}
+	commits, err := c.git.GetCommits(stackCtx.Stack.Branch, baseRef)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
 
-		if err := c.git.UpdateRef(branchName, change.CommitHash); err != nil {
-			return updatedCount, fmt.Errorf("failed to update branch %s: %w", branchName, err)
+	hashes := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		if !drop[commit.Message.Trailers["PR-UUID"]] {
+			hashes = append(hashes, commit.Hash)
 		}
-		updatedCount++
 	}
 
-	return updatedCount, nil
+	return c.replayStack(stackCtx, baseRef, hashes)
 }
 
-// RebaseParams contains parameters for rebasing subsequent commits with recovery
-type RebaseParams struct {
-	StackName         string
-	StackBranch       string
-	OldCommitHash     string
-	NewCommitHash     string
-	OriginalStackHead string
-}
+// replayStack rebuilds the TOP branch from hashes on top of baseRef, saving rebase state
+// for recovery, and moves the UUID branches to the new commits.
+func (c *Client) replayStack(stackCtx *StackContext, baseRef string, hashes []string) error {
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+	baseHash, err := c.git.GetCommitHash(baseRef)
+	if err != nil {
+		return fmt.Errorf("failed to get base hash: %w", err)
+	}
 
-// RebaseSubsequentCommitsWithRecovery rebases commits with automatic state save/clear for recovery
-func (c *Client) RebaseSubsequentCommitsWithRecovery(params RebaseParams) (int, error) {
+	// Replaying the original commits onto the base restores the original stack,
+	// which is what 'stack restack --recover --retry' does with this state.
 	rebaseState := RebaseState{
-		OriginalStackHead: params.OriginalStackHead,
-		NewCommitHash:     params.NewCommitHash,
-		OldCommitHash:     params.OldCommitHash,
-		StackBranch:       params.StackBranch,
+		OriginalStackHead: originalHead,
+		NewCommitHash:     baseHash,
+		OldCommitHash:     baseHash,
+		StackBranch:       stackCtx.Stack.Branch,
 	}
-	if err := c.SaveRebaseState(params.StackName, rebaseState); err != nil {
+	if err := c.SaveRebaseState(stackCtx.StackName, rebaseState); err != nil {
 		ui.Warningf("failed to save rebase state: %v", err)
 	}
 
-	gitClient, ok := c.git.(*git.Client)
-	if !ok {
-		return 0, fmt.Errorf("git client type assertion failed")
+	if err := c.git.ReplayCommits(stackCtx.Stack.Branch, baseHash, hashes); err != nil {
+		return err
 	}
 
-	rebasedCount, err := gitClient.RebaseSubsequentCommits(
-		params.StackBranch,
-		params.OldCommitHash,
-		params.NewCommitHash,
-		params.OriginalStackHead,
-	)
-	if err != nil {
-		return 0, err
+	if err := c.ClearRebaseState(stackCtx.StackName); err != nil {
+		ui.Warningf("failed to clear rebase state: %v", err)
 	}
 
-	if err := c.ClearRebaseState(params.StackName); err != nil {
-		ui.Warningf("failed to clear rebase state: %v", err)
+	if _, err := c.UpdateUUIDBranches(stackCtx.StackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
 	}
 
-	return rebasedCount, nil
+	return nil
 }
 
-func (c *Client) ArchiveStack(stackName string) error {
+// ArchiveStack moves the stack's metadata to .git/stack/.archived/<name>-<timestamp> and
+// returns the archive path
+func (c *Client) ArchiveStack(stackName string) (string, error) {
+	c.InvalidateContext(stackName)
 	stackDir := c.getStackDir(stackName)
 
 	if _, err := os.Stat(stackDir); os.IsNotExist(err) {
-		return fmt.Errorf("stack '%s' does not exist", stackName)
+		return "", fmt.Errorf("%w: '%s'", ErrStackNotFound, stackName)
 	}
 
-	archiveRoot := filepath.Join(c.getStacksRootDir(), ".archived")
+	archiveRoot := c.getArchiveRootDir()
 	if err := os.MkdirAll(archiveRoot, 0755); err != nil {
-		return fmt.Errorf("failed to create archive directory: %w", err)
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
 	}
 
-	timestamp := time.Now().Format("20060102-150405")
-	archiveName := fmt.Sprintf("%s-%s", stackName, timestamp)
-	archivePath := filepath.Join(archiveRoot, archiveName)
-
+	archivePath := c.getArchivePath(stackName, time.Now())
 	if err := os.Rename(stackDir, archivePath); err != nil {
-		return fmt.Errorf("failed to archive stack: %w", err)
+		return "", fmt.Errorf("failed to archive stack: %w", err)
 	}
 
-	return nil
+	return archivePath, nil
+}
+
+// getArchivePath returns where a stack archived at the given time is kept
+func (c *Client) getArchivePath(stackName string, archivedAt time.Time) string {
+	return filepath.Join(c.getArchiveRootDir(), fmt.Sprintf("%s-%s", stackName, archivedAt.Format(archiveTimestampFormat)))
 }
 
 func (c *Client) GetStackBranches(stackName string) ([]string, error) {
-	pattern := fmt.Sprintf("refs/heads/%s/stack-%s/*", c.username, stackName)
-	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short)", pattern)
-	output, err := cmd.Output()
+	branches, err := c.git.ListBranches(fmt.Sprintf("%s/stack-%s/*", c.username, stackName))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stack branches: %w", err)
 	}
+	return branches, nil
+}
 
-	branchesStr := strings.TrimSpace(string(output))
-	if branchesStr == "" {
-		return []string{}, nil
-	}
+// DeleteOptions configures DeleteStack
+type DeleteOptions struct {
+	DryRun     bool // Work out what would be deleted without changing anything
+	KeepRemote bool // Leave the remote branches, so the stack's open PRs stay open
+}
 
-	return strings.Split(branchesStr, "\n"), nil
+// DeletePlan lists what DeleteStack deletes, or with DryRun would delete
+type DeletePlan struct {
+	LocalBranches  []string // Local stack branches (UUID branches and TOP)
+	RemoteBranches []string // Stack branches deleted from the push remote
+	ArchivePath    string   // Where the stack's metadata is archived
 }
 
-func (c *Client) DeleteStack(stackName string, skipConfirm bool) error {
+// DeleteStack archives the stack's metadata and deletes its local and remote branches. With
+// opts.DryRun nothing is changed: the plan lists the branches that would be deleted, with the
+// remote branches found by listing the push remote, and the archive path the stack would get.
+// With opts.KeepRemote the remote branches are left alone, e.g. when handing the stack's PRs
+// over to someone else; deleting them would close the PRs.
+func (c *Client) DeleteStack(stackName string, opts DeleteOptions) (*DeletePlan, error) {
 	stack, err := c.LoadStack(stackName)
 	if err != nil {
-		return fmt.Errorf("failed to load stack: %w", err)
+		return nil, fmt.Errorf("failed to load stack: %w", err)
 	}
 
 	branches, err := c.GetStackBranches(stackName)
 	if err != nil {
-		return fmt.Errorf("failed to get stack branches: %w", err)
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
 	}
 
 	// Ensure TOP branch is in the list (GetStackBranches might not include it)
@@ -1201,23 +2821,177 @@ func (c *Client) DeleteStack(stackName string, skipConfirm bool) error {
 		branches = append(branches, stack.Branch)
 	}
 
+	if opts.DryRun {
+		return c.planDeletion(stack, branches, opts.KeepRemote), nil
+	}
+
 	// Ensure it's safe to delete branches (checkout base if needed)
 	if err := c.ensureSafeForDeletion(stack, branches); err != nil {
+		return nil, err
+	}
+
+	archivePath, err := c.ArchiveStack(stackName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive stack metadata: %w", err)
+	}
+
+	ui.Successf("Archived stack metadata to .git/stack/.archived/%s (restore with 'stack restore %s')", filepath.Base(archivePath), stackName)
+
+	local, remote := c.deleteBranches(branches, opts.KeepRemote)
+	return &DeletePlan{LocalBranches: local, RemoteBranches: remote, ArchivePath: archivePath}, nil
+}
+
+// planDeletion returns what deleting stack with the given branches would do. Remote branches
+// are listed with one 'git ls-remote'; if the remote can't be listed they're left out.
+func (c *Client) planDeletion(stack *model.Stack, branches []string, keepRemote bool) *DeletePlan {
+	plan := &DeletePlan{
+		LocalBranches:  []string{},
+		RemoteBranches: []string{},
+		ArchivePath:    c.getArchivePath(stack.Name, time.Now()),
+	}
+	for _, branch := range branches {
+		if c.git.BranchExists(branch) {
+			plan.LocalBranches = append(plan.LocalBranches, branch)
+		}
+	}
+	if keepRemote {
+		return plan
+	}
+
+	remote, err := c.git.GetPushRemoteName()
+	if err == nil {
+		var remoteHeads map[string]string
+		remoteHeads, err = c.git.LsRemoteHeads(remote, fmt.Sprintf("%s/stack-%s/*", c.username, stack.Name))
+		for _, branch := range branches {
+			if _, ok := remoteHeads[branch]; ok {
+				plan.RemoteBranches = append(plan.RemoteBranches, branch)
+			}
+		}
+	}
+	if err != nil {
+		ui.Warningf("could not list remote branches: %v", err)
+	}
+	return plan
+}
+
+// DeleteStackSafe deletes a stack like DeleteStack, but refuses when the stack still has open PRs
+// on GitHub unless force is set. When forcing, it warns about the PRs that will be orphaned.
+// Returns the numbers of the orphaned PRs. With opts.KeepRemote the PRs keep their branches,
+// so they aren't orphaned and force isn't needed.
+func (c *Client) DeleteStackSafe(stackName string, force bool, opts DeleteOptions) ([]int, error) {
+	stackCtx, err := c.GetStackContextByName(stackName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	var openPRs []int
+	if !opts.KeepRemote {
+		openPRs = stackCtx.OpenPRNumbers()
+	}
+	if len(openPRs) > 0 {
+		if !force {
+			return nil, fmt.Errorf("stack '%s' has %d open PR(s) (%s): merge or close them first, or use --force to delete anyway",
+				stackName, len(openPRs), formatPRNumbers(openPRs))
+		}
+		ui.Warningf("Deleting stack with open PR(s) that will be orphaned: %s", formatPRNumbers(openPRs))
+	}
+
+	if _, err := c.DeleteStack(stackName, opts); err != nil {
+		return nil, err
+	}
+
+	return openPRs, nil
+}
+
+// RenameStack renames a stack: its metadata directory, its TOP branch and all of its UUID
+// branches. If the old TOP or UUID branch is checked out, git keeps it checked out under its
+// new name. Commits are not rewritten, so their PR-Stack trailers keep the old name; it is
+// recorded in Stack.PreviousNames so those commits still belong to the stack.
+//
+// Stacks with open PRs are refused because GitHub can't change a PR's head branch.
+func (c *Client) RenameStack(oldName, newName string) error {
+	if err := validateStackName(newName); err != nil {
+		return err
+	}
+	if c.StackExists(newName) {
+		return fmt.Errorf("stack '%s' already exists", newName)
+	}
+
+	stackCtx, err := c.GetStackContextByName(oldName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	oldName = stackCtx.StackName
+
+	if openPRs := stackCtx.OpenPRNumbers(); len(openPRs) > 0 {
+		return fmt.Errorf("stack '%s' has %d open PR(s) (%s): GitHub can't change a PR's head branch, so merge or close them before renaming",
+			oldName, len(openPRs), formatPRNumbers(openPRs))
+	}
+
+	branches, err := c.GetStackBranches(oldName)
+	if err != nil {
+		return err
+	}
+
+	oldPrefix := fmt.Sprintf("%s/stack-%s/", c.username, oldName)
+	newPrefix := fmt.Sprintf("%s/stack-%s/", c.username, newName)
+	for _, branch := range branches {
+		newBranch := newPrefix + strings.TrimPrefix(branch, oldPrefix)
+		if c.git.BranchExists(newBranch) {
+			return fmt.Errorf("branch '%s' already exists", newBranch)
+		}
+	}
+
+	// A stack whose branches and metadata disagree on its name can't be loaded under either
+	// name, so a failure partway through puts back what was already renamed
+	var renamed []string
+	rollback := func(err error) error {
+		for i := len(renamed) - 1; i >= 0; i-- {
+			branch := renamed[i]
+			if rollbackErr := c.git.RenameBranch(newPrefix+strings.TrimPrefix(branch, oldPrefix), branch); rollbackErr != nil {
+				ui.Warningf("failed to rename branch back to %s: %v", branch, rollbackErr)
+			}
+		}
 		return err
 	}
 
-	if err := c.ArchiveStack(stackName); err != nil {
-		return fmt.Errorf("failed to archive stack metadata: %w", err)
+	for _, branch := range branches {
+		if err := c.git.RenameBranch(branch, newPrefix+strings.TrimPrefix(branch, oldPrefix)); err != nil {
+			return rollback(err)
+		}
+		renamed = append(renamed, branch)
 	}
 
-	ui.Successf("Archived stack metadata to .git/stack/.archived/%s-*", stackName)
+	c.InvalidateContext(oldName)
+	if err := os.Rename(c.getStackDir(oldName), c.getStackDir(newName)); err != nil {
+		return rollback(fmt.Errorf("failed to move stack metadata: %w", err))
+	}
 
-	if err := c.deleteBranches(branches); err != nil {
-		return fmt.Errorf("failed to delete branches: %w", err)
+	stack := stackCtx.Stack
+	stack.Name = newName
+	stack.Branch = formatStackBranch(c.username, newName)
+	if !slices.Contains(stack.PreviousNames, oldName) {
+		stack.PreviousNames = append(stack.PreviousNames, oldName)
+	}
+	if err := c.SaveStack(stack); err != nil {
+		if moveErr := os.Rename(c.getStackDir(newName), c.getStackDir(oldName)); moveErr != nil {
+			ui.Warningf("failed to move stack metadata back: %v", moveErr)
+		}
+		c.InvalidateContext(newName)
+		return rollback(fmt.Errorf("failed to save stack: %w", err))
 	}
+
 	return nil
 }
 
+func formatPRNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		parts[i] = fmt.Sprintf("#%d", number)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ensureSafeForDeletion ensures we're not on any stack branch before deletion
 // If we are, it checks out the base branch. This is the single point of safety
 // validation before deleting stack branches.
@@ -1240,37 +3014,41 @@ func (c *Client) ensureSafeForDeletion(stack *model.Stack, branches []string) er
 	return nil
 }
 
-// deleteBranches deletes the specified branches (local and remote)
+// deleteBranches deletes the specified branches (local and, unless keepRemote, remote), warning
+// about the ones that can't be deleted. Returns the local and remote branches that were deleted.
 // Assumes safety checks have already been performed by caller (e.g., ensureSafeForDeletion)
-func (c *Client) deleteBranches(branches []string) error {
-	deletedLocal, deletedRemote := 0, 0
+func (c *Client) deleteBranches(branches []string, keepRemote bool) (deletedLocal []string, deletedRemote []string) {
+	deletedLocal, deletedRemote = []string{}, []string{}
 
 	for _, branch := range branches {
 		if c.git.BranchExists(branch) {
 			if err := c.git.DeleteBranch(branch, true); err != nil {
 				ui.Warningf("failed to delete local branch %s: %v", branch, err)
 			} else {
-				deletedLocal++
+				deletedLocal = append(deletedLocal, branch)
 			}
 		}
 
+		if keepRemote {
+			continue
+		}
 		if err := c.git.DeleteRemoteBranch(branch); err != nil {
 			if !strings.Contains(err.Error(), "remote ref does not exist") {
 				ui.Warningf("failed to delete remote branch %s: %v", branch, err)
 			}
 		} else {
-			deletedRemote++
+			deletedRemote = append(deletedRemote, branch)
 		}
 	}
 
-	if deletedLocal > 0 {
-		ui.Successf("Deleted %d local branch(es)", deletedLocal)
+	if len(deletedLocal) > 0 {
+		ui.Successf("Deleted %d local branch(es)", len(deletedLocal))
 	}
-	if deletedRemote > 0 {
-		ui.Successf("Deleted %d remote branch(es)", deletedRemote)
+	if len(deletedRemote) > 0 {
+		ui.Successf("Deleted %d remote branch(es)", len(deletedRemote))
 	}
 
-	return nil
+	return deletedLocal, deletedRemote
 }
 
 type CleanupCandidate struct {
@@ -1293,29 +3071,50 @@ func (c *Client) IsStackEligibleForCleanup(stackCtx *StackContext) (bool, string
 	return true, "all_merged"
 }
 
+// stackSyncConcurrency bounds how many stacks GetCleanupCandidates and RefreshAll sync with
+// GitHub at once
+const stackSyncConcurrency = 4
+
 func (c *Client) GetCleanupCandidates() ([]CleanupCandidate, error) {
 	stacks, err := c.ListStacks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stacks: %w", err)
 	}
 
-	var candidates []CleanupCandidate
+	// Each stack syncs with GitHub independently, so fan out with a bounded pool.
+	// Failures are reported as warnings and only skip that stack.
+	results := make([]*CleanupCandidate, len(stacks))
+	g := errgroup.Group{}
+	g.SetLimit(stackSyncConcurrency)
+	for i, s := range stacks {
+		g.Go(func() error {
+			stackCtx, err := c.loadStackWithSync(s.Name)
+			if err != nil {
+				ui.Warningf("failed to load stack %s: %v", s.Name, err)
+				return nil
+			}
 
-	for _, s := range stacks {
-		stackCtx, err := c.loadStackWithSync(s.Name)
-		if err != nil {
-			ui.Warningf("failed to load stack %s: %v", s.Name, err)
-			continue
-		}
+			if eligible, reason := c.IsStackEligibleForCleanup(stackCtx); eligible {
+				results[i] = &CleanupCandidate{
+					StackCtx:    stackCtx,
+					Reason:      reason,
+					ChangeCount: len(stackCtx.AllChanges),
+				}
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
 
-		if eligible, reason := c.IsStackEligibleForCleanup(stackCtx); eligible {
-			candidates = append(candidates, CleanupCandidate{
-				StackCtx:    stackCtx,
-				Reason:      reason,
-				ChangeCount: len(stackCtx.AllChanges),
-			})
+	var candidates []CleanupCandidate
+	for _, candidate := range results {
+		if candidate != nil {
+			candidates = append(candidates, *candidate)
 		}
 	}
+	slices.SortFunc(candidates, func(a, b CleanupCandidate) int {
+		return strings.Compare(a.StackCtx.StackName, b.StackCtx.StackName)
+	})
 
 	return candidates, nil
 }
@@ -1326,7 +3125,7 @@ func (c *Client) loadStackWithSync(name string) (*StackContext, error) {
 		return nil, err
 	}
 
-	if _, err := c.SyncPRMetadata(stackCtx); err != nil {
+	if _, err := c.SyncPRMetadata(stackCtx, nil, RefreshOptions{}); err != nil {
 		ui.Warningf("failed to sync stack %s with GitHub: %v", name, err)
 	}
 
@@ -1334,8 +3133,14 @@ func (c *Client) loadStackWithSync(name string) (*StackContext, error) {
 	return stackCtx, nil
 }
 
-// getUsername returns the username for branch naming
-func getUsername() (string, error) {
+// resolveUsername returns the owner used in branch names: the STACK_USER env var, then the
+// repository config's user, then the current OS user. config.Load rejects overrides that
+// aren't valid branch name components.
+func (c *Client) resolveUsername() (string, error) {
+	if c.config.User != "" {
+		return c.config.User, nil
+	}
+
 	currentUser, err := user.Current()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current user: %w", err)
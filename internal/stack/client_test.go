@@ -1,6 +1,7 @@
 package stack
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -24,7 +25,8 @@ func TestClient(t *testing.T) {
 	t.Run("Install", func(t *testing.T) {
 		mockGithubClient := &gh.MockGithubClient{}
 		mockGitClient := testutil.NewTestGitClient(t)
-		stackClient := NewClient(mockGitClient, mockGithubClient)
+		stackClient, err := NewClient(mockGitClient, mockGithubClient)
+		require.NoError(t, err)
 
 		installed, err := stackClient.IsInstalled()
 		assert.NoError(t, err)
@@ -64,6 +66,44 @@ func TestClient(t *testing.T) {
 	})
 }
 
+func TestCreateStack_DefaultBase(t *testing.T) {
+	setup := func(t *testing.T) (*Client, *gh.MockGithubClient) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		require.NoError(t, stackClient.git.(*git.Client).CreateBranchAt("develop", "main"))
+		return stackClient, mockGithubClient
+	}
+
+	t.Run("FallsBackToDefaultBranch", func(t *testing.T) {
+		stackClient, mockGithubClient := setup(t)
+		mockGithubClient.On("GetDefaultBranch").Return("develop", nil).Once()
+
+		s, err := stackClient.CreateStack("test-stack", "")
+		require.NoError(t, err)
+		assert.Equal(t, "develop", s.Base)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("ExplicitBaseTakesPrecedence", func(t *testing.T) {
+		stackClient, mockGithubClient := setup(t)
+
+		s, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		assert.Equal(t, "main", s.Base)
+		mockGithubClient.AssertNotCalled(t, "GetDefaultBranch")
+	})
+
+	t.Run("DefaultBranchUnavailable", func(t *testing.T) {
+		stackClient, mockGithubClient := setup(t)
+		mockGithubClient.On("GetDefaultBranch").Return("", fmt.Errorf("gh CLI error: not logged in")).Once()
+
+		_, err := stackClient.CreateStack("test-stack", "")
+		assert.ErrorContains(t, err, "no base branch given and failed to get the default branch")
+		assert.False(t, stackClient.StackExists("test-stack"))
+	})
+}
+
 func TestGetStackContext_WithMultipleActiveChanges(t *testing.T) {
 	mockGithubClient := &gh.MockGithubClient{}
 	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
@@ -111,6 +151,7 @@ func TestGetStackContext_WithMultipleActiveChanges(t *testing.T) {
 		{
 			Title:          "First change",
 			Description:    "Description of first change",
+			Author:         "Test User",
 			UUID:           uuid1,
 			Position:       1,
 			ActivePosition: 1,
@@ -120,6 +161,7 @@ func TestGetStackContext_WithMultipleActiveChanges(t *testing.T) {
 		{
 			Title:          "Second change",
 			Description:    "Description of second change",
+			Author:         "Test User",
 			UUID:           uuid2,
 			Position:       2,
 			ActivePosition: 2,
@@ -129,6 +171,7 @@ func TestGetStackContext_WithMultipleActiveChanges(t *testing.T) {
 		{
 			Title:          "Third change",
 			Description:    "Description of third change",
+			Author:         "Test User",
 			UUID:           uuid3,
 			Position:       3,
 			ActivePosition: 3,
@@ -196,6 +239,7 @@ func TestGetStackContext_WithMergedAndActiveChanges(t *testing.T) {
 		{
 			Title:       "First change",
 			Description: "Description of first change",
+			Author:      "Test User",
 			UUID:        uuid1,
 			CommitHash:  hash1,
 			Position:    1,
@@ -226,6 +270,7 @@ func TestGetStackContext_WithMergedAndActiveChanges(t *testing.T) {
 	expectedMergedChange := &model.Change{
 		Title:       "First change",
 		Description: "Description of first change",
+		Author:      "Test User",
 		UUID:        uuid1,
 		CommitHash:  hash1,
 		Position:    1,
@@ -246,6 +291,7 @@ func TestGetStackContext_WithMergedAndActiveChanges(t *testing.T) {
 		{
 			Title:          "Second change",
 			Description:    "Description of second change",
+			Author:         "Test User",
 			UUID:           uuid2,
 			CommitHash:     stackCtx.ActiveChanges[0].CommitHash, // Use actual hash
 			Position:       2,                                    // Position 2 because merged PR is #1
@@ -255,6 +301,7 @@ func TestGetStackContext_WithMergedAndActiveChanges(t *testing.T) {
 		{
 			Title:          "Third change",
 			Description:    "Description of third change",
+			Author:         "Test User",
 			UUID:           uuid3,
 			CommitHash:     stackCtx.ActiveChanges[1].CommitHash, // Use actual hash
 			Position:       3,                                    // Position 3
@@ -343,6 +390,7 @@ func TestGetStackContext_WithStaleMergedChanges(t *testing.T) {
 	expectedStale := &model.Change{
 		Title:       "First change",
 		Description: "Description of first change",
+		Author:      "Test User",
 		UUID:        uuid1,
 		CommitHash:  hash1,
 		Position:    1, // Gets position 1 since there are no merged changes in Stack.MergedChanges
@@ -362,6 +410,7 @@ func TestGetStackContext_WithStaleMergedChanges(t *testing.T) {
 	expectedActive := &model.Change{
 		Title:          "Second change",
 		Description:    "Description of second change",
+		Author:         "Test User",
 		UUID:           uuid2,
 		CommitHash:     hash2,
 		Position:       2, // Position 2 (after the stale merged change)
@@ -382,6 +431,198 @@ func TestGetStackContext_WithStaleMergedChanges(t *testing.T) {
 	assert.Equal(t, uuid2, stackCtx.AllChanges[1].UUID, "second change in AllChanges should be active")
 }
 
+func TestClassifyChanges(t *testing.T) {
+	uuid1 := "aaaa111111111111"
+	uuid2 := "bbbb222222222222"
+	uuid3 := "cccc333333333333"
+
+	setup := func(t *testing.T) (*Client, *model.Stack, []string) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		var hashes []string
+		for i, uuid := range []string{uuid1, uuid2, uuid3} {
+			hashes = append(hashes, testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			}))
+		}
+		return stackClient, stack, hashes
+	}
+
+	uuids := func(changes []*model.Change) []string {
+		var result []string
+		for _, change := range changes {
+			result = append(result, change.UUID)
+		}
+		return result
+	}
+
+	t.Run("MultipleActiveChanges", func(t *testing.T) {
+		stackClient, stack, hashes := setup(t)
+
+		active, merged, staleMerged, err := stackClient.ClassifyChanges("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, merged)
+		assert.Empty(t, staleMerged)
+		require.Len(t, active, 3)
+		assert.Equal(t, &model.Change{
+			Title:          "Change 1",
+			Author:         "Test User",
+			UUID:           uuid1,
+			CommitHash:     hashes[0],
+			Position:       1,
+			ActivePosition: 1,
+			DesiredBase:    stack.Base,
+		}, active[0])
+		assert.Equal(t, "test-user/stack-test-stack/"+uuid2, active[2].DesiredBase)
+	})
+
+	t.Run("MergedAndActiveChanges", func(t *testing.T) {
+		stackClient, stack, hashes := setup(t)
+		mergedPR := &model.PR{PRNumber: 101, State: "merged"}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs:     map[string]*model.PR{uuid1: mergedPR},
+		}))
+		stack.MergedChanges = []model.Change{{Title: "Change 1", UUID: uuid1, CommitHash: hashes[0], PR: mergedPR}}
+		require.NoError(t, stackClient.SaveStack(stack))
+
+		active, merged, staleMerged, err := stackClient.ClassifyChanges("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []string{uuid1}, uuids(merged))
+		assert.Equal(t, 1, merged[0].Position)
+		// Still on TOP with a merged PR, so it's also stale until the next refresh
+		assert.Equal(t, []string{uuid1}, uuids(staleMerged))
+		assert.Equal(t, []string{uuid2, uuid3}, uuids(active))
+		assert.Equal(t, 2, active[0].Position)
+		assert.Equal(t, 1, active[0].ActivePosition)
+	})
+
+	t.Run("StaleMergedChanges", func(t *testing.T) {
+		stackClient, _, _ := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuid1: {PRNumber: 201, State: "merged"},
+				uuid2: {PRNumber: 202, State: "open"},
+			},
+		}))
+
+		active, merged, staleMerged, err := stackClient.ClassifyChanges("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, merged)
+		assert.Equal(t, []string{uuid1}, uuids(staleMerged))
+		assert.Equal(t, []string{uuid2, uuid3}, uuids(active))
+		assert.Equal(t, 202, active[0].PR.PRNumber)
+	})
+
+	t.Run("IndependentOfCurrentBranch", func(t *testing.T) {
+		stackClient, _, _ := setup(t)
+		require.NoError(t, stackClient.git.CheckoutBranch("main"))
+
+		active, _, _, err := stackClient.ClassifyChanges("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []string{uuid1, uuid2, uuid3}, uuids(active))
+
+		_, _, _, err = stackClient.ClassifyChanges("missing")
+		assert.ErrorIs(t, err, ErrStackNotFound)
+	})
+}
+
+func TestGetStackContext_PopulatesMergedAt(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid1 := "ffff111111111111"
+	uuid2 := "ffff222222222222"
+	uuid3 := "ffff333333333333"
+
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "First change", "", map[string]string{
+		"PR-UUID":  uuid1,
+		"PR-Stack": "test-stack",
+	})
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Second change", "", map[string]string{
+		"PR-UUID":  uuid2,
+		"PR-Stack": "test-stack",
+	})
+
+	mergedAt1 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	mergedAt3 := time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)
+
+	prData := &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 301, State: "merged", MergedAt: mergedAt1},
+			uuid2: {PRNumber: 302, State: "open"},
+			uuid3: {PRNumber: 300, State: "merged", MergedAt: mergedAt3},
+		},
+	}
+	require.NoError(t, stackClient.savePRs("test-stack", prData))
+
+	// uuid3 was merged and already removed from TOP; it only lives in Stack.MergedChanges
+	// without a MergedAt of its own.
+	stack.MergedChanges = []model.Change{
+		{Title: "Already merged", UUID: uuid3, Position: 1},
+	}
+	require.NoError(t, stackClient.SaveStack(stack))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	merged := stackCtx.FindChange(uuid3)
+	require.NotNil(t, merged)
+	assert.Equal(t, mergedAt3, merged.MergedAt, "merged change should pick up MergedAt from prs.json")
+
+	require.Len(t, stackCtx.StaleMergedChanges, 1)
+	assert.Equal(t, mergedAt1, stackCtx.StaleMergedChanges[0].MergedAt, "stale merged change should pick up MergedAt from prs.json")
+
+	require.Len(t, stackCtx.ActiveChanges, 1)
+	assert.True(t, stackCtx.ActiveChanges[0].MergedAt.IsZero(), "open change should not have MergedAt")
+}
+
+func TestGetStackContext_PopulatesAuthor(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	t.Setenv("GIT_AUTHOR_NAME", "Ada Lovelace")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ada@example.com")
+	hash := testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+		"PR-UUID":  "aaaa111111111111",
+		"PR-Stack": "test-stack",
+	})
+
+	meta, err := gitClient.GetCommitMeta(hash)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", meta.AuthorName)
+	assert.Equal(t, "ada@example.com", meta.AuthorEmail)
+	assert.True(t, meta.Date.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	commit, err := gitClient.GetCommit(hash)
+	require.NoError(t, err)
+	assert.Equal(t, meta, commit.Meta)
+	assert.Equal(t, "First change", commit.Message.Title)
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	require.Len(t, stackCtx.ActiveChanges, 1)
+	assert.Equal(t, "Ada Lovelace", stackCtx.ActiveChanges[0].Author)
+}
+
 func TestCheckSyncStatus(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -543,6 +784,52 @@ func TestCheckSyncStatus(t *testing.T) {
 	}
 }
 
+func TestCheckSyncStatusAll(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	// saveSynced creates a stack last synced at lastSynced with a hash matching its branch
+	saveSynced := func(name string, lastSynced time.Time) {
+		stack, err := stackClient.CreateStack(name, "main")
+		require.NoError(t, err)
+		stack.SyncHash, err = stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		stack.LastSynced = lastSynced
+		require.NoError(t, stackClient.SaveStack(stack))
+	}
+
+	saveSynced("fresh", time.Now())
+	saveSynced("stale", time.Now().Add(-time.Hour))
+
+	neverSynced, err := stackClient.CreateStack("never-synced", "main")
+	require.NoError(t, err)
+	neverSynced.LastSynced = time.Time{}
+	require.NoError(t, stackClient.SaveStack(neverSynced))
+
+	_, err = stackClient.CreateStack("broken", "main")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(stackClient.getStackDir("broken"), "config.json"), []byte("invalid json{"), 0644))
+
+	statuses, err := stackClient.CheckSyncStatusAll()
+	require.NoError(t, err)
+
+	reasons := make(map[string]string, len(statuses))
+	for name, status := range statuses {
+		reasons[name] = status.Reason
+		assert.Equal(t, status.Reason != "", status.NeedsSync, "stack %s", name)
+	}
+	assert.Equal(t, map[string]string{
+		"fresh":        "",
+		"stale":        "stale",
+		"never-synced": "never_synced",
+		"broken":       "hash_check_failed",
+	}, reasons)
+
+	mockGithubClient.AssertNotCalled(t, "BatchGetPRs", mock.Anything)
+}
+
 // Helper to create multiple stacks for testing
 func createTestStacks(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient, stackNames []string) {
 	if len(stackNames) == 0 {
@@ -680,6 +967,7 @@ func TestGetStackContext(t *testing.T) {
 					UUID:           "1111111111111111",
 					Title:          "Test change",
 					Description:    "Description",
+					Author:         "Test User",
 					CommitHash:     commitHash,
 					Position:       1,
 					ActivePosition: 1,
@@ -725,6 +1013,7 @@ func TestGetStackContext(t *testing.T) {
 					UUID:           "1111111111111111",
 					Title:          "Test change",
 					Description:    "Description",
+					Author:         "Test User",
 					CommitHash:     commitHash,
 					Position:       1,
 					ActivePosition: 1,
@@ -889,6 +1178,7 @@ func TestCheckoutChangeForEditing(t *testing.T) {
 	tests := []struct {
 		name         string
 		setup        func(*testing.T, *Client, *gh.MockGithubClient) (*StackContext, *model.Change)
+		opts         EditOptions
 		expectBranch string
 		expectError  error
 	}{
@@ -925,6 +1215,29 @@ func TestCheckoutChangeForEditing(t *testing.T) {
 			},
 			expectBranch: "test-user/stack-test-stack/1111111111111111",
 		},
+		{
+			name: "RefusesUncommittedChanges",
+			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) (*StackContext, *model.Change) {
+				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+				_, err := client.CreateStack("test-stack", "main")
+				require.NoError(t, err)
+				for i, uuid := range []string{"1111111111111111", "1111111111111112"} {
+					_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), fmt.Sprintf("Test change %d", i+1), "", map[string]string{
+						"PR-UUID":  uuid,
+						"PR-Stack": "test-stack",
+					})
+				}
+
+				stackCtx, err := client.GetStackContextByName("test-stack")
+				require.NoError(t, err)
+
+				// 'git checkout -B' would carry this edit onto the change's branch
+				require.NoError(t, os.WriteFile(filepath.Join(client.git.GitRoot(), "wip.txt"), []byte("wip"), 0644))
+				return stackCtx, stackCtx.ActiveChanges[0]
+			},
+			expectError: ErrUncommittedChanges,
+		},
 		{
 			name: "UUIDBranchExistsAtCorrectCommit",
 			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) (*StackContext, *model.Change) {
@@ -1037,6 +1350,32 @@ func TestCheckoutChangeForEditing(t *testing.T) {
 			},
 			expectBranch: "test-user/stack-test-stack/TOP",
 		},
+		{
+			name: "TopChange_StayOnUUIDBranch",
+			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) (*StackContext, *model.Change) {
+				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+				_, err := client.CreateStack("test-stack", "main")
+				require.NoError(t, err)
+
+				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "First change", "Description 1", map[string]string{
+					"PR-UUID":  "6666666666666666",
+					"PR-Stack": "test-stack",
+				})
+				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "Second change", "Description 2", map[string]string{
+					"PR-UUID":  "7777777777777777",
+					"PR-Stack": "test-stack",
+				})
+
+				stackCtx, err := client.GetStackContextByName("test-stack")
+				require.NoError(t, err)
+
+				require.Len(t, stackCtx.ActiveChanges, 2)
+				return stackCtx, stackCtx.ActiveChanges[1]
+			},
+			opts:         EditOptions{StayOnUUIDBranch: true},
+			expectBranch: "test-user/stack-test-stack/7777777777777777",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1047,7 +1386,7 @@ func TestCheckoutChangeForEditing(t *testing.T) {
 
 				stackCtx, change := tt.setup(t, stackClient, mockGithubClient)
 
-				branchName, err := stackClient.CheckoutChangeForEditing(stackCtx, change)
+				branchName, err := stackClient.CheckoutChangeForEditing(stackCtx, change, tt.opts)
 
 				if tt.expectError != nil {
 					require.Error(t, err)
@@ -1203,7 +1542,7 @@ func TestApplyRefresh(t *testing.T) {
 				stackCtx := tt.setup(t, stackClient, mockGithubClient)
 
 				merged := stackCtx.StaleMergedChanges
-				err := stackClient.ApplyRefresh(stackCtx, merged)
+				err := stackClient.ApplyRefresh(stackCtx, merged, false)
 
 				if tt.expectError != nil {
 					require.Error(t, err)
@@ -1330,98 +1669,595 @@ func TestRestack(t *testing.T) {
 	}
 }
 
-func TestUpdateLocalBaseRef(t *testing.T) {
-	tests := []struct {
-		name        string
-		setup       func(*testing.T, *Client) string
-		expectError error
-	}{
-		// NOTE: The other test cases (BranchExists_HashMatches, BranchExists_HashDiffers, BranchDoesNotExist_CreateIt)
-		// are complex to test because they require a real remote repository with upstream tracking.
-		// In a test environment without a remote, these cases would fail with "no upstream tracking branch configured".
-		// The NoUpstream_Error test below covers the primary error case for this function.
-		{
-			name: "NoUpstream_Error",
-			setup: func(t *testing.T, client *Client) string {
-				// Create a local branch with no upstream configured
-				cmd := exec.Command("git", "branch", "local-only")
-				cmd.Dir = client.git.GitRoot()
-				err := cmd.Run()
-				require.NoError(t, err)
+func TestRestack_UpdatesUUIDBranches(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
 
-				return "local-only"
-			},
-			expectError: fmt.Errorf("no upstream tracking branch configured"),
-		},
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	for i, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			synctest.Test(t, func(t *testing.T) {
-				mockGithubClient := &gh.MockGithubClient{}
-				stackClient := NewTestStack(t, mockGithubClient)
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
 
-				baseBranch := tt.setup(t, stackClient)
+	// Only the first two changes have UUID branches; the third must be skipped
+	for i := range 2 {
+		require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch(uuids[i]), stackCtx.ActiveChanges[i].CommitHash))
+	}
 
-				err := stackClient.UpdateLocalBaseRef(baseBranch)
+	// Move main forward
+	require.NoError(t, gitClient.CheckoutBranch("main"))
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Base moved", "", nil)
+	require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
 
-				if tt.expectError != nil {
-					require.Error(t, err)
-					assert.ErrorContains(t, err, tt.expectError.Error())
-				} else {
-					require.NoError(t, err)
-				}
+	require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "main"}))
 
-				mockGithubClient.AssertExpectations(t)
-			})
-		})
+	stackCtx, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	require.Len(t, stackCtx.ActiveChanges, 3)
+
+	mainHash, err := stackClient.git.GetCommitHash("main")
+	require.NoError(t, err)
+	assert.Equal(t, mainHash, stackCtx.Stack.BaseRef)
+
+	for i := range 2 {
+		branchHash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch(uuids[i]))
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[i].CommitHash, branchHash, "UUID branch for change %d should point at the rebased commit", i+1)
+		assert.True(t, stackClient.git.IsAncestor(mainHash, branchHash))
 	}
+	assert.False(t, stackClient.git.BranchExists(stackCtx.FormatUUIDBranch(uuids[2])))
 }
 
-func TestDeleteStack(t *testing.T) {
-	tests := []struct {
-		name        string
-		setup       func(*testing.T, *Client, *gh.MockGithubClient) string
-		expectError error
-	}{
-		{
-			name: "Success_NotOnStackBranch",
-			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) string {
-				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+func TestRestack_ConflictIsResumable(t *testing.T) {
+	// setup creates a two-change stack whose first change conflicts with a new commit on main
+	setup := func(t *testing.T) (*Client, *StackContext, string) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
 
-				_, err := client.CreateStack("test-stack", "main")
-				require.NoError(t, err)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
 
-				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "Test change", "Description", map[string]string{
-					"PR-UUID":  "1111111111111111",
-					"PR-Stack": "test-stack",
-				})
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
 
-				// Switch to main so we're not on the stack branch
-				err = client.git.CheckoutBranch("main")
-				require.NoError(t, err)
+		testutil.WriteFile(t, gitClient.GitRoot(), "shared.txt", "from the stack\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 2", "", map[string]string{
+			"PR-UUID":  "2222222222222222",
+			"PR-Stack": "test-stack",
+		})
 
-				return "test-stack"
-			},
-		},
-		{
-			name: "Success_OnStackBranch",
-			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) string {
-				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch("1111111111111111"), stackCtx.ActiveChanges[0].CommitHash))
 
-				stack, err := client.CreateStack("test-stack", "main")
-				require.NoError(t, err)
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		testutil.WriteFile(t, gitClient.GitRoot(), "shared.txt", "from main\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Main change", "", nil)
+		require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
 
-				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "Test change", "Description", map[string]string{
-					"PR-UUID":  "2222222222222222",
-					"PR-Stack": "test-stack",
-				})
+		originalHead, err := stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		return stackClient, stackCtx, originalHead
+	}
 
-				// Stay on stack branch
-				err = client.git.CheckoutBranch(stack.Branch)
-				require.NoError(t, err)
+	restackWithConflict := func(t *testing.T, stackClient *Client, stackCtx *StackContext, originalHead string) {
+		err := stackClient.Restack(stackCtx, RestackOptions{Onto: "main"})
+		require.Error(t, err)
 
-				return "test-stack"
+		var conflictErr *ErrRebaseConflict
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "main", conflictErr.TargetBase)
+		assert.Equal(t, []string{"shared.txt"}, conflictErr.Files)
+		assert.ErrorContains(t, err, "Conflicted files:\n  shared.txt\n")
+		assert.ErrorContains(t, err, "stack restack --continue")
+		assert.True(t, stackClient.git.IsRebaseInProgress())
+
+		state, err := stackClient.LoadRebaseState("test-stack")
+		require.NoError(t, err)
+		mainHash, err := stackClient.git.GetCommitHash("main")
+		require.NoError(t, err)
+		assert.Equal(t, "main", state.TargetBase)
+		assert.Equal(t, mainHash, state.TargetBaseRef)
+		assert.Equal(t, originalHead, state.OriginalStackHead)
+		assert.Equal(t, stackCtx.Stack.Branch, state.StackBranch)
+	}
+
+	t.Run("ContinueAfterResolving", func(t *testing.T) {
+		stackClient, stackCtx, originalHead := setup(t)
+		gitRoot := stackClient.git.GitRoot()
+		originalBaseRef := stackCtx.Stack.BaseRef
+
+		restackWithConflict(t, stackClient, stackCtx, originalHead)
+
+		// Continuing before the conflict is resolved stops again and keeps the state
+		err := stackClient.ContinueRestack("test-stack")
+		var conflictErr *ErrRebaseConflict
+		require.ErrorAs(t, err, &conflictErr)
+		assert.True(t, stackClient.HasRebaseState("test-stack"))
+
+		testutil.WriteFile(t, gitRoot, "shared.txt", "resolved\n")
+		cmd := exec.Command("git", "add", "shared.txt")
+		cmd.Dir = gitRoot
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git add failed: %s", string(output))
+
+		require.NoError(t, stackClient.ContinueRestack("test-stack"))
+		assert.False(t, stackClient.git.IsRebaseInProgress())
+		assert.False(t, stackClient.HasRebaseState("test-stack"))
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		mainHash, err := stackClient.git.GetCommitHash("main")
+		require.NoError(t, err)
+		assert.Equal(t, "main", stackCtx.Stack.Base)
+		assert.Equal(t, mainHash, stackCtx.Stack.BaseRef)
+		assert.NotEqual(t, originalBaseRef, stackCtx.Stack.BaseRef)
+		require.Len(t, stackCtx.ActiveChanges, 2)
+
+		branchHash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch("1111111111111111"))
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[0].CommitHash, branchHash)
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+
+		content, err := os.ReadFile(filepath.Join(gitRoot, "shared.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "resolved\n", string(content))
+	})
+
+	t.Run("ContinueAfterAbort", func(t *testing.T) {
+		stackClient, stackCtx, originalHead := setup(t)
+		originalBaseRef := stackCtx.Stack.BaseRef
+
+		restackWithConflict(t, stackClient, stackCtx, originalHead)
+
+		cmd := exec.Command("git", "rebase", "--abort")
+		cmd.Dir = stackClient.git.GitRoot()
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git rebase --abort failed: %s", string(output))
+
+		err = stackClient.ContinueRestack("test-stack")
+		assert.ErrorContains(t, err, "was aborted")
+		assert.False(t, stackClient.HasRebaseState("test-stack"))
+
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, originalBaseRef, stack.BaseRef)
+		head, err := stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head)
+	})
+
+	t.Run("AbortStackOperation", func(t *testing.T) {
+		stackClient, stackCtx, originalHead := setup(t)
+		originalBaseRef := stackCtx.Stack.BaseRef
+
+		restackWithConflict(t, stackClient, stackCtx, originalHead)
+
+		require.NoError(t, stackClient.AbortStackOperation("test-stack"))
+		assert.False(t, stackClient.git.IsRebaseInProgress())
+		assert.False(t, stackClient.HasRebaseState("test-stack"))
+
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, originalBaseRef, stack.BaseRef)
+		head, err := stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head)
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stack.Branch, currentBranch)
+
+		// Nothing is left to abort
+		assert.Error(t, stackClient.AbortStackOperation("test-stack"))
+		assert.ErrorContains(t, stackClient.git.AbortRebase(), "no rebase in progress")
+	})
+}
+
+func TestAbortStackOperation_AfterAmendConflict(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	testutil.WriteFile(t, gitClient.GitRoot(), "shared.txt", "1\n")
+	first := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "test-stack",
+	})
+	testutil.WriteFile(t, gitClient.GitRoot(), "shared.txt", "2\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 2", "", map[string]string{
+		"PR-UUID":  "2222222222222222",
+		"PR-Stack": "test-stack",
+	})
+	originalHead, err := gitClient.GetCommitHash(stack.Branch)
+	require.NoError(t, err)
+
+	// Amend the first change so that the change above it no longer applies
+	require.NoError(t, gitClient.CheckoutDetached(first))
+	testutil.WriteFile(t, gitClient.GitRoot(), "shared.txt", "amended\n")
+	cmd := exec.Command("git", "commit", "-a", "--amend", "--no-edit", "--no-verify")
+	cmd.Dir = gitClient.GitRoot()
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git commit --amend failed: %s", string(output))
+	amended, err := gitClient.GetCommitHash("HEAD")
+	require.NoError(t, err)
+
+	_, err = stackClient.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+		StackName:         "test-stack",
+		StackBranch:       stack.Branch,
+		OldCommitHash:     first,
+		NewCommitHash:     amended,
+		OriginalStackHead: originalHead,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "stack restack --abort")
+	require.True(t, gitClient.IsRebaseInProgress())
+
+	require.NoError(t, stackClient.AbortStackOperation("test-stack"))
+	assert.False(t, gitClient.IsRebaseInProgress())
+	assert.False(t, stackClient.HasRebaseState("test-stack"))
+
+	head, err := gitClient.GetCommitHash(stack.Branch)
+	require.NoError(t, err)
+	assert.Equal(t, originalHead, head)
+	currentBranch, err := gitClient.GetCurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, stack.Branch, currentBranch)
+}
+
+func TestRestack_AutoStash(t *testing.T) {
+	// setup creates a one-change stack whose base has moved on: main gained a commit that
+	// changes shared.txt. The change's stack.txt is left modified in the working tree,
+	// along with an untracked file.
+	setup := func(t *testing.T) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+		gitRoot := gitClient.GitRoot()
+
+		testutil.WriteFile(t, gitRoot, "shared.txt", "original\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Add shared file", "", nil)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		testutil.WriteFile(t, gitRoot, "stack.txt", "from the stack\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		testutil.WriteFile(t, gitRoot, "shared.txt", "from main\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Main change", "", nil)
+		require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
+
+		testutil.WriteFile(t, gitRoot, "stack.txt", "work in progress\n")
+		testutil.WriteFile(t, gitRoot, "notes.txt", "untracked\n")
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		return stackClient, stackCtx
+	}
+
+	stashList := func(t *testing.T, stackClient *Client) string {
+		cmd := exec.Command("git", "stash", "list")
+		cmd.Dir = stackClient.git.GitRoot()
+		output, err := cmd.Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+
+	assertWorkInProgress := func(t *testing.T, stackClient *Client) {
+		gitRoot := stackClient.git.GitRoot()
+		data, err := os.ReadFile(filepath.Join(gitRoot, "stack.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "work in progress\n", string(data))
+		data, err = os.ReadFile(filepath.Join(gitRoot, "notes.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "untracked\n", string(data))
+		assert.Empty(t, stashList(t, stackClient), "the autostash should be popped")
+	}
+
+	assertRestackedOntoMain := func(t *testing.T, stackClient *Client, stackCtx *StackContext) {
+		mainHash, err := stackClient.git.GetCommitHash("main")
+		require.NoError(t, err)
+		assert.True(t, stackClient.git.IsAncestor(mainHash, stackCtx.Stack.Branch))
+	}
+
+	t.Run("ChangesSurviveRestack", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "main", AutoStash: true}))
+
+		assertRestackedOntoMain(t, stackClient, stackCtx)
+		assertWorkInProgress(t, stackClient)
+	})
+
+	t.Run("WithoutAutoStash", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		assert.Error(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "main"}))
+		assert.ErrorContains(t, stackClient.ApplyRefresh(stackCtx, nil, false), "uncommitted changes")
+		assert.False(t, stackClient.git.IsRebaseInProgress())
+	})
+
+	t.Run("ApplyRefresh", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		// Refresh fetches before rebasing
+		testutil.AddBareRemote(t, stackClient.git.(*git.Client))
+
+		require.NoError(t, stackClient.ApplyRefresh(stackCtx, nil, true))
+
+		assertRestackedOntoMain(t, stackClient, stackCtx)
+		assertWorkInProgress(t, stackClient)
+	})
+
+	t.Run("ReappliedAfterConflictIsAborted", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		gitRoot := stackClient.git.GitRoot()
+
+		// Make the stack's change conflict with main
+		cmd := exec.Command("git", "stash", "push", "--include-untracked")
+		cmd.Dir = gitRoot
+		require.NoError(t, cmd.Run())
+		testutil.WriteFile(t, gitRoot, "shared.txt", "from the stack\n")
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Change 2", "", map[string]string{
+			"PR-UUID":  "2222222222222222",
+			"PR-Stack": "test-stack",
+		})
+		cmd = exec.Command("git", "stash", "pop")
+		cmd.Dir = gitRoot
+		require.NoError(t, cmd.Run())
+
+		err := stackClient.Restack(stackCtx, RestackOptions{Onto: "main", AutoStash: true})
+		var conflictErr *ErrRebaseConflict
+		require.ErrorAs(t, err, &conflictErr)
+		state, err := stackClient.LoadRebaseState("test-stack")
+		require.NoError(t, err)
+		assert.NotEmpty(t, state.AutoStash)
+		assert.NotEmpty(t, stashList(t, stackClient), "the changes stay stashed while the rebase is stopped")
+
+		// Something else stashed while resolving the conflicts stays put; the autostash is popped
+		cmd = exec.Command("git", "stash", "store", "-m", "user stash", "main")
+		cmd.Dir = gitRoot
+		require.NoError(t, cmd.Run())
+
+		require.NoError(t, stackClient.AbortStackOperation("test-stack"))
+		assert.Equal(t, "stash@{0}: user stash", stashList(t, stackClient))
+		cmd = exec.Command("git", "stash", "clear")
+		cmd.Dir = gitRoot
+		require.NoError(t, cmd.Run())
+		assertWorkInProgress(t, stackClient)
+	})
+
+	t.Run("PopConflictKeepsStash", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		// Uncommitted edits to the file main changed conflict when reapplied
+		testutil.WriteFile(t, stackClient.git.GitRoot(), "shared.txt", "local edit\n")
+
+		err := stackClient.Restack(stackCtx, RestackOptions{Onto: "main", AutoStash: true})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "git stash drop")
+		assertRestackedOntoMain(t, stackClient, stackCtx)
+		assert.Contains(t, stashList(t, stackClient), "stack autostash")
+	})
+}
+
+func TestRestack_OntoValidation(t *testing.T) {
+	setup := func(t *testing.T) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+
+		// develop branches off main and has one extra commit
+		require.NoError(t, gitClient.CreateBranchAt("develop", "main"))
+		require.NoError(t, gitClient.CheckoutBranch("develop"))
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Develop change", "", nil)
+		require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		return stackClient, stackCtx
+	}
+
+	t.Run("NonexistentTarget", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		originalHead, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.Restack(stackCtx, RestackOptions{Onto: "no-such-branch"})
+		assert.ErrorContains(t, err, "cannot restack onto 'no-such-branch': branch does not exist")
+
+		head, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head)
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, "main", stack.Base)
+	})
+
+	t.Run("SwitchBase", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "develop"}))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		developHash, err := stackClient.git.GetCommitHash("develop")
+		require.NoError(t, err)
+		assert.Equal(t, "develop", stackCtx.Stack.Base)
+		assert.Equal(t, developHash, stackCtx.Stack.BaseRef)
+		require.Len(t, stackCtx.ActiveChanges, 1)
+		assert.True(t, stackClient.git.IsAncestor(developHash, stackCtx.ActiveChanges[0].CommitHash))
+	})
+
+	t.Run("RefusesWithUnsyncedMergedChanges", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {PRNumber: 1, State: "merged"},
+			},
+		}))
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.StaleMergedChanges, 1)
+
+		err = stackClient.Restack(stackCtx, RestackOptions{Onto: "develop"})
+		assert.ErrorContains(t, err, "run 'stack refresh' first")
+
+		// Restacking onto the same base is still allowed
+		require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "main"}))
+	})
+}
+
+func TestUpdateLocalBaseRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(*testing.T, *Client) string
+		expectError error
+	}{
+		// NOTE: The other test cases (BranchExists_HashMatches, BranchExists_HashDiffers, BranchDoesNotExist_CreateIt)
+		// are complex to test because they require a real remote repository with upstream tracking.
+		// In a test environment without a remote, these cases would fail with "no upstream tracking branch configured".
+		// The NoUpstream_Error test below covers the primary error case for this function.
+		{
+			name: "NoUpstream_Error",
+			setup: func(t *testing.T, client *Client) string {
+				// Create a local branch with no upstream configured
+				cmd := exec.Command("git", "branch", "local-only")
+				cmd.Dir = client.git.GitRoot()
+				err := cmd.Run()
+				require.NoError(t, err)
+
+				return "local-only"
+			},
+			expectError: fmt.Errorf("no upstream tracking branch configured"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				mockGithubClient := &gh.MockGithubClient{}
+				stackClient := NewTestStack(t, mockGithubClient)
+
+				baseBranch := tt.setup(t, stackClient)
+
+				err := stackClient.UpdateLocalBaseRef(baseBranch)
+
+				if tt.expectError != nil {
+					require.Error(t, err)
+					assert.ErrorContains(t, err, tt.expectError.Error())
+				} else {
+					require.NoError(t, err)
+				}
+
+				mockGithubClient.AssertExpectations(t)
+			})
+		})
+	}
+}
+
+func TestSetStackDescription(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	require.NoError(t, stackClient.SetStackDescription("test-stack", "  Auth refactor epic \n"))
+	stack, err := stackClient.LoadStack("test-stack")
+	require.NoError(t, err)
+	assert.Equal(t, "Auth refactor epic", stack.Description)
+
+	assert.ErrorContains(t, stackClient.SetStackDescription("test-stack", "line one\nline two"), "single line")
+
+	require.NoError(t, stackClient.SetStackDescription("test-stack", ""))
+	stack, err = stackClient.LoadStack("test-stack")
+	require.NoError(t, err)
+	assert.Empty(t, stack.Description)
+}
+
+func TestDeleteStack(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(*testing.T, *Client, *gh.MockGithubClient) string
+		expectError error
+	}{
+		{
+			name: "Success_NotOnStackBranch",
+			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) string {
+				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+				_, err := client.CreateStack("test-stack", "main")
+				require.NoError(t, err)
+
+				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "Test change", "Description", map[string]string{
+					"PR-UUID":  "1111111111111111",
+					"PR-Stack": "test-stack",
+				})
+
+				// Switch to main so we're not on the stack branch
+				err = client.git.CheckoutBranch("main")
+				require.NoError(t, err)
+
+				return "test-stack"
+			},
+		},
+		{
+			name: "Success_OnStackBranch",
+			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) string {
+				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+				stack, err := client.CreateStack("test-stack", "main")
+				require.NoError(t, err)
+
+				_ = testutil.CreateCommitWithTrailers(t, client.git.(*git.Client), "Test change", "Description", map[string]string{
+					"PR-UUID":  "2222222222222222",
+					"PR-Stack": "test-stack",
+				})
+
+				// Stay on stack branch
+				err = client.git.CheckoutBranch(stack.Branch)
+				require.NoError(t, err)
+
+				return "test-stack"
 			},
 		},
 		{
@@ -1441,7 +2277,7 @@ func TestDeleteStack(t *testing.T) {
 
 				stackName := tt.setup(t, stackClient, mockGithubClient)
 
-				err := stackClient.DeleteStack(stackName, true)
+				_, err := stackClient.DeleteStack(stackName, DeleteOptions{})
 
 				if tt.expectError != nil {
 					require.Error(t, err)
@@ -1484,23 +2320,79 @@ func TestDeleteStack(t *testing.T) {
 	}
 }
 
-func TestIsStackEligibleForCleanup(t *testing.T) {
-	tests := []struct {
-		name           string
-		setup          func(*testing.T, *Client, *gh.MockGithubClient) *StackContext
-		expectEligible bool
-		expectReason   string
-	}{
-		{
-			name: "EmptyStack",
-			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) *StackContext {
-				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
-
-				_, err := client.CreateStack("empty-stack", "main")
-				require.NoError(t, err)
+func TestDeleteStack_DryRun(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
 
-				stackCtx, err := client.GetStackContextByName("empty-stack")
-				require.NoError(t, err)
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+	testutil.AddBareRemote(t, gitClient)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+	hash1 := testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "test-stack",
+	})
+	hash2 := testutil.CreateCommitWithTrailers(t, gitClient, "Second change", "", map[string]string{
+		"PR-UUID":  "2222222222222222",
+		"PR-Stack": "test-stack",
+	})
+	branch1 := "test-user/stack-test-stack/1111111111111111"
+	branch2 := "test-user/stack-test-stack/2222222222222222"
+	require.NoError(t, gitClient.CreateBranchAt(branch1, hash1))
+	require.NoError(t, gitClient.CreateBranchAt(branch2, hash2))
+
+	// Only the first change has been pushed
+	require.NoError(t, gitClient.Push(branch1, false))
+
+	plan, err := stackClient.DeleteStack("test-stack", DeleteOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{branch1, branch2, stack.Branch}, plan.LocalBranches)
+	assert.Equal(t, []string{branch1}, plan.RemoteBranches)
+	assert.Equal(t, filepath.Join(stackClient.getStacksRootDir(), ".archived"), filepath.Dir(plan.ArchivePath))
+	assert.True(t, strings.HasPrefix(filepath.Base(plan.ArchivePath), "test-stack-"))
+
+	// Nothing was deleted or archived
+	assert.True(t, stackClient.StackExists("test-stack"))
+	for _, branch := range plan.LocalBranches {
+		assert.True(t, gitClient.BranchExists(branch), "branch %s should still exist", branch)
+	}
+	remoteHeads, err := gitClient.LsRemoteHeads("origin", "test-user/stack-test-stack/*")
+	require.NoError(t, err)
+	assert.Contains(t, remoteHeads, branch1)
+	_, err = os.Stat(filepath.Join(stackClient.getStacksRootDir(), ".archived"))
+	assert.True(t, os.IsNotExist(err), "no archive should be created")
+
+	// The real deletion deletes what the plan listed
+	deleted, err := stackClient.DeleteStack("test-stack", DeleteOptions{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, plan.LocalBranches, deleted.LocalBranches)
+	remoteHeads, err = gitClient.LsRemoteHeads("origin", "test-user/stack-test-stack/*")
+	require.NoError(t, err)
+	assert.Empty(t, remoteHeads)
+	assert.False(t, stackClient.StackExists("test-stack"))
+	_, err = os.Stat(deleted.ArchivePath)
+	assert.NoError(t, err)
+}
+
+func TestIsStackEligibleForCleanup(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(*testing.T, *Client, *gh.MockGithubClient) *StackContext
+		expectEligible bool
+		expectReason   string
+	}{
+		{
+			name: "EmptyStack",
+			setup: func(t *testing.T, client *Client, mockGithubClient *gh.MockGithubClient) *StackContext {
+				mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+				_, err := client.CreateStack("empty-stack", "main")
+				require.NoError(t, err)
+
+				stackCtx, err := client.GetStackContextByName("empty-stack")
+				require.NoError(t, err)
 
 				return stackCtx
 			},
@@ -2034,7 +2926,7 @@ func TestRefreshStackMetadata(t *testing.T) {
 				// Store original pointer for verification
 				originalPtr := stackCtx
 
-				result, err := stackClient.RefreshStackMetadata(stackCtx)
+				result, err := stackClient.RefreshStackMetadata(stackCtx, RefreshOptions{})
 
 				if tt.expectError != nil {
 					require.Error(t, err)
@@ -2058,6 +2950,154 @@ func TestRefreshStackMetadata(t *testing.T) {
 	}
 }
 
+func TestRefreshStackMetadata_AllowOutOfOrderMerges(t *testing.T) {
+	// setup creates a two-change stack whose upper PR was merged before the lower one
+	setup := func(t *testing.T) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		for _, uuid := range []string{"1111111111111111", "2222222222222222"} {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {PRNumber: 101, State: "open"},
+				"2222222222222222": {PRNumber: 102, State: "open"},
+			},
+		}))
+		mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", mock.AnythingOfType("[]int")).Return(&gh.BatchPRsResult{
+			PRStates: map[int]*gh.PRState{
+				101: {Number: 101, State: "OPEN"},
+				102: {Number: 102, State: "MERGED", IsMerged: true},
+			},
+		}, nil)
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		return stackClient, stackCtx
+	}
+
+	t.Run("DefaultFails", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		_, err := stackClient.RefreshStackMetadata(stackCtx, RefreshOptions{})
+		assert.ErrorIs(t, err, ErrOutOfOrderMerge)
+	})
+
+	t.Run("AllowedRecordsWarning", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		result, err := stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{AllowOutOfOrderMerges: true})
+		require.NoError(t, err)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "out-of-order merge detected: PR #102 (change #2) is merged, but change #1 is not; recording it as merged anyway", result.Warnings[0])
+
+		// The merged change is recorded even though the one below it is still open
+		reloadedStack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		require.Len(t, reloadedStack.MergedChanges, 1)
+		assert.Equal(t, 102, reloadedStack.MergedChanges[0].PR.PRNumber)
+		assert.False(t, reloadedStack.LastSynced.IsZero())
+	})
+
+	t.Run("RefreshStackMetadata", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		refreshed, err := stackClient.RefreshStackMetadata(stackCtx, RefreshOptions{AllowOutOfOrderMerges: true})
+		require.NoError(t, err)
+		assert.Equal(t, "merged", refreshed.FindChange("2222222222222222").PR.State)
+	})
+}
+
+func TestSyncPRMetadata_RepoMoved(t *testing.T) {
+	// setup creates a stack with one PR, cached under old-owner/old-repo
+	setup := func(t *testing.T) (*Client, *gh.MockGithubClient, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("old-owner", "old-repo", nil).Once()
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		uuid := "5555555555555555"
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "First change", "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuid: {PRNumber: 101, URL: "https://github.com/old-owner/old-repo/pull/101", State: "open"},
+			},
+		}))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Equal(t, "old-owner", stackCtx.Stack.Owner)
+		return stackClient, mockGithubClient, stackCtx
+	}
+
+	notFound := errors.New("failed to execute GraphQL query: gh CLI error: GraphQL: Could not resolve to a Repository with the name 'old-owner/old-repo'. (repository)")
+
+	t.Run("RetriesWithNewCoordinates", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := setup(t)
+
+		mockGithubClient.On("BatchGetPRs", "old-owner", "old-repo", []int{101}).Return(nil, notFound).Once()
+		mockGithubClient.On("GetRepoInfo").Return("new-owner", "new-repo", nil).Once()
+		mockGithubClient.On("BatchGetPRs", "new-owner", "new-repo", []int{101}).Return(&gh.BatchPRsResult{
+			PRStates: map[int]*gh.PRState{
+				101: {Number: 101, State: "MERGED", IsMerged: true},
+			},
+		}, nil).Once()
+
+		_, err := stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+		require.NoError(t, err)
+		assert.True(t, stackCtx.AllChanges[0].PR.IsMerged())
+
+		// The new coordinates are persisted so the next sync uses them directly
+		reloaded, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, "new-owner", reloaded.Owner)
+		assert.Equal(t, "new-repo", reloaded.RepoName)
+
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("StillNotFound", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := setup(t)
+
+		// GitHub reports the same coordinates, so there's nothing to retry with
+		mockGithubClient.On("BatchGetPRs", "old-owner", "old-repo", []int{101}).Return(nil, notFound).Once()
+		mockGithubClient.On("GetRepoInfo").Return("old-owner", "old-repo", nil).Once()
+
+		_, err := stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "repository old-owner/old-repo not found on GitHub")
+
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("OtherErrorsAreNotRetried", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := setup(t)
+
+		mockGithubClient.On("BatchGetPRs", "old-owner", "old-repo", []int{101}).Return(nil, errors.New("gh CLI error: HTTP 401")).Once()
+
+		_, err := stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to batch query PRs")
+
+		mockGithubClient.AssertExpectations(t)
+	})
+}
+
 func TestMaybeRefreshStackMetadata(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -2328,3 +3368,2245 @@ func TestMaybeRefreshStackMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileChangeHashes(t *testing.T) {
+	tests := []struct {
+		name          string
+		prBranchAt    func(pushed, rebased string) string // Where the PR branch points, or "" for no local PR branch
+		expectedCount int
+		expectPRHash  func(pushed, rebased string) string
+	}{
+		{
+			name:          "PR branch rebased outside the tool",
+			prBranchAt:    func(pushed, rebased string) string { return rebased },
+			expectedCount: 1,
+			expectPRHash:  func(pushed, rebased string) string { return rebased },
+		},
+		{
+			name:          "PR branch not moved - needs push",
+			prBranchAt:    func(pushed, rebased string) string { return pushed },
+			expectedCount: 0,
+			expectPRHash:  func(pushed, rebased string) string { return pushed },
+		},
+		{
+			name:          "no local PR branch",
+			prBranchAt:    func(pushed, rebased string) string { return "" },
+			expectedCount: 0,
+			expectPRHash:  func(pushed, rebased string) string { return pushed },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGithubClient := &gh.MockGithubClient{}
+			mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+			stackClient := NewTestStack(t, mockGithubClient)
+			gitClient := stackClient.git.(*git.Client)
+
+			_, err := stackClient.CreateStack("test-stack", "main")
+			require.NoError(t, err)
+
+			uuid := "abcd111111111111"
+			trailers := map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			}
+			pushed := testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", trailers)
+			prBranch := "test-user/stack-test-stack/" + uuid
+			require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+				Version: 1,
+				PRs: map[string]*model.PR{
+					uuid: {
+						PRNumber:   101,
+						Branch:     prBranch,
+						CommitHash: pushed,
+						State:      "open",
+					},
+				},
+			}))
+
+			// Rewrite the change on TOP outside the tool
+			require.NoError(t, gitClient.ResetHard("HEAD~1"))
+			rebased := testutil.CreateCommitWithTrailers(t, gitClient, "First change", "Rebased", trailers)
+			if at := tt.prBranchAt(pushed, rebased); at != "" {
+				require.NoError(t, gitClient.CreateBranchAt(prBranch, at))
+			}
+
+			stackCtx, err := stackClient.GetStackContextByName("test-stack")
+			require.NoError(t, err)
+			require.Len(t, stackCtx.ActiveChanges, 1)
+
+			count, err := stackClient.ReconcileChangeHashes(stackCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+			assert.Equal(t, rebased, stackCtx.ActiveChanges[0].CommitHash)
+
+			prData, err := stackClient.LoadPRs("test-stack")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectPRHash(pushed, rebased), prData.PRs[uuid].CommitHash)
+		})
+	}
+}
+
+func TestFindChangeByRef(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	for i, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		ref          string
+		expectedUUID string
+		expectError  error
+	}{
+		{name: "HEAD", ref: "HEAD", expectedUUID: uuids[2]},
+		{name: "HEAD~1", ref: "HEAD~1", expectedUUID: uuids[1]},
+		{name: "HEAD~2", ref: "HEAD~2", expectedUUID: uuids[0]},
+		{name: "branch", ref: "test-user/stack-test-stack/TOP", expectedUUID: uuids[2]},
+		{name: "outside stack", ref: "main", expectError: fmt.Errorf("does not point to a change in stack 'test-stack'")},
+		{name: "unknown ref", ref: "does-not-exist", expectError: fmt.Errorf("failed to resolve does-not-exist")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := stackClient.FindChangeByRef(stackCtx, tt.ref)
+			if tt.expectError != nil {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectError.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedUUID, change.UUID)
+		})
+	}
+}
+
+func TestValidateStackName(t *testing.T) {
+	tests := []struct {
+		name        string
+		stackName   string
+		expectedErr string
+	}{
+		{name: "Simple", stackName: "auth-rework_2.0"},
+		{name: "MaxLength", stackName: strings.Repeat("a", maxStackNameLength)},
+		{name: "Empty", stackName: "", expectedErr: "can't be empty"},
+		{name: "TooLong", stackName: strings.Repeat("a", maxStackNameLength+1), expectedErr: "at most 100 characters (got 101)"},
+		{name: "InvalidCharacters", stackName: "auth/rework", expectedErr: "only letters, numbers"},
+		{name: "ReservedArchive", stackName: ".archived", expectedErr: "is reserved"},
+		{name: "ReservedLock", stackName: ".lock", expectedErr: "is reserved"},
+		{name: "ReservedHooks", stackName: "hooks", expectedErr: "is reserved"},
+		{name: "ReservedConfig", stackName: "config.json", expectedErr: "is reserved"},
+		{name: "LeadingDot", stackName: ".hidden", expectedErr: "can't start or end with a dot"},
+		{name: "TrailingDot", stackName: "auth.", expectedErr: "can't start or end with a dot"},
+		{name: "ParentDir", stackName: "..", expectedErr: "can't start or end with a dot"},
+		{name: "DoubleDot", stackName: "auth..rework", expectedErr: "can't contain '..'"},
+		{name: "LockSuffix", stackName: "auth.lock", expectedErr: "end with '.lock'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStackName(tt.stackName)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestCreateStack_NameValidation(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack(".archived", "main")
+	assert.ErrorContains(t, err, "invalid stack name '.archived': the name is reserved")
+	assert.False(t, stackClient.StackExists(".archived"))
+
+	// The longest allowed name still makes valid branch names
+	longName := strings.Repeat("a", maxStackNameLength)
+	stack, err := stackClient.CreateStack(longName, "main")
+	require.NoError(t, err)
+	assert.True(t, stackClient.git.BranchExists(stack.Branch))
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Change 1", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": longName,
+	})
+	stackCtx, err := stackClient.GetStackContextByName(longName)
+	require.NoError(t, err)
+	uuidBranch := stackCtx.FormatUUIDBranch("1111111111111111")
+	require.NoError(t, stackClient.git.CreateBranchAt(uuidBranch, stackCtx.ActiveChanges[0].CommitHash))
+	assert.True(t, stackClient.git.BranchExists(uuidBranch))
+}
+
+func TestCreateStack_RevisionBase(t *testing.T) {
+	// setup makes two commits on main and tags the first one v1.0
+	setup := func(t *testing.T) (*Client, *git.Client, string, string) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		tagged := testutil.CreateCommitWithTrailers(t, gitClient, "Release 1.0", "", nil)
+		cmd := exec.Command("git", "tag", "v1.0", tagged)
+		cmd.Dir = gitClient.GitRoot()
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git tag failed: %s", string(output))
+		head := testutil.CreateCommitWithTrailers(t, gitClient, "After 1.0", "", nil)
+		return stackClient, gitClient, tagged, head
+	}
+
+	assertStartsAt := func(t *testing.T, stackClient *Client, name string, base string, baseRef string) {
+		stack, err := stackClient.LoadStack(name)
+		require.NoError(t, err)
+		assert.Equal(t, base, stack.Base)
+		assert.Equal(t, baseRef, stack.BaseRef)
+
+		top, err := stackClient.git.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, baseRef, top, "the TOP branch should start at the base")
+	}
+
+	t.Run("Tag", func(t *testing.T) {
+		stackClient, _, tagged, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "v1.0")
+		require.NoError(t, err)
+		assertStartsAt(t, stackClient, "test-stack", "v1.0", tagged)
+
+		// A tag has no upstream to be behind
+		behind, _, err := stackClient.IsBaseBehindUpstream("test-stack")
+		require.NoError(t, err)
+		assert.False(t, behind)
+	})
+
+	t.Run("CommitHash", func(t *testing.T) {
+		stackClient, _, tagged, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", tagged)
+		require.NoError(t, err)
+		assertStartsAt(t, stackClient, "test-stack", git.ShortHash(tagged), tagged)
+	})
+
+	t.Run("RelativeRevision", func(t *testing.T) {
+		stackClient, _, tagged, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "main~1")
+		require.NoError(t, err)
+		assertStartsAt(t, stackClient, "test-stack", git.ShortHash(tagged), tagged)
+	})
+
+	t.Run("HeadOnBranch", func(t *testing.T) {
+		stackClient, _, _, head := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "HEAD")
+		require.NoError(t, err)
+		assertStartsAt(t, stackClient, "test-stack", "main", head)
+	})
+
+	t.Run("Unresolvable", func(t *testing.T) {
+		stackClient, _, _, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "does-not-exist")
+		assert.ErrorContains(t, err, "cannot resolve 'does-not-exist'")
+		assert.False(t, stackClient.StackExists("test-stack"))
+	})
+
+	t.Run("AmbiguousRef", func(t *testing.T) {
+		stackClient, gitClient, tagged, _ := setup(t)
+
+		// A branch with the same name as the tag
+		require.NoError(t, gitClient.CreateBranchAt("v1.0", tagged))
+
+		_, err := stackClient.CreateStack("test-stack", "v1.0")
+		assert.ErrorContains(t, err, "ambiguous")
+		assert.False(t, stackClient.StackExists("test-stack"))
+	})
+
+	t.Run("RestackWithoutLocalBranch", func(t *testing.T) {
+		stackClient, _, _, _ := setup(t)
+
+		_, err := stackClient.CreateStack("test-stack", "v1.0")
+		require.NoError(t, err)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		// Restacking in place onto the tag works without a local branch to update
+		require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "v1.0"}))
+
+		require.NoError(t, stackClient.Restack(stackCtx, RestackOptions{Onto: "main"}))
+		mainHash, err := stackClient.git.GetCommitHash("main")
+		require.NoError(t, err)
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, "main", stack.Base)
+		assert.Equal(t, mainHash, stack.BaseRef)
+	})
+}
+
+func TestCreateStackFromTemplate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		templatesDir := filepath.Join(stackClient.getStacksRootDir(), "templates")
+		require.NoError(t, os.MkdirAll(templatesDir, 0755))
+		template := `{"changes": [{"title": "Add feature"}, {"title": "Add tests", "description": "Unit tests"}, {"title": "Add docs"}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "feature.json"), []byte(template), 0644))
+
+		err := stackClient.CreateStackFromTemplate("test-stack", "main", "feature")
+		require.NoError(t, err)
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		require.Len(t, stackCtx.ActiveChanges, 3)
+		assert.Equal(t, "Add feature", stackCtx.ActiveChanges[0].Title)
+		assert.Equal(t, "Add tests", stackCtx.ActiveChanges[1].Title)
+		assert.Equal(t, "Unit tests", stackCtx.ActiveChanges[1].Description)
+		assert.Equal(t, "Add docs", stackCtx.ActiveChanges[2].Title)
+
+		seen := make(map[string]bool)
+		for _, change := range stackCtx.ActiveChanges {
+			assert.True(t, validUUID(change.UUID), "placeholder should have a valid PR-UUID")
+			assert.False(t, seen[change.UUID], "placeholder UUIDs should be unique")
+			seen[change.UUID] = true
+		}
+	})
+
+	t.Run("Error_TemplateNotFound", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		err := stackClient.CreateStackFromTemplate("test-stack", "main", "missing")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "template 'missing' not found")
+		assert.False(t, stackClient.StackExists("test-stack"), "stack should not be created when the template is missing")
+	})
+}
+
+func TestBaseMovedHint(t *testing.T) {
+	t.Run("BaseUnchanged", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		hint, err := stackClient.BaseMovedHint(stack)
+		require.NoError(t, err)
+		assert.Empty(t, hint)
+	})
+
+	t.Run("BaseAdvanced", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		// Advance main beyond the recorded BaseRef
+		require.NoError(t, stackClient.git.CheckoutBranch("main"))
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Upstream change", "", map[string]string{})
+
+		hint, err := stackClient.BaseMovedHint(stack)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("local main has moved since last restack - positions are computed against %s (run 'stack restack' to update)", git.ShortHash(stack.BaseRef)), hint)
+	})
+}
+
+func TestIsBaseBehindUpstream(t *testing.T) {
+	// setup creates a stack on main, optionally with main tracking a bare origin
+	setup := func(t *testing.T, withUpstream bool) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		if withUpstream {
+			testutil.AddBareRemote(t, gitClient)
+			cmd := exec.Command("git", "push", "-u", "origin", "main")
+			cmd.Dir = gitClient.GitRoot()
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, "git push failed: %s", string(output))
+		}
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		return stackClient, gitClient
+	}
+
+	t.Run("UpToDate", func(t *testing.T) {
+		stackClient, _ := setup(t, true)
+
+		behind, count, err := stackClient.IsBaseBehindUpstream("test-stack")
+		require.NoError(t, err)
+		assert.False(t, behind)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Behind", func(t *testing.T) {
+		stackClient, gitClient := setup(t, true)
+
+		// Someone else lands two commits on main; the stack is still based on the old main
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Upstream change 1", "", nil)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Upstream change 2", "", nil)
+		require.NoError(t, gitClient.Push("main", false))
+
+		behind, count, err := stackClient.IsBaseBehindUpstream("test-stack")
+		require.NoError(t, err)
+		assert.True(t, behind)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("NoUpstream", func(t *testing.T) {
+		stackClient, _ := setup(t, false)
+
+		behind, count, err := stackClient.IsBaseBehindUpstream("test-stack")
+		require.NoError(t, err)
+		assert.False(t, behind)
+		assert.Zero(t, count)
+	})
+}
+
+func TestValidatePRNumbersUnique(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+	hash1 := testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "First change", "", map[string]string{
+		"PR-UUID":  uuid1,
+		"PR-Stack": "test-stack",
+	})
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Second change", "", map[string]string{
+		"PR-UUID":  uuid2,
+		"PR-Stack": "test-stack",
+	})
+
+	// Both changes claim PR #101, but only the first change's commit is the PR's head
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 101, CommitHash: hash1, State: "open"},
+			uuid2: {PRNumber: 101, CommitHash: hash1, State: "open"},
+		},
+	}))
+
+	// Loading the stack rejects the ambiguous PR numbers
+	_, err = stackClient.GetStackContextByName("test-stack")
+	var dupErr *DuplicatePRError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, map[int][]string{101: {uuid1, uuid2}}, dupErr.Duplicates)
+	assert.ErrorContains(t, err, "PR #101 is referenced by changes 1111111111111111, 2222222222222222")
+	_, err = stackClient.LoadPRs("test-stack")
+	require.ErrorAs(t, err, &dupErr)
+
+	// Doctor loads it leniently to report and fix the duplicates
+	stackCtx, err := stackClient.GetStackContextForRepair("test-stack")
+	require.NoError(t, err)
+	require.ErrorAs(t, stackClient.ValidatePRNumbersUnique(stackCtx), &dupErr)
+
+	cleared, err := stackClient.FixDuplicatePRNumbers(stackCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cleared)
+	assert.NoError(t, stackClient.ValidatePRNumbersUnique(stackCtx))
+	_, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	prData, err := stackClient.LoadPRs("test-stack")
+	require.NoError(t, err)
+	assert.Contains(t, prData.PRs, uuid1, "change matching the PR head keeps the PR")
+	assert.NotContains(t, prData.PRs, uuid2, "wrong change has its PR cleared")
+}
+
+// remoteDeleteRecorder is a GitClient that records DeleteRemoteBranch calls instead of
+// deleting anything
+type remoteDeleteRecorder struct {
+	GitClient
+	deletedRemote []string
+}
+
+func (r *remoteDeleteRecorder) DeleteRemoteBranch(branchName string) error {
+	r.deletedRemote = append(r.deletedRemote, branchName)
+	return nil
+}
+
+func TestDeleteStack_KeepRemote(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	gitClient := testutil.NewTestGitClient(t)
+	testutil.AddBareRemote(t, gitClient)
+	recorder := &remoteDeleteRecorder{GitClient: gitClient}
+	stackClient, err := NewClient(recorder, mockGithubClient)
+	require.NoError(t, err)
+	stackClient.username = "test-user"
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+	hash := testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "test-stack",
+	})
+	branch := "test-user/stack-test-stack/1111111111111111"
+	require.NoError(t, gitClient.CreateBranchAt(branch, hash))
+	require.NoError(t, gitClient.Push(branch, false))
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: map[string]*model.PR{
+		"1111111111111111": {PRNumber: 101, State: "open"},
+	}}))
+
+	plan, err := stackClient.DeleteStack("test-stack", DeleteOptions{DryRun: true, KeepRemote: true})
+	require.NoError(t, err)
+	assert.Empty(t, plan.RemoteBranches)
+
+	// Open PRs don't need force, since they keep their branches
+	orphaned, err := stackClient.DeleteStackSafe("test-stack", false, DeleteOptions{KeepRemote: true})
+	require.NoError(t, err)
+	assert.Empty(t, orphaned)
+	assert.Empty(t, recorder.deletedRemote, "remote branches should not be deleted")
+
+	// Local branches are deleted and the metadata archived
+	assert.False(t, gitClient.BranchExists(branch))
+	assert.False(t, gitClient.BranchExists(stack.Branch))
+	assert.False(t, stackClient.StackExists("test-stack"))
+	remoteHeads, err := gitClient.LsRemoteHeads("origin", branch)
+	require.NoError(t, err)
+	assert.Contains(t, remoteHeads, branch)
+}
+
+func TestDeleteStackSafe(t *testing.T) {
+	setup := func(t *testing.T) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		// Two pushed PRs (one open, one draft) and one local change
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[0]: {PRNumber: 101, State: "open"},
+				uuids[1]: {PRNumber: 102, State: "draft"},
+			},
+		}))
+
+		require.NoError(t, stackClient.git.CheckoutBranch("main"))
+		return stackClient
+	}
+
+	t.Run("RefuseWithoutForce", func(t *testing.T) {
+		stackClient := setup(t)
+
+		orphaned, err := stackClient.DeleteStackSafe("test-stack", false, DeleteOptions{})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "stack 'test-stack' has 2 open PR(s) (#101, #102)")
+		assert.Nil(t, orphaned)
+		assert.True(t, stackClient.StackExists("test-stack"), "stack should not be deleted")
+	})
+
+	t.Run("ForceWithWarning", func(t *testing.T) {
+		stackClient := setup(t)
+
+		orphaned, err := stackClient.DeleteStackSafe("test-stack", true, DeleteOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []int{101, 102}, orphaned, "open PRs should be reported as orphaned")
+		assert.False(t, stackClient.StackExists("test-stack"), "stack should be deleted")
+	})
+}
+
+func TestVerifyDesiredBaseChain(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	for i, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+
+	// Change 3's PR was pushed against change 1 before change 2 was inserted
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuids[2]: {PRNumber: 103, State: "open", Base: "test-user/stack-test-stack/" + uuids[0]},
+		},
+	}))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	// Corrupt change 2's DesiredBase
+	stackCtx.ActiveChanges[1].DesiredBase = "main"
+
+	err = stackClient.VerifyDesiredBaseChain(stackCtx)
+	require.Error(t, err)
+	var chainErr *BaseChainError
+	require.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, []BaseMismatch{
+		{UUID: uuids[1], Position: 2, Got: "main", Want: "test-user/stack-test-stack/" + uuids[0]},
+		{UUID: uuids[2], Position: 3, Got: "test-user/stack-test-stack/" + uuids[0], Want: "test-user/stack-test-stack/" + uuids[1]},
+	}, chainErr.Mismatches)
+
+	mockGithubClient.On("UpdatePRBase", 103, "test-user/stack-test-stack/"+uuids[1]).Return(nil).Once()
+
+	fixed, err := stackClient.FixDesiredBaseChain(stackCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fixed)
+	assert.NoError(t, stackClient.VerifyDesiredBaseChain(stackCtx))
+
+	prData, err := stackClient.LoadPRs("test-stack")
+	require.NoError(t, err)
+	assert.Equal(t, "test-user/stack-test-stack/"+uuids[1], prData.PRs[uuids[2]].Base)
+
+	mockGithubClient.AssertExpectations(t)
+}
+
+func TestReorderChange(t *testing.T) {
+	setup := func(t *testing.T, uuids []string) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		return stackClient
+	}
+
+	t.Run("SwapTwoActiveChanges", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222"}
+		stackClient := setup(t, uuids)
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		// UUID branch for the first change should follow it to its new commit
+		uuidBranch := stackCtx.FormatUUIDBranch(uuids[0])
+		require.NoError(t, stackClient.git.CreateBranchAt(uuidBranch, stackCtx.ActiveChanges[0].CommitHash))
+
+		err = stackClient.ReorderChange(stackCtx, uuids[1], 1)
+		require.NoError(t, err)
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 2)
+		assert.Equal(t, uuids[1], stackCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, uuids[0], stackCtx.ActiveChanges[1].UUID)
+		assert.Equal(t, "main", stackCtx.ActiveChanges[0].DesiredBase)
+		assert.Equal(t, stackCtx.FormatUUIDBranch(uuids[1]), stackCtx.ActiveChanges[1].DesiredBase)
+
+		branchHash, err := stackClient.git.GetCommitHash(uuidBranch)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[1].CommitHash, branchHash)
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+		assert.False(t, stackClient.HasRebaseState("test-stack"))
+	})
+
+	t.Run("RejectMoveBelowMergedChange", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+		stackClient := setup(t, uuids)
+
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[1]: {PRNumber: 102, State: "merged"},
+			},
+		}))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalHead, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.ReorderChange(stackCtx, uuids[2], 1)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "cannot move change across merged change #102")
+
+		head, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head, "TOP branch should be untouched")
+	})
+
+	t.Run("KeepsCustomTrailers", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222"}
+		stackClient := setup(t, nil)
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":   uuid,
+				"PR-Stack":  "test-stack",
+				"Change-Id": fmt.Sprintf("I%d", i+1),
+			})
+		}
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.ReorderChange(stackCtx, uuids[1], 1))
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 2)
+		assert.Equal(t, map[string]string{"Change-Id": "I2"}, stackCtx.ActiveChanges[0].Trailers)
+		assert.Equal(t, map[string]string{"Change-Id": "I1"}, stackCtx.ActiveChanges[1].Trailers)
+	})
+}
+
+func TestSquashChanges(t *testing.T) {
+	setup := func(t *testing.T, uuids []string) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		for i, uuid := range uuids {
+			createCommit := testutil.CreateCommitWithTrailers
+			if i == 0 {
+				// The bottom change is a teammate's, whose authorship squashing into it must keep
+				createCommit = createCommitByOtherAuthor
+			}
+			_ = createCommit(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), fmt.Sprintf("Body %d", i+1), map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		return stackClient
+	}
+
+	t.Run("SquashSecondIntoFirst", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+		stackClient := setup(t, uuids)
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch(uuids[0]), stackCtx.ActiveChanges[0].CommitHash))
+		require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch(uuids[1]), stackCtx.ActiveChanges[1].CommitHash))
+		originalTree, err := stackClient.git.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.SquashChanges(stackCtx, uuids[1])
+		require.NoError(t, err)
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 2)
+		assert.Equal(t, uuids[0], stackCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, uuids[2], stackCtx.ActiveChanges[1].UUID)
+
+		combined, err := stackClient.git.GetCommit(stackCtx.ActiveChanges[0].CommitHash)
+		require.NoError(t, err)
+		assert.Equal(t, "Change 1", combined.Message.Title)
+		assert.Equal(t, "Body 1\n\nChange 2\n\nBody 2", combined.Message.Body)
+		assert.Equal(t, uuids[0], combined.Message.Trailers["PR-UUID"])
+		assertOtherAuthor(t, stackClient.git.(*git.Client), combined.Hash)
+
+		// The combined stack has the same content as before
+		tree, err := stackClient.git.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalTree, tree)
+
+		branchHash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch(uuids[0]))
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[0].CommitHash, branchHash)
+		assert.False(t, stackClient.git.BranchExists(stackCtx.FormatUUIDBranch(uuids[1])))
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+	})
+
+	t.Run("RejectBottomChange", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222"}
+		stackClient := setup(t, uuids)
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalHead, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.SquashChanges(stackCtx, uuids[0])
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "it is the bottom change of the stack")
+
+		head, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head, "TOP branch should be untouched")
+	})
+
+	t.Run("KeepsCustomTrailers", func(t *testing.T) {
+		uuids := []string{"1111111111111111", "2222222222222222"}
+		stackClient := setup(t, nil)
+		require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{PropagateTrailers: []string{"Change-Id", "Reviewed-on"}}))
+		gitClient := stackClient.git.(*git.Client)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+			"PR-UUID":       uuids[0],
+			"PR-Stack":      "test-stack",
+			"Change-Id":     "I1",
+			"Signed-off-by": "Jane Doe <jane@example.com>",
+		})
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 2", "", map[string]string{
+			"PR-UUID":     uuids[1],
+			"PR-Stack":    "test-stack",
+			"Change-Id":   "I2",
+			"Reviewed-on": "https://review.example.com/2",
+			"Tested-by":   "CI",
+		})
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.SquashChanges(stackCtx, uuids[1]))
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 1)
+		// The lower change keeps its own trailers and picks up the upper change's propagated
+		// trailers it doesn't have; trailers that aren't propagated stay with the squashed commit
+		assert.Equal(t, map[string]string{
+			"Change-Id":     "I1",
+			"Signed-off-by": "Jane Doe <jane@example.com>",
+			"Reviewed-on":   "https://review.example.com/2",
+		}, stackCtx.ActiveChanges[0].Trailers)
+		assert.Equal(t, uuids[0], stackCtx.ActiveChanges[0].UUID)
+	})
+}
+
+func TestSplitChange(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+
+	// setup creates a stack whose middle change touches a.txt and b.txt
+	setup := func(t *testing.T) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		for i, uuid := range uuids {
+			if i == 1 {
+				testutil.WriteFile(t, gitClient.GitRoot(), "a.txt", "first\n")
+				testutil.WriteFile(t, gitClient.GitRoot(), "b.txt", "second\n")
+			}
+			createCommit := testutil.CreateCommitWithTrailers
+			if i == 1 {
+				// A teammate's change, whose authorship both halves of the split must keep
+				createCommit = createCommitByOtherAuthor
+			}
+			_ = createCommit(t, gitClient, fmt.Sprintf("Change %d", i+1), fmt.Sprintf("Body %d", i+1), map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch(uuids[1]), stackCtx.ActiveChanges[1].CommitHash))
+		return stackClient
+	}
+
+	// fileAt returns the contents of path at ref, or "" if it doesn't exist
+	fileAt := func(t *testing.T, stackClient *Client, ref string, path string) string {
+		cmd := exec.Command("git", "show", ref+":"+path)
+		cmd.Dir = stackClient.git.GitRoot()
+		output, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return string(output)
+	}
+
+	// assertSplit checks the middle change was split into a.txt then b.txt with the right trailers
+	assertSplit := func(t *testing.T, stackClient *Client, originalTree string) *StackContext {
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 4)
+
+		first := stackCtx.ActiveChanges[1]
+		second := stackCtx.ActiveChanges[2]
+		assert.Equal(t, uuids[0], stackCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, uuids[1], first.UUID)
+		assert.NotEmpty(t, second.UUID)
+		assert.NotContains(t, uuids, second.UUID)
+		assert.Equal(t, uuids[2], stackCtx.ActiveChanges[3].UUID)
+
+		for _, change := range []*model.Change{first, second} {
+			commit, err := stackClient.git.GetCommit(change.CommitHash)
+			require.NoError(t, err)
+			assert.Equal(t, change.UUID, commit.Message.Trailers["PR-UUID"])
+			assert.Equal(t, "test-stack", commit.Message.Trailers["PR-Stack"])
+			assertOtherAuthor(t, stackClient.git.(*git.Client), change.CommitHash)
+		}
+
+		assert.Equal(t, "first\n", fileAt(t, stackClient, first.CommitHash, "a.txt"))
+		assert.Equal(t, "", fileAt(t, stackClient, first.CommitHash, "b.txt"))
+		assert.Equal(t, "first\n", fileAt(t, stackClient, second.CommitHash, "a.txt"))
+		assert.Equal(t, "second\n", fileAt(t, stackClient, second.CommitHash, "b.txt"))
+
+		tree, err := stackClient.git.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalTree, tree, "the stack should have the same content after the split")
+
+		branchHash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch(uuids[1]))
+		require.NoError(t, err)
+		assert.Equal(t, first.CommitHash, branchHash)
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+		return stackCtx
+	}
+
+	t.Run("ByPaths", func(t *testing.T) {
+		stackClient := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalTree, err := stackClient.git.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.SplitChangeByPaths(stackCtx, uuids[1], []string{"a.txt"}, [2]string{"Add a", "Add b"})
+		require.NoError(t, err)
+
+		stackCtx = assertSplit(t, stackClient, originalTree)
+		assert.Equal(t, "Add a", stackCtx.ActiveChanges[1].Title)
+		assert.Equal(t, "Body 2", stackCtx.ActiveChanges[1].Description)
+		assert.Equal(t, "Add b", stackCtx.ActiveChanges[2].Title)
+		assert.NotEmpty(t, fileAt(t, stackClient, stackCtx.ActiveChanges[2].CommitHash, "file-Change 2.txt"))
+	})
+
+	t.Run("ByPathsRejectsEmptySide", func(t *testing.T) {
+		stackClient := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalHead, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.SplitChangeByPaths(stackCtx, uuids[1], []string{"missing.txt"}, [2]string{"A", "B"})
+		assert.ErrorContains(t, err, "doesn't modify any of the given paths")
+
+		err = stackClient.SplitChangeByPaths(stackCtx, uuids[1], []string{"."}, [2]string{"A", "B"})
+		assert.ErrorContains(t, err, "nothing is left for the second change")
+
+		head, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head, "TOP branch should be untouched")
+	})
+
+	t.Run("Interactive", func(t *testing.T) {
+		stackClient := setup(t)
+		gitRoot := stackClient.git.GitRoot()
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalTree, err := stackClient.git.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		require.NoError(t, stackClient.SplitChange(stackCtx, uuids[1]))
+
+		state, err := stackClient.LoadSplitState()
+		require.NoError(t, err)
+		require.NotNil(t, state)
+		assert.Equal(t, uuids[1], state.UUID)
+		assert.True(t, stackClient.SplitInProgress("test-stack"))
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.FormatUUIDBranch(uuids[1]), currentBranch, "the split should happen on the change's branch")
+
+		hasChanges, err := stackClient.git.HasUncommittedChanges()
+		require.NoError(t, err)
+		assert.True(t, hasChanges, "the change should be staged")
+
+		commit := func(message string, paths ...string) {
+			args := append([]string{"commit", "-m", message, "--"}, paths...)
+			cmd := exec.Command("git", args...)
+			cmd.Dir = gitRoot
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, "git commit failed: %s", string(output))
+		}
+		commit("Add a", "a.txt")
+		commit("Add b and the rest")
+
+		require.NoError(t, stackClient.ContinueSplit())
+
+		stackCtx = assertSplit(t, stackClient, originalTree)
+		assert.Equal(t, "Add a", stackCtx.ActiveChanges[1].Title)
+		assert.Equal(t, "Add b and the rest", stackCtx.ActiveChanges[2].Title)
+
+		state, err = stackClient.LoadSplitState()
+		require.NoError(t, err)
+		assert.Nil(t, state)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		stackClient := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalHead, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		originalCommit := stackCtx.FindChange(uuids[1]).CommitHash
+
+		require.NoError(t, stackClient.SplitChange(stackCtx, uuids[1]))
+		require.NoError(t, stackClient.AbortSplit())
+
+		branchHash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch(uuids[1]))
+		require.NoError(t, err)
+		assert.Equal(t, originalCommit, branchHash, "the change's branch should be restored")
+		assert.False(t, stackClient.SplitInProgress("test-stack"))
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+		head, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head)
+
+		hasChanges, err := stackClient.git.HasUncommittedChanges()
+		require.NoError(t, err)
+		assert.False(t, hasChanges)
+
+		state, err := stackClient.LoadSplitState()
+		require.NoError(t, err)
+		assert.Nil(t, state)
+	})
+}
+
+func TestGetStackContext_DuplicateUUIDs(t *testing.T) {
+	setup := func(t *testing.T) (*Client, []string) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		// The third commit is a bad cherry-pick of the first and carries the same PR-UUID
+		hashes := make([]string, 0, 3)
+		for i, uuid := range []string{"1111111111111111", "2222222222222222", "1111111111111111"} {
+			hashes = append(hashes, testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			}))
+		}
+		return stackClient, hashes
+	}
+
+	t.Run("ActiveDuplicateIsAnError", func(t *testing.T) {
+		stackClient, hashes := setup(t)
+
+		_, err := stackClient.GetStackContextByName("test-stack")
+		require.Error(t, err)
+
+		var dupErr *DuplicateUUIDError
+		require.ErrorAs(t, err, &dupErr)
+		assert.Equal(t, map[string][]string{"1111111111111111": {hashes[0], hashes[2]}}, dupErr.Duplicates)
+		assert.ErrorContains(t, err, git.ShortHash(hashes[0]))
+		assert.ErrorContains(t, err, git.ShortHash(hashes[2]))
+		assert.ErrorContains(t, err, "stack repair")
+	})
+
+	t.Run("RepairLoadToleratesDuplicate", func(t *testing.T) {
+		stackClient, hashes := setup(t)
+
+		stackCtx, err := stackClient.GetStackContextForRepair("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"1111111111111111": {hashes[0], hashes[2]}}, stackCtx.DuplicateUUIDs)
+		assert.Len(t, stackCtx.ActiveChanges, 3)
+	})
+
+	t.Run("MergedDuplicateIsTolerated", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {PRNumber: 1, State: "merged"},
+			},
+		}))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 1)
+		assert.Equal(t, "2222222222222222", stackCtx.ActiveChanges[0].UUID)
+	})
+}
+
+func TestDropChanges(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	for i, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	err = stackClient.DropChanges(stackCtx, []*model.Change{stackCtx.ActiveChanges[1]})
+	require.NoError(t, err)
+
+	stackCtx, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	require.Len(t, stackCtx.ActiveChanges, 2)
+	assert.Equal(t, uuids[0], stackCtx.ActiveChanges[0].UUID)
+	assert.Equal(t, uuids[2], stackCtx.ActiveChanges[1].UUID)
+	assert.False(t, stackClient.HasRebaseState("test-stack"))
+}
+
+func TestGetStackContextByName_CaseInsensitive(t *testing.T) {
+	setup := func(t *testing.T, names ...string) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		for _, name := range names {
+			_, err := stackClient.CreateStack(name, "main")
+			require.NoError(t, err)
+		}
+		return stackClient
+	}
+
+	t.Run("ExactMatch", func(t *testing.T) {
+		stackClient := setup(t, "auth", "Auth")
+
+		stackCtx, err := stackClient.GetStackContextByName("Auth")
+		require.NoError(t, err)
+		assert.Equal(t, "Auth", stackCtx.StackName)
+		assert.Equal(t, "Auth", stackCtx.Stack.Name)
+	})
+
+	t.Run("SingleCaseInsensitiveMatch", func(t *testing.T) {
+		stackClient := setup(t, "auth")
+
+		stackCtx, err := stackClient.GetStackContextByName("AUTH")
+		require.NoError(t, err)
+		assert.Equal(t, "auth", stackCtx.StackName)
+		assert.Equal(t, "test-user/stack-auth/TOP", stackCtx.Stack.Branch)
+		assert.True(t, stackCtx.IsStack())
+	})
+
+	t.Run("AmbiguousMatch", func(t *testing.T) {
+		stackClient := setup(t, "auth", "Auth")
+
+		_, err := stackClient.GetStackContextByName("AUTH")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "stack name 'AUTH' is ambiguous: matches Auth, auth")
+	})
+
+	t.Run("ExactDisablesFallback", func(t *testing.T) {
+		stackClient := setup(t, "auth")
+
+		_, err := stackClient.ResolveStackName("AUTH", true)
+		assert.ErrorIs(t, err, ErrStackNotFound)
+
+		name, err := stackClient.ResolveStackName("auth", true)
+		require.NoError(t, err)
+		assert.Equal(t, "auth", name)
+
+		// Other lookups keep the case-insensitive fallback
+		name, err = stackClient.ResolveStackName("AUTH", false)
+		require.NoError(t, err)
+		assert.Equal(t, "auth", name)
+	})
+}
+
+func TestPushChangeBranch(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+	testutil.AddBareRemote(t, gitClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid := "1111111111111111"
+	firstHash := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+		"PR-UUID":  uuid,
+		"PR-Stack": "test-stack",
+	})
+
+	prBranch := "test-user/stack-test-stack/" + uuid
+	require.NoError(t, gitClient.CreateBranchAt(prBranch, firstHash))
+
+	// First push has no lease to check against
+	change := &model.Change{UUID: uuid, CommitHash: firstHash}
+	require.NoError(t, stackClient.PushChangeBranch(change, prBranch))
+
+	// Record the pushed commit, as the push command does after syncing the PR
+	change.PR = &model.PR{PRNumber: 1, CommitHash: firstHash}
+
+	t.Run("LeaseHeld", func(t *testing.T) {
+		amendedHash := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "amended", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+		require.NoError(t, gitClient.UpdateRef(prBranch, amendedHash))
+
+		require.NoError(t, stackClient.PushChangeBranch(change, prBranch))
+		change.PR.CommitHash = amendedHash
+	})
+
+	t.Run("LeaseRejectedWhenRemoteMoved", func(t *testing.T) {
+		// A teammate pushes to the branch behind our back
+		teammateHash := testutil.CreateCommitWithTrailers(t, gitClient, "Teammate fix", "", nil)
+		cmd := exec.Command("git", "push", "--force", "origin", teammateHash+":refs/heads/"+prBranch)
+		cmd.Dir = gitClient.GitRoot()
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git push failed: %s", string(output))
+
+		localHash := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "local edit", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+		require.NoError(t, gitClient.UpdateRef(prBranch, localHash))
+
+		err = stackClient.PushChangeBranch(change, prBranch)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "remote branch "+prBranch+" has moved since it was last pushed")
+
+		remoteHeads, err := gitClient.LsRemoteHeads("origin", prBranch)
+		require.NoError(t, err)
+		assert.Equal(t, teammateHash, remoteHeads[prBranch], "teammate's commit should not be overwritten")
+	})
+
+	t.Run("RemoteBranchDeleted", func(t *testing.T) {
+		// e.g. GitHub deleted the head branch; the lease still expects the last pushed commit
+		require.NoError(t, gitClient.DeleteRemoteBranch(prBranch))
+
+		localHash, err := gitClient.GetCommitHash(prBranch)
+		require.NoError(t, err)
+		require.NoError(t, stackClient.PushChangeBranch(change, prBranch))
+
+		remoteHeads, err := gitClient.LsRemoteHeads("origin", prBranch)
+		require.NoError(t, err)
+		assert.Equal(t, localHash, remoteHeads[prBranch])
+	})
+}
+
+func TestPushChangeBranch_ToForkRemote(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+	testutil.AddBareRemote(t, gitClient)
+
+	forkDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare", "--initial-branch=main")
+	cmd.Dir = forkDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git init --bare failed: %s", string(output))
+	cmd = exec.Command("git", "remote", "add", "fork", forkDir)
+	cmd.Dir = gitClient.GitRoot()
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "git remote add failed: %s", string(output))
+
+	require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{PushRemote: "fork", HeadRepo: "contributor"}))
+	assert.Equal(t, "fork", stackClient.PushRemote())
+	assert.Equal(t, "contributor", stackClient.HeadRepo())
+	gitClient.SetPushRemote(stackClient.PushRemote())
+
+	_, err = stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid := "1111111111111111"
+	hash := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", map[string]string{
+		"PR-UUID":  uuid,
+		"PR-Stack": "test-stack",
+	})
+	prBranch := "test-user/stack-test-stack/" + uuid
+	require.NoError(t, gitClient.CreateBranchAt(prBranch, hash))
+
+	change := &model.Change{UUID: uuid, CommitHash: hash}
+	require.NoError(t, stackClient.PushChangeBranch(change, prBranch))
+
+	forkHeads, err := gitClient.LsRemoteHeads("fork", prBranch)
+	require.NoError(t, err)
+	assert.Equal(t, hash, forkHeads[prBranch])
+
+	originHeads, err := gitClient.LsRemoteHeads("origin", prBranch)
+	require.NoError(t, err)
+	assert.Empty(t, originHeads, "PR branch should not be pushed to the fetch remote")
+
+	// Later pushes lease against the fork's branch
+	change.PR = &model.PR{PRNumber: 1, CommitHash: hash}
+	amendedHash := testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "amended", map[string]string{
+		"PR-UUID":  uuid,
+		"PR-Stack": "test-stack",
+	})
+	require.NoError(t, gitClient.UpdateRef(prBranch, amendedHash))
+	require.NoError(t, stackClient.PushChangeBranch(change, prBranch))
+
+	forkHeads, err = gitClient.LsRemoteHeads("fork", prBranch)
+	require.NoError(t, err)
+	assert.Equal(t, amendedHash, forkHeads[prBranch])
+}
+
+func TestRenameStack(t *testing.T) {
+	setup := func(t *testing.T) (*Client, []string) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		_, err := stackClient.CreateStack("old-stack", "main")
+		require.NoError(t, err)
+
+		uuids := []string{"1111111111111111", "2222222222222222"}
+		for i, uuid := range uuids {
+			hash := testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "old-stack",
+			})
+			require.NoError(t, stackClient.git.CreateBranchAt("test-user/stack-old-stack/"+uuid, hash))
+		}
+
+		require.NoError(t, stackClient.savePRs("old-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[0]: {PRNumber: 101, State: "merged"},
+			},
+		}))
+		return stackClient, uuids
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		stackClient, uuids := setup(t)
+
+		require.NoError(t, stackClient.RenameStack("old-stack", "new-stack"))
+
+		assert.False(t, stackClient.StackExists("old-stack"))
+		assert.True(t, stackClient.StackExists("new-stack"))
+
+		branches, err := stackClient.GetStackBranches("old-stack")
+		require.NoError(t, err)
+		assert.Empty(t, branches)
+
+		branches, err = stackClient.GetStackBranches("new-stack")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"test-user/stack-new-stack/TOP",
+			"test-user/stack-new-stack/" + uuids[0],
+			"test-user/stack-new-stack/" + uuids[1],
+		}, branches)
+
+		currentBranch, err := stackClient.git.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "test-user/stack-new-stack/TOP", currentBranch)
+
+		// Commits still carry the old PR-Stack trailer and PR metadata is kept
+		stackCtx, err := stackClient.GetStackContext()
+		require.NoError(t, err)
+		assert.True(t, stackCtx.IsStack())
+		assert.Equal(t, "new-stack", stackCtx.StackName)
+		assert.Equal(t, "test-user/stack-new-stack/TOP", stackCtx.Stack.Branch)
+		assert.Equal(t, []string{"old-stack"}, stackCtx.Stack.PreviousNames)
+		require.Len(t, stackCtx.AllChanges, 2)
+		assert.Equal(t, 101, stackCtx.AllChanges[0].PR.PRNumber)
+		assert.Equal(t, uuids[1], stackCtx.AllChanges[1].UUID)
+	})
+
+	t.Run("RejectExistingName", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		_, err := stackClient.CreateStack("taken", "main")
+		require.NoError(t, err)
+
+		err = stackClient.RenameStack("old-stack", "taken")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "stack 'taken' already exists")
+		assert.True(t, stackClient.StackExists("old-stack"), "original stack should be untouched")
+		assert.True(t, stackClient.git.BranchExists("test-user/stack-old-stack/TOP"))
+	})
+
+	t.Run("FailedBranchRenameRollsBack", func(t *testing.T) {
+		stackClient, uuids := setup(t)
+
+		// A stale ref lock makes renaming the TOP branch, the last one, fail after the UUID
+		// branches were renamed
+		lockDir := filepath.Join(stackClient.git.GitRoot(), ".git", "refs", "heads", "test-user", "stack-new-stack")
+		require.NoError(t, os.MkdirAll(lockDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(lockDir, "TOP.lock"), nil, 0644))
+
+		require.Error(t, stackClient.RenameStack("old-stack", "new-stack"))
+
+		assert.False(t, stackClient.StackExists("new-stack"))
+		branches, err := stackClient.GetStackBranches("old-stack")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{
+			"test-user/stack-old-stack/TOP",
+			"test-user/stack-old-stack/" + uuids[0],
+			"test-user/stack-old-stack/" + uuids[1],
+		}, branches)
+		stackCtx, err := stackClient.GetStackContextByName("old-stack")
+		require.NoError(t, err)
+		assert.Len(t, stackCtx.AllChanges, 2)
+	})
+}
+
+func TestNavigateRelative(t *testing.T) {
+	merged := &model.Change{UUID: "0000000000000000", Title: "Merged", PR: &model.PR{PRNumber: 100, State: "merged"}}
+	change1 := &model.Change{UUID: "1111111111111111", Title: "First", ActivePosition: 1}
+	change2 := &model.Change{UUID: "2222222222222222", Title: "Second", ActivePosition: 2}
+	change3 := &model.Change{UUID: "3333333333333333", Title: "Third", ActivePosition: 3}
+
+	newStackCtx := func(currentUUID string) *StackContext {
+		return &StackContext{
+			changes: map[string]*model.Change{
+				merged.UUID:  merged,
+				change1.UUID: change1,
+				change2.UUID: change2,
+				change3.UUID: change3,
+			},
+			AllChanges:    []*model.Change{merged, change1, change2, change3},
+			ActiveChanges: []*model.Change{change1, change2, change3},
+			currentUUID:   currentUUID,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		currentUUID      string
+		delta            int
+		expectedTarget   *model.Change
+		expectedBoundary bool
+		expectError      error
+	}{
+		{name: "UpFromMiddle", currentUUID: change2.UUID, delta: 1, expectedTarget: change3},
+		{name: "DownFromMiddle", currentUUID: change2.UUID, delta: -1, expectedTarget: change1},
+		{name: "UpFromTop", currentUUID: change3.UUID, delta: 1, expectedTarget: change3, expectedBoundary: true},
+		{name: "DownFromBottom", currentUUID: change1.UUID, delta: -1, expectedTarget: change1, expectedBoundary: true},
+		{name: "UpPastTopClamps", currentUUID: change1.UUID, delta: 5, expectedTarget: change3, expectedBoundary: true},
+		{name: "UpFromSpecificChange", currentUUID: change1.UUID, delta: 2, expectedTarget: change3},
+		{name: "UpFromMergedChange", currentUUID: merged.UUID, delta: 1, expectedTarget: change1},
+		{name: "DownFromMergedChange", currentUUID: merged.UUID, delta: -1, expectError: fmt.Errorf("no active change below it: run 'stack refresh' first")},
+		{name: "UnknownCurrentChange", currentUUID: "9999999999999999", delta: 1, expectError: fmt.Errorf("current change is not a valid change in the stack")},
+	}
+
+	stackClient := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, atBoundary, err := stackClient.NavigateRelative(newStackCtx(tt.currentUUID), tt.delta)
+			if tt.expectError != nil {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectError.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTarget, target)
+			assert.Equal(t, tt.expectedBoundary, atBoundary)
+		})
+	}
+}
+
+func TestGetCleanupCandidates_ParallelMatchesSerial(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		_, err := stackClient.CreateStack("stack-empty", "main")
+		require.NoError(t, err)
+
+		const numStacks = 8
+		for i := 0; i < numStacks; i++ {
+			require.NoError(t, stackClient.git.CheckoutBranch("main"))
+
+			name := fmt.Sprintf("stack-%d", i)
+			_, err := stackClient.CreateStack(name, "main")
+			require.NoError(t, err)
+
+			uuid := fmt.Sprintf("%016d", i+1)
+			_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": name,
+			})
+
+			// Even stacks are fully merged (eligible), odd stacks still have an open PR
+			prNumber := 200 + i
+			state, ghState := "open", "OPEN"
+			if i%2 == 0 {
+				state, ghState = "merged", "MERGED"
+			}
+			require.NoError(t, stackClient.savePRs(name, &model.PRData{
+				Version: 1,
+				PRs:     map[string]*model.PR{uuid: {PRNumber: prNumber, State: state}},
+			}))
+
+			// Earlier stacks respond slower so syncs complete out of order
+			delay := time.Duration(numStacks-i) * time.Millisecond
+			mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", []int{prNumber}).
+				Run(func(mock.Arguments) { time.Sleep(delay) }).
+				Return(&gh.BatchPRsResult{
+					PRStates: map[int]*gh.PRState{
+						prNumber: {Number: prNumber, State: ghState, IsMerged: i%2 == 0},
+					},
+				}, nil)
+		}
+
+		// Serial reference: same per-stack logic, one stack at a time
+		stacks, err := stackClient.ListStacks()
+		require.NoError(t, err)
+		var expected []string
+		for _, s := range stacks {
+			stackCtx, err := stackClient.loadStackWithSync(s.Name)
+			require.NoError(t, err)
+			if eligible, _ := stackClient.IsStackEligibleForCleanup(stackCtx); eligible {
+				expected = append(expected, s.Name)
+			}
+		}
+		require.Equal(t, []string{"stack-0", "stack-2", "stack-4", "stack-6", "stack-empty"}, expected)
+
+		for range 3 {
+			candidates, err := stackClient.GetCleanupCandidates()
+			require.NoError(t, err)
+
+			actual := make([]string, len(candidates))
+			for i, candidate := range candidates {
+				actual[i] = candidate.StackCtx.StackName
+			}
+			assert.Equal(t, expected, actual)
+		}
+	})
+}
+
+func TestRefreshWouldModifyHistory(t *testing.T) {
+	merged := &model.Change{UUID: "1111111111111111", Title: "Merged", PR: &model.PR{PRNumber: 101, State: "merged"}}
+	active := &model.Change{UUID: "2222222222222222", Title: "Active", PR: &model.PR{PRNumber: 102, State: "open"}}
+
+	stackClient := &Client{}
+
+	t.Run("StaleMergedChange", func(t *testing.T) {
+		stackCtx := &StackContext{
+			StackName:          "test-stack",
+			AllChanges:         []*model.Change{merged, active},
+			ActiveChanges:      []*model.Change{merged, active},
+			StaleMergedChanges: []*model.Change{merged},
+		}
+
+		rewrite, changes, err := stackClient.RefreshWouldModifyHistory(stackCtx)
+		require.NoError(t, err)
+		assert.True(t, rewrite)
+		assert.Equal(t, []*model.Change{merged}, changes)
+	})
+
+	t.Run("AllActive", func(t *testing.T) {
+		stackCtx := &StackContext{
+			StackName:     "test-stack",
+			AllChanges:    []*model.Change{active},
+			ActiveChanges: []*model.Change{active},
+		}
+
+		rewrite, changes, err := stackClient.RefreshWouldModifyHistory(stackCtx)
+		require.NoError(t, err)
+		assert.False(t, rewrite)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestLinkedWorktreeSharesStackMetadata(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	mainClient := NewTestStack(t, mockGithubClient)
+	_, err := mainClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	worktreePath := filepath.Join(t.TempDir(), "worktree")
+	cmd := exec.Command("git", "worktree", "add", "--detach", worktreePath, "main")
+	cmd.Dir = mainClient.git.GitRoot()
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git worktree add failed: %s", string(output))
+
+	worktreeGit, err := git.NewClientAt(worktreePath)
+	require.NoError(t, err)
+	worktreeClient := NewTestStackWithClients(t, mockGithubClient, worktreeGit)
+
+	// .git is a file in a linked worktree; metadata must still resolve to the main git dir
+	info, err := os.Stat(filepath.Join(worktreePath, ".git"))
+	require.NoError(t, err)
+	require.False(t, info.IsDir())
+	assert.Equal(t, mainClient.getStacksRootDir(), worktreeClient.getStacksRootDir())
+
+	stack, err := worktreeClient.LoadStack("test-stack")
+	require.NoError(t, err)
+	assert.Equal(t, "test-stack", stack.Name)
+
+	// Metadata saved from the worktree is visible from the main checkout
+	stack.HoldReady = true
+	require.NoError(t, worktreeClient.SaveStack(stack))
+	reloaded, err := mainClient.LoadStack("test-stack")
+	require.NoError(t, err)
+	assert.True(t, reloaded.HoldReady)
+}
+
+func TestPruneMergedChanges(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+
+	// setup creates a three-change stack whose first mergedCount PRs were squash merged into main
+	setup := func(t *testing.T, mergedCount int) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		prs := make(map[string]*model.PR)
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+			state := "open"
+			if i < mergedCount {
+				state = "merged"
+			}
+			prs[uuid] = &model.PR{PRNumber: 100 + i, State: state}
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: prs}))
+
+		// Squash merge the merged changes into main: same content, different commits
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		for i := 0; i < mergedCount; i++ {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), "", nil)
+		}
+		require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.StaleMergedChanges, mergedCount)
+		return stackClient, stackCtx
+	}
+
+	for _, mergedCount := range []int{1, 2} {
+		t.Run(fmt.Sprintf("Prunes%dMerged", mergedCount), func(t *testing.T) {
+			stackClient, stackCtx := setup(t, mergedCount)
+
+			pruned, err := stackClient.PruneMergedChanges(stackCtx)
+			require.NoError(t, err)
+			assert.Equal(t, mergedCount, pruned)
+
+			stackCtx, err = stackClient.GetStackContextByName("test-stack")
+			require.NoError(t, err)
+			assert.Empty(t, stackCtx.StaleMergedChanges)
+			require.Len(t, stackCtx.ActiveChanges, len(uuids)-mergedCount)
+			for i, change := range stackCtx.ActiveChanges {
+				assert.Equal(t, uuids[mergedCount+i], change.UUID)
+			}
+
+			mainHash, err := stackClient.git.GetCommitHash("main")
+			require.NoError(t, err)
+			assert.Equal(t, mainHash, stackCtx.Stack.BaseRef)
+
+			// Only the unmerged commits are left on TOP
+			commits, err := stackClient.git.GetCommits(stackCtx.Stack.Branch, "main")
+			require.NoError(t, err)
+			assert.Len(t, commits, len(uuids)-mergedCount)
+			assert.True(t, stackClient.git.IsAncestor(mainHash, stackCtx.ActiveChanges[0].CommitHash))
+		})
+	}
+
+	t.Run("NothingToPrune", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, 0)
+		headBefore, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		pruned, err := stackClient.PruneMergedChanges(stackCtx)
+		require.NoError(t, err)
+		assert.Zero(t, pruned)
+
+		headAfter, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, headBefore, headAfter)
+	})
+
+	t.Run("RefusesUncommittedChanges", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, 1)
+		testutil.WriteFile(t, stackClient.git.GitRoot(), "file-Change 3.txt", "edited")
+
+		_, err := stackClient.PruneMergedChanges(stackCtx)
+		assert.ErrorContains(t, err, "uncommitted changes")
+	})
+
+	t.Run("RefusesOutOfOrderMerges", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, 0)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[0]: {PRNumber: 100, State: "open"},
+				uuids[1]: {PRNumber: 101, State: "merged"},
+				uuids[2]: {PRNumber: 102, State: "open"},
+			},
+		}))
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		_, err = stackClient.PruneMergedChanges(stackCtx)
+		assert.ErrorContains(t, err, "out-of-order merge")
+	})
+}
+
+func TestCollapseMergedChanges(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222"}
+
+	// setup creates a two-change stack whose first PR is merged, with a UUID branch for each
+	// change. With squashIntoMain, main gets the first change's content as a new commit.
+	setup := func(t *testing.T, squashIntoMain bool) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[0]: {PRNumber: 100, State: "merged"},
+				uuids[1]: {PRNumber: 101, State: "open"},
+			},
+		}))
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		for _, change := range stackCtx.AllChanges {
+			require.NoError(t, gitClient.CreateBranchAt(stackCtx.FormatUUIDBranch(change.UUID), change.CommitHash))
+		}
+
+		if squashIntoMain {
+			require.NoError(t, gitClient.CheckoutBranch("main"))
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change 1", "", nil)
+			require.NoError(t, gitClient.CheckoutBranch(stack.Branch))
+		}
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.StaleMergedChanges, 1)
+		return stackClient, stackCtx
+	}
+
+	t.Run("CollapsesMergedChangeInBase", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, true)
+		uuidBranch := stackCtx.FormatUUIDBranch(uuids[1])
+
+		collapsed, err := stackClient.CollapseMergedChanges(stackCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, collapsed)
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, stackCtx.StaleMergedChanges)
+		require.Len(t, stackCtx.ActiveChanges, 1)
+		assert.Equal(t, uuids[1], stackCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, 1, stackCtx.ActiveChanges[0].ActivePosition)
+		require.Len(t, stackCtx.Stack.MergedChanges, 1)
+		assert.Equal(t, uuids[0], stackCtx.Stack.MergedChanges[0].UUID)
+
+		mainHash, err := stackClient.git.GetCommitHash("main")
+		require.NoError(t, err)
+		assert.Equal(t, mainHash, stackCtx.Stack.BaseRef)
+		commits, err := stackClient.git.GetCommits(stackCtx.Stack.Branch, "main")
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+
+		// The UUID branch follows the replayed commit
+		branchHash, err := stackClient.git.GetCommitHash(uuidBranch)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[0].CommitHash, branchHash)
+	})
+
+	t.Run("RefusesWhenBaseLacksMergedChange", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, false)
+		headBefore, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		_, err = stackClient.CollapseMergedChanges(stackCtx)
+		assert.ErrorContains(t, err, "main doesn't contain the 1 merged change(s) yet")
+
+		headAfter, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, headBefore, headAfter)
+	})
+}
+
+func TestContainsChanges(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+	gitRoot := gitClient.GitRoot()
+
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("feature", "main"))
+	testutil.WriteFile(t, gitRoot, "shared.txt", "one\ntwo\nthree\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 1", "", nil)
+	feature, err := gitClient.GetCommitHash("feature")
+	require.NoError(t, err)
+
+	contains, err := gitClient.ContainsChanges("main", "main", feature)
+	require.NoError(t, err)
+	assert.False(t, contains, "main doesn't have the feature yet")
+
+	// A squash merge: same content as a different commit
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("squashed", "main"))
+	testutil.WriteFile(t, gitRoot, "shared.txt", "one\ntwo\nthree\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 1", "", nil)
+	contains, err = gitClient.ContainsChanges("squashed", "main", feature)
+	require.NoError(t, err)
+	assert.True(t, contains)
+
+	// Unrelated later commits don't matter
+	testutil.WriteFile(t, gitRoot, "other.txt", "other\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Other", "", nil)
+	contains, err = gitClient.ContainsChanges("squashed", "main", feature)
+	require.NoError(t, err)
+	assert.True(t, contains)
+
+	// Ancestors are contained
+	contains, err = gitClient.ContainsChanges("feature", "main", feature)
+	require.NoError(t, err)
+	assert.True(t, contains)
+}
+
+func TestCommitMessage_Trailers(t *testing.T) {
+	message := "Title\n\nBody\n\nChange-Id: I1\nSigned-off-by: Jane <jane@example.com>\nSigned-off-by: John <john@example.com>\nPR-UUID: 1111111111111111\n"
+
+	msg := git.ParseCommitMessage(message)
+	assert.Equal(t, "Body", msg.Body)
+	assert.Equal(t, "John <john@example.com>", msg.Trailers["Signed-off-by"])
+	assert.Equal(t, message, msg.String(), "unchanged message is written back as parsed")
+
+	// Changed trailers keep their place, removed ones are dropped and new ones follow sorted
+	msg.Trailers["PR-UUID"] = "2222222222222222"
+	delete(msg.Trailers, "Change-Id")
+	msg.Trailers["PR-Stack"] = "test-stack"
+	msg.AddTrailer("Acked-by", "Ann")
+	assert.Equal(t, []git.Trailer{
+		{Key: "Signed-off-by", Value: "Jane <jane@example.com>"},
+		{Key: "Signed-off-by", Value: "John <john@example.com>"},
+		{Key: "PR-UUID", Value: "2222222222222222"},
+		{Key: "Acked-by", Value: "Ann"},
+		{Key: "PR-Stack", Value: "test-stack"},
+	}, msg.OrderedTrailers())
+
+	// Trailers set on a new message are written in a stable order
+	built := git.CommitMessage{Title: "Title", Trailers: map[string]string{"PR-UUID": "1", "PR-Stack": "s", "Change-Id": "I1"}}
+	assert.Equal(t, "Title\n\nChange-Id: I1\nPR-Stack: s\nPR-UUID: 1\n", built.String())
+}
+
+func TestParseCommitMessage_Whitespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{
+			name:    "CRLF",
+			message: "Add feature\r\n\r\nFirst paragraph\r\n\r\nSecond paragraph\r\n\r\nPR-UUID: 1111111111111111\r\nPR-Stack: test-stack\r\n",
+		},
+		{
+			name:    "TrailingSpaces",
+			message: "Add feature  \n\nFirst paragraph \t\n\nSecond paragraph  \n\nPR-UUID: 1111111111111111 \nPR-Stack: test-stack\t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := git.ParseCommitMessage(tt.message)
+			assert.Equal(t, "Add feature", msg.Title)
+			assert.Equal(t, "First paragraph\n\nSecond paragraph", msg.Body)
+			assert.Equal(t, map[string]string{
+				"PR-UUID":  "1111111111111111",
+				"PR-Stack": "test-stack",
+			}, msg.Trailers)
+		})
+	}
+}
+
+func TestGetCommit_CRLFMessage(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+	testutil.WriteFile(t, gitClient.GitRoot(), "crlf.txt", "content")
+	add := exec.Command("git", "add", ".")
+	add.Dir = gitClient.GitRoot()
+	output, err := add.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	// --cleanup=verbatim keeps the CR characters git would otherwise strip as trailing whitespace
+	cmd := exec.Command("git", "commit", "--cleanup=verbatim", "-m", "Windows title\r\n\r\nBody line\r\n\r\nPR-UUID: 1111111111111111\r\n")
+	cmd.Dir = gitClient.GitRoot()
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	commit, err := gitClient.GetCommit("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, "Windows title", commit.Message.Title)
+	assert.Equal(t, "Body line", commit.Message.Body)
+	assert.Equal(t, "1111111111111111", commit.Message.Trailers["PR-UUID"])
+}
+
+func TestEnsureBranchAt(t *testing.T) {
+	// setup returns a repository with two commits on main: the first and the second (HEAD)
+	setup := func(t *testing.T) (*git.Client, string, string) {
+		gitClient := testutil.NewTestGitClient(t)
+		first := testutil.CreateCommitWithTrailers(t, gitClient, "First", "", nil)
+		second := testutil.CreateCommitWithTrailers(t, gitClient, "Second", "", nil)
+		return gitClient, first, second
+	}
+
+	assertOnBranchAt := func(t *testing.T, gitClient *git.Client, branch string, hash string) {
+		currentBranch, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, branch, currentBranch)
+		head, err := gitClient.GetCommitHash("HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, hash, head)
+	}
+
+	t.Run("CreatesMissingBranch", func(t *testing.T) {
+		gitClient, first, _ := setup(t)
+
+		require.NoError(t, gitClient.EnsureBranchAt("feature", first))
+		assertOnBranchAt(t, gitClient, "feature", first)
+	})
+
+	t.Run("MovesBranchAtAnotherCommit", func(t *testing.T) {
+		gitClient, first, second := setup(t)
+		require.NoError(t, gitClient.CreateBranchAt("feature", first))
+
+		require.NoError(t, gitClient.EnsureBranchAt("feature", second))
+		assertOnBranchAt(t, gitClient, "feature", second)
+	})
+
+	t.Run("ChecksOutBranchAtTheCommit", func(t *testing.T) {
+		gitClient, first, _ := setup(t)
+		require.NoError(t, gitClient.CreateBranchAt("feature", first))
+
+		require.NoError(t, gitClient.EnsureBranchAt("feature", first))
+		assertOnBranchAt(t, gitClient, "feature", first)
+
+		// Already checked out at the commit
+		require.NoError(t, gitClient.EnsureBranchAt("feature", first))
+		assertOnBranchAt(t, gitClient, "feature", first)
+	})
+}
+
+func TestCountCommitsBetween(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("feature", "main"))
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 1", "", nil)
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 2", "", nil)
+
+	count, err := gitClient.CountCommitsBetween("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = gitClient.CountCommitsBetween("feature", "main")
+	require.NoError(t, err)
+	assert.Zero(t, count, "main has nothing feature doesn't")
+
+	count, err = gitClient.CountCommitsBetween("main", "main")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = gitClient.CountCommitsBetween("main", "does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestAheadBehind(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("feature", "main"))
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 1", "", nil)
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Feature 2", "", nil)
+
+	ahead, behind, err := gitClient.AheadBehind("feature", "main")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Zero(t, behind)
+
+	// main moves on too, so the branches diverge
+	require.NoError(t, gitClient.CheckoutBranch("main"))
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Main 1", "", nil)
+
+	ahead, behind, err = gitClient.AheadBehind("feature", "main")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Equal(t, 1, behind)
+
+	ahead, behind, err = gitClient.AheadBehind("main", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, 1, ahead)
+	assert.Equal(t, 2, behind)
+
+	_, _, err = gitClient.AheadBehind("feature", "does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestGetCommitsWithPaths(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+	gitRoot := gitClient.GitRoot()
+
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("feature", "main"))
+	testutil.WriteFile(t, gitRoot, "api.go", "package api\n")
+	api1 := testutil.CreateCommitWithTrailers(t, gitClient, "API 1", "", nil)
+	testutil.WriteFile(t, gitRoot, "index.html", "<html></html>\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Web 1", "", nil)
+	testutil.WriteFile(t, gitRoot, "api.go", "package api\n\nfunc Handle() {}\n")
+	api2 := testutil.CreateCommitWithTrailers(t, gitClient, "API 2", "", nil)
+	testutil.WriteFile(t, gitRoot, "docs.md", "# Docs\n")
+	docs := testutil.CreateCommitWithTrailers(t, gitClient, "Docs 1", "", nil)
+
+	hashes := func(commits []git.Commit) []string {
+		result := make([]string, len(commits))
+		for i, commit := range commits {
+			result[i] = commit.Hash
+		}
+		return result
+	}
+
+	commits, err := gitClient.GetCommitsWithPaths("feature", "main", []string{"api.go"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{api1, api2}, hashes(commits))
+	assert.Equal(t, "API 1", commits[0].Message.Title)
+
+	commits, err = gitClient.GetCommitsWithPaths("feature", "main", []string{"api.go", "docs.md"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{api1, api2, docs}, hashes(commits))
+
+	commits, err = gitClient.GetCommitsWithPaths("feature", "main", []string{"missing"})
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+
+	// Without paths every commit in the range is returned, as with GetCommits
+	all, err := gitClient.GetCommits("feature", "main")
+	require.NoError(t, err)
+	commits, err = gitClient.GetCommitsWithPaths("feature", "main", nil)
+	require.NoError(t, err)
+	assert.Len(t, commits, 4)
+	assert.Equal(t, hashes(all), hashes(commits))
+}
+
+func TestListBranches(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+
+	for _, branch := range []string{"alice/stack-auth/TOP", "alice/stack-auth/1111111111111111", "alice/stack-authz/TOP", "bob/stack-auth/TOP"} {
+		require.NoError(t, gitClient.CreateBranchAt(branch, "main"))
+	}
+
+	branches, err := gitClient.ListBranches("alice/stack-auth/*")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice/stack-auth/TOP", "alice/stack-auth/1111111111111111"}, branches)
+
+	branches, err = gitClient.ListBranches("carol/stack-auth/*")
+	require.NoError(t, err)
+	assert.NotNil(t, branches)
+	assert.Empty(t, branches)
+}
+
+func TestConflictedFiles(t *testing.T) {
+	gitClient := testutil.NewTestGitClient(t)
+	gitRoot := gitClient.GitRoot()
+
+	files, err := gitClient.ConflictedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	// Change the same files differently on main and on a side branch
+	mainHash, err := gitClient.GetCommitHash("main")
+	require.NoError(t, err)
+	require.NoError(t, gitClient.CreateAndCheckoutBranchAt("side", mainHash))
+	testutil.WriteFile(t, gitRoot, "a.txt", "side\n")
+	testutil.WriteFile(t, gitRoot, "b.txt", "side\n")
+	testutil.WriteFile(t, gitRoot, "clean.txt", "side\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Side", "", nil)
+
+	require.NoError(t, gitClient.CheckoutBranch("main"))
+	testutil.WriteFile(t, gitRoot, "a.txt", "main\n")
+	testutil.WriteFile(t, gitRoot, "b.txt", "main\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Main", "", nil)
+
+	require.NoError(t, gitClient.CheckoutBranch("side"))
+	require.Error(t, gitClient.Rebase("main"))
+	require.True(t, gitClient.IsRebaseInProgress())
+
+	files, err = gitClient.ConflictedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "b.txt"}, files)
+}
+
+// listBranchesGitClient is a git client whose ListBranches is mocked; everything else goes to
+// the embedded GitClient
+type listBranchesGitClient struct {
+	GitClient
+	mock.Mock
+}
+
+// ListBranches implements GitClient.
+func (m *listBranchesGitClient) ListBranches(pattern string) ([]string, error) {
+	args := m.Called(pattern)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func TestGetStackBranches(t *testing.T) {
+	newClient := func(t *testing.T) (*Client, *listBranchesGitClient) {
+		gitClient := &listBranchesGitClient{GitClient: testutil.NewTestGitClient(t)}
+		stackClient, err := NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
+		stackClient.SetUsernameForTesting("test-user")
+		return stackClient, gitClient
+	}
+
+	t.Run("ListsMatchingBranches", func(t *testing.T) {
+		stackClient, gitClient := newClient(t)
+		gitClient.On("ListBranches", "test-user/stack-auth/*").Return([]string{"test-user/stack-auth/TOP", "test-user/stack-auth/1111111111111111"}, nil)
+
+		branches, err := stackClient.GetStackBranches("auth")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"test-user/stack-auth/TOP", "test-user/stack-auth/1111111111111111"}, branches)
+		gitClient.AssertExpectations(t)
+	})
+
+	t.Run("NoBranches", func(t *testing.T) {
+		stackClient, gitClient := newClient(t)
+		gitClient.On("ListBranches", "test-user/stack-auth/*").Return([]string{}, nil)
+
+		branches, err := stackClient.GetStackBranches("auth")
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, branches)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		stackClient, gitClient := newClient(t)
+		gitClient.On("ListBranches", "test-user/stack-auth/*").Return(nil, errors.New("not a git repository"))
+
+		_, err := stackClient.GetStackBranches("auth")
+		assert.ErrorContains(t, err, "failed to list stack branches: not a git repository")
+	})
+}
+
+func TestStripComments(t *testing.T) {
+	message := "Add feature\n\nUse # for headings; not a comment\n# Please enter the commit message\n; semicolon line\n"
+
+	t.Run("DefaultChar", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+
+		stripped, err := gitClient.StripComments(message)
+		require.NoError(t, err)
+		assert.Equal(t, "Add feature\n\nUse # for headings; not a comment\n; semicolon line\n", stripped)
+	})
+
+	t.Run("CustomChar", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		require.NoError(t, gitClient.SetConfig("core.commentChar", ";"))
+
+		stripped, err := gitClient.StripComments(message)
+		require.NoError(t, err)
+		assert.Equal(t, "Add feature\n\nUse # for headings; not a comment\n# Please enter the commit message\n", stripped)
+	})
+
+	t.Run("AutoChar", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		require.NoError(t, gitClient.SetConfig("core.commentChar", "auto"))
+
+		// A message starting with # makes git pick the next candidate, ;
+		autoMessage := "#123 Fix the bug\n\n; Please enter the commit message\n; Lines starting with ';' will be ignored\n"
+		assert.Equal(t, ";", gitClient.CommentChar(autoMessage))
+
+		stripped, err := gitClient.StripComments(autoMessage)
+		require.NoError(t, err)
+		assert.Equal(t, "#123 Fix the bug\n", stripped)
+
+		// Without a comment block there's nothing to detect
+		assert.Equal(t, "#", gitClient.CommentChar("Fix the bug\n"))
+	})
+
+	t.Run("WithChar", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		require.NoError(t, gitClient.SetConfig("core.commentChar", ";"))
+
+		// The explicit character wins over core.commentChar
+		stripped, err := gitClient.StripCommentsWithChar(message, "#")
+		require.NoError(t, err)
+		assert.Equal(t, "Add feature\n\nUse # for headings; not a comment\n; semicolon line\n", stripped)
+	})
+}
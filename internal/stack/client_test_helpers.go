@@ -5,7 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bjulian5/stack/internal/git"
@@ -15,13 +17,15 @@ import (
 // NewTestStackClient creates a new stack client for testing with a mock GitHub client
 func NewTestStack(t *testing.T, gh GithubClient) *Client {
 	mockGitClient := testutil.NewTestGitClient(t)
-	c := NewClient(mockGitClient, gh)
+	c, err := NewClient(mockGitClient, gh)
+	require.NoError(t, err)
 	c.username = "test-user"
 	return c
 }
 
 func NewTestStackWithClients(t *testing.T, gh GithubClient, gitClient *git.Client) *Client {
-	c := NewClient(gitClient, gh)
+	c, err := NewClient(gitClient, gh)
+	require.NoError(t, err)
 	c.username = "test-user"
 	return c
 }
@@ -70,3 +74,26 @@ func simulateCommitRewrite(t *testing.T, gitClient *git.Client, stackName, uuid
 
 	return newCommitHash
 }
+
+// createCommitByOtherAuthor creates a commit authored by someone other than the test user, so
+// tests can check that rewriting it keeps its author (see assertOtherAuthor)
+func createCommitByOtherAuthor(t *testing.T, gitClient *git.Client, title, body string, trailers map[string]string) string {
+	t.Setenv("GIT_AUTHOR_NAME", "Ada Lovelace")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ada@example.com")
+	hash := testutil.CreateCommitWithTrailers(t, gitClient, title, body, trailers)
+
+	// Rewrites made after this would otherwise pick the author up from the environment
+	require.NoError(t, os.Unsetenv("GIT_AUTHOR_NAME"))
+	require.NoError(t, os.Unsetenv("GIT_AUTHOR_EMAIL"))
+	return hash
+}
+
+// assertOtherAuthor checks that the commit at ref is still authored by the author and date
+// createCommitByOtherAuthor used
+func assertOtherAuthor(t *testing.T, gitClient *git.Client, ref string) {
+	meta, err := gitClient.GetCommitMeta(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", meta.AuthorName)
+	assert.Equal(t, "ada@example.com", meta.AuthorEmail)
+	assert.True(t, meta.AuthorDate.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), "author date: %s", meta.AuthorDate)
+}
@@ -1,93 +1,215 @@
 package stack
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/bjulian5/stack/internal/config"
+	"github.com/bjulian5/stack/internal/model"
 )
 
-// RepositoryConfig represents the repository-level stack installation configuration
-type RepositoryConfig struct {
-	HooksInstalled bool      `json:"hooks_installed"`
-	HooksVersion   string    `json:"hooks_version"`   // Version of hooks for future compatibility
-	GitConfigured  bool      `json:"git_configured"`  // Whether git settings have been configured
-	InstalledAt    time.Time `json:"installed_at"`    // When stack was first installed
-	LastUpdatedAt  time.Time `json:"last_updated_at"` // Last time config was updated
-}
+// RepositoryConfig is the repository-level configuration stored in .git/stack/config.json
+type RepositoryConfig = config.Config
 
 // CurrentHooksVersion is the current version of the hooks system
 const CurrentHooksVersion = "1.0.0"
 
+// vizCommentStackPlaceholder is replaced with the stack name in visualization markers
+const vizCommentStackPlaceholder = "{stack}"
+
 // getRepositoryConfigPath returns the path to the repository config file
 func (c *Client) getRepositoryConfigPath() string {
-	return filepath.Join(c.getStacksRootDir(), "config.json")
+	return config.Path(c.gitDir)
 }
 
-// loadRepositoryConfig loads the repository stack configuration.
-// Returns a default config if the file doesn't exist.
-func (c *Client) loadRepositoryConfig() (*RepositoryConfig, error) {
-	data, err := os.ReadFile(c.getRepositoryConfigPath())
+// UserHooksDir returns the directory of the user hooks run after push and refresh
+func (c *Client) UserHooksDir() string {
+	return filepath.Join(c.getStacksRootDir(), "hooks")
+}
+
+// loadConfig loads the repository config with environment overrides and defaults applied and
+// stores it on the client, where the setting accessors read it. NewClient loads it once;
+// saveRepositoryConfig reloads it so changes take effect for the rest of the command.
+func (c *Client) loadConfig() error {
+	cfg, err := config.Load(c.gitDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return &RepositoryConfig{}, nil
-		}
-		return nil, fmt.Errorf("failed to read repository config: %w", err)
+		return err
 	}
+	c.config = cfg
+	return nil
+}
+
+// loadRepositoryConfig loads the repository stack configuration as stored, for updating it.
+// Returns a default config if the file doesn't exist.
+func (c *Client) loadRepositoryConfig() (*RepositoryConfig, error) {
+	return config.ReadFile(c.gitDir)
+}
 
-	var config RepositoryConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse repository config: %w", err)
+// saveRepositoryConfig saves the repository stack configuration and reloads the client's
+// settings from it.
+func (c *Client) saveRepositoryConfig(cfg *RepositoryConfig) error {
+	if err := config.Save(c.gitDir, cfg); err != nil {
+		return err
 	}
+	return c.loadConfig()
+}
 
-	return &config, nil
+// SyncThreshold returns how long a sync with GitHub stays fresh. It is resolved from the
+// STACK_SYNC_THRESHOLD env var, then the repository config, then config.DefaultSyncThreshold.
+// Malformed or negative values fall back to the default. Zero means never stale based on time.
+func (c *Client) SyncThreshold() time.Duration {
+	return c.config.SyncThresholdDuration()
 }
 
-// saveRepositoryConfig saves the repository stack configuration.
-func (c *Client) saveRepositoryConfig(config *RepositoryConfig) error {
-	if err := os.MkdirAll(c.getStacksRootDir(), 0755); err != nil {
-		return fmt.Errorf("failed to create stack directory: %w", err)
-	}
+// holdReadyNewStacks reports whether new stacks should hold their PRs as drafts
+func (c *Client) holdReadyNewStacks() bool {
+	return c.config.HoldReadyNewStacks
+}
 
-	config.LastUpdatedAt = time.Now()
+// PRBatchSize returns the configured number of PRs to fetch per GraphQL query, or 0 for the
+// gh client's default
+func (c *Client) PRBatchSize() int {
+	return c.config.PRBatchSize
+}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// DefaultRemote returns the remote to use for branches without a configured remote. It is
+// resolved from the STACK_REMOTE env var, then the repository config; "" means origin, or
+// the first remote.
+func (c *Client) DefaultRemote() string {
+	return c.config.Remote
+}
+
+// GitHubHost returns the GitHub host to point gh at, resolved from the GH_HOST env var, then
+// the repository config. "" leaves the choice to gh (github.com, or the git remote's host).
+func (c *Client) GitHubHost() string {
+	return c.config.Host
+}
+
+// SetGithubClientFactory sets how GitHub clients are created for stacks whose Host differs
+// from GitHubHost (see ghFor). Without one, every stack uses the client given to NewClient.
+func (c *Client) SetGithubClientFactory(newClient func(host string) GithubClient) {
+	c.newGithubClient = newClient
+}
+
+// ghFor returns the GitHub client for stack's PRs. A stack keeps talking to the host it was
+// created against (Stack.Host), even if gh has since been pointed at another one.
+func (c *Client) ghFor(stack *model.Stack) GithubClient {
+	if stack == nil || stack.Host == "" || stack.Host == c.GitHubHost() || c.newGithubClient == nil {
+		return c.gh
+	}
+
+	c.hostClientsMu.Lock()
+	defer c.hostClientsMu.Unlock()
+	if client, ok := c.hostClients[stack.Host]; ok {
+		return client
+	}
+	if c.hostClients == nil {
+		c.hostClients = make(map[string]GithubClient)
 	}
+	client := c.newGithubClient(stack.Host)
+	c.hostClients[stack.Host] = client
+	return client
+}
+
+// PushRemote returns the configured remote to push PR branches to, or "" for the fetch remote
+func (c *Client) PushRemote() string {
+	return c.config.PushRemote
+}
+
+// HeadRepo returns the configured owner of the fork PR branches are pushed to, or "" when
+// branches are pushed to the repository the PRs are opened against
+func (c *Client) HeadRepo() string {
+	return c.config.HeadRepo
+}
 
-	if err := os.WriteFile(c.getRepositoryConfigPath(), data, 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+// PRLabels returns the labels configured to be added to every PR
+func (c *Client) PRLabels() []string {
+	return c.config.PRLabels
+}
+
+// PRAutoMerge returns the configured auto-merge setting for PRs: a merge method,
+// gh.AutoMergeOff, or "" to leave auto-merge alone
+func (c *Client) PRAutoMerge() string {
+	return c.config.PRAutoMerge
+}
+
+// AllowMaintainerEdit reports whether maintainers may push to the branches of PRs opened
+// from a fork
+func (c *Client) AllowMaintainerEdit() bool {
+	return !c.config.DisableMaintainerEdit
+}
+
+// PropagateTrailers returns the commit trailers copied to the commits derived from a change
+// when it's split or squashed
+func (c *Client) PropagateTrailers() []string {
+	return c.config.PropagateTrailers
+}
+
+// PRReviewers returns the reviewers configured to be requested on every PR
+func (c *Client) PRReviewers() []string {
+	return c.config.PRReviewers
+}
+
+// VisualizationCommentsEnabled reports whether stack visualization comments are posted on PRs
+func (c *Client) VisualizationCommentsEnabled() bool {
+	return !c.config.DisableVizComments
+}
+
+// closedVizCommentsMode returns what to do with the visualization comment of merged and closed
+// PRs (one of the config.ClosedVizComments values)
+func (c *Client) closedVizCommentsMode() string {
+	return c.config.ClosedVizComments
+}
+
+// vizCommentMarkerTemplate returns the configured visualization marker template
+func (c *Client) vizCommentMarkerTemplate() string {
+	return c.config.VizCommentMarker
+}
+
+// vizCommentMarker returns the visualization marker for the given stack
+func (c *Client) vizCommentMarker(stackName string) string {
+	return formatVizCommentMarker(c.vizCommentMarkerTemplate(), stackName)
+}
+
+// vizCommentMarkerPrefix returns the part of the marker before the stack name, which is the
+// same for every stack. It is empty when the template starts with the stack name.
+func (c *Client) vizCommentMarkerPrefix() string {
+	template := c.vizCommentMarkerTemplate()
+	if i := strings.Index(template, vizCommentStackPlaceholder); i >= 0 {
+		return template[:i]
 	}
+	return template
+}
 
-	return nil
+func formatVizCommentMarker(template string, stackName string) string {
+	return strings.ReplaceAll(template, vizCommentStackPlaceholder, stackName)
 }
 
 // IsInstalled checks if stack is properly installed in this repository.
 func (c *Client) IsInstalled() (bool, error) {
-	config, err := c.loadRepositoryConfig()
+	cfg, err := c.loadRepositoryConfig()
 	if err != nil {
 		return false, err
 	}
 
-	return config.HooksInstalled && config.GitConfigured, nil
+	return cfg.HooksInstalled && cfg.GitConfigured, nil
 }
 
 // MarkInstalled marks stack as installed with current timestamp.
 func (c *Client) MarkInstalled() error {
-	config, err := c.loadRepositoryConfig()
+	cfg, err := c.loadRepositoryConfig()
 	if err != nil {
 		return err
 	}
 
-	if config.InstalledAt.IsZero() {
-		config.InstalledAt = time.Now()
+	if cfg.InstalledAt.IsZero() {
+		cfg.InstalledAt = time.Now()
 	}
 
-	config.HooksInstalled = true
-	config.HooksVersion = CurrentHooksVersion
-	config.GitConfigured = true
+	cfg.HooksInstalled = true
+	cfg.HooksVersion = CurrentHooksVersion
+	cfg.GitConfigured = true
 
-	return c.saveRepositoryConfig(config)
+	return c.saveRepositoryConfig(cfg)
 }
@@ -3,21 +3,27 @@ package stack
 import (
 	"encoding/json"
 	"os"
+	"os/user"
 	"path/filepath"
 	"testing"
 	"testing/synctest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bjulian5/stack/internal/config"
 	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
 	"github.com/bjulian5/stack/internal/testutil"
 )
 
 func TestGetRepositoryConfigPath(t *testing.T) {
 	gitClient := testutil.NewTestGitClient(t)
-	client := NewClient(gitClient, &gh.MockGithubClient{})
+	client, err := NewClient(gitClient, &gh.MockGithubClient{})
+	require.NoError(t, err)
 
 	expected := filepath.Join(client.gitRoot, ".git", "stack", "config.json")
 	actual := client.getRepositoryConfigPath()
@@ -27,7 +33,8 @@ func TestGetRepositoryConfigPath(t *testing.T) {
 
 func TestLoadRepositoryConfig_NotExists(t *testing.T) {
 	gitClient := testutil.NewTestGitClient(t)
-	client := NewClient(gitClient, &gh.MockGithubClient{})
+	client, err := NewClient(gitClient, &gh.MockGithubClient{})
+	require.NoError(t, err)
 
 	config, err := client.loadRepositoryConfig()
 	require.NoError(t, err)
@@ -40,7 +47,8 @@ func TestLoadRepositoryConfig_NotExists(t *testing.T) {
 func TestSaveAndLoadRepositoryConfig(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		gitClient := testutil.NewTestGitClient(t)
-		client := NewClient(gitClient, &gh.MockGithubClient{})
+		client, err := NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
 
 		now := time.Now()
 		testConfig := &RepositoryConfig{
@@ -50,7 +58,7 @@ func TestSaveAndLoadRepositoryConfig(t *testing.T) {
 			InstalledAt:    now,
 		}
 
-		err := client.saveRepositoryConfig(testConfig)
+		err = client.saveRepositoryConfig(testConfig)
 		require.NoError(t, err)
 
 		loadedConfig, err := client.loadRepositoryConfig()
@@ -70,10 +78,11 @@ func TestSaveAndLoadRepositoryConfig(t *testing.T) {
 
 func TestSaveRepositoryConfig_CreatesDirectory(t *testing.T) {
 	gitClient := testutil.NewTestGitClient(t)
-	client := NewClient(gitClient, &gh.MockGithubClient{})
+	client, err := NewClient(gitClient, &gh.MockGithubClient{})
+	require.NoError(t, err)
 
 	stackDir := client.getStacksRootDir()
-	_, err := os.Stat(stackDir)
+	_, err = os.Stat(stackDir)
 	assert.True(t, os.IsNotExist(err))
 
 	testConfig := &RepositoryConfig{
@@ -89,9 +98,10 @@ func TestSaveRepositoryConfig_CreatesDirectory(t *testing.T) {
 
 func TestLoadRepositoryConfig_MalformedJSON(t *testing.T) {
 	gitClient := testutil.NewTestGitClient(t)
-	client := NewClient(gitClient, &gh.MockGithubClient{})
+	client, err := NewClient(gitClient, &gh.MockGithubClient{})
+	require.NoError(t, err)
 
-	err := os.MkdirAll(client.getStacksRootDir(), 0755)
+	err = os.MkdirAll(client.getStacksRootDir(), 0755)
 	require.NoError(t, err)
 
 	err = os.WriteFile(client.getRepositoryConfigPath(), []byte("invalid json{"), 0644)
@@ -117,13 +127,14 @@ func TestIsInstalled(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gitClient := testutil.NewTestGitClient(t)
-			client := NewClient(gitClient, &gh.MockGithubClient{})
+			client, err := NewClient(gitClient, &gh.MockGithubClient{})
+			require.NoError(t, err)
 
 			config := &RepositoryConfig{
 				HooksInstalled: tt.hooksInstalled,
 				GitConfigured:  tt.gitConfigured,
 			}
-			err := client.saveRepositoryConfig(config)
+			err = client.saveRepositoryConfig(config)
 			require.NoError(t, err)
 
 			installed, err := client.IsInstalled()
@@ -136,10 +147,11 @@ func TestIsInstalled(t *testing.T) {
 func TestMarkInstalled_FirstTime(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		gitClient := testutil.NewTestGitClient(t)
-		client := NewClient(gitClient, &gh.MockGithubClient{})
+		client, err := NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
 
 		now := time.Now()
-		err := client.MarkInstalled()
+		err = client.MarkInstalled()
 		require.NoError(t, err)
 
 		config, err := client.loadRepositoryConfig()
@@ -160,11 +172,12 @@ func TestMarkInstalled_FirstTime(t *testing.T) {
 func TestMarkInstalled_AlreadyInstalled(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		gitClient := testutil.NewTestGitClient(t)
-		client := NewClient(gitClient, &gh.MockGithubClient{})
+		client, err := NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
 
 		firstInstallTime := time.Now()
 
-		err := client.MarkInstalled()
+		err = client.MarkInstalled()
 		require.NoError(t, err)
 
 		synctest.Wait()
@@ -209,3 +222,185 @@ func TestRepositoryConfig_JSONSerialization(t *testing.T) {
 		assert.Equal(t, original, &decoded)
 	})
 }
+
+func TestSyncThreshold(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		setEnv   bool
+		config   string
+		expected time.Duration
+	}{
+		{name: "Default", expected: config.DefaultSyncThreshold},
+		{name: "EnvOverride", env: "30m", setEnv: true, expected: 30 * time.Minute},
+		{name: "EnvOverridesConfig", env: "1h", setEnv: true, config: "10m", expected: time.Hour},
+		{name: "ConfigOverride", config: "10m", expected: 10 * time.Minute},
+		{name: "MalformedEnvFallsBackToDefault", env: "soon", setEnv: true, expected: config.DefaultSyncThreshold},
+		{name: "MalformedConfigFallsBackToDefault", config: "ten minutes", expected: config.DefaultSyncThreshold},
+		{name: "NegativeFallsBackToDefault", env: "-5m", setEnv: true, expected: config.DefaultSyncThreshold},
+		{name: "ZeroDisablesTimeBasedStaleness", env: "0", setEnv: true, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setEnv {
+				t.Setenv(config.SyncThresholdEnvVar, tt.env)
+			} else {
+				// Make sure an env var from the outer environment doesn't leak in
+				t.Setenv(config.SyncThresholdEnvVar, "")
+				os.Unsetenv(config.SyncThresholdEnvVar)
+			}
+
+			gitClient := testutil.NewTestGitClient(t)
+			client, err := NewClient(gitClient, &gh.MockGithubClient{})
+			require.NoError(t, err)
+
+			if tt.config != "" {
+				require.NoError(t, client.saveRepositoryConfig(&RepositoryConfig{SyncThreshold: tt.config}))
+			}
+
+			assert.Equal(t, tt.expected, client.SyncThreshold())
+		})
+	}
+}
+
+func TestCheckSyncStatus_ZeroThresholdNeverStale(t *testing.T) {
+	t.Setenv(config.SyncThresholdEnvVar, "0")
+
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	client := NewTestStack(t, mockGithubClient)
+
+	stack, err := client.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	currentHash, err := client.git.GetCommitHash(stack.Branch)
+	require.NoError(t, err)
+
+	// Synced long ago with a matching hash: only the time check could trigger a sync
+	stack.LastSynced = time.Now().Add(-24 * time.Hour)
+	stack.SyncHash = currentHash
+	require.NoError(t, client.SaveStack(stack))
+
+	status, err := client.CheckSyncStatus("test-stack")
+	require.NoError(t, err)
+	assert.False(t, status.NeedsSync)
+}
+
+func TestResolveUsername(t *testing.T) {
+	currentUser, err := user.Current()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		env         string
+		config      string
+		expected    string
+		expectedErr string
+	}{
+		{name: "DefaultsToCurrentUser", expected: currentUser.Username},
+		{name: "ConfigOverride", config: "ci-bot", expected: "ci-bot"},
+		{name: "EnvOverride", env: "jane.doe_2", expected: "jane.doe_2"},
+		{name: "EnvBeatsConfig", env: "jane", config: "ci-bot", expected: "jane"},
+		{name: "InvalidEnv", env: "jane doe", expectedErr: "invalid STACK_USER 'jane doe'"},
+		{name: "InvalidEnvSlash", env: "team/jane", expectedErr: "invalid STACK_USER 'team/jane'"},
+		{name: "InvalidEnvDot", env: ".", expectedErr: "invalid STACK_USER '.'"},
+		{name: "InvalidEnvDotDot", env: "..", expectedErr: "invalid STACK_USER '..'"},
+		{name: "InvalidEnvLeadingDot", env: ".jane", expectedErr: "invalid STACK_USER '.jane'"},
+		{name: "InvalidEnvLock", env: "jane.lock", expectedErr: "invalid STACK_USER 'jane.lock'"},
+		{name: "InvalidConfig", config: "ci~bot", expectedErr: "invalid user 'ci~bot'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.UserEnvVar, tt.env)
+
+			gitClient := testutil.NewTestGitClient(t)
+			if tt.config != "" {
+				gitDir, err := gitClient.GitCommonDir()
+				require.NoError(t, err)
+				require.NoError(t, config.Save(gitDir, &RepositoryConfig{User: tt.config}))
+			}
+
+			client, err := NewClient(gitClient, &gh.MockGithubClient{})
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, client.username)
+		})
+	}
+}
+
+func TestGitHubHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		config   string
+		expected string
+	}{
+		{name: "DefaultsToGhResolution", expected: ""},
+		{name: "ConfigOverride", config: "github.example.com", expected: "github.example.com"},
+		{name: "EnvOverride", env: "ghe.corp.example", expected: "ghe.corp.example"},
+		{name: "EnvBeatsConfig", env: "ghe.corp.example", config: "github.example.com", expected: "ghe.corp.example"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.HostEnvVar, tt.env)
+
+			client, err := NewClient(testutil.NewTestGitClient(t), &gh.MockGithubClient{})
+			require.NoError(t, err)
+			if tt.config != "" {
+				require.NoError(t, client.saveRepositoryConfig(&RepositoryConfig{Host: tt.config}))
+			}
+
+			assert.Equal(t, tt.expected, client.GitHubHost())
+		})
+	}
+}
+
+func TestStackKeepsItsHost(t *testing.T) {
+	t.Setenv(config.HostEnvVar, "ghe.corp.example")
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	s, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "ghe.corp.example", s.Host)
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "test-stack",
+	})
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs:     map[string]*model.PR{"1111111111111111": {PRNumber: 101, State: "open"}},
+	}))
+
+	// gh is pointed at another host now, e.g. GH_HOST was unset
+	t.Setenv(config.HostEnvVar, "")
+	require.NoError(t, stackClient.loadConfig())
+
+	hostClient := &gh.MockGithubClient{}
+	hostClient.On("BatchGetPRs", "test-owner", "test-repo", []int{101}).
+		Return(&gh.BatchPRsResult{PRStates: map[int]*gh.PRState{101: {Number: 101, State: "OPEN"}}}, nil).Once()
+	var hosts []string
+	stackClient.SetGithubClientFactory(func(host string) GithubClient {
+		hosts = append(hosts, host)
+		return hostClient
+	})
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	_, err = stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ghe.corp.example"}, hosts)
+	hostClient.AssertExpectations(t)
+	mockGithubClient.AssertNotCalled(t, "BatchGetPRs", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -17,6 +17,7 @@ type StackContext struct {
 	AllChanges         []*model.Change          // Complete history (merged + active)
 	ActiveChanges      []*model.Change          // Only unmerged changes from TOP branch
 	StaleMergedChanges []*model.Change          // Changes merged on GitHub but still on TOP branch
+	DuplicateUUIDs     map[string][]string      // PR-UUID -> commits sharing it (only set by GetStackContextForRepair)
 	currentUUID        string                   // UUID of the current editing position
 	onUUIDBranch       bool                     // Whether positioned on a UUID branch
 	stackActive        bool                     // Whether this stack is the active stack in the repo
@@ -33,6 +34,11 @@ func (s *StackContext) OnUUIDBranch() bool {
 	return s.onUUIDBranch
 }
 
+// OnTopBranch returns true if this stack's TOP branch is checked out (editing the whole stack).
+func (s *StackContext) OnTopBranch() bool {
+	return s.stackActive && !s.onUUIDBranch
+}
+
 // CurrentChange returns the change at the current editing position, or nil if at TOP.
 func (s *StackContext) CurrentChange() *model.Change {
 	if s.currentUUID == "" {
@@ -66,9 +72,51 @@ func (s *StackContext) FindChangeInActive(uuid string) *model.Change {
 	return nil
 }
 
+// ChangeByPosition looks up a change by its 1-indexed position in AllChanges (merged changes
+// included), as shown by 'stack status'. Returns nil if no change has that position.
+func (s *StackContext) ChangeByPosition(pos int) *model.Change {
+	for _, change := range s.AllChanges {
+		if change.Position == pos {
+			return change
+		}
+	}
+	return nil
+}
+
+// ChangeByPRNumber looks up the change whose PR has the given number. Returns nil if no
+// change in the stack has that PR.
+func (s *StackContext) ChangeByPRNumber(n int) *model.Change {
+	if n <= 0 {
+		return nil
+	}
+	for _, change := range s.AllChanges {
+		if !change.IsLocal() && change.PR.PRNumber == n {
+			return change
+		}
+	}
+	return nil
+}
+
 // FormatUUIDBranch returns the branch name for a UUID in this stack.
 func (s *StackContext) FormatUUIDBranch(uuid string) string {
-	return fmt.Sprintf("%s/stack-%s/%s", s.username, s.StackName, uuid)
+	return formatUUIDBranch(s.username, s.StackName, uuid)
+}
+
+// FormatUUIDBranch returns the branch name for a UUID in the stack called stackName, for
+// callers that don't have the stack's context loaded.
+func (c *Client) FormatUUIDBranch(stackName, uuid string) string {
+	return formatUUIDBranch(c.username, stackName, uuid)
+}
+
+// OpenPRNumbers returns the numbers of the pushed PRs in the stack that are still open or draft.
+func (s *StackContext) OpenPRNumbers() []int {
+	var numbers []int
+	for _, change := range s.ActiveChanges {
+		if hasOpenPR(change) {
+			numbers = append(numbers, change.PR.PRNumber)
+		}
+	}
+	return numbers
 }
 
 // Save persists the current state to disk, including PR metadata and stack configuration.
@@ -107,6 +155,10 @@ func formatStackBranch(username, stackName string) string {
 	return fmt.Sprintf("%s/stack-%s/TOP", username, stackName)
 }
 
+func formatUUIDBranch(username, stackName, uuid string) string {
+	return fmt.Sprintf("%s/stack-%s/%s", username, stackName, uuid)
+}
+
 // validateBottomUpMerges ensures that only bottom PRs are merged (no out-of-order merges).
 func validateBottomUpMerges(activeChanges []*model.Change, mergedPRNumbers map[int]bool) error {
 	if len(mergedPRNumbers) == 0 {
@@ -129,12 +181,12 @@ func validateBottomUpMerges(activeChanges []*model.Change, mergedPRNumbers map[i
 		change := activeChanges[i]
 		if !change.IsLocal() && mergedPRNumbers[change.PR.PRNumber] {
 			return fmt.Errorf(
-				"out-of-order merge detected: PR #%d (change #%d) is merged, but change #%d is not\n\n"+
+				"%w: PR #%d (change #%d) is merged, but change #%d is not\n\n"+
 					"Stack requires bottom-up merging. To fix:\n"+
 					"  1. Ask for PR #%d to be reverted, OR\n"+
 					"  2. Manually merge the earlier PRs first, OR\n"+
 					"  3. Use git commands to manually rebase the stack",
-				change.PR.PRNumber, i+1, firstUnmergedIdx+1, change.PR.PRNumber,
+				ErrOutOfOrderMerge, change.PR.PRNumber, i+1, firstUnmergedIdx+1, change.PR.PRNumber,
 			)
 		}
 	}
@@ -144,14 +196,37 @@ func validateBottomUpMerges(activeChanges []*model.Change, mergedPRNumbers map[i
 
 // Branch parsing helpers
 
-func isUUIDBranch(branch string) bool {
-	parts := strings.Split(branch, "/")
-	if len(parts) != 3 || !strings.HasPrefix(parts[1], "stack-") {
-		return false
+// parseStackBranch splits one of this user's stack branches, <username>/stack-<name>/<TOP|uuid>,
+// into the stack name and its last segment (TOP or the change UUID). The username is matched
+// as a prefix rather than by position, so it may contain slashes, and branches of other users
+// or ones that merely have three parts aren't mistaken for stack branches. The one exception
+// is the TOP branch of an imported stack, which keeps the name of the user who exported it.
+func (c *Client) parseStackBranch(branch string) (stackName string, suffix string, ok bool) {
+	if rest, found := strings.CutPrefix(branch, c.username+"/stack-"); found {
+		stackName, suffix, found = strings.Cut(rest, "/")
+		if !found || stackName == "" || (suffix != "TOP" && !validUUID(suffix)) {
+			return "", "", false
+		}
+		return stackName, suffix, true
+	}
+
+	// Stack names can't contain slashes, so the name follows the last "/stack-"
+	rest, found := strings.CutSuffix(branch, "/TOP")
+	idx := strings.LastIndex(rest, "/stack-")
+	if !found || idx <= 0 {
+		return "", "", false
 	}
+	stackName = rest[idx+len("/stack-"):]
+	if stack, err := c.LoadStack(stackName); err != nil || stack.Branch != branch {
+		return "", "", false
+	}
+	return stackName, "TOP", true
+}
 
-	uuid := parts[2]
-	return uuid != "TOP" && validUUID(uuid)
+// IsStackBranch reports whether branch is the TOP or a UUID branch of one of this user's stacks
+func (c *Client) IsStackBranch(branch string) bool {
+	_, _, ok := c.parseStackBranch(branch)
+	return ok
 }
 
 func validUUID(uuid string) bool {
@@ -165,26 +240,3 @@ func validUUID(uuid string) bool {
 	}
 	return true
 }
-
-func extractStackName(branch string) string {
-	parts := strings.Split(branch, "/")
-	if len(parts) != 3 || !strings.HasPrefix(parts[1], "stack-") {
-		return ""
-	}
-
-	suffix := parts[2]
-	if suffix != "TOP" && !validUUID(suffix) {
-		return ""
-	}
-
-	return strings.TrimPrefix(parts[1], "stack-")
-}
-
-func extractUUIDFromBranch(branch string) (stackName string, uuid string) {
-	parts := strings.Split(branch, "/")
-	if len(parts) != 3 || !strings.HasPrefix(parts[1], "stack-") {
-		return "", ""
-	}
-
-	return strings.TrimPrefix(parts[1], "stack-"), parts[2]
-}
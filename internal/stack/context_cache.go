@@ -0,0 +1,147 @@
+package stack
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// cachedContext is a StackContext loaded by getStackContextByName along with the state it was
+// computed from. It's reused as long as none of that state has changed. Callers change the
+// contexts they're given (e.g. SyncPRMetadata updates PR state before deciding whether to
+// save), so the cache keeps its own copy and hands out copies of it.
+type cachedContext struct {
+	ctx           *StackContext
+	currentBranch string // Branch checked out when the context was loaded
+	topHash       string // Commit the stack's TOP branch pointed at
+	baseHash      string // Commit the stack's base pointed at; active commits are computed against it
+}
+
+// cachedStackContext returns the cached context for the stack called name if the TOP branch,
+// the base and the current branch haven't moved since it was loaded. Metadata written by this
+// client invalidates the cache (see InvalidateContext), so only git state has to be checked.
+func (c *Client) cachedStackContext(name string, currentBranch string) *StackContext {
+	c.contextsMu.Lock()
+	cached, ok := c.contexts[name]
+	c.contextsMu.Unlock()
+	if !ok || cached.currentBranch != currentBranch {
+		return nil
+	}
+	topHash, baseHash, err := c.contextHashes(cached.ctx.Stack)
+	if err != nil || topHash != cached.topHash || baseHash != cached.baseHash {
+		c.InvalidateContext(name)
+		return nil
+	}
+	return cached.ctx.clone()
+}
+
+// cacheStackContext remembers a freshly loaded context. Contexts whose branches can't be
+// resolved aren't cached.
+func (c *Client) cacheStackContext(stackCtx *StackContext, currentBranch string) {
+	topHash, baseHash, err := c.contextHashes(stackCtx.Stack)
+	if err != nil {
+		return
+	}
+	c.contextsMu.Lock()
+	defer c.contextsMu.Unlock()
+	if c.contexts == nil {
+		c.contexts = make(map[string]*cachedContext)
+	}
+	c.contexts[stackCtx.StackName] = &cachedContext{
+		ctx:           stackCtx.clone(),
+		currentBranch: currentBranch,
+		topHash:       topHash,
+		baseHash:      baseHash,
+	}
+}
+
+// contextHashes resolves the commits a stack's active changes are computed from
+func (c *Client) contextHashes(stack *model.Stack) (string, string, error) {
+	topHash, err := c.git.GetCommitHash(stack.Branch)
+	if err != nil {
+		return "", "", err
+	}
+	baseRef := stack.BaseRef
+	if baseRef == "" {
+		baseRef = stack.Base
+	}
+	baseHash, err := c.git.ResolveCommit(baseRef)
+	if err != nil {
+		return "", "", err
+	}
+	return topHash, baseHash, nil
+}
+
+// InvalidateContext drops the cached context of the stack called name, so the next
+// GetStackContextByName reloads it from disk. Every method that writes stack metadata calls
+// it; callers only need it after changing a context they don't intend to save.
+func (c *Client) InvalidateContext(name string) {
+	c.contextsMu.Lock()
+	defer c.contextsMu.Unlock()
+	delete(c.contexts, name)
+}
+
+// clone returns a deep copy of the context. Changes and PRs shared between its lists and
+// the changes map stay shared in the copy.
+func (s *StackContext) clone() *StackContext {
+	res := *s
+
+	stack := *s.Stack
+	stack.MergedChanges = slices.Clone(s.Stack.MergedChanges)
+	for i := range stack.MergedChanges {
+		stack.MergedChanges[i].PR = clonePR(stack.MergedChanges[i].PR)
+		stack.MergedChanges[i].Trailers = maps.Clone(stack.MergedChanges[i].Trailers)
+	}
+	stack.PreviousNames = slices.Clone(s.Stack.PreviousNames)
+	res.Stack = &stack
+
+	prs := make(map[*model.PR]*model.PR)
+	changes := make(map[*model.Change]*model.Change)
+	cloneChange := func(change *model.Change) *model.Change {
+		if copied, ok := changes[change]; ok {
+			return copied
+		}
+		copied := *change
+		copied.Trailers = maps.Clone(change.Trailers)
+		if change.PR != nil {
+			if _, ok := prs[change.PR]; !ok {
+				prs[change.PR] = clonePR(change.PR)
+			}
+			copied.PR = prs[change.PR]
+		}
+		changes[change] = &copied
+		return &copied
+	}
+	cloneList := func(list []*model.Change) []*model.Change {
+		if list == nil {
+			return nil
+		}
+		result := make([]*model.Change, len(list))
+		for i, change := range list {
+			result[i] = cloneChange(change)
+		}
+		return result
+	}
+
+	res.AllChanges = cloneList(s.AllChanges)
+	res.ActiveChanges = cloneList(s.ActiveChanges)
+	res.StaleMergedChanges = cloneList(s.StaleMergedChanges)
+	res.changes = make(map[string]*model.Change, len(s.changes))
+	for uuid, change := range s.changes {
+		res.changes[uuid] = cloneChange(change)
+	}
+	res.DuplicateUUIDs = maps.Clone(s.DuplicateUUIDs)
+	return &res
+}
+
+// clonePR returns a copy of pr that shares no slices with it, or nil if pr is nil
+func clonePR(pr *model.PR) *model.PR {
+	if pr == nil {
+		return nil
+	}
+	copied := *pr
+	copied.Labels = slices.Clone(pr.Labels)
+	copied.Reviewers = slices.Clone(pr.Reviewers)
+	return &copied
+}
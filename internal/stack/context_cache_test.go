@@ -0,0 +1,124 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestGetStackContextByName_Cache(t *testing.T) {
+	setup := func(t *testing.T) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "First", "", map[string]string{
+			"PR-UUID":  "1111111111111111",
+			"PR-Stack": "test-stack",
+		})
+		return stackClient, gitClient
+	}
+
+	t.Run("ReturnsCopyWhenUnchanged", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.Equal(t, first, second)
+
+		current, err := stackClient.GetStackContext()
+		require.NoError(t, err)
+		assert.Equal(t, first, current)
+	})
+
+	t.Run("CallersChangesDontLeak", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		// An unsaved change, like a sync that bails out before saving
+		first.ActiveChanges[0].PR = &model.PR{PRNumber: 101, State: "open"}
+		first.Stack.Description = "changed"
+
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.Nil(t, second.ActiveChanges[0].PR)
+		assert.Empty(t, second.Stack.Description)
+
+		// The copy keeps the changes map and the lists pointing at the same changes
+		second.ActiveChanges[0].Title = "Renamed"
+		assert.Equal(t, "Renamed", second.FindChange("1111111111111111").Title)
+		assert.Same(t, second.AllChanges[0], second.ActiveChanges[0])
+	})
+
+	t.Run("ReloadsAfterTopMoves", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, first.ActiveChanges, 1)
+
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Second", "", map[string]string{
+			"PR-UUID":  "2222222222222222",
+			"PR-Stack": "test-stack",
+		})
+
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.Len(t, second.ActiveChanges, 2)
+	})
+
+	t.Run("ReloadsAfterSave", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		first.ActiveChanges[0].PR = &model.PR{PRNumber: 101, State: "open"}
+		require.NoError(t, first.Save())
+
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		require.NotNil(t, second.ActiveChanges[0].PR)
+		assert.Equal(t, 101, second.ActiveChanges[0].PR.PRNumber)
+	})
+
+	t.Run("ReloadsAfterCheckout", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.True(t, first.stackActive)
+
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+		assert.False(t, second.stackActive)
+	})
+
+	t.Run("InvalidateContext", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		first, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		stackClient.InvalidateContext("test-stack")
+
+		second, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.NotSame(t, first, second)
+	})
+}
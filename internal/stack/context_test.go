@@ -157,6 +157,55 @@ func TestStackContext_FindChangeInActive(t *testing.T) {
 	})
 }
 
+// newLookupTestContext builds a context with one merged change below two active ones, the
+// top one not pushed yet
+func newLookupTestContext() (*StackContext, *model.Change, *model.Change, *model.Change) {
+	merged := &model.Change{UUID: "1111111111111111", Title: "Merged change", Position: 1,
+		PR: &model.PR{PRNumber: 101, State: "merged"}}
+	active := &model.Change{UUID: "2222222222222222", Title: "Active change", Position: 2, ActivePosition: 1,
+		PR: &model.PR{PRNumber: 102, State: "open"}}
+	local := &model.Change{UUID: "3333333333333333", Title: "Local change", Position: 3, ActivePosition: 2}
+
+	ctx := &StackContext{
+		AllChanges:    []*model.Change{merged, active, local},
+		ActiveChanges: []*model.Change{active, local},
+	}
+	return ctx, merged, active, local
+}
+
+func TestStackContext_ChangeByPosition(t *testing.T) {
+	ctx, merged, active, local := newLookupTestContext()
+
+	t.Run("positions count merged changes", func(t *testing.T) {
+		assert.Equal(t, merged, ctx.ChangeByPosition(1))
+		assert.Equal(t, active, ctx.ChangeByPosition(2), "position 2 is the first active change")
+		assert.Equal(t, local, ctx.ChangeByPosition(3))
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		assert.Nil(t, ctx.ChangeByPosition(0))
+		assert.Nil(t, ctx.ChangeByPosition(4))
+		assert.Nil(t, ctx.ChangeByPosition(-1))
+	})
+}
+
+func TestStackContext_ChangeByPRNumber(t *testing.T) {
+	ctx, merged, active, _ := newLookupTestContext()
+
+	t.Run("finds merged and open PRs", func(t *testing.T) {
+		assert.Equal(t, merged, ctx.ChangeByPRNumber(101))
+		assert.Equal(t, active, ctx.ChangeByPRNumber(102))
+	})
+
+	t.Run("unknown PR", func(t *testing.T) {
+		assert.Nil(t, ctx.ChangeByPRNumber(999))
+	})
+
+	t.Run("local changes have no PR number", func(t *testing.T) {
+		assert.Nil(t, ctx.ChangeByPRNumber(0))
+	})
+}
+
 func TestStackContext_FormatUUIDBranch(t *testing.T) {
 	ctx := &StackContext{username: "test-user", StackName: "auth-refactor"}
 	assert.Equal(t, "test-user/stack-auth-refactor/1234567890abcdef", ctx.FormatUUIDBranch("1234567890abcdef"))
@@ -344,32 +393,6 @@ func TestValidateBottomUpMerges(t *testing.T) {
 	})
 }
 
-func TestIsUUIDBranch(t *testing.T) {
-	tests := []struct {
-		name     string
-		branch   string
-		expected bool
-	}{
-		{"valid UUID branch", "user/stack-feature/1234567890abcdef", true},
-		{"valid UUID branch uppercase", "user/stack-feature/1234567890ABCDEF", true},
-		{"TOP branch", "user/stack-feature/TOP", false},
-		{"invalid UUID too short", "user/stack-feature/123456", false},
-		{"invalid UUID too long", "user/stack-feature/1234567890abcdef1", false},
-		{"invalid UUID non-hex", "user/stack-feature/123456789012345g", false},
-		{"missing stack prefix", "user/feature/1234567890abcdef", false},
-		{"wrong number of parts", "user-stack-feature/1234567890abcdef", false},
-		{"empty branch", "", false},
-		{"regular branch", "main", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isUUIDBranch(tt.branch)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestValidUUID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -397,85 +420,83 @@ func TestValidUUID(t *testing.T) {
 	}
 }
 
-func TestExtractStackName(t *testing.T) {
-	tests := []struct {
-		name     string
-		branch   string
-		expected string
-	}{
-		{"TOP branch", "user/stack-feature/TOP", "feature"},
-		{"UUID branch", "user/stack-feature/1234567890abcdef", "feature"},
-		{"hyphenated stack", "user/stack-auth-refactor/TOP", "auth-refactor"},
-		{"missing stack prefix", "user/feature/TOP", ""},
-		{"wrong suffix", "user/stack-feature/BOTTOM", ""},
-		{"invalid UUID", "user/stack-feature/invalid", ""},
-		{"too few parts", "user/TOP", ""},
-		{"too many parts", "user/stack-feature/TOP/extra", ""},
-		{"empty branch", "", ""},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractStackName(tt.branch)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
+func TestParseStackBranch(t *testing.T) {
+	stackClient := NewTestStack(t, &gh.MockGithubClient{})
+	stackClient.username = "user"
 
-func TestExtractUUIDFromBranch(t *testing.T) {
 	tests := []struct {
 		name              string
 		branch            string
 		expectedStackName string
-		expectedUUID      string
+		expectedSuffix    string
+		expectedOK        bool
 	}{
-		{
-			name:              "TOP branch",
-			branch:            "user/stack-feature/TOP",
-			expectedStackName: "feature",
-			expectedUUID:      "TOP",
-		},
-		{
-			name:              "UUID branch",
-			branch:            "user/stack-feature/1234567890abcdef",
-			expectedStackName: "feature",
-			expectedUUID:      "1234567890abcdef",
-		},
-		{
-			name:              "hyphenated stack with UUID",
-			branch:            "user/stack-auth-refactor/1234567890abcdef",
-			expectedStackName: "auth-refactor",
-			expectedUUID:      "1234567890abcdef",
-		},
-		{
-			name:              "missing stack prefix",
-			branch:            "user/feature/TOP",
-			expectedStackName: "",
-			expectedUUID:      "",
-		},
-		{
-			name:              "wrong format",
-			branch:            "user-stack-feature",
-			expectedStackName: "",
-			expectedUUID:      "",
-		},
-		{
-			name:              "empty branch",
-			branch:            "",
-			expectedStackName: "",
-			expectedUUID:      "",
-		},
+		{"TOP branch", "user/stack-feature/TOP", "feature", "TOP", true},
+		{"UUID branch", "user/stack-feature/1234567890abcdef", "feature", "1234567890abcdef", true},
+		{"UUID branch uppercase", "user/stack-feature/1234567890ABCDEF", "feature", "1234567890ABCDEF", true},
+		{"hyphenated stack", "user/stack-auth-refactor/1234567890abcdef", "auth-refactor", "1234567890abcdef", true},
+		{"invalid UUID too short", "user/stack-feature/123456", "", "", false},
+		{"invalid UUID too long", "user/stack-feature/1234567890abcdef1", "", "", false},
+		{"invalid UUID non-hex", "user/stack-feature/123456789012345g", "", "", false},
+		{"wrong suffix", "user/stack-feature/BOTTOM", "", "", false},
+		{"missing stack prefix", "user/feature/TOP", "", "", false},
+		{"too few parts", "user/TOP", "", "", false},
+		{"too many parts", "user/stack-feature/TOP/extra", "", "", false},
+		{"wrong format", "user-stack-feature/1234567890abcdef", "", "", false},
+		{"another user's stack", "alice/stack-feature/TOP", "", "", false},
+		{"empty branch", "", "", "", false},
+		{"regular branch", "main", "", "", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stackName, uuid := extractUUIDFromBranch(tt.branch)
+			stackName, suffix, ok := stackClient.parseStackBranch(tt.branch)
+			assert.Equal(t, tt.expectedOK, ok)
 			assert.Equal(t, tt.expectedStackName, stackName)
-			assert.Equal(t, tt.expectedUUID, uuid)
+			assert.Equal(t, tt.expectedSuffix, suffix)
 		})
 	}
 }
 
+func TestIsStackBranch(t *testing.T) {
+	t.Run("MatchesCurrentUser", func(t *testing.T) {
+		stackClient := NewTestStack(t, &gh.MockGithubClient{})
+		stackClient.username = "alice"
+
+		assert.True(t, stackClient.IsStackBranch("alice/stack-x/TOP"))
+		assert.True(t, stackClient.IsStackBranch("alice/stack-x/1234567890abcdef"))
+		assert.False(t, stackClient.IsStackBranch("alice/feature/login"))
+		assert.False(t, stackClient.IsStackBranch("bob/stack-x/TOP"))
+	})
+
+	t.Run("UsernameWithSlash", func(t *testing.T) {
+		stackClient := NewTestStack(t, &gh.MockGithubClient{})
+		stackClient.username = "team/alice"
+
+		assert.True(t, stackClient.IsStackBranch("team/alice/stack-x/TOP"))
+		assert.False(t, stackClient.IsStackBranch("alice/stack-x/TOP"))
+	})
+
+	t.Run("ImportedStackTop", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		stack, err := stackClient.CreateStack("imported", "main")
+		require.NoError(t, err)
+
+		// An imported stack keeps the TOP branch of the user who exported it
+		stack.Branch = "bob/stack-imported/TOP"
+		require.NoError(t, stackClient.SaveStack(stack))
+
+		stackName, suffix, ok := stackClient.parseStackBranch("bob/stack-imported/TOP")
+		assert.True(t, ok)
+		assert.Equal(t, "imported", stackName)
+		assert.Equal(t, "TOP", suffix)
+		assert.False(t, stackClient.IsStackBranch("bob/stack-other/TOP"), "unknown stacks of other users aren't stack branches")
+		assert.False(t, stackClient.IsStackBranch("carol/stack-imported/TOP"), "only the stack's recorded TOP branch matches")
+	})
+}
+
 func TestStackContext_Integration(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
 		mockGithubClient := &gh.MockGithubClient{}
@@ -0,0 +1,63 @@
+package stack
+
+import "fmt"
+
+// GetChangeDiff returns the patch of a single change: its commit against its parent, which is
+// what the change's DesiredBase branch points at once the stack is pushed
+func (c *Client) GetChangeDiff(stackCtx *StackContext, uuid string) (string, error) {
+	from, to, err := c.changeDiffRange(stackCtx, uuid)
+	if err != nil {
+		return "", err
+	}
+	return c.git.Diff(from, to)
+}
+
+// GetChangeDiffStat is GetChangeDiff as a `git diff --stat` summary
+func (c *Client) GetChangeDiffStat(stackCtx *StackContext, uuid string) (string, error) {
+	from, to, err := c.changeDiffRange(stackCtx, uuid)
+	if err != nil {
+		return "", err
+	}
+	return c.git.DiffSummary(from, to)
+}
+
+// GetStackDiff returns the cumulative patch of the whole stack, from Stack.BaseRef to the TOP branch
+func (c *Client) GetStackDiff(stackCtx *StackContext) (string, error) {
+	from, to, err := stackDiffRange(stackCtx)
+	if err != nil {
+		return "", err
+	}
+	return c.git.Diff(from, to)
+}
+
+// GetStackDiffStat is GetStackDiff as a `git diff --stat` summary
+func (c *Client) GetStackDiffStat(stackCtx *StackContext) (string, error) {
+	from, to, err := stackDiffRange(stackCtx)
+	if err != nil {
+		return "", err
+	}
+	return c.git.DiffSummary(from, to)
+}
+
+func (c *Client) changeDiffRange(stackCtx *StackContext, uuid string) (string, string, error) {
+	change := stackCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return "", "", fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+	parent, err := c.git.GetParentCommit(change.CommitHash)
+	if err != nil {
+		return "", "", err
+	}
+	return parent, change.CommitHash, nil
+}
+
+func stackDiffRange(stackCtx *StackContext) (string, string, error) {
+	if !stackCtx.IsStack() {
+		return "", "", fmt.Errorf("not a stack")
+	}
+	from := stackCtx.Stack.BaseRef
+	if from == "" {
+		from = stackCtx.Stack.Base
+	}
+	return from, stackCtx.Stack.Branch, nil
+}
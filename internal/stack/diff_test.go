@@ -0,0 +1,70 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestGetChangeAndStackDiff(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+
+	// Each commit adds its own file-<title>.txt
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "First change", "Body", map[string]string{
+		"PR-UUID":  uuid1,
+		"PR-Stack": "test-stack",
+	})
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Second change", "Body", map[string]string{
+		"PR-UUID":  uuid2,
+		"PR-Stack": "test-stack",
+	})
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	t.Run("ChangeDiffOnlyHasItsOwnChanges", func(t *testing.T) {
+		diff, err := stackClient.GetChangeDiff(stackCtx, uuid2)
+		require.NoError(t, err)
+		assert.Contains(t, diff, "+++ b/file-Second change.txt")
+		assert.Contains(t, diff, "+Second change")
+		assert.NotContains(t, diff, "file-First change.txt")
+	})
+
+	t.Run("ChangeDiffStat", func(t *testing.T) {
+		stat, err := stackClient.GetChangeDiffStat(stackCtx, uuid2)
+		require.NoError(t, err)
+		assert.Contains(t, stat, "file-Second change.txt")
+		assert.Contains(t, stat, "1 file changed, 2 insertions(+)")
+		assert.NotContains(t, stat, "file-First change.txt")
+	})
+
+	t.Run("StackDiffHasEveryChange", func(t *testing.T) {
+		diff, err := stackClient.GetStackDiff(stackCtx)
+		require.NoError(t, err)
+		assert.Contains(t, diff, "+++ b/file-First change.txt")
+		assert.Contains(t, diff, "+++ b/file-Second change.txt")
+
+		stat, err := stackClient.GetStackDiffStat(stackCtx)
+		require.NoError(t, err)
+		assert.Contains(t, stat, "2 files changed, 4 insertions(+)")
+	})
+
+	t.Run("UnknownChange", func(t *testing.T) {
+		_, err := stackClient.GetChangeDiff(stackCtx, "ffffffffffffffff")
+		assert.ErrorContains(t, err, "is not an active change")
+	})
+}
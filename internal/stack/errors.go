@@ -0,0 +1,19 @@
+package stack
+
+import "errors"
+
+// Errors for common failure modes. They are wrapped with details about the operation that
+// failed, so check for them with errors.Is rather than by matching messages.
+var (
+	// ErrNotOnTopBranch is returned by operations that must run on a stack's TOP branch
+	ErrNotOnTopBranch = errors.New("must be on TOP branch")
+
+	// ErrUncommittedChanges is returned by operations that need a clean working tree
+	ErrUncommittedChanges = errors.New("uncommitted changes")
+
+	// ErrStackNotFound is returned when a named stack doesn't exist
+	ErrStackNotFound = errors.New("stack does not exist")
+
+	// ErrOutOfOrderMerge is returned when a PR was merged before the PRs below it in the stack
+	ErrOutOfOrderMerge = errors.New("out-of-order merge detected")
+)
@@ -0,0 +1,100 @@
+package stack
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func setupErrorsTest(t *testing.T) (*Client, *gh.MockGithubClient) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	gitClient := stackClient.git.(*git.Client)
+	for _, uuid := range []string{"1111111111111111", "2222222222222222"} {
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+	return stackClient, mockGithubClient
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("NotOnTopBranch", func(t *testing.T) {
+		stackClient, _ := setupErrorsTest(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.git.CheckoutBranch("main"))
+		stackCtx, err = stackClient.GetStackContext()
+		require.NoError(t, err)
+
+		err = stackClient.ApplyRefresh(stackCtx, nil, false)
+		assert.ErrorIs(t, err, ErrNotOnTopBranch)
+		assert.ErrorContains(t, err, "must be on TOP branch to apply refresh, currently on main")
+	})
+
+	t.Run("UncommittedChanges", func(t *testing.T) {
+		stackClient, _ := setupErrorsTest(t)
+		testutil.WriteFile(t, stackClient.git.GitRoot(), "dirty.txt", "uncommitted")
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		err = stackClient.ApplyRefresh(stackCtx, nil, false)
+		assert.ErrorIs(t, err, ErrUncommittedChanges)
+		assert.ErrorContains(t, err, "cannot apply refresh with uncommitted changes")
+
+		assert.ErrorIs(t, stackClient.DropChanges(stackCtx, stackCtx.ActiveChanges[:1]), ErrUncommittedChanges)
+	})
+
+	t.Run("StackNotFound", func(t *testing.T) {
+		stackClient, _ := setupErrorsTest(t)
+
+		_, err := stackClient.LoadStack("missing")
+		assert.ErrorIs(t, err, ErrStackNotFound)
+		assert.ErrorContains(t, err, "'missing'")
+
+		_, err = stackClient.GetStackContextByName("missing")
+		assert.ErrorIs(t, err, ErrStackNotFound)
+
+		_, err = stackClient.ArchiveStack("missing")
+		assert.ErrorIs(t, err, ErrStackNotFound)
+	})
+
+	t.Run("OutOfOrderMerge", func(t *testing.T) {
+		stackClient, mockGithubClient := setupErrorsTest(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {PRNumber: 101, State: "open"},
+				"2222222222222222": {PRNumber: 102, State: "open"},
+			},
+		}))
+		mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", mock.AnythingOfType("[]int")).Return(&gh.BatchPRsResult{
+			PRStates: map[int]*gh.PRState{
+				101: {Number: 101, State: "OPEN"},
+				102: {Number: 102, State: "MERGED", IsMerged: true},
+			},
+		}, nil)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		_, err = stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrOutOfOrderMerge))
+		assert.False(t, errors.Is(err, ErrUncommittedChanges))
+		assert.ErrorContains(t, err, "out-of-order merge detected: PR #102 (change #2) is merged, but change #1 is not")
+	})
+}
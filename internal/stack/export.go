@@ -0,0 +1,90 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// stackExportVersion is the format version written by ExportStack. ImportStack rejects
+// exports written with any other version.
+const stackExportVersion = 1
+
+// StackExport is a stack's metadata (config.json and prs.json) bundled for sharing
+type StackExport struct {
+	Version int           `json:"version"` // Export format version (currently 1)
+	Stack   *model.Stack  `json:"stack"`   // Contents of config.json
+	PRs     *model.PRData `json:"prs"`     // Contents of prs.json
+}
+
+// ExportStack serializes a stack's config and PR mapping into a single JSON document that
+// ImportStack can recreate in another clone. Git branches aren't included; the importer
+// fetches them from the remote.
+func (c *Client) ExportStack(name string) ([]byte, error) {
+	stack, err := c.LoadStack(name)
+	if err != nil {
+		return nil, err
+	}
+	prData, err := c.LoadPRs(stack.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(StackExport{Version: stackExportVersion, Stack: stack, PRs: prData}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stack export: %w", err)
+	}
+	return data, nil
+}
+
+// ImportStack recreates a stack's metadata from a document written by ExportStack and returns
+// the stack's name. It refuses to replace an existing stack unless force is set, in which case
+// the existing stack's metadata (including undo checkpoints) is discarded. The PRs keep the
+// exporter's head branches, so PushChange refuses to push them unless the UUID branches this
+// clone would push to are the same (see checkPRHead).
+func (c *Client) ImportStack(data []byte, force bool) (string, error) {
+	var export StackExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return "", fmt.Errorf("failed to parse stack export: %w", err)
+	}
+	if export.Version != stackExportVersion {
+		return "", fmt.Errorf("unsupported stack export version %d (expected %d)", export.Version, stackExportVersion)
+	}
+	if export.Stack == nil {
+		return "", fmt.Errorf("stack export has no stack config")
+	}
+	// The name becomes a directory under .git/stack, so this also keeps it from escaping
+	if err := validateStackName(export.Stack.Name); err != nil {
+		return "", err
+	}
+	prData := export.PRs
+	if prData == nil {
+		prData = &model.PRData{Version: 1, PRs: make(map[string]*model.PR)}
+	}
+
+	name := export.Stack.Name
+	if c.StackExists(name) {
+		if !force {
+			return "", fmt.Errorf("stack '%s' already exists: delete it or import with force", name)
+		}
+		c.InvalidateContext(name)
+		if err := os.RemoveAll(c.getStackDir(name)); err != nil {
+			return "", fmt.Errorf("failed to remove existing stack metadata: %w", err)
+		}
+	}
+
+	if err := c.SaveStack(export.Stack); err != nil {
+		return "", err
+	}
+	if err := c.savePRs(name, prData); err != nil {
+		return "", err
+	}
+
+	if !c.git.BranchExists(export.Stack.Branch) {
+		ui.Warningf("Branch %s doesn't exist locally; fetch it before switching to stack '%s'", export.Stack.Branch, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,130 @@
+package stack
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestExportImportStack(t *testing.T) {
+	newClient := func(t *testing.T) *Client {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		return NewTestStack(t, mockGithubClient)
+	}
+
+	// setup creates a stack with two changes, one of which has an open PR, and exports it
+	setup := func(t *testing.T) (*Client, []byte) {
+		stackClient := newClient(t)
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.SetStackDescription("test-stack", "Auth rework"))
+
+		gitClient := stackClient.git.(*git.Client)
+		for _, uuid := range []string{"1111111111111111", "2222222222222222"} {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {
+					PRNumber:  101,
+					URL:       "https://github.com/test-owner/test-repo/pull/101",
+					Branch:    "test-user/stack-test-stack/1111111111111111",
+					State:     "open",
+					Base:      "main",
+					CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+					Labels:    []string{"auth"},
+				},
+			},
+		}))
+
+		data, err := stackClient.ExportStack("test-stack")
+		require.NoError(t, err)
+		return stackClient, data
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		source, data := setup(t)
+
+		var export StackExport
+		require.NoError(t, json.Unmarshal(data, &export))
+		assert.Equal(t, 1, export.Version)
+
+		target := newClient(t)
+		name, err := target.ImportStack(data, false)
+		require.NoError(t, err)
+		assert.Equal(t, "test-stack", name)
+
+		wantStack, err := source.LoadStack("test-stack")
+		require.NoError(t, err)
+		gotStack, err := target.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, wantStack, gotStack)
+		assert.Equal(t, "Auth rework", gotStack.Description)
+
+		wantPRs, err := source.LoadPRs("test-stack")
+		require.NoError(t, err)
+		gotPRs, err := target.LoadPRs("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, wantPRs, gotPRs)
+		assert.Equal(t, 101, gotPRs.PRs["1111111111111111"].PRNumber)
+	})
+
+	t.Run("RefusesExistingStackUnlessForced", func(t *testing.T) {
+		stackClient, data := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: map[string]*model.PR{}}))
+
+		_, err := stackClient.ImportStack(data, false)
+		assert.ErrorContains(t, err, "stack 'test-stack' already exists")
+		prData, err := stackClient.LoadPRs("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, prData.PRs, "a refused import leaves the stack alone")
+
+		_, err = stackClient.ImportStack(data, true)
+		require.NoError(t, err)
+		prData, err = stackClient.LoadPRs("test-stack")
+		require.NoError(t, err)
+		assert.Contains(t, prData.PRs, "1111111111111111")
+	})
+
+	t.Run("ValidatesExport", func(t *testing.T) {
+		_, data := setup(t)
+		target := newClient(t)
+
+		var export StackExport
+		require.NoError(t, json.Unmarshal(data, &export))
+
+		export.Version = 2
+		future, err := json.Marshal(export)
+		require.NoError(t, err)
+		_, err = target.ImportStack(future, false)
+		assert.ErrorContains(t, err, "unsupported stack export version 2 (expected 1)")
+
+		export.Version = 1
+		export.Stack.Name = ".."
+		escaping, err := json.Marshal(export)
+		require.NoError(t, err)
+		_, err = target.ImportStack(escaping, true)
+		assert.ErrorContains(t, err, "invalid stack name '..'")
+
+		_, err = target.ImportStack([]byte(`{"version": 1}`), false)
+		assert.ErrorContains(t, err, "no stack config")
+
+		_, err = target.ImportStack([]byte("not json"), false)
+		assert.ErrorContains(t, err, "failed to parse stack export")
+
+		assert.False(t, target.StackExists("test-stack"))
+	})
+}
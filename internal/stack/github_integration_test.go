@@ -11,7 +11,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
 	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
 )
 
 func TestIsChangeMerged(t *testing.T) {
@@ -548,7 +550,69 @@ func TestMarkChangeReady(t *testing.T) {
 	})
 }
 
+func TestMarkChangesReady_SyncsVisualizationOnce(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Once()
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		var changes []*model.Change
+		changesByUUID := make(map[string]*model.Change)
+		for i := 0; i < 5; i++ {
+			prNumber := 101 + i
+			change := &model.Change{
+				UUID:  fmt.Sprintf("%016d", i+1),
+				Title: fmt.Sprintf("Change %d", i+1),
+				PR: &model.PR{
+					PRNumber:          prNumber,
+					State:             "draft",
+					LocalDraftStatus:  true,
+					RemoteDraftStatus: true,
+				},
+			}
+			changes = append(changes, change)
+			changesByUUID[change.UUID] = change
+
+			// Each PR is toggled once and its visualization comment is created exactly once.
+			// A per-change sync would list/update every PR's comment again on each toggle.
+			mockGithubClient.On("MarkPRReady", prNumber).Return(nil).Once()
+			mockGithubClient.On("ListPRComments", prNumber).Return([]gh.Comment{}, nil).Once()
+			mockGithubClient.On("CreatePRComment", prNumber, mock.AnythingOfType("string")).Return(fmt.Sprintf("comment-%d", prNumber), nil).Once()
+		}
+
+		stackCtx := &StackContext{
+			StackName:     "test-stack",
+			Stack:         stack,
+			changes:       changesByUUID,
+			AllChanges:    changes,
+			ActiveChanges: changes,
+			username:      "test-user",
+			client:        stackClient,
+		}
+
+		results, err := stackClient.MarkChangesReady(stackCtx, changes)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+
+		for i, change := range changes {
+			assert.True(t, results[i].SyncedToGitHub)
+			assert.Equal(t, change.PR.PRNumber, results[i].PRNumber)
+			assert.Equal(t, "open", change.PR.State)
+			assert.False(t, change.PR.LocalDraftStatus)
+			assert.Equal(t, fmt.Sprintf("comment-%d", change.PR.PRNumber), change.PR.VizCommentID)
+		}
+
+		mockGithubClient.AssertExpectations(t)
+		mockGithubClient.AssertNotCalled(t, "UpdatePRComment", mock.Anything, mock.Anything)
+	})
+}
+
 func TestSyncPRMetadata(t *testing.T) {
+	mergedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
 	tests := []struct {
 		name                     string
 		changes                  []*model.Change
@@ -557,6 +621,9 @@ func TestSyncPRMetadata(t *testing.T) {
 		expectedResult           *RefreshResult
 		expectedChanges          []*model.Change
 		expectedStaleMergedUUIDs []string // UUIDs of expected stale merged changes
+		expectedClosedUUIDs      []string // UUIDs of expected closed-not-merged changes
+		expectedBaseDriftUUIDs   []string // UUIDs of expected changes whose base differs on GitHub
+		expectedEditedUUIDs      []string // UUIDs of expected changes whose title or body was edited on GitHub
 	}{
 		{
 			name: "empty stack - no changes",
@@ -750,7 +817,7 @@ func TestSyncPRMetadata(t *testing.T) {
 							Number:   101,
 							State:    "MERGED",
 							IsMerged: true,
-							MergedAt: time.Now(),
+							MergedAt: mergedAt,
 						},
 						102: {
 							Number:   102,
@@ -772,9 +839,11 @@ func TestSyncPRMetadata(t *testing.T) {
 					UUID:     "1111111111111111",
 					Title:    "First PR - merged",
 					Position: 1,
+					MergedAt: mergedAt,
 					PR: &model.PR{
 						PRNumber: 101,
 						State:    "merged",
+						MergedAt: mergedAt,
 					},
 				},
 				{
@@ -824,7 +893,7 @@ func TestSyncPRMetadata(t *testing.T) {
 							Number:   102,
 							State:    "MERGED",
 							IsMerged: true,
-							MergedAt: time.Now(),
+							MergedAt: mergedAt,
 						},
 					},
 				}, nil).Once()
@@ -861,7 +930,7 @@ func TestSyncPRMetadata(t *testing.T) {
 							Number:   101,
 							State:    "MERGED",
 							IsMerged: true,
-							MergedAt: time.Now(),
+							MergedAt: mergedAt,
 						},
 						102: {
 							Number:   102,
@@ -877,14 +946,17 @@ func TestSyncPRMetadata(t *testing.T) {
 				RemainingCount:   1,
 			},
 			expectedStaleMergedUUIDs: []string{"1111111111111111"},
+			expectedClosedUUIDs:      []string{"2222222222222222"},
 			expectedChanges: []*model.Change{
 				{
 					UUID:     "1111111111111111",
 					Title:    "PR 1 - will be merged",
 					Position: 1,
+					MergedAt: mergedAt,
 					PR: &model.PR{
 						PRNumber: 101,
 						State:    "merged",
+						MergedAt: mergedAt,
 					},
 				},
 				{
@@ -898,6 +970,148 @@ func TestSyncPRMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "single closed PR",
+			changes: []*model.Change{
+				{
+					UUID:     "1111111111111111",
+					Title:    "PR 1 - closed",
+					Position: 1,
+					PR: &model.PR{
+						PRNumber: 101,
+						State:    "open",
+					},
+				},
+			},
+			setupMocks: func(m *gh.MockGithubClient, changes []*model.Change) {
+				m.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Once()
+				m.On("BatchGetPRs", "test-owner", "test-repo", []int{101}).Return(&gh.BatchPRsResult{
+					PRStates: map[int]*gh.PRState{
+						101: {
+							Number:   101,
+							State:    "CLOSED",
+							IsMerged: false,
+						},
+					},
+				}, nil).Once()
+			},
+			expectedResult: &RefreshResult{
+				StaleMergedCount: 0,
+				RemainingCount:   1,
+				ClosedCount:      1,
+			},
+			expectedClosedUUIDs: []string{"1111111111111111"},
+			expectedChanges: []*model.Change{
+				{
+					UUID:     "1111111111111111",
+					Title:    "PR 1 - closed",
+					Position: 1,
+					PR: &model.PR{
+						PRNumber: 101,
+						State:    "closed",
+					},
+				},
+			},
+		},
+		{
+			name: "mix of open, closed and merged PRs",
+			changes: []*model.Change{
+				{
+					UUID:     "1111111111111111",
+					Title:    "PR 1 - merged",
+					Position: 1,
+					PR: &model.PR{
+						PRNumber: 101,
+						State:    "open",
+					},
+				},
+				{
+					UUID:     "2222222222222222",
+					Title:    "PR 2 - closed",
+					Position: 2,
+					PR: &model.PR{
+						PRNumber: 102,
+						State:    "open",
+					},
+				},
+				{
+					UUID:     "3333333333333333",
+					Title:    "PR 3 - open",
+					Position: 3,
+					PR: &model.PR{
+						PRNumber: 103,
+						State:    "open",
+					},
+				},
+				{
+					UUID:     "4444444444444444",
+					Title:    "PR 4 - closed",
+					Position: 4,
+					PR: &model.PR{
+						PRNumber: 104,
+						State:    "draft",
+					},
+				},
+			},
+			setupMocks: func(m *gh.MockGithubClient, changes []*model.Change) {
+				m.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Once()
+				m.On("BatchGetPRs", "test-owner", "test-repo", []int{101, 102, 103, 104}).Return(&gh.BatchPRsResult{
+					PRStates: map[int]*gh.PRState{
+						101: {Number: 101, State: "MERGED", IsMerged: true, MergedAt: mergedAt},
+						102: {Number: 102, State: "CLOSED", IsMerged: false},
+						103: {Number: 103, State: "OPEN", IsMerged: false},
+						104: {Number: 104, State: "CLOSED", IsMerged: false},
+					},
+				}, nil).Once()
+			},
+			expectedResult: &RefreshResult{
+				StaleMergedCount: 1,
+				RemainingCount:   3,
+				ClosedCount:      2,
+			},
+			expectedStaleMergedUUIDs: []string{"1111111111111111"},
+			expectedClosedUUIDs:      []string{"2222222222222222", "4444444444444444"},
+			expectedChanges: []*model.Change{
+				{
+					UUID:     "1111111111111111",
+					Title:    "PR 1 - merged",
+					Position: 1,
+					MergedAt: mergedAt,
+					PR: &model.PR{
+						PRNumber: 101,
+						State:    "merged",
+						MergedAt: mergedAt,
+					},
+				},
+				{
+					UUID:     "2222222222222222",
+					Title:    "PR 2 - closed",
+					Position: 2,
+					PR: &model.PR{
+						PRNumber: 102,
+						State:    "closed",
+					},
+				},
+				{
+					UUID:     "3333333333333333",
+					Title:    "PR 3 - open",
+					Position: 3,
+					PR: &model.PR{
+						PRNumber: 103,
+						State:    "open",
+					},
+				},
+				{
+					UUID:     "4444444444444444",
+					Title:    "PR 4 - closed",
+					Position: 4,
+					PR: &model.PR{
+						PRNumber: 104,
+						State:    "closed",
+					},
+				},
+			},
+		},
 		{
 			name: "draft status updates from GitHub",
 			changes: []*model.Change{
@@ -996,6 +1210,7 @@ func TestSyncPRMetadata(t *testing.T) {
 				StaleMergedCount:   0,
 				RemainingCount:     2,
 				StaleMergedChanges: nil,
+				MissingPRs:         []int{102},
 			},
 			expectedChanges: []*model.Change{
 				{
@@ -1016,6 +1231,141 @@ func TestSyncPRMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "base changed on GitHub",
+			changes: []*model.Change{
+				{
+					UUID:        "1111111111111111",
+					Title:       "Bottom change",
+					DesiredBase: "main",
+					PR: &model.PR{
+						PRNumber: 101,
+						State:    "open",
+						Base:     "main",
+					},
+				},
+				{
+					UUID:        "2222222222222222",
+					Title:       "Retargeted change",
+					DesiredBase: "test-user/stack-test-stack/1111111111111111",
+					PR: &model.PR{
+						PRNumber: 102,
+						State:    "open",
+						Base:     "test-user/stack-test-stack/1111111111111111",
+					},
+				},
+			},
+			setupMocks: func(m *gh.MockGithubClient, changes []*model.Change) {
+				m.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Once()
+
+				m.On("BatchGetPRs", "test-owner", "test-repo", []int{101, 102}).Return(&gh.BatchPRsResult{
+					PRStates: map[int]*gh.PRState{
+						101: {Number: 101, State: "OPEN", BaseRefName: "main"},
+						102: {Number: 102, State: "OPEN", BaseRefName: "release"},
+					},
+				}, nil).Once()
+			},
+			expectedResult: &RefreshResult{
+				StaleMergedCount: 0,
+				RemainingCount:   2,
+			},
+			expectedBaseDriftUUIDs: []string{"2222222222222222"},
+			expectedChanges: []*model.Change{
+				{
+					UUID:        "1111111111111111",
+					Title:       "Bottom change",
+					DesiredBase: "main",
+					PR: &model.PR{
+						PRNumber:   101,
+						State:      "open",
+						Base:       "main",
+						RemoteBase: "main",
+					},
+				},
+				{
+					UUID:        "2222222222222222",
+					Title:       "Retargeted change",
+					DesiredBase: "test-user/stack-test-stack/1111111111111111",
+					PR: &model.PR{
+						PRNumber:   102,
+						State:      "open",
+						Base:       "test-user/stack-test-stack/1111111111111111",
+						RemoteBase: "release",
+					},
+				},
+			},
+		},
+		{
+			name: "title and body edited on GitHub",
+			changes: []*model.Change{
+				{
+					UUID:  "1111111111111111",
+					Title: "Unchanged change",
+					PR: &model.PR{
+						PRNumber:    101,
+						State:       "open",
+						Title:       "Unchanged change",
+						RemoteTitle: "Unchanged change",
+						RemoteBody:  "Description",
+						PushedBody:  "Description",
+					},
+				},
+				{
+					UUID:  "2222222222222222",
+					Title: "Edited change",
+					PR: &model.PR{
+						PRNumber:    102,
+						State:       "open",
+						Title:       "Edited change",
+						RemoteTitle: "Edited change",
+						RemoteBody:  "Description",
+						PushedBody:  "Description",
+					},
+				},
+			},
+			setupMocks: func(m *gh.MockGithubClient, changes []*model.Change) {
+				m.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Once()
+
+				m.On("BatchGetPRs", "test-owner", "test-repo", []int{101, 102}).Return(&gh.BatchPRsResult{
+					PRStates: map[int]*gh.PRState{
+						// GitHub returns descriptions saved in its UI with CRLF line endings
+						101: {Number: 101, State: "OPEN", Title: "Unchanged change", Body: "Description\r\n"},
+						102: {Number: 102, State: "OPEN", Title: "Edited in the UI", Body: "Rewritten description"},
+					},
+				}, nil).Once()
+			},
+			expectedResult: &RefreshResult{
+				StaleMergedCount: 0,
+				RemainingCount:   2,
+			},
+			expectedEditedUUIDs: []string{"2222222222222222"},
+			expectedChanges: []*model.Change{
+				{
+					UUID:  "1111111111111111",
+					Title: "Unchanged change",
+					PR: &model.PR{
+						PRNumber:    101,
+						State:       "open",
+						Title:       "Unchanged change",
+						RemoteTitle: "Unchanged change",
+						RemoteBody:  "Description\r\n",
+						PushedBody:  "Description",
+					},
+				},
+				{
+					UUID:  "2222222222222222",
+					Title: "Edited change",
+					PR: &model.PR{
+						PRNumber:    102,
+						State:       "open",
+						Title:       "Edited change",
+						RemoteTitle: "Edited in the UI",
+						RemoteBody:  "Rewritten description",
+						PushedBody:  "Description",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1051,7 +1401,7 @@ func TestSyncPRMetadata(t *testing.T) {
 					client:        stackClient,
 				}
 
-				result, err := stackClient.SyncPRMetadata(stackCtx)
+				result, err := stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
 
 				if tt.expectError != nil {
 					require.Error(t, err)
@@ -1064,6 +1414,7 @@ func TestSyncPRMetadata(t *testing.T) {
 				require.NotNil(t, result)
 				assert.Equal(t, tt.expectedResult.StaleMergedCount, result.StaleMergedCount)
 				assert.Equal(t, tt.expectedResult.RemainingCount, result.RemainingCount)
+				assert.Equal(t, tt.expectedResult.MissingPRs, result.MissingPRs)
 
 				// Verify stale merged UUIDs if expected
 				if len(tt.expectedStaleMergedUUIDs) > 0 {
@@ -1076,6 +1427,29 @@ func TestSyncPRMetadata(t *testing.T) {
 					assert.Empty(t, result.StaleMergedChanges)
 				}
 
+				assert.Equal(t, len(tt.expectedClosedUUIDs), result.ClosedCount)
+				if len(tt.expectedClosedUUIDs) > 0 {
+					actualUUIDs := make([]string, len(result.ClosedChanges))
+					for i, change := range result.ClosedChanges {
+						actualUUIDs[i] = change.UUID
+					}
+					assert.Equal(t, tt.expectedClosedUUIDs, actualUUIDs)
+				} else {
+					assert.Empty(t, result.ClosedChanges)
+				}
+
+				actualDriftUUIDs := make([]string, len(result.BaseDriftChanges))
+				for i, change := range result.BaseDriftChanges {
+					actualDriftUUIDs[i] = change.UUID
+				}
+				assert.ElementsMatch(t, tt.expectedBaseDriftUUIDs, actualDriftUUIDs)
+
+				actualEditedUUIDs := make([]string, len(result.EditedChanges))
+				for i, change := range result.EditedChanges {
+					actualEditedUUIDs[i] = change.UUID
+				}
+				assert.ElementsMatch(t, tt.expectedEditedUUIDs, actualEditedUUIDs)
+
 				assert.False(t, stackCtx.Stack.LastSynced.IsZero())
 
 				assert.Equal(t, tt.expectedChanges, stackCtx.AllChanges)
@@ -1085,3 +1459,113 @@ func TestSyncPRMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncPRMetadata_PersistsMergedAt(t *testing.T) {
+	mergedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+	for _, uuid := range []string{uuid1, uuid2} {
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Change "+uuid[:1], "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+			uuid2: {PRNumber: 102, State: "open"},
+		},
+	}))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", []int{101, 102}).Return(&gh.BatchPRsResult{
+		PRStates: map[int]*gh.PRState{
+			101: {Number: 101, State: "MERGED", IsMerged: true, MergedAt: mergedAt},
+			102: {Number: 102, State: "OPEN"},
+		},
+	}, nil).Once()
+
+	_, err = stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+	require.NoError(t, err)
+
+	// The merge time is saved with the PR and recorded on the stack's merged changes
+	stackCtx, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	merged := stackCtx.FindChange(uuid1)
+	require.NotNil(t, merged)
+	assert.True(t, mergedAt.Equal(merged.PR.MergedAt))
+	assert.True(t, mergedAt.Equal(merged.MergedAt))
+	require.Len(t, stackCtx.Stack.MergedChanges, 1)
+	assert.True(t, mergedAt.Equal(stackCtx.Stack.MergedChanges[0].MergedAt))
+
+	open := stackCtx.FindChange(uuid2)
+	require.NotNil(t, open)
+	assert.True(t, open.PR.MergedAt.IsZero())
+	assert.True(t, open.MergedAt.IsZero())
+}
+
+func TestHoldReady_FirstPushDraftThenReadyAll(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		stack, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.SetHoldReady(stack, true))
+
+		for i := 0; i < 3; i++ {
+			_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  fmt.Sprintf("%016d", i+1),
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.True(t, stackCtx.Stack.HoldReady)
+
+		// Marking a change ready locally does not escape the hold on first push
+		_, err = stackClient.MarkChangesReady(stackCtx, stackCtx.ActiveChanges[:1])
+		require.NoError(t, err)
+
+		// First push: every PR is created as a draft
+		for i, change := range stackCtx.ActiveChanges {
+			draft := stackClient.PushDraftStatus(stackCtx, change)
+			assert.True(t, draft, "change %d should be pushed as draft", i+1)
+
+			change.UpdateFromPush(&gh.PR{Number: 101 + i, State: "draft", IsDraft: draft}, stackCtx.FormatUUIDBranch(change.UUID))
+
+			mockGithubClient.On("MarkPRReady", 101+i).Return(nil).Once()
+			mockGithubClient.On("ListPRComments", 101+i).Return([]gh.Comment{}, nil).Once()
+			mockGithubClient.On("CreatePRComment", 101+i, mock.AnythingOfType("string")).Return(fmt.Sprintf("comment-%d", 101+i), nil).Once()
+		}
+		require.NoError(t, stackCtx.Save())
+
+		// Release the hold and mark the whole stack ready
+		require.NoError(t, stackClient.SetHoldReady(stackCtx.Stack, false))
+		results, err := stackClient.MarkChangesReady(stackCtx, stackCtx.ActiveChanges)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.False(t, stackCtx.Stack.HoldReady)
+		for _, change := range stackCtx.ActiveChanges {
+			assert.Equal(t, "open", change.PR.State)
+			assert.False(t, change.GetDraftStatus())
+		}
+
+		mockGithubClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,74 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// ChangesInBase returns the active changes whose patch is already in the stack's base branch
+// even though their PR isn't merged, e.g. because the same change was merged through another
+// PR or cherry-picked onto the base. Changes with a merged or closed PR are left out, since
+// refresh already handles them. The base is compared as of the last fetch (see baseTip).
+func (c *Client) ChangesInBase(stackCtx *StackContext) ([]*model.Change, error) {
+	inBase, err := c.patchesInBase(stackCtx.Stack)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*model.Change
+	for _, change := range stackCtx.ActiveChanges {
+		if c.IsChangeMerged(change) || (change.PR != nil && change.PR.State == "closed") {
+			continue
+		}
+		if inBase[change.CommitHash] {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// IsChangeInBase reports whether change's patch is already in the stack's base branch. Unlike
+// IsChangeMerged it looks at the commits rather than the PR state, so it also catches changes
+// that landed some other way while their PR is still open.
+func (c *Client) IsChangeInBase(stackCtx *StackContext, change *model.Change) (bool, error) {
+	inBase, err := c.patchesInBase(stackCtx.Stack)
+	if err != nil {
+		return false, err
+	}
+	return inBase[change.CommitHash], nil
+}
+
+// patchesInBase returns the commits on the stack's TOP branch whose patch is in its base tip
+func (c *Client) patchesInBase(stack *model.Stack) (map[string]bool, error) {
+	tip, err := c.baseTip(stack)
+	if err != nil {
+		return nil, err
+	}
+	baseRef := stack.BaseRef
+	if baseRef == "" {
+		baseRef = stack.Base
+	}
+	return c.git.PatchesInUpstream(tip, stack.Branch, baseRef)
+}
+
+// baseTip returns the commit changes are expected to land on: the upstream of the base branch
+// when it has one, otherwise the base branch itself. A base that isn't a branch (a tag or a
+// commit) doesn't move, so its recorded BaseRef is used.
+func (c *Client) baseTip(stack *model.Stack) (string, error) {
+	if !c.git.IsLocalBranch(stack.Base) {
+		if stack.BaseRef != "" {
+			return stack.BaseRef, nil
+		}
+		return stack.Base, nil
+	}
+
+	upstream, err := c.git.GetUpstreamBranch(stack.Base)
+	if err != nil {
+		return "", fmt.Errorf("failed to get upstream of %s: %w", stack.Base, err)
+	}
+	if upstream != "" {
+		return upstream, nil
+	}
+	return stack.Base, nil
+}
@@ -0,0 +1,72 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestChangesInBase(t *testing.T) {
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+	uuid3 := "3333333333333333"
+
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+	hashes := map[string]string{}
+	for _, uuid := range []string{uuid1, uuid2, uuid3} {
+		hashes[uuid] = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+			uuid2: {PRNumber: 102, State: "open"},
+			uuid3: {PRNumber: 103, State: "merged"},
+		},
+	}))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	changes, err := stackClient.ChangesInBase(stackCtx)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "nothing has landed on main yet")
+
+	// The first and third changes land on main through other means (cherry-picked); the
+	// first one's PR is still open
+	require.NoError(t, gitClient.CheckoutBranch("main"))
+	require.NoError(t, gitClient.CherryPick(hashes[uuid1]))
+	require.NoError(t, gitClient.CherryPick(hashes[uuid3]))
+	require.NoError(t, gitClient.CheckoutBranch(stackCtx.Stack.Branch))
+
+	stackCtx, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	changes, err = stackClient.ChangesInBase(stackCtx)
+	require.NoError(t, err)
+	require.Len(t, changes, 1, "the merged change is left to refresh")
+	assert.Equal(t, uuid1, changes[0].UUID)
+	assert.Equal(t, "open", changes[0].PR.State)
+
+	for uuid, expected := range map[string]bool{uuid1: true, uuid2: false, uuid3: true} {
+		inBase, err := stackClient.IsChangeInBase(stackCtx, stackCtx.FindChange(uuid))
+		require.NoError(t, err)
+		assert.Equal(t, expected, inBase, "change %s", uuid)
+		assert.Equal(t, uuid == uuid3, stackClient.IsChangeMerged(stackCtx.FindChange(uuid)))
+	}
+}
@@ -0,0 +1,225 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InsertState records an insert started by PrepareInsertAt. While it exists the post-commit
+// hook leaves commits on the change's UUID branch alone so FinalizeInsert can pick them up.
+type InsertState struct {
+	BelowUUID         string `json:"below_uuid"`          // Change the new commits go on top of
+	BelowCommit       string `json:"below_commit"`        // Commit of that change when the insert started
+	OriginalStackHead string `json:"original_stack_head"` // TOP branch head when the insert started
+}
+
+func (c *Client) getInsertStatePath(stackName string) string {
+	return filepath.Join(c.getStackDir(stackName), "insert-state.json")
+}
+
+// LoadInsertState returns the stack's in-progress insert, or nil if no insert is in progress
+func (c *Client) LoadInsertState(stackName string) (*InsertState, error) {
+	data, err := os.ReadFile(c.getInsertStatePath(stackName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read insert state: %w", err)
+	}
+
+	var state InsertState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse insert state: %w", err)
+	}
+	return &state, nil
+}
+
+// InsertInProgress reports whether PrepareInsertAt was called for the stack without a
+// matching FinalizeInsert or AbortInsert
+func (c *Client) InsertInProgress(stackName string) bool {
+	state, err := c.LoadInsertState(stackName)
+	return err == nil && state != nil
+}
+
+func (c *Client) saveInsertState(stackName string, state *InsertState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal insert state: %w", err)
+	}
+	if err := os.WriteFile(c.getInsertStatePath(stackName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write insert state: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) clearInsertState(stackName string) error {
+	if err := os.Remove(c.getInsertStatePath(stackName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove insert state: %w", err)
+	}
+	return nil
+}
+
+// PrepareInsertAt starts inserting new changes directly above the change with the given UUID.
+// It checks out that change's UUID branch, so the next 'git commit' lands on top of it, and
+// returns the branch name. FinalizeInsert moves the rest of the stack back on top of the new
+// commits; AbortInsert drops them.
+func (c *Client) PrepareInsertAt(stackCtx *StackContext, belowUUID string) (string, error) {
+	if !stackCtx.IsStack() {
+		return "", fmt.Errorf("not on a stack branch")
+	}
+
+	below := stackCtx.FindChangeInActive(belowUUID)
+	if below == nil {
+		return "", fmt.Errorf("change %s is not an active change in stack '%s'", belowUUID, stackCtx.StackName)
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return "", fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return "", fmt.Errorf("cannot insert with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	if c.InsertInProgress(stackCtx.StackName) {
+		return "", fmt.Errorf("an insert into stack '%s' is already in progress: run 'stack insert --continue' or 'stack insert --abort'", stackCtx.StackName)
+	}
+
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get stack head: %w", err)
+	}
+
+	branch := stackCtx.FormatUUIDBranch(belowUUID)
+	if c.git.BranchExists(branch) {
+		if err := c.git.UpdateRef(branch, below.CommitHash); err != nil {
+			return "", fmt.Errorf("failed to update branch %s: %w", branch, err)
+		}
+		if err := c.git.CheckoutBranch(branch); err != nil {
+			return "", fmt.Errorf("failed to checkout branch: %w", err)
+		}
+	} else if err := c.git.CreateAndCheckoutBranchAt(branch, below.CommitHash); err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	if err := c.saveInsertState(stackCtx.StackName, &InsertState{
+		BelowUUID:         belowUUID,
+		BelowCommit:       below.CommitHash,
+		OriginalStackHead: originalHead,
+	}); err != nil {
+		return "", err
+	}
+
+	return branch, nil
+}
+
+// FinalizeInsert finishes an insert started by PrepareInsertAt. Every commit made on the UUID
+// branch becomes a new change with a fresh PR-UUID and the stack's PR-Stack trailer, and the
+// changes that were above the insertion point are rebased on top of them, keeping their
+// PR-UUIDs and PRs. The TOP branch is checked out afterwards.
+func (c *Client) FinalizeInsert(stackName string) error {
+	state, err := c.LoadInsertState(stackName)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("no insert in progress for stack '%s'", stackName)
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("commit or stash your changes before finishing the insert")
+	}
+
+	stackCtx, err := c.GetStackContextByName(stackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	stackHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+	if stackHead != state.OriginalStackHead {
+		return fmt.Errorf("the TOP branch of stack '%s' moved during the insert; run 'stack insert --abort' and start again", stackName)
+	}
+
+	branch := stackCtx.FormatUUIDBranch(state.BelowUUID)
+	inserted, err := c.git.GetCommits(branch, state.BelowCommit)
+	if err != nil {
+		return fmt.Errorf("failed to get inserted commits: %w", err)
+	}
+	if len(inserted) == 0 {
+		return fmt.Errorf("no new commits on %s; commit the change to insert first", branch)
+	}
+
+	// Recreate the commits with stack trailers. A PR-UUID is only kept if it's unique:
+	// amending an existing change on the branch would otherwise duplicate its UUID.
+	parent := state.BelowCommit
+	for _, commit := range inserted {
+		uuid := commit.Message.Trailers["PR-UUID"]
+		if uuid == "" || stackCtx.FindChange(uuid) != nil {
+			uuid = GenerateUUID()
+		}
+		msg := commit.Message
+		msg.Trailers = withStackTrailers(msg.Trailers, uuid, stackName)
+
+		tree, err := c.git.GetCommitTree(commit.Hash)
+		if err != nil {
+			return err
+		}
+		parent, err = c.git.CommitTreeAs(tree, parent, msg.String(), commit.Meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+		StackName:         stackName,
+		StackBranch:       stackCtx.Stack.Branch,
+		OldCommitHash:     state.BelowCommit,
+		NewCommitHash:     parent,
+		OriginalStackHead: state.OriginalStackHead,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := c.UpdateUUIDBranches(stackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+
+	return c.clearInsertState(stackName)
+}
+
+// AbortInsert abandons an insert started by PrepareInsertAt, dropping any commits made on the
+// UUID branch and checking out the TOP branch
+func (c *Client) AbortInsert(stackName string) error {
+	state, err := c.LoadInsertState(stackName)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("no insert in progress for stack '%s'", stackName)
+	}
+
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	if err := c.git.CheckoutBranch(stack.Branch); err != nil {
+		return err
+	}
+	branch := c.FormatUUIDBranch(stackName, state.BelowUUID)
+	if c.git.BranchExists(branch) {
+		if err := c.git.UpdateRef(branch, state.BelowCommit); err != nil {
+			return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+		}
+	}
+
+	return c.clearInsertState(stackName)
+}
@@ -0,0 +1,147 @@
+package stack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestInsertChange(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+
+	setup := func(t *testing.T) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		for i, uuid := range uuids {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), fmt.Sprintf("Body %d", i+1), map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		err = stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuids[0]: {PRNumber: 101, State: "open"},
+				uuids[1]: {PRNumber: 102, State: "open"},
+				uuids[2]: {PRNumber: 103, State: "open"},
+			},
+		})
+		require.NoError(t, err)
+		return stackClient, gitClient
+	}
+
+	t.Run("BetweenFirstAndSecond", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		branch, err := stackClient.PrepareInsertAt(stackCtx, uuids[0])
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.FormatUUIDBranch(uuids[0]), branch)
+
+		currentBranch, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, branch, currentBranch)
+
+		// A commit made without hooks has no trailers; a teammate's keeps its author
+		createCommitByOtherAuthor(t, gitClient, "Inserted change", "Inserted body", nil)
+
+		require.NoError(t, stackClient.FinalizeInsert("test-stack"))
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 4)
+
+		inserted := stackCtx.ActiveChanges[1]
+		assert.Equal(t, "Inserted change", inserted.Title)
+		assert.Equal(t, 2, inserted.Position)
+		assert.NotEmpty(t, inserted.UUID)
+		assert.NotContains(t, uuids, inserted.UUID)
+		assert.Nil(t, inserted.PR, "the inserted change has no PR until it is pushed")
+		assertOtherAuthor(t, gitClient, inserted.CommitHash)
+
+		expected := []struct {
+			uuid     string
+			title    string
+			prNumber int
+		}{
+			{uuids[0], "Change 1", 101},
+			{uuids[1], "Change 2", 102},
+			{uuids[2], "Change 3", 103},
+		}
+		for i, change := range []*model.Change{stackCtx.ActiveChanges[0], stackCtx.ActiveChanges[2], stackCtx.ActiveChanges[3]} {
+			assert.Equal(t, expected[i].uuid, change.UUID)
+			assert.Equal(t, expected[i].title, change.Title)
+			require.NotNil(t, change.PR)
+			assert.Equal(t, expected[i].prNumber, change.PR.PRNumber)
+		}
+		for i, change := range stackCtx.ActiveChanges {
+			assert.Equal(t, i+1, change.Position)
+
+			commit, err := gitClient.GetCommit(change.CommitHash)
+			require.NoError(t, err)
+			assert.Equal(t, change.UUID, commit.Message.Trailers["PR-UUID"])
+			assert.Equal(t, "test-stack", commit.Message.Trailers["PR-Stack"])
+		}
+
+		branchHash, err := gitClient.GetCommitHash(branch)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[0].CommitHash, branchHash, "the UUID branch should be back on its change")
+
+		currentBranch, err = gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+		assert.False(t, stackClient.InsertInProgress("test-stack"))
+	})
+
+	t.Run("NoNewCommits", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+
+		_, err = stackClient.PrepareInsertAt(stackCtx, uuids[0])
+		require.NoError(t, err)
+
+		err = stackClient.FinalizeInsert("test-stack")
+		assert.ErrorContains(t, err, "no new commits")
+		assert.True(t, stackClient.InsertInProgress("test-stack"))
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		originalHead, err := gitClient.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		branch, err := stackClient.PrepareInsertAt(stackCtx, uuids[0])
+		require.NoError(t, err)
+		testutil.CreateCommitWithTrailers(t, gitClient, "Inserted change", "Inserted body", nil)
+
+		require.NoError(t, stackClient.AbortInsert("test-stack"))
+
+		head, err := gitClient.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, originalHead, head)
+
+		branchHash, err := gitClient.GetCommitHash(branch)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[0].CommitHash, branchHash)
+		assert.False(t, stackClient.InsertInProgress("test-stack"))
+	})
+}
@@ -0,0 +1,155 @@
+package stack
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bjulian5/stack/internal/git"
+)
+
+// IntegritySeverity says how serious an IntegrityIssue is
+type IntegritySeverity string
+
+const (
+	IntegrityError   IntegritySeverity = "error"   // The stack can't be pushed or refreshed reliably until this is fixed
+	IntegrityWarning IntegritySeverity = "warning" // Leftover or stale metadata that commands work around
+)
+
+// IntegrityCheck names the check that reported an IntegrityIssue
+type IntegrityCheck string
+
+const (
+	CheckTrailers   IntegrityCheck = "trailers"    // Active commits carry PR-UUID and PR-Stack trailers for this stack
+	CheckOrphanedPR IntegrityCheck = "orphaned-pr" // Every prs.json entry belongs to a known change
+	CheckUUIDBranch IntegrityCheck = "uuid-branch" // UUID branches point at their change's commit
+	CheckBaseRef    IntegrityCheck = "base-ref"    // BaseRef is an ancestor of the TOP branch
+	CheckMergeOrder IntegrityCheck = "merge-order" // PRs were merged bottom-up
+)
+
+// IntegrityIssue is a problem found by ValidateStackIntegrity
+type IntegrityIssue struct {
+	Severity IntegritySeverity
+	Check    IntegrityCheck
+	UUID     string // Change the issue is about (empty for stack-wide issues)
+	Message  string
+}
+
+// ValidateStackIntegrity checks a stack's metadata against git without changing anything.
+// It reads the stack's commits and prs.json directly rather than building a StackContext, so
+// it also works on stacks that are too broken to load. Returns the issues found, in the order
+// the checks ran.
+func (c *Client) ValidateStackIntegrity(stackName string) ([]IntegrityIssue, error) {
+	s, err := c.LoadStack(stackName)
+	if err != nil {
+		return nil, err
+	}
+	prData, err := c.loadPRs(s.Name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []IntegrityIssue
+	report := func(severity IntegritySeverity, check IntegrityCheck, uuid string, format string, args ...any) {
+		issues = append(issues, IntegrityIssue{Severity: severity, Check: check, UUID: uuid, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !c.git.BranchExists(s.Branch) {
+		report(IntegrityError, CheckBaseRef, "", "TOP branch %s doesn't exist", s.Branch)
+		return issues, nil
+	}
+
+	baseRef := s.BaseRef
+	if baseRef == "" {
+		baseRef = s.Base
+	}
+	if s.BaseRef != "" && !c.git.IsAncestor(s.BaseRef, s.Branch) {
+		report(IntegrityError, CheckBaseRef, "", "base ref %s is not an ancestor of %s: run 'stack restack'", git.ShortHash(s.BaseRef), s.Branch)
+		return issues, nil
+	}
+
+	commits, err := c.git.GetCommits(s.Branch, baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	// Trailers: every commit between the base and TOP must belong to this stack
+	commitsByUUID := make(map[string]git.Commit, len(commits))
+	for i, commit := range commits {
+		uuid := commit.Message.Trailers["PR-UUID"]
+		stackTrailer := commit.Message.Trailers["PR-Stack"]
+		label := fmt.Sprintf("commit #%d %s (%s)", i+1, git.ShortHash(commit.Hash), commit.Message.Title)
+
+		switch {
+		case uuid == "":
+			report(IntegrityError, CheckTrailers, "", "%s has no PR-UUID trailer", label)
+		case stackTrailer == "":
+			report(IntegrityError, CheckTrailers, uuid, "%s has no PR-Stack trailer", label)
+		case !s.HasStackTrailer(stackTrailer):
+			report(IntegrityError, CheckTrailers, uuid, "%s has PR-Stack trailer '%s', expected '%s'", label, stackTrailer, s.Name)
+		}
+
+		if uuid != "" {
+			commitsByUUID[uuid] = commit
+		}
+	}
+
+	// Orphaned PRs: prs.json entries must map to an active or merged change
+	merged := make(map[string]bool, len(s.MergedChanges))
+	for _, change := range s.MergedChanges {
+		merged[change.UUID] = true
+	}
+	for _, uuid := range slices.Sorted(maps.Keys(prData.PRs)) {
+		if _, ok := commitsByUUID[uuid]; ok || merged[uuid] {
+			continue
+		}
+		report(IntegrityWarning, CheckOrphanedPR, uuid, "prs.json has PR #%d for unknown change %s", prData.PRs[uuid].PRNumber, uuid)
+	}
+
+	// UUID branches: each must point at its change's commit
+	branches, err := c.GetStackBranches(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		_, uuid, ok := c.parseStackBranch(branch)
+		if !ok || uuid == "TOP" {
+			continue
+		}
+		commit, ok := commitsByUUID[uuid]
+		if !ok {
+			if !merged[uuid] {
+				report(IntegrityWarning, CheckUUIDBranch, uuid, "branch %s doesn't belong to any change", branch)
+			}
+			continue
+		}
+		hash, err := c.git.GetCommitHash(branch)
+		if err != nil {
+			return nil, err
+		}
+		if hash != commit.Hash {
+			report(IntegrityWarning, CheckUUIDBranch, uuid, "branch %s is at %s, but its change is at %s", branch, git.ShortHash(hash), git.ShortHash(commit.Hash))
+		}
+	}
+
+	// Merge order: no merged PR may sit above an unmerged change
+	firstUnmerged := -1
+	for i, commit := range commits {
+		uuid := commit.Message.Trailers["PR-UUID"]
+		if uuid == "" {
+			continue
+		}
+		pr := prData.PRs[uuid]
+		if !pr.IsMerged() {
+			if firstUnmerged == -1 {
+				firstUnmerged = i
+			}
+			continue
+		}
+		if firstUnmerged != -1 {
+			report(IntegrityError, CheckMergeOrder, uuid, "PR #%d (commit #%d) is merged, but commit #%d below it is not", pr.PRNumber, i+1, firstUnmerged+1)
+		}
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,174 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestValidateStackIntegrity(t *testing.T) {
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+
+	// setup creates a healthy stack with two changes, each with a UUID branch
+	setup := func(t *testing.T) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		gitClient := stackClient.git.(*git.Client)
+		for _, uuid := range []string{uuid1, uuid2} {
+			hash := testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+			require.NoError(t, gitClient.CreateBranchAt("test-user/stack-test-stack/"+uuid, hash))
+		}
+		return stackClient, gitClient
+	}
+
+	type summary struct {
+		Severity IntegritySeverity
+		Check    IntegrityCheck
+		UUID     string
+	}
+	summarize := func(issues []IntegrityIssue) []summary {
+		var result []summary
+		for _, issue := range issues {
+			result = append(result, summary{issue.Severity, issue.Check, issue.UUID})
+		}
+		return result
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuid1: {PRNumber: 101, State: "merged"},
+				uuid2: {PRNumber: 102, State: "open"},
+			},
+		}))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("Trailers", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "No trailers", "", nil)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "No stack", "", map[string]string{"PR-UUID": "3333333333333333"})
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Other stack", "", map[string]string{
+			"PR-UUID":  "4444444444444444",
+			"PR-Stack": "other-stack",
+		})
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []summary{
+			{IntegrityError, CheckTrailers, ""},
+			{IntegrityError, CheckTrailers, "3333333333333333"},
+			{IntegrityError, CheckTrailers, "4444444444444444"},
+		}, summarize(issues))
+		assert.Contains(t, issues[0].Message, "commit #3")
+		assert.Contains(t, issues[0].Message, "(No trailers) has no PR-UUID trailer")
+		assert.Contains(t, issues[1].Message, "has no PR-Stack trailer")
+		assert.Contains(t, issues[2].Message, "has PR-Stack trailer 'other-stack', expected 'test-stack'")
+	})
+
+	t.Run("OrphanedPR", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		s, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		s.MergedChanges = []model.Change{{UUID: "0000000000000001", Title: "Merged"}}
+		require.NoError(t, stackClient.SaveStack(s))
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"0000000000000001": {PRNumber: 100, State: "merged"},
+				uuid1:              {PRNumber: 101, State: "open"},
+				"9999999999999999": {PRNumber: 199, State: "open"},
+			},
+		}))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []summary{{IntegrityWarning, CheckOrphanedPR, "9999999999999999"}}, summarize(issues))
+		assert.Equal(t, "prs.json has PR #199 for unknown change 9999999999999999", issues[0].Message)
+	})
+
+	t.Run("UUIDBranch", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		require.NoError(t, gitClient.UpdateRef("test-user/stack-test-stack/"+uuid2, "main"))
+		require.NoError(t, gitClient.CreateBranchAt("test-user/stack-test-stack/9999999999999999", "main"))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []summary{
+			{IntegrityWarning, CheckUUIDBranch, uuid2},
+			{IntegrityWarning, CheckUUIDBranch, "9999999999999999"},
+		}, summarize(issues))
+	})
+
+	t.Run("BaseRef", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		tree, err := gitClient.GetCommitTree("main")
+		require.NoError(t, err)
+		mainHash, err := gitClient.GetCommitHash("main")
+		require.NoError(t, err)
+		sideCommit, err := gitClient.CommitTree(tree, mainHash, "Side commit")
+		require.NoError(t, err)
+
+		s, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		s.BaseRef = sideCommit
+		require.NoError(t, stackClient.SaveStack(s))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []summary{{IntegrityError, CheckBaseRef, ""}}, summarize(issues))
+		assert.Contains(t, issues[0].Message, "is not an ancestor of test-user/stack-test-stack/TOP")
+	})
+
+	t.Run("MissingTOPBranch", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		require.NoError(t, gitClient.DeleteBranch("test-user/stack-test-stack/TOP", true))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []summary{{IntegrityError, CheckBaseRef, ""}}, summarize(issues))
+		assert.Equal(t, "TOP branch test-user/stack-test-stack/TOP doesn't exist", issues[0].Message)
+	})
+
+	t.Run("MergeOrder", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				uuid1: {PRNumber: 101, State: "open"},
+				uuid2: {PRNumber: 102, State: "merged"},
+			},
+		}))
+
+		issues, err := stackClient.ValidateStackIntegrity("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, []summary{{IntegrityError, CheckMergeOrder, uuid2}}, summarize(issues))
+		assert.Equal(t, "PR #102 (commit #2) is merged, but commit #1 below it is not", issues[0].Message)
+	})
+
+	t.Run("StackNotFound", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		_, err := stackClient.ValidateStackIntegrity("missing")
+		assert.ErrorIs(t, err, ErrStackNotFound)
+	})
+}
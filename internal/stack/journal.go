@@ -0,0 +1,178 @@
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxJournalEntries is how many checkpoints are kept per stack; older ones are dropped
+const maxJournalEntries = 20
+
+// JournalEntry records the state of a stack before a history-rewriting operation, so the
+// operation can be undone with UndoLast
+type JournalEntry struct {
+	Label     string    `json:"label"`          // Operation that was about to run (e.g. "refresh")
+	Head      string    `json:"head"`           // Commit of the TOP branch before the operation
+	Base      string    `json:"base,omitempty"` // Base branch before the operation
+	BaseRef   string    `json:"base_ref"`       // Commit of the base the stack was built on
+	Timestamp time.Time `json:"timestamp"`      // When the checkpoint was taken
+}
+
+func (c *Client) getJournalPath(stackName string) string {
+	return filepath.Join(c.getStackDir(stackName), "journal.jsonl")
+}
+
+// LoadJournal returns the stack's checkpoints, oldest first
+func (c *Client) LoadJournal(stackName string) ([]JournalEntry, error) {
+	data, err := os.ReadFile(c.getJournalPath(stackName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []JournalEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	entries := []JournalEntry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// saveJournal rewrites the journal with entries, keeping only the newest maxJournalEntries
+func (c *Client) saveJournal(stackName string, entries []JournalEntry) error {
+	if len(entries) > maxJournalEntries {
+		entries = entries[len(entries)-maxJournalEntries:]
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal journal entry: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.WriteFile(c.getJournalPath(stackName), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// RecordCheckpoint appends the stack's current TOP commit and base to its journal, labelled
+// with the operation about to run. Call it before rewriting the stack's history so
+// UndoLast can put the stack back.
+func (c *Client) RecordCheckpoint(stackName string, label string) error {
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	head, err := c.git.GetCommitHash(stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+
+	entries, err := c.LoadJournal(stackName)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, JournalEntry{
+		Label:     label,
+		Head:      head,
+		Base:      stack.Base,
+		BaseRef:   stack.BaseRef,
+		Timestamp: time.Now(),
+	})
+	return c.saveJournal(stackName, entries)
+}
+
+// LastCheckpoint returns the checkpoint UndoLast would restore, or nil if there is none
+func (c *Client) LastCheckpoint(stackName string) (*JournalEntry, error) {
+	entries, err := c.LoadJournal(stackName)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[len(entries)-1], nil
+}
+
+// UndoLast restores the stack to its most recent checkpoint: the TOP branch is reset to the
+// recorded commit, the base and BaseRef are restored, and the UUID branches are moved back.
+// The checkpoint is then removed, so repeated calls step further back. Requires no
+// uncommitted changes and no rebase in progress. PR metadata and remote branches are left
+// alone; push again to update the PRs.
+func (c *Client) UndoLast(stackName string) error {
+	entries, err := c.LoadJournal(stackName)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("nothing to undo for stack '%s'", stackName)
+	}
+	entry := entries[len(entries)-1]
+
+	if c.git.IsRebaseInProgress() {
+		return fmt.Errorf("a rebase is in progress; finish it or run 'git rebase --abort' before undoing")
+	}
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("cannot undo with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	stack, err := c.LoadStack(stackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	currentBranch, err := c.git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if currentBranch == stack.Branch {
+		err = c.git.ResetHard(entry.Head)
+	} else {
+		err = c.git.UpdateRef(stack.Branch, entry.Head)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", stack.Branch, err)
+	}
+
+	if entry.Base != "" {
+		stack.Base = entry.Base
+	}
+	stack.BaseRef = entry.BaseRef
+	if err := c.SaveStack(stack); err != nil {
+		return fmt.Errorf("failed to update stack metadata: %w", err)
+	}
+
+	if _, err := c.UpdateUUIDBranches(stackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+
+	return c.saveJournal(stackName, entries[:len(entries)-1])
+}
@@ -0,0 +1,132 @@
+package stack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func setupJournalTest(t *testing.T) (*Client, *git.Client) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	gitClient := stackClient.git.(*git.Client)
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "First change", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "test-stack",
+	})
+	return stackClient, gitClient
+}
+
+func TestUndoLast(t *testing.T) {
+	t.Run("RestoresTopBranch", func(t *testing.T) {
+		stackClient, gitClient := setupJournalTest(t)
+
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		before, err := gitClient.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+
+		require.NoError(t, stackClient.RecordCheckpoint("test-stack", "squash"))
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Second change", "", map[string]string{
+			"PR-UUID":  "2222222222222222",
+			"PR-Stack": "test-stack",
+		})
+
+		entry, err := stackClient.LastCheckpoint("test-stack")
+		require.NoError(t, err)
+		require.NotNil(t, entry)
+		assert.Equal(t, "squash", entry.Label)
+		assert.Equal(t, before, entry.Head)
+
+		require.NoError(t, stackClient.UndoLast("test-stack"))
+
+		after, err := gitClient.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+
+		// The checkpoint was used up
+		entry, err = stackClient.LastCheckpoint("test-stack")
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+		assert.ErrorContains(t, stackClient.UndoLast("test-stack"), "nothing to undo")
+	})
+
+	t.Run("RestoresBase", func(t *testing.T) {
+		stackClient, gitClient := setupJournalTest(t)
+
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		require.NoError(t, stackClient.RecordCheckpoint("test-stack", "restack"))
+
+		// Simulate a restack onto another branch
+		require.NoError(t, gitClient.CreateBranchAt("develop", "main"))
+		stack.Base = "develop"
+		stack.BaseRef = "0000000000000000000000000000000000000000"
+		require.NoError(t, stackClient.SaveStack(stack))
+
+		require.NoError(t, stackClient.UndoLast("test-stack"))
+
+		restored, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, "main", restored.Base)
+		mainHash, err := gitClient.GetCommitHash("main")
+		require.NoError(t, err)
+		assert.Equal(t, mainHash, restored.BaseRef)
+	})
+
+	t.Run("NotOnTopBranch", func(t *testing.T) {
+		stackClient, gitClient := setupJournalTest(t)
+
+		stack, err := stackClient.LoadStack("test-stack")
+		require.NoError(t, err)
+		before, err := gitClient.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+
+		require.NoError(t, stackClient.RecordCheckpoint("test-stack", "reorder"))
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Second change", "", nil)
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+
+		require.NoError(t, stackClient.UndoLast("test-stack"))
+
+		after, err := gitClient.GetCommitHash(stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+		current, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "main", current, "undo shouldn't switch branches")
+	})
+
+	t.Run("UncommittedChanges", func(t *testing.T) {
+		stackClient, gitClient := setupJournalTest(t)
+
+		require.NoError(t, stackClient.RecordCheckpoint("test-stack", "refresh"))
+		testutil.WriteFile(t, gitClient.GitRoot(), "dirty.txt", "uncommitted")
+
+		assert.ErrorContains(t, stackClient.UndoLast("test-stack"), "uncommitted changes")
+	})
+}
+
+func TestRecordCheckpoint_CapsJournal(t *testing.T) {
+	stackClient, _ := setupJournalTest(t)
+
+	for i := range maxJournalEntries + 5 {
+		require.NoError(t, stackClient.RecordCheckpoint("test-stack", fmt.Sprintf("op-%d", i)))
+	}
+
+	entries, err := stackClient.LoadJournal("test-stack")
+	require.NoError(t, err)
+	require.Len(t, entries, maxJournalEntries)
+	assert.Equal(t, "op-5", entries[0].Label, "the oldest entries are dropped")
+	assert.Equal(t, fmt.Sprintf("op-%d", maxJournalEntries+4), entries[len(entries)-1].Label)
+}
@@ -0,0 +1,104 @@
+package stack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// GetMergeableRun returns the longest run of changes from the bottom of the stack that can be
+// merged now: each has an open (non-draft) PR that is approved and whose checks pass. PRs are
+// queried bottom-up and the run stops at the first change that isn't ready, so PRs above it
+// are never queried. Merging the run in order keeps the stack's bottom-up merge invariant.
+func (c *Client) GetMergeableRun(stackCtx *StackContext) ([]*model.Change, error) {
+	if len(stackCtx.StaleMergedChanges) > 0 {
+		return nil, fmt.Errorf("%d change(s) are merged but still on the stack branch: run 'stack refresh' first", len(stackCtx.StaleMergedChanges))
+	}
+
+	mergedPRNumbers := make(map[int]bool)
+	for _, change := range stackCtx.AllChanges {
+		if change.PR.IsMerged() {
+			mergedPRNumbers[change.PR.PRNumber] = true
+		}
+	}
+	if err := validateBottomUpMerges(stackCtx.AllChanges, mergedPRNumbers); err != nil {
+		return nil, err
+	}
+
+	var run []*model.Change
+	for _, change := range stackCtx.ActiveChanges {
+		ready, err := c.isReadyToMerge(c.ghFor(stackCtx.Stack), change)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			break
+		}
+		run = append(run, change)
+	}
+	return run, nil
+}
+
+// isReadyToMerge reports whether a change's PR is open, approved and passing its checks
+func (c *Client) isReadyToMerge(github GithubClient, change *model.Change) (bool, error) {
+	if change.IsLocal() || change.PR.State != "open" || change.PR.RemoteDraftStatus {
+		return false, nil
+	}
+
+	decision, err := github.GetPRReview(change.PR.PRNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to get reviews for PR #%d: %w", change.PR.PRNumber, err)
+	}
+	if decision != gh.ReviewApproved {
+		return false, nil
+	}
+
+	checks, err := github.GetPRChecks(change.PR.PRNumber)
+	if err != nil {
+		return false, fmt.Errorf("failed to get checks for PR #%d: %w", change.PR.PRNumber, err)
+	}
+	return checks.Failed == 0 && checks.Pending == 0, nil
+}
+
+// MergeChange merges the PR of a change in stackCtx on GitHub with method ("squash", "merge"
+// or "rebase")
+func (c *Client) MergeChange(stackCtx *StackContext, change *model.Change, method string) error {
+	if !slices.Contains(gh.MergeMethods, method) {
+		return fmt.Errorf("invalid merge method '%s': must be one of %s", method, strings.Join(gh.MergeMethods, ", "))
+	}
+	if change.IsLocal() {
+		return fmt.Errorf("change '%s' has no PR to merge", change.Title)
+	}
+	return c.ghFor(stackCtx.Stack).MergePR(change.PR.PRNumber, method)
+}
+
+// MergeReadyChanges merges the stack's mergeable run (see GetMergeableRun) bottom-up. Before
+// each PR above the first is merged it is retargeted at the stack's base, since the PR below
+// it, which it was based on, has just been merged. Returns the changes that were merged; on
+// error these are the changes merged before the failure. Run 'stack refresh' afterwards to
+// drop the merged commits from the stack branch.
+func (c *Client) MergeReadyChanges(stackCtx *StackContext, method string) ([]*model.Change, error) {
+	run, err := c.GetMergeableRun(stackCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []*model.Change
+	for i, change := range run {
+		if i > 0 {
+			if err := c.ghFor(stackCtx.Stack).UpdatePRBase(change.PR.PRNumber, stackCtx.Stack.Base); err != nil {
+				return merged, fmt.Errorf("failed to retarget PR #%d at %s: %w", change.PR.PRNumber, stackCtx.Stack.Base, err)
+			}
+			change.PR.SetBase(stackCtx.Stack.Base)
+		}
+
+		if err := c.MergeChange(stackCtx, change, method); err != nil {
+			return merged, fmt.Errorf("failed to merge PR #%d: %w", change.PR.PRNumber, err)
+		}
+		merged = append(merged, change)
+	}
+	return merged, nil
+}
@@ -0,0 +1,210 @@
+package stack
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// newMergeTestContext builds a stack context whose active changes have the given PRs,
+// bottom first. A nil PR makes the change local.
+func newMergeTestContext(t *testing.T, prs ...*model.PR) (*Client, *gh.MockGithubClient, *StackContext) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil).Maybe()
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	changes := make([]*model.Change, len(prs))
+	changeMap := make(map[string]*model.Change)
+	for i, pr := range prs {
+		changes[i] = &model.Change{
+			UUID:     fmt.Sprintf("%016d", i+1),
+			Title:    fmt.Sprintf("Change %d", i+1),
+			Position: i + 1,
+			PR:       pr,
+		}
+		changeMap[changes[i].UUID] = changes[i]
+	}
+
+	return stackClient, mockGithubClient, &StackContext{
+		StackName:     "test-stack",
+		Stack:         stack,
+		changes:       changeMap,
+		AllChanges:    changes,
+		ActiveChanges: changes,
+		username:      "test-user",
+		client:        stackClient,
+	}
+}
+
+func TestGetMergeableRun(t *testing.T) {
+	passing := &gh.ChecksSummary{Passed: 2, Total: 2}
+
+	t.Run("StopsAtFirstUnapproved", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open"},
+			&model.PR{PRNumber: 2, State: "open"},
+			&model.PR{PRNumber: 3, State: "open"},
+			&model.PR{PRNumber: 4, State: "open"},
+		)
+		mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewApproved, nil).Once()
+		mockGithubClient.On("GetPRChecks", 1).Return(passing, nil).Once()
+		mockGithubClient.On("GetPRReview", 2).Return(gh.ReviewApproved, nil).Once()
+		mockGithubClient.On("GetPRChecks", 2).Return(passing, nil).Once()
+		mockGithubClient.On("GetPRReview", 3).Return(gh.ReviewRequired, nil).Once()
+		// PR 4 is never queried: the run already stopped at PR 3
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[:2], run)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("StopsAtDraft", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open"},
+			&model.PR{PRNumber: 2, State: "draft"},
+			&model.PR{PRNumber: 3, State: "open"},
+		)
+		mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewApproved, nil).Once()
+		mockGithubClient.On("GetPRChecks", 1).Return(passing, nil).Once()
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.ActiveChanges[:1], run)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("StopsAtRemoteDraft", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open", RemoteDraftStatus: true},
+		)
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Empty(t, run)
+		mockGithubClient.AssertNotCalled(t, "GetPRReview", mock.Anything)
+	})
+
+	t.Run("StopsAtFailingOrPendingChecks", func(t *testing.T) {
+		for name, checks := range map[string]*gh.ChecksSummary{
+			"Failing": {Passed: 1, Failed: 1, Total: 2},
+			"Pending": {Passed: 1, Pending: 1, Total: 2},
+		} {
+			t.Run(name, func(t *testing.T) {
+				stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+					&model.PR{PRNumber: 1, State: "open"},
+					&model.PR{PRNumber: 2, State: "open"},
+				)
+				mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewApproved, nil).Once()
+				mockGithubClient.On("GetPRChecks", 1).Return(passing, nil).Once()
+				mockGithubClient.On("GetPRReview", 2).Return(gh.ReviewApproved, nil).Once()
+				mockGithubClient.On("GetPRChecks", 2).Return(checks, nil).Once()
+
+				run, err := stackClient.GetMergeableRun(stackCtx)
+				require.NoError(t, err)
+				assert.Equal(t, stackCtx.ActiveChanges[:1], run)
+			})
+		}
+	})
+
+	t.Run("NoChecksCountsAsPassing", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open"},
+		)
+		mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewApproved, nil).Once()
+		mockGithubClient.On("GetPRChecks", 1).Return(&gh.ChecksSummary{}, nil).Once()
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Len(t, run, 1)
+	})
+
+	t.Run("BottomNotReady", func(t *testing.T) {
+		stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open"},
+			&model.PR{PRNumber: 2, State: "open"},
+		)
+		mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewChangesRequested, nil).Once()
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Empty(t, run)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("StopsAtLocalChange", func(t *testing.T) {
+		stackClient, _, stackCtx := newMergeTestContext(t, nil, &model.PR{PRNumber: 2, State: "open"})
+
+		run, err := stackClient.GetMergeableRun(stackCtx)
+		require.NoError(t, err)
+		assert.Empty(t, run)
+	})
+
+	t.Run("RefusesStaleMergedChanges", func(t *testing.T) {
+		stackClient, _, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "merged"},
+			&model.PR{PRNumber: 2, State: "open"},
+		)
+		stackCtx.StaleMergedChanges = stackCtx.ActiveChanges[:1]
+
+		_, err := stackClient.GetMergeableRun(stackCtx)
+		assert.ErrorContains(t, err, "stack refresh")
+	})
+
+	t.Run("RefusesOutOfOrderMerges", func(t *testing.T) {
+		stackClient, _, stackCtx := newMergeTestContext(t,
+			&model.PR{PRNumber: 1, State: "open"},
+			&model.PR{PRNumber: 2, State: "merged"},
+		)
+
+		_, err := stackClient.GetMergeableRun(stackCtx)
+		assert.ErrorContains(t, err, "out-of-order merge")
+	})
+}
+
+func TestMergeReadyChanges(t *testing.T) {
+	passing := &gh.ChecksSummary{Passed: 1, Total: 1}
+
+	stackClient, mockGithubClient, stackCtx := newMergeTestContext(t,
+		&model.PR{PRNumber: 1, State: "open"},
+		&model.PR{PRNumber: 2, State: "open"},
+		&model.PR{PRNumber: 3, State: "draft"},
+	)
+	mockGithubClient.On("GetPRReview", 1).Return(gh.ReviewApproved, nil).Once()
+	mockGithubClient.On("GetPRChecks", 1).Return(passing, nil).Once()
+	mockGithubClient.On("GetPRReview", 2).Return(gh.ReviewApproved, nil).Once()
+	mockGithubClient.On("GetPRChecks", 2).Return(passing, nil).Once()
+
+	var calls []string
+	mockGithubClient.On("MergePR", mock.Anything, "squash").Run(func(args mock.Arguments) {
+		calls = append(calls, fmt.Sprintf("merge #%d", args.Int(0)))
+	}).Return(nil).Twice()
+	mockGithubClient.On("UpdatePRBase", 2, "main").Run(func(args mock.Arguments) {
+		calls = append(calls, "retarget #2")
+	}).Return(nil).Once()
+
+	merged, err := stackClient.MergeReadyChanges(stackCtx, "squash")
+	require.NoError(t, err)
+	assert.Equal(t, stackCtx.ActiveChanges[:2], merged)
+
+	// PR 2 is retargeted at the base after PR 1 merges, and before it is merged itself
+	assert.Equal(t, []string{"merge #1", "retarget #2", "merge #2"}, calls)
+	mockGithubClient.AssertExpectations(t)
+}
+
+func TestMergeChange_InvalidMethod(t *testing.T) {
+	stackClient, mockGithubClient, stackCtx := newMergeTestContext(t, &model.PR{PRNumber: 1, State: "open"})
+
+	err := stackClient.MergeChange(stackCtx, stackCtx.ActiveChanges[0], "octopus")
+	assert.ErrorContains(t, err, "invalid merge method")
+	mockGithubClient.AssertNotCalled(t, "MergePR", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,144 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// MoveChangeToStack moves the change with the given UUID from fromCtx's stack to the top of
+// targetStack. The change's commit is cherry-picked onto the target's TOP branch with its
+// PR-Stack trailer rewritten to the target (PR-UUID is kept), then dropped from the source
+// stack, whose remaining changes are replayed onto its base. Both stacks' UUID branches are
+// updated and the source's UUID branch for the change is deleted.
+//
+// GitHub can't change a PR's head branch, so a PR opened for the change stays on the source
+// stack's branch: its metadata is dropped and the target stack opens a new PR on the next
+// push. Merged changes can't be moved.
+func (c *Client) MoveChangeToStack(fromCtx *StackContext, uuid string, targetStack string) error {
+	change, target, err := c.validateMove(fromCtx, uuid, targetStack)
+	if err != nil {
+		return err
+	}
+
+	targetHead, err := c.git.GetCommitHash(target.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get target stack head: %w", err)
+	}
+	original, err := c.git.GetCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+
+	// Cherry-pick on a detached HEAD so the stack's git hooks stay out of the way
+	if err := c.git.CheckoutDetached(targetHead); err != nil {
+		return err
+	}
+	if err := c.git.CherryPick(change.CommitHash); err != nil {
+		if resetErr := c.git.ResetHard("HEAD"); resetErr != nil {
+			ui.Warningf("failed to clean up the cherry-pick: %v", resetErr)
+		}
+		if checkoutErr := c.git.CheckoutBranch(fromCtx.Stack.Branch); checkoutErr != nil {
+			ui.Warningf("failed to return to %s: %v", fromCtx.Stack.Branch, checkoutErr)
+		}
+		return fmt.Errorf("change %s conflicts with stack '%s'; neither stack was changed: %w", uuid, targetStack, err)
+	}
+
+	tree, err := c.git.GetCommitTree("HEAD")
+	if err != nil {
+		return err
+	}
+	msg := original.Message
+	msg.Trailers = withStackTrailers(msg.Trailers, uuid, targetStack)
+	movedHash, err := c.git.CommitTreeAs(tree, targetHead, msg.String(), original.Meta)
+	if err != nil {
+		return err
+	}
+	if err := c.git.UpdateRef(target.Branch, movedHash); err != nil {
+		return err
+	}
+	if err := c.git.CheckoutBranch(fromCtx.Stack.Branch); err != nil {
+		return err
+	}
+
+	if err := c.DropChanges(fromCtx, []*model.Change{change}); err != nil {
+		return fmt.Errorf("change was added to stack '%s' but could not be dropped from stack '%s': %w", targetStack, fromCtx.StackName, err)
+	}
+
+	if err := c.forgetMovedChange(fromCtx, change); err != nil {
+		return err
+	}
+
+	if _, err := c.UpdateUUIDBranches(targetStack); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+	return nil
+}
+
+// validateMove checks that the change with the given UUID can be moved to targetStack and
+// returns it along with the target stack
+func (c *Client) validateMove(fromCtx *StackContext, uuid string, targetStack string) (*model.Change, *model.Stack, error) {
+	if !fromCtx.IsStack() || fromCtx.OnUUIDBranch() {
+		return nil, nil, fmt.Errorf("%w to move a change", ErrNotOnTopBranch)
+	}
+	if targetStack == fromCtx.StackName {
+		return nil, nil, fmt.Errorf("change is already in stack '%s'", targetStack)
+	}
+
+	// Merged changes aren't active, so check for them first to explain the refusal
+	if change := fromCtx.FindChange(uuid); change != nil && change.PR.IsMerged() {
+		return nil, nil, fmt.Errorf("cannot move merged change #%d", change.PR.PRNumber)
+	}
+	change := fromCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return nil, nil, fmt.Errorf("change %s is not an active change in stack '%s'", uuid, fromCtx.StackName)
+	}
+
+	if !c.StackExists(targetStack) {
+		return nil, nil, fmt.Errorf("%w: '%s'", ErrStackNotFound, targetStack)
+	}
+	target, err := c.LoadStack(targetStack)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	if c.git.IsRebaseInProgress() {
+		return nil, nil, fmt.Errorf("a rebase is in progress; finish it or run 'stack restack --abort' first")
+	}
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return nil, nil, fmt.Errorf("cannot move a change with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	return change, target, nil
+}
+
+// forgetMovedChange removes a moved change's PR metadata and UUID branch from the source
+// stack. An open PR is left on GitHub with a warning.
+func (c *Client) forgetMovedChange(fromCtx *StackContext, change *model.Change) error {
+	prData, err := c.LoadPRs(fromCtx.StackName)
+	if err != nil {
+		return err
+	}
+	if pr, ok := prData.PRs[change.UUID]; ok {
+		if pr.PRNumber > 0 && pr.State != "closed" {
+			ui.Warningf("PR #%d stays open on GitHub for stack '%s': close it once the moved change has a new PR", pr.PRNumber, fromCtx.StackName)
+		}
+		delete(prData.PRs, change.UUID)
+		if err := c.savePRs(fromCtx.StackName, prData); err != nil {
+			return err
+		}
+	}
+
+	branch := fromCtx.FormatUUIDBranch(change.UUID)
+	if c.git.BranchExists(branch) {
+		if err := c.git.DeleteBranch(branch, true); err != nil {
+			ui.Warningf("failed to delete branch %s: %v", branch, err)
+		}
+	}
+	return nil
+}
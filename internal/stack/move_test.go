@@ -0,0 +1,172 @@
+package stack
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+// setupMoveTest creates stack "source" with changes 1111… (a.txt) and 2222… (b.txt), and
+// stack "target" with change 3333… (c.txt), and checks out the source's TOP branch
+func setupMoveTest(t *testing.T) (*Client, *git.Client) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+	gitRoot := gitClient.GitRoot()
+
+	_, err := stackClient.CreateStack("target", "main")
+	require.NoError(t, err)
+	testutil.WriteFile(t, gitRoot, "c.txt", "c\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Target change", "", map[string]string{
+		"PR-UUID":  "3333333333333333",
+		"PR-Stack": "target",
+	})
+
+	require.NoError(t, gitClient.CheckoutBranch("main"))
+	source, err := stackClient.CreateStack("source", "main")
+	require.NoError(t, err)
+	testutil.WriteFile(t, gitRoot, "a.txt", "a\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Source change 1", "", map[string]string{
+		"PR-UUID":  "1111111111111111",
+		"PR-Stack": "source",
+	})
+	testutil.WriteFile(t, gitRoot, "b.txt", "b\n")
+	_ = createCommitByOtherAuthor(t, gitClient, "Source change 2", "Moves to the target", map[string]string{
+		"PR-UUID":  "2222222222222222",
+		"PR-Stack": "source",
+	})
+	require.NoError(t, gitClient.CheckoutBranch(source.Branch))
+
+	return stackClient, gitClient
+}
+
+func TestMoveChangeToStack(t *testing.T) {
+	t.Run("MovesTopChange", func(t *testing.T) {
+		stackClient, gitClient := setupMoveTest(t)
+
+		fromCtx, err := stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+		uuidBranch := fromCtx.FormatUUIDBranch("2222222222222222")
+		require.NoError(t, gitClient.CreateBranchAt(uuidBranch, fromCtx.ActiveChanges[1].CommitHash))
+		require.NoError(t, stackClient.savePRs("source", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"2222222222222222": {PRNumber: 42, State: "open", Branch: uuidBranch},
+			},
+		}))
+		fromCtx, err = stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+
+		require.NoError(t, stackClient.MoveChangeToStack(fromCtx, "2222222222222222", "target"))
+
+		// The source keeps only its first change
+		sourceCtx, err := stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+		require.Len(t, sourceCtx.ActiveChanges, 1)
+		assert.Equal(t, "1111111111111111", sourceCtx.ActiveChanges[0].UUID)
+		assert.Equal(t, 1, sourceCtx.ActiveChanges[0].Position)
+		assert.False(t, gitClient.BranchExists(uuidBranch), "the source's UUID branch is deleted")
+		prData, err := stackClient.LoadPRs("source")
+		require.NoError(t, err)
+		assert.NotContains(t, prData.PRs, "2222222222222222")
+
+		// The target gains the change on top, with its trailers pointing at the target
+		targetCtx, err := stackClient.GetStackContextByName("target")
+		require.NoError(t, err)
+		require.Len(t, targetCtx.ActiveChanges, 2)
+		assert.Equal(t, "3333333333333333", targetCtx.ActiveChanges[0].UUID)
+		moved := targetCtx.ActiveChanges[1]
+		assert.Equal(t, "2222222222222222", moved.UUID)
+		assert.Equal(t, 2, moved.Position)
+		assert.Equal(t, "Source change 2", moved.Title)
+		assert.Nil(t, moved.PR, "the moved change gets a new PR from the target")
+
+		commit, err := gitClient.GetCommit(moved.CommitHash)
+		require.NoError(t, err)
+		assert.Equal(t, "2222222222222222", commit.Message.Trailers["PR-UUID"])
+		assert.Equal(t, "target", commit.Message.Trailers["PR-Stack"])
+		assert.Equal(t, "Moves to the target", commit.Message.Body)
+		assertOtherAuthor(t, gitClient, moved.CommitHash)
+
+		// The moved commit brought its file along
+		cmd := exec.Command("git", "cat-file", "-e", moved.CommitHash+":b.txt")
+		cmd.Dir = gitClient.GitRoot()
+		assert.NoError(t, cmd.Run())
+
+		current, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, sourceCtx.Stack.Branch, current)
+	})
+
+	t.Run("RefusesMergedChange", func(t *testing.T) {
+		stackClient, _ := setupMoveTest(t)
+		require.NoError(t, stackClient.savePRs("source", &model.PRData{
+			Version: 1,
+			PRs: map[string]*model.PR{
+				"1111111111111111": {PRNumber: 41, State: "merged"},
+			},
+		}))
+
+		fromCtx, err := stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+		assert.ErrorContains(t, stackClient.MoveChangeToStack(fromCtx, "1111111111111111", "target"), "cannot move merged change #41")
+	})
+
+	t.Run("RefusesUnknownTarget", func(t *testing.T) {
+		stackClient, _ := setupMoveTest(t)
+
+		fromCtx, err := stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+		assert.ErrorContains(t, stackClient.MoveChangeToStack(fromCtx, "2222222222222222", "missing"), "does not exist")
+		assert.ErrorContains(t, stackClient.MoveChangeToStack(fromCtx, "2222222222222222", "source"), "already in stack")
+	})
+
+	t.Run("ConflictLeavesStacksUnchanged", func(t *testing.T) {
+		stackClient, gitClient := setupMoveTest(t)
+		gitRoot := gitClient.GitRoot()
+
+		// Give the target its own b.txt so the moved change conflicts
+		target, err := stackClient.LoadStack("target")
+		require.NoError(t, err)
+		require.NoError(t, gitClient.CheckoutBranch(target.Branch))
+		testutil.WriteFile(t, gitRoot, "b.txt", "target\n")
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Target change 2", "", map[string]string{
+			"PR-UUID":  "4444444444444444",
+			"PR-Stack": "target",
+		})
+		targetHead, err := gitClient.GetCommitHash(target.Branch)
+		require.NoError(t, err)
+
+		fromCtx, err := stackClient.GetStackContextByName("source")
+		require.NoError(t, err)
+		require.NoError(t, gitClient.CheckoutBranch(fromCtx.Stack.Branch))
+		sourceHead, err := gitClient.GetCommitHash(fromCtx.Stack.Branch)
+		require.NoError(t, err)
+
+		err = stackClient.MoveChangeToStack(fromCtx, "2222222222222222", "target")
+		assert.ErrorContains(t, err, "conflicts with stack 'target'")
+
+		afterTarget, err := gitClient.GetCommitHash(target.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, targetHead, afterTarget)
+		afterSource, err := gitClient.GetCommitHash(fromCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, sourceHead, afterSource)
+
+		current, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, fromCtx.Stack.Branch, current)
+		hasChanges, err := gitClient.HasUncommittedChanges()
+		require.NoError(t, err)
+		assert.False(t, hasChanges)
+	})
+}
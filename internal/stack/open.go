@@ -0,0 +1,37 @@
+package stack
+
+import "fmt"
+
+// MaxTabsWithoutConfirm is how many PRs OpenStack opens before asking for confirmation
+const MaxTabsWithoutConfirm = 8
+
+// OpenStack opens the PR of every pushed change in the stack in the browser, bottom to top,
+// and returns the opened PR numbers. Local changes are skipped, and with onlyActive so are
+// merged changes. If more than MaxTabsWithoutConfirm PRs would open, confirm is called with
+// the count and nothing is opened unless it returns true; a nil confirm opens them all.
+func (c *Client) OpenStack(stackCtx *StackContext, onlyActive bool, confirm func(count int) bool) ([]int, error) {
+	changes := stackCtx.AllChanges
+	if onlyActive {
+		changes = stackCtx.ActiveChanges
+	}
+
+	var prNumbers []int
+	for _, change := range changes {
+		if !change.IsLocal() {
+			prNumbers = append(prNumbers, change.PR.PRNumber)
+		}
+	}
+	if len(prNumbers) == 0 {
+		return nil, fmt.Errorf("no PRs to open in stack '%s': use 'stack push' to create PRs", stackCtx.StackName)
+	}
+	if len(prNumbers) > MaxTabsWithoutConfirm && confirm != nil && !confirm(len(prNumbers)) {
+		return nil, nil
+	}
+
+	for i, prNumber := range prNumbers {
+		if err := c.ghFor(stackCtx.Stack).OpenPR(prNumber); err != nil {
+			return prNumbers[:i], fmt.Errorf("failed to open PR #%d in browser: %w (ensure 'gh' CLI is installed)", prNumber, err)
+		}
+	}
+	return prNumbers, nil
+}
@@ -0,0 +1,101 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+func TestOpenStack(t *testing.T) {
+	merged := &model.Change{UUID: "1111111111111111", PR: &model.PR{PRNumber: 101, State: "merged"}}
+	open := &model.Change{UUID: "2222222222222222", PR: &model.PR{PRNumber: 102, State: "open"}}
+	local := &model.Change{UUID: "3333333333333333"}
+	draft := &model.Change{UUID: "4444444444444444", PR: &model.PR{PRNumber: 104, State: "draft"}}
+	stackCtx := &StackContext{
+		StackName:     "test-stack",
+		AllChanges:    []*model.Change{merged, open, local, draft},
+		ActiveChanges: []*model.Change{open, local, draft},
+	}
+
+	t.Run("AllChanges", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("OpenPR", mock.Anything).Return(nil)
+		stackClient := &Client{gh: mockGithubClient}
+
+		opened, err := stackClient.OpenStack(stackCtx, false, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{101, 102, 104}, opened)
+		mockGithubClient.AssertNumberOfCalls(t, "OpenPR", 3)
+		for _, prNumber := range opened {
+			mockGithubClient.AssertCalled(t, "OpenPR", prNumber)
+		}
+	})
+
+	t.Run("OnlyActive", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("OpenPR", mock.Anything).Return(nil)
+		stackClient := &Client{gh: mockGithubClient}
+
+		opened, err := stackClient.OpenStack(stackCtx, true, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{102, 104}, opened)
+		mockGithubClient.AssertNumberOfCalls(t, "OpenPR", 2)
+		mockGithubClient.AssertNotCalled(t, "OpenPR", 101)
+	})
+
+	t.Run("NoPRs", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		stackClient := &Client{gh: mockGithubClient}
+
+		_, err := stackClient.OpenStack(&StackContext{StackName: "test-stack", AllChanges: []*model.Change{local}}, false, nil)
+		assert.ErrorContains(t, err, "no PRs to open in stack 'test-stack'")
+		assert.NotContains(t, err.Error(), "'gh' CLI", "only gh failures get the install hint")
+		mockGithubClient.AssertNotCalled(t, "OpenPR", mock.Anything)
+	})
+
+	t.Run("ConfirmsManyTabs", func(t *testing.T) {
+		var changes []*model.Change
+		for i := range MaxTabsWithoutConfirm + 1 {
+			changes = append(changes, &model.Change{UUID: fmt.Sprintf("%016d", i), PR: &model.PR{PRNumber: 200 + i, State: "open"}})
+		}
+		bigCtx := &StackContext{StackName: "big", AllChanges: changes, ActiveChanges: changes}
+
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("OpenPR", mock.Anything).Return(nil)
+		stackClient := &Client{gh: mockGithubClient}
+
+		var asked int
+		opened, err := stackClient.OpenStack(bigCtx, false, func(count int) bool {
+			asked = count
+			return false
+		})
+		require.NoError(t, err)
+		assert.Empty(t, opened)
+		assert.Equal(t, MaxTabsWithoutConfirm+1, asked)
+		mockGithubClient.AssertNotCalled(t, "OpenPR", mock.Anything)
+
+		opened, err = stackClient.OpenStack(bigCtx, false, func(count int) bool { return true })
+		require.NoError(t, err)
+		assert.Len(t, opened, MaxTabsWithoutConfirm+1)
+		mockGithubClient.AssertNumberOfCalls(t, "OpenPR", MaxTabsWithoutConfirm+1)
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("OpenPR", 101).Return(nil)
+		mockGithubClient.On("OpenPR", 102).Return(errors.New("gh not found"))
+		stackClient := &Client{gh: mockGithubClient}
+
+		opened, err := stackClient.OpenStack(stackCtx, false, nil)
+		assert.ErrorContains(t, err, "failed to open PR #102 in browser: gh not found (ensure 'gh' CLI is installed)")
+		assert.Equal(t, []int{101}, opened)
+		mockGithubClient.AssertNotCalled(t, "OpenPR", 104)
+	})
+}
@@ -0,0 +1,60 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// PRBodyData is the data a PR body template is rendered with
+type PRBodyData struct {
+	StackName   string
+	Position    int    // Position of the change in the stack (1 = bottom)
+	Total       int    // Number of changes in the stack, including merged ones
+	Title       string // The commit title
+	Description string // The commit description, used as the PR body without a template
+	Base        string // Branch the PR is opened against
+}
+
+// getPRTemplatePath returns the path of the PR body template. The repository's GitHub PR
+// template isn't used here: the prepare-commit-msg hook already copies it into new commit
+// descriptions, so it ends up in {{.Description}}.
+func (c *Client) getPRTemplatePath() string {
+	return filepath.Join(c.getStacksRootDir(), "pr_template.md")
+}
+
+// RenderPRBody returns the body of change's PR. When .git/stack/pr_template.md exists it is
+// rendered as a Go text/template with PRBodyData (e.g. {{.StackName}}, {{.Position}} of
+// {{.Total}}, {{.Description}}); otherwise the body is the commit description.
+func (c *Client) RenderPRBody(stackCtx *StackContext, change *model.Change) (string, error) {
+	content, err := os.ReadFile(c.getPRTemplatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return change.Description, nil
+		}
+		return "", fmt.Errorf("failed to read PR template: %w", err)
+	}
+
+	tmpl, err := template.New("pr_template.md").Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse PR template: %w", err)
+	}
+
+	var body strings.Builder
+	data := PRBodyData{
+		StackName:   stackCtx.StackName,
+		Position:    change.Position,
+		Total:       len(stackCtx.AllChanges),
+		Title:       change.Title,
+		Description: change.Description,
+		Base:        change.DesiredBase,
+	}
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to render PR template: %w", err)
+	}
+	return body.String(), nil
+}
@@ -0,0 +1,63 @@
+package stack
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+func TestRenderPRBody(t *testing.T) {
+	changes := []*model.Change{
+		{UUID: "1111111111111111", Title: "Add JWT auth", Position: 1, Description: "Adds JWT auth.", DesiredBase: "main"},
+		{UUID: "2222222222222222", Title: "Refresh tokens", Position: 2, Description: "Adds refresh tokens.", DesiredBase: "test-user/stack-auth/1111111111111111"},
+	}
+
+	// setup returns a client and a stack context for changes, with the PR template set to
+	// template ("" for no template)
+	setup := func(t *testing.T, template string) (*Client, *StackContext) {
+		stackClient := NewTestStack(t, &gh.MockGithubClient{})
+		if template != "" {
+			require.NoError(t, os.MkdirAll(stackClient.getStacksRootDir(), 0755))
+			require.NoError(t, os.WriteFile(stackClient.getPRTemplatePath(), []byte(template), 0644))
+		}
+		return stackClient, &StackContext{StackName: "auth", AllChanges: changes, ActiveChanges: changes}
+	}
+
+	t.Run("NoTemplateUsesDescription", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, "")
+
+		body, err := stackClient.RenderPRBody(stackCtx, changes[1])
+		require.NoError(t, err)
+		assert.Equal(t, "Adds refresh tokens.", body)
+	})
+
+	t.Run("SubstitutesPlaceholders", func(t *testing.T) {
+		stackClient, stackCtx := setup(t,
+			"{{.Description}}\n\n---\nPart of stack **{{.StackName}}** ({{.Position}} of {{.Total}}), based on `{{.Base}}`: {{.Title}}\n- [ ] Tests added\n")
+
+		body, err := stackClient.RenderPRBody(stackCtx, changes[1])
+		require.NoError(t, err)
+		assert.Equal(t,
+			"Adds refresh tokens.\n\n---\nPart of stack **auth** (2 of 2), based on `test-user/stack-auth/1111111111111111`: Refresh tokens\n- [ ] Tests added\n",
+			body)
+	})
+
+	t.Run("UnknownPlaceholder", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, "{{.Reviewer}}")
+
+		_, err := stackClient.RenderPRBody(stackCtx, changes[0])
+		assert.ErrorContains(t, err, "failed to render PR template")
+	})
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		stackClient, stackCtx := setup(t, "{{.StackName")
+
+		_, err := stackClient.RenderPRBody(stackCtx, changes[0])
+		assert.ErrorContains(t, err, "failed to parse PR template")
+	})
+}
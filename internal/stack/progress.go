@@ -0,0 +1,49 @@
+package stack
+
+import "github.com/bjulian5/stack/internal/model"
+
+// ProgressPhase says whether a ProgressEvent marks the start or the end of work on a PR
+type ProgressPhase string
+
+const (
+	ProgressStart  ProgressPhase = "start"  // Work on the PR is about to begin
+	ProgressFinish ProgressPhase = "finish" // Work on the PR is done; Action says what happened
+)
+
+// ProgressEvent reports progress on one PR during a multi-PR operation such as a refresh or push
+type ProgressEvent struct {
+	Phase    ProgressPhase
+	Change   *model.Change
+	Position int    // Change's position in the stack (1-indexed)
+	Total    int    // Number of changes in the stack
+	PRNumber int    // PR number (0 until the PR exists)
+	URL      string // PR URL (empty until the PR exists)
+	Action   string // Set on finish: the PR's synced state for a refresh; "created", "updated" or "skipped" for a push
+	Reason   string // Optional detail on finish (e.g. why a PR was updated or skipped)
+}
+
+// ProgressFunc receives ProgressEvents as an operation runs. A nil ProgressFunc ignores them.
+type ProgressFunc func(event ProgressEvent)
+
+// Emit sends event to f, doing nothing if f is nil
+func (f ProgressFunc) Emit(event ProgressEvent) {
+	if f != nil {
+		f(event)
+	}
+}
+
+// NewProgressEvent returns a ProgressEvent for change in stackCtx with its position and PR
+// filled in
+func NewProgressEvent(stackCtx *StackContext, phase ProgressPhase, change *model.Change) ProgressEvent {
+	event := ProgressEvent{
+		Phase:    phase,
+		Change:   change,
+		Position: change.Position,
+		Total:    len(stackCtx.AllChanges),
+	}
+	if change.PR != nil {
+		event.PRNumber = change.PR.PRNumber
+		event.URL = change.PR.URL
+	}
+	return event
+}
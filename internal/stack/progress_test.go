@@ -0,0 +1,169 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestSyncPRMetadata_Progress(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", mock.AnythingOfType("[]int")).Return(&gh.BatchPRsResult{
+		PRStates: map[int]*gh.PRState{
+			101: {Number: 101, State: "MERGED", IsMerged: true},
+			102: {Number: 102, State: "OPEN"},
+		},
+	}, nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	for _, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Change "+uuid[:1], "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+	// PR 103 was deleted on GitHub, so it's missing from the batch result
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuids[0]: {PRNumber: 101, State: "open", URL: "https://github.com/test-owner/test-repo/pull/101"},
+			uuids[1]: {PRNumber: 102, State: "open", URL: "https://github.com/test-owner/test-repo/pull/102"},
+			uuids[2]: {PRNumber: 103, State: "open", URL: "https://github.com/test-owner/test-repo/pull/103"},
+		},
+	}))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	var events []ProgressEvent
+	_, err = stackClient.SyncPRMetadata(stackCtx, func(event ProgressEvent) {
+		events = append(events, event)
+	}, RefreshOptions{})
+	require.NoError(t, err)
+
+	// Every PR starts before GitHub is queried, then finishes in stack order
+	require.Len(t, events, 6)
+	type summary struct {
+		Phase    ProgressPhase
+		Position int
+		PRNumber int
+		Action   string
+	}
+	var got []summary
+	for _, event := range events {
+		got = append(got, summary{event.Phase, event.Position, event.PRNumber, event.Action})
+		assert.Equal(t, 3, event.Total)
+		assert.Equal(t, event.Position, event.Change.Position)
+	}
+	assert.Equal(t, []summary{
+		{ProgressStart, 1, 101, ""},
+		{ProgressStart, 2, 102, ""},
+		{ProgressStart, 3, 103, ""},
+		{ProgressFinish, 1, 101, "merged"},
+		{ProgressFinish, 2, 102, "open"},
+		{ProgressFinish, 3, 103, "skipped"},
+	}, got)
+	assert.Equal(t, "https://github.com/test-owner/test-repo/pull/102", events[4].URL)
+	assert.Equal(t, "PR not found on GitHub", events[5].Reason)
+
+	// A nil ProgressFunc is a no-op
+	stackCtx, err = stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	_, err = stackClient.SyncPRMetadata(stackCtx, nil, RefreshOptions{})
+	require.NoError(t, err)
+}
+
+func TestPushStack_Progress(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+	testutil.AddBareRemote(t, gitClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+	var hashes []string
+	for _, uuid := range uuids {
+		hashes = append(hashes, testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		}))
+	}
+	// The bottom PR is in sync with GitHub, the middle one was pushed before its commit was
+	// amended and the top change has no PR yet
+	branch1 := "test-user/stack-test-stack/" + uuids[0]
+	branch2 := "test-user/stack-test-stack/" + uuids[1]
+	require.NoError(t, gitClient.CreateBranchAt(branch1, hashes[0]))
+	require.NoError(t, gitClient.Push(branch1, true))
+	require.NoError(t, gitClient.CreateBranchAt(branch2, hashes[0]))
+	require.NoError(t, gitClient.Push(branch2, true))
+	require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+		Version: 1,
+		PRs: map[string]*model.PR{
+			uuids[0]: {PRNumber: 101, State: "open", Branch: branch1, CommitHash: hashes[0], Title: "Change 1", Base: "main"},
+			uuids[1]: {PRNumber: 102, State: "open", Branch: branch2, CommitHash: hashes[0], Title: "Change 2", Base: branch1},
+		},
+	}))
+
+	mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool { return spec.Number == 102 })).
+		Return(&gh.PR{Number: 102, URL: "https://github.com/test-owner/test-repo/pull/102", State: "open", IsDraft: true}, nil).Once()
+	mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool { return spec.Number == 0 })).
+		Return(&gh.PR{Number: 103, URL: "https://github.com/test-owner/test-repo/pull/103", State: "open", IsDraft: true}, nil).Once()
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	var events []ProgressEvent
+	result, err := stackClient.PushStack(stackCtx, PushOptions{}, func(event ProgressEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	mockGithubClient.AssertExpectations(t)
+
+	// Each PR starts and finishes before the next one starts
+	type summary struct {
+		Phase    ProgressPhase
+		Position int
+		PRNumber int
+		Action   string
+		Reason   string
+	}
+	var got []summary
+	for _, event := range events {
+		got = append(got, summary{event.Phase, event.Position, event.PRNumber, event.Action, event.Reason})
+		assert.Equal(t, 3, event.Total)
+	}
+	assert.Equal(t, []summary{
+		{ProgressStart, 1, 101, "", ""},
+		{ProgressFinish, 1, 101, "skipped", ""},
+		{ProgressStart, 2, 102, "", ""},
+		{ProgressFinish, 2, 102, "updated", "commit changed"},
+		{ProgressStart, 3, 0, "", ""},
+		{ProgressFinish, 3, 103, "created", ""},
+	}, got)
+	assert.Equal(t, "https://github.com/test-owner/test-repo/pull/103", events[5].URL)
+
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Skipped)
+	require.Len(t, result.Pushed, 2)
+	assert.Equal(t, uuids[1], result.Pushed[0].Change.UUID)
+	assert.Equal(t, "updated", result.Pushed[0].Action)
+	assert.Equal(t, uuids[2], result.Pushed[1].Change.UUID)
+	assert.Equal(t, 103, result.Pushed[1].PRNumber)
+	assert.Equal(t, "created", result.Pushed[1].Action)
+}
@@ -0,0 +1,222 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// PushResult is the outcome of pushing a change with PushChange
+type PushResult struct {
+	PRNumber int
+	URL      string
+	Created  bool // The PR was created rather than updated
+}
+
+// PushOptions controls which changes PushStack pushes
+type PushOptions struct {
+	Force bool // Push every open PR, even ones that are already in sync with GitHub
+}
+
+// PushedChange is a change whose PR PushStack created or updated
+type PushedChange struct {
+	Change   *model.Change
+	PRNumber int
+	URL      string
+	Action   string // "created" or "updated"
+}
+
+// PushStackResult is the outcome of pushing a stack with PushStack
+type PushStackResult struct {
+	Created int
+	Updated int
+	Skipped int
+	Pushed  []PushedChange // The changes whose PRs were created or updated, in stack order
+}
+
+// PushStack creates or updates the PR for every active change from the bottom up, skipping
+// closed PRs and (unless opts.Force is set) PRs that are already in sync. progress receives a
+// start and finish event per change. Visualization comments are left to the caller.
+func (c *Client) PushStack(stackCtx *StackContext, opts PushOptions, progress ProgressFunc) (*PushStackResult, error) {
+	result := &PushStackResult{Pushed: []PushedChange{}}
+
+	for _, change := range stackCtx.ActiveChanges {
+		progress.Emit(NewProgressEvent(stackCtx, ProgressStart, change))
+
+		existingPR := change.PR
+
+		// Skip PRs that are closed on GitHub (not merged)
+		// GitHub doesn't allow updating the base branch of closed PRs
+		if existingPR != nil && existingPR.State == "closed" {
+			result.Skipped++
+			event := NewProgressEvent(stackCtx, ProgressFinish, change)
+			event.Action = "skipped"
+			event.Reason = "PR is closed on GitHub - reopen it or remove the commit from the stack"
+			progress.Emit(event)
+			continue
+		}
+
+		var updateReason string
+		if existingPR != nil && !opts.Force {
+			syncStatus := change.NeedsSyncToGitHub()
+			if !syncStatus.NeedsSync && c.hasPendingLabelsOrReviewers(existingPR) {
+				syncStatus = model.ChangeSyncStatus{NeedsSync: true, Reason: "labels or reviewers to add"}
+			}
+
+			if !syncStatus.NeedsSync {
+				result.Skipped++
+				event := NewProgressEvent(stackCtx, ProgressFinish, change)
+				event.Action = "skipped"
+				progress.Emit(event)
+				continue
+			}
+
+			updateReason = syncStatus.Reason
+		}
+
+		if change.HasTitleDrift() || change.HasBodyDrift() {
+			ui.Warningf("PR #%d was edited on GitHub; replacing its title and description with the commit message", change.PR.PRNumber)
+		}
+
+		pushResult, err := c.PushChange(stackCtx, change.UUID)
+		if err != nil {
+			return result, err
+		}
+
+		action := "updated"
+		if pushResult.Created {
+			result.Created++
+			action = "created"
+		} else {
+			result.Updated++
+		}
+		result.Pushed = append(result.Pushed, PushedChange{Change: change, PRNumber: pushResult.PRNumber, URL: pushResult.URL, Action: action})
+
+		event := NewProgressEvent(stackCtx, ProgressFinish, change)
+		event.PRNumber = pushResult.PRNumber
+		event.URL = pushResult.URL
+		event.Action = action
+		event.Reason = updateReason
+		progress.Emit(event)
+	}
+
+	return result, nil
+}
+
+// hasPendingLabelsOrReviewers reports whether the configured labels or reviewers haven't all
+// been applied to the PR yet
+func (c *Client) hasPendingLabelsOrReviewers(pr *model.PR) bool {
+	return len(pr.LabelsToAdd(c.PRLabels())) > 0 || len(pr.ReviewersToRequest(c.PRReviewers())) > 0
+}
+
+// PushChange pushes a single active change: its UUID branch is moved to the change's commit and
+// force pushed, then its PR is created or updated to target the change's DesiredBase. Every
+// change below it must already have a PR, since a PR's base is the UUID branch of the change
+// below it. The PR metadata is saved; visualization comments are left to the caller.
+func (c *Client) PushChange(stackCtx *StackContext, uuid string) (*PushResult, error) {
+	change := stackCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return nil, fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+	// GitHub doesn't allow updating the base branch of closed PRs
+	if change.PR != nil && change.PR.State == "closed" {
+		return nil, fmt.Errorf("PR #%d is closed on GitHub - reopen it or remove the commit from the stack", change.PR.PRNumber)
+	}
+
+	for _, lower := range stackCtx.ActiveChanges {
+		if err := checkPRHead(stackCtx, lower); err != nil {
+			return nil, err
+		}
+		if lower.UUID == change.UUID {
+			break
+		}
+		if lower.IsLocal() {
+			return nil, fmt.Errorf("change #%d (%s) below it hasn't been pushed, so its branch doesn't exist on the remote: run 'stack push' to push the whole stack", lower.Position, lower.Title)
+		}
+	}
+	if change.DesiredBase == stackCtx.Stack.Base && !c.git.IsLocalBranch(stackCtx.Stack.Base) {
+		return nil, fmt.Errorf("stack base '%s' is not a branch, so the bottom PR has nothing to target: run 'stack restack --onto <branch>' first", stackCtx.Stack.Base)
+	}
+
+	// Render the body first so a broken PR template fails before anything is pushed
+	body, err := c.RenderPRBody(stackCtx, change)
+	if err != nil {
+		return nil, err
+	}
+
+	prBranch := stackCtx.FormatUUIDBranch(change.UUID)
+	if err := c.git.UpdateRef(prBranch, change.CommitHash); err != nil {
+		return nil, fmt.Errorf("failed to update branch %s: %w", prBranch, err)
+	}
+	if err := c.PushChangeBranch(change, prBranch); err != nil {
+		return nil, fmt.Errorf("failed to push branch %s: %w", prBranch, err)
+	}
+
+	existingPRNumber := 0
+	if change.PR != nil {
+		existingPRNumber = change.PR.PRNumber
+	}
+	spec := gh.PRSpec{
+		Number:   existingPRNumber,
+		Title:    change.Title,
+		Body:     body,
+		Base:     change.DesiredBase,
+		Head:     prBranch,
+		HeadRepo: c.HeadRepo(),
+		Draft:    c.PushDraftStatus(stackCtx, change),
+
+		Labels:    change.PR.LabelsToAdd(c.PRLabels()),
+		Reviewers: change.PR.ReviewersToRequest(c.PRReviewers()),
+
+		AllowMaintainerEdit: c.AllowMaintainerEdit(),
+		AutoMerge:           c.prAutoMerge(stackCtx, change),
+	}
+
+	ghPR, err := c.ghFor(stackCtx.Stack).SyncPR(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync PR for %s: %w", change.Title, err)
+	}
+
+	change.UpdateFromPush(ghPR, prBranch)
+	// Cache the commit description rather than the rendered body, so a PR body template
+	// doesn't make every change look modified
+	change.UpdateTitle(spec.Title, change.Description, spec.Base)
+	change.PR.RemoteBase = spec.Base
+	change.PR.SetPushedContent(spec.Title, spec.Body)
+	change.PR.RecordApplied(spec.Labels, spec.Reviewers)
+
+	if err := stackCtx.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save stack context: %w", err)
+	}
+
+	return &PushResult{PRNumber: ghPR.Number, URL: ghPR.URL, Created: existingPRNumber == 0}, nil
+}
+
+// prAutoMerge returns the auto-merge setting to push change's PR with. Only the PR targeting
+// the stack base may auto-merge: the PRs above it target the UUID branch of the change below,
+// and auto-merging them would fold each into its parent PR's branch instead of the base. Their
+// auto-merge is turned off when one is configured and left alone otherwise.
+func (c *Client) prAutoMerge(stackCtx *StackContext, change *model.Change) string {
+	autoMerge := c.PRAutoMerge()
+	if autoMerge != "" && change.DesiredBase != stackCtx.Stack.Base {
+		return gh.AutoMergeOff
+	}
+	return autoMerge
+}
+
+// checkPRHead returns an error if change's PR was opened from a branch other than the one this
+// clone pushes the change to, e.g. for a stack imported from a teammate (the UUID branches
+// start with the username). GitHub can't change a PR's head branch, so pushing would leave the
+// PR on its old head while the PRs above it were retargeted to the new branch.
+func checkPRHead(stackCtx *StackContext, change *model.Change) error {
+	if change.IsLocal() || change.PR.Branch == "" {
+		return nil
+	}
+	if branch := stackCtx.FormatUUIDBranch(change.UUID); change.PR.Branch != branch {
+		return fmt.Errorf("PR #%d (%s) was opened from branch %s, but this clone pushes the change to %s: GitHub can't change a PR's head branch, so push it from the clone that opened it (was the stack imported from another user?)",
+			change.PR.PRNumber, change.Title, change.PR.Branch, branch)
+	}
+	return nil
+}
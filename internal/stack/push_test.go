@@ -0,0 +1,161 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestPushChange(t *testing.T) {
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+
+	// setup creates a two-change stack with an origin remote; prs are saved before loading
+	setup := func(t *testing.T, prs map[string]*model.PR) (*Client, *gh.MockGithubClient, *git.Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+		testutil.AddBareRemote(t, gitClient)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		hashes := map[string]string{}
+		for _, uuid := range []string{uuid1, uuid2} {
+			hashes[uuid] = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "Body "+uuid[:1], map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		// Pushed changes have their branch on the remote at the pushed commit
+		for uuid, pr := range prs {
+			pr.CommitHash = hashes[uuid]
+			pr.Branch = "test-user/stack-test-stack/" + uuid
+			require.NoError(t, gitClient.CreateBranchAt(pr.Branch, hashes[uuid]))
+			require.NoError(t, gitClient.Push(pr.Branch, true))
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: prs}))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		return stackClient, mockGithubClient, gitClient, stackCtx
+	}
+
+	t.Run("TopChangeWithLowerPushed", func(t *testing.T) {
+		stackClient, mockGithubClient, gitClient, stackCtx := setup(t, map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+		})
+		top := stackCtx.ActiveChanges[1]
+
+		mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool {
+			return spec.Number == 0 &&
+				spec.Title == "Change 2" &&
+				spec.Body == "Body 2" &&
+				spec.Base == "test-user/stack-test-stack/"+uuid1 &&
+				spec.Head == "test-user/stack-test-stack/"+uuid2
+		})).Return(&gh.PR{Number: 102, URL: "https://github.com/test-owner/test-repo/pull/102", State: "open", IsDraft: true}, nil).Once()
+
+		result, err := stackClient.PushChange(stackCtx, uuid2)
+		require.NoError(t, err)
+		assert.Equal(t, &PushResult{PRNumber: 102, URL: "https://github.com/test-owner/test-repo/pull/102", Created: true}, result)
+		mockGithubClient.AssertExpectations(t)
+
+		// Only the pushed change's branch moved on the remote
+		remoteHeads, err := gitClient.LsRemoteHeads("origin", "test-user/stack-test-stack/"+uuid2)
+		require.NoError(t, err)
+		assert.Equal(t, top.CommitHash, remoteHeads["test-user/stack-test-stack/"+uuid2])
+
+		// The PR metadata is saved
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		pr := stackCtx.ActiveChanges[1].PR
+		require.NotNil(t, pr)
+		assert.Equal(t, 102, pr.PRNumber)
+		assert.Equal(t, top.CommitHash, pr.CommitHash)
+		assert.Equal(t, "test-user/stack-test-stack/"+uuid1, pr.Base)
+		assert.False(t, stackCtx.ActiveChanges[1].NeedsSyncToGitHub().NeedsSync)
+		assert.Equal(t, 101, stackCtx.ActiveChanges[0].PR.PRNumber)
+	})
+
+	t.Run("UpdatesExistingPR", func(t *testing.T) {
+		stackClient, mockGithubClient, _, stackCtx := setup(t, map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+		})
+
+		mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool {
+			return spec.Number == 101 && spec.Base == "main"
+		})).Return(&gh.PR{Number: 101, URL: "https://github.com/test-owner/test-repo/pull/101", State: "open"}, nil).Once()
+
+		result, err := stackClient.PushChange(stackCtx, uuid1)
+		require.NoError(t, err)
+		assert.False(t, result.Created)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("AutoMergeOnlyOnBottomPR", func(t *testing.T) {
+		stackClient, mockGithubClient, _, stackCtx := setup(t, map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+			uuid2: {PRNumber: 102, State: "open"},
+		})
+		require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{PRAutoMerge: "squash"}))
+
+		mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool {
+			return spec.Number == 101 && spec.Base == "main" && spec.AutoMerge == "squash"
+		})).Return(&gh.PR{Number: 101, State: "open"}, nil).Once()
+		// The upper PR targets the bottom change's branch, which auto-merge would merge it into
+		mockGithubClient.On("SyncPR", mock.MatchedBy(func(spec gh.PRSpec) bool {
+			return spec.Number == 102 && spec.Base == "test-user/stack-test-stack/"+uuid1 && spec.AutoMerge == gh.AutoMergeOff
+		})).Return(&gh.PR{Number: 102, State: "open"}, nil).Once()
+
+		_, err := stackClient.PushChange(stackCtx, uuid1)
+		require.NoError(t, err)
+		_, err = stackClient.PushChange(stackCtx, uuid2)
+		require.NoError(t, err)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("RejectsWhenLowerChangeIsLocal", func(t *testing.T) {
+		stackClient, mockGithubClient, gitClient, stackCtx := setup(t, map[string]*model.PR{})
+
+		_, err := stackClient.PushChange(stackCtx, uuid2)
+		assert.ErrorContains(t, err, "change #1 (Change 1) below it hasn't been pushed")
+		mockGithubClient.AssertNotCalled(t, "SyncPR", mock.Anything)
+
+		remoteHeads, err := gitClient.LsRemoteHeads("origin", "test-user/stack-test-stack/"+uuid2)
+		require.NoError(t, err)
+		assert.Empty(t, remoteHeads)
+	})
+
+	t.Run("RejectsPRsOpenedByAnotherUser", func(t *testing.T) {
+		stackClient, mockGithubClient, _, stackCtx := setup(t, map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+		})
+		// As if imported from a teammate, whose UUID branches start with their username
+		stackCtx.ActiveChanges[0].PR.Branch = "teammate/stack-test-stack/" + uuid1
+
+		for _, uuid := range []string{uuid1, uuid2} {
+			_, err := stackClient.PushChange(stackCtx, uuid)
+			assert.ErrorContains(t, err, "PR #101 (Change 1) was opened from branch teammate/stack-test-stack/"+uuid1)
+		}
+		mockGithubClient.AssertNotCalled(t, "SyncPR", mock.Anything)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		stackClient, _, _, stackCtx := setup(t, map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "closed"},
+		})
+
+		_, err := stackClient.PushChange(stackCtx, "9999999999999999")
+		assert.ErrorContains(t, err, "is not an active change")
+		_, err = stackClient.PushChange(stackCtx, uuid1)
+		assert.ErrorContains(t, err, "PR #101 is closed on GitHub")
+	})
+}
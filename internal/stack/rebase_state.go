@@ -10,11 +10,14 @@ import (
 
 // RebaseState stores information needed to recover from a failed rebase operation
 type RebaseState struct {
-	OriginalStackHead string `json:"original_stack_head"` // HEAD before the operation started
-	NewCommitHash     string `json:"new_commit_hash"`     // The new commit (e.g., amended commit)
-	OldCommitHash     string `json:"old_commit_hash"`     // The old commit being replaced
-	StackBranch       string `json:"stack_branch"`        // The stack branch name (e.g., user/stack-name/TOP)
-	Timestamp         string `json:"timestamp"`           // When the operation started
+	OriginalStackHead string `json:"original_stack_head"`       // HEAD before the operation started
+	NewCommitHash     string `json:"new_commit_hash"`           // The new commit (e.g., amended commit)
+	OldCommitHash     string `json:"old_commit_hash"`           // The old commit being replaced
+	StackBranch       string `json:"stack_branch"`              // The stack branch name (e.g., user/stack-name/TOP)
+	TargetBase        string `json:"target_base,omitempty"`     // Branch a restack is rebasing onto (restacks only)
+	TargetBaseRef     string `json:"target_base_ref,omitempty"` // Commit of TargetBase when the restack started
+	AutoStash         string `json:"auto_stash,omitempty"`      // Commit of the stash holding uncommitted changes from before the restack, popped when it ends
+	Timestamp         string `json:"timestamp"`                 // When the operation started
 }
 
 // SaveRebaseState saves rebase state for recovery
@@ -0,0 +1,48 @@
+package stack
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// RefreshAll syncs the PR metadata of every stack with GitHub, like SyncPRMetadata with opts: nothing
+// is rebased and no branch is touched, so it's safe from any branch. Stacks are synced
+// concurrently. A stack that fails to load or sync doesn't stop the others; the results of
+// the stacks that synced are returned by name, along with the failures joined into one error.
+func (c *Client) RefreshAll(opts RefreshOptions) (map[string]*RefreshResult, error) {
+	stacks, err := c.ListStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stacks: %w", err)
+	}
+
+	results := make(map[string]*RefreshResult, len(stacks))
+	errs := make([]error, len(stacks))
+	var mu sync.Mutex
+	g := errgroup.Group{}
+	g.SetLimit(stackSyncConcurrency)
+	for i, s := range stacks {
+		g.Go(func() error {
+			stackCtx, err := c.GetStackContextByName(s.Name)
+			if err != nil {
+				errs[i] = fmt.Errorf("stack '%s': failed to load: %w", s.Name, err)
+				return nil
+			}
+			result, err := c.SyncPRMetadata(stackCtx, nil, opts)
+			if err != nil {
+				errs[i] = fmt.Errorf("stack '%s': %w", s.Name, err)
+				return nil
+			}
+
+			mu.Lock()
+			results[s.Name] = result
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return results, errors.Join(errs...)
+}
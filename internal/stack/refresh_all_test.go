@@ -0,0 +1,74 @@
+package stack
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestRefreshAll(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	// Each stack has two changes; "local" hasn't been pushed
+	stacks := map[string][]int{
+		"alpha":  {101, 102},
+		"broken": {201, 202},
+		"local":  nil,
+	}
+	for _, name := range []string{"alpha", "broken", "local"} {
+		_, err := stackClient.CreateStack(name, "main")
+		require.NoError(t, err)
+
+		prs := map[string]*model.PR{}
+		for i, uuid := range []string{name[:1] + "111111111111111", name[:1] + "222222222222222"} {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, name+" change "+uuid[:2], "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": name,
+			})
+			if stacks[name] != nil {
+				prs[uuid] = &model.PR{PRNumber: stacks[name][i], State: "open"}
+			}
+		}
+		require.NoError(t, stackClient.savePRs(name, &model.PRData{Version: 1, PRs: prs}))
+	}
+
+	mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", []int{101, 102}).Return(&gh.BatchPRsResult{
+		PRStates: map[int]*gh.PRState{
+			101: {Number: 101, State: "MERGED", IsMerged: true},
+			102: {Number: 102, State: "OPEN"},
+		},
+	}, nil).Once()
+	mockGithubClient.On("BatchGetPRs", "test-owner", "test-repo", []int{201, 202}).Return(nil, errors.New("API rate limit exceeded")).Once()
+
+	results, err := stackClient.RefreshAll(RefreshOptions{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "stack 'broken': failed to batch query PRs: API rate limit exceeded")
+	mockGithubClient.AssertExpectations(t)
+
+	// The failing stack doesn't stop the others
+	require.Len(t, results, 2)
+	require.Contains(t, results, "alpha")
+	assert.Equal(t, 1, results["alpha"].StaleMergedCount)
+	assert.Equal(t, 1, results["alpha"].RemainingCount)
+	require.Contains(t, results, "local")
+	assert.Equal(t, 0, results["local"].StaleMergedCount)
+	assert.Equal(t, 2, results["local"].RemainingCount)
+	assert.NotContains(t, results, "broken")
+
+	// Synced metadata is saved; nothing is rebased
+	alphaCtx, err := stackClient.GetStackContextByName("alpha")
+	require.NoError(t, err)
+	assert.Equal(t, "merged", alphaCtx.FindChange("a111111111111111").PR.State)
+	assert.Len(t, alphaCtx.ActiveChanges, 1)
+	assert.Len(t, alphaCtx.StaleMergedChanges, 1)
+}
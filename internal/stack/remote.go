@@ -0,0 +1,65 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// AnnotateRemoteState sets RemoteState on each active change by comparing its local branch
+// with the remote branch. All of the stack's remote branches are listed with a single
+// `git ls-remote` call.
+func (c *Client) AnnotateRemoteState(stackCtx *StackContext) error {
+	remote, err := c.git.GetPushRemoteName()
+	if err != nil {
+		return fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	pattern := fmt.Sprintf("%s/stack-%s/*", stackCtx.username, stackCtx.StackName)
+	remoteHeads, err := c.git.LsRemoteHeads(remote, pattern)
+	if err != nil {
+		return err
+	}
+
+	c.annotateRemoteState(stackCtx, remoteHeads)
+	return nil
+}
+
+// annotateRemoteState sets RemoteState on each active change from a branch -> hash map of remote heads.
+func (c *Client) annotateRemoteState(stackCtx *StackContext, remoteHeads map[string]string) {
+	for _, change := range stackCtx.ActiveChanges {
+		branch := stackCtx.FormatUUIDBranch(change.UUID)
+		if !change.IsLocal() && change.PR.Branch != "" {
+			branch = change.PR.Branch
+		}
+
+		remoteHash, ok := remoteHeads[branch]
+		if !ok {
+			change.RemoteState = model.RemoteMissing
+			continue
+		}
+
+		localHash := change.CommitHash
+		if c.git.BranchExists(branch) {
+			if hash, err := c.git.GetCommitHash(branch); err == nil {
+				localHash = hash
+			}
+		}
+
+		if remoteHash == localHash {
+			change.RemoteState = model.RemoteInSync
+			continue
+		}
+
+		// A remote commit that was never fetched can't be compared, so it counts as diverged
+		ahead, behind, err := c.git.AheadBehind(localHash, remoteHash)
+		switch {
+		case err == nil && behind == 0:
+			change.RemoteState = model.RemoteAhead
+		case err == nil && ahead == 0:
+			change.RemoteState = model.RemoteBehind
+		default:
+			change.RemoteState = model.RemoteDiverged
+		}
+	}
+}
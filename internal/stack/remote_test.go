@@ -0,0 +1,197 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/config"
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestAnnotateRemoteState(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333", "4444444444444444"}
+	hashes := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		hashes[i] = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	require.Len(t, stackCtx.ActiveChanges, 4)
+
+	// Change 2's local branch was amended past what is on the remote (remote still at change 1)
+	require.NoError(t, stackClient.git.CreateBranchAt(stackCtx.FormatUUIDBranch(uuids[1]), hashes[1]))
+
+	lsRemoteOutput := fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/%s\n", hashes[0], uuids[0]) +
+		fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/%s\n", hashes[0], uuids[1]) +
+		fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/%s\n", hashes[3], uuids[2]) +
+		fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/TOP\n", hashes[3])
+
+	stackClient.annotateRemoteState(stackCtx, git.ParseLsRemote(lsRemoteOutput))
+
+	assert.Equal(t, model.RemoteInSync, stackCtx.ActiveChanges[0].RemoteState)
+	assert.Equal(t, model.RemoteAhead, stackCtx.ActiveChanges[1].RemoteState)
+	assert.Equal(t, model.RemoteBehind, stackCtx.ActiveChanges[2].RemoteState)
+	assert.Equal(t, model.RemoteMissing, stackCtx.ActiveChanges[3].RemoteState)
+
+	// Change 4 was amended on the remote on top of change 3, and change 1's remote commit
+	// was never fetched
+	cmd := exec.Command("git", "commit-tree", hashes[3]+"^{tree}", "-p", hashes[2], "-m", "Change 4 (remote)")
+	cmd.Dir = stackClient.git.GitRoot()
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	lsRemoteOutput = fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/%s\n", strings.Repeat("f", 40), uuids[0]) +
+		fmt.Sprintf("%s\trefs/heads/test-user/stack-test-stack/%s\n", strings.TrimSpace(string(output)), uuids[3])
+
+	stackClient.annotateRemoteState(stackCtx, git.ParseLsRemote(lsRemoteOutput))
+
+	assert.Equal(t, model.RemoteDiverged, stackCtx.ActiveChanges[0].RemoteState)
+	assert.Equal(t, model.RemoteDiverged, stackCtx.ActiveChanges[3].RemoteState)
+}
+
+func TestResolveRemote(t *testing.T) {
+	// runGit runs a git command in the test repository
+	runGit := func(t *testing.T, gitClient *git.Client, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = gitClient.GitRoot()
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v failed: %s", args, string(output))
+		return string(output)
+	}
+
+	// addRemote adds a bare repository as the named remote
+	addRemote := func(t *testing.T, gitClient *git.Client, name string) string {
+		remoteDir := t.TempDir()
+		cmd := exec.Command("git", "init", "--bare", "--initial-branch=main")
+		cmd.Dir = remoteDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git init --bare failed: %s", string(output))
+		runGit(t, gitClient, "remote", "add", name, remoteDir)
+		return remoteDir
+	}
+
+	t.Run("NoRemotes", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+
+		_, err := gitClient.ResolveRemote("main")
+		assert.Error(t, err)
+	})
+
+	t.Run("PrefersOrigin", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		addRemote(t, gitClient, "backup")
+		addRemote(t, gitClient, "origin")
+
+		remote, err := gitClient.ResolveRemote("main")
+		require.NoError(t, err)
+		assert.Equal(t, "origin", remote)
+	})
+
+	t.Run("FallsBackToFirstRemote", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		addRemote(t, gitClient, "alpha")
+		addRemote(t, gitClient, "beta")
+
+		remote, err := gitClient.ResolveRemote("main")
+		require.NoError(t, err)
+		assert.Equal(t, "alpha", remote)
+	})
+
+	t.Run("DefaultRemoteOverridesOrigin", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		addRemote(t, gitClient, "origin")
+		addRemote(t, gitClient, "upstream")
+		gitClient.SetDefaultRemote("upstream")
+
+		remote, err := gitClient.ResolveRemote("main")
+		require.NoError(t, err)
+		assert.Equal(t, "upstream", remote)
+	})
+
+	t.Run("TrackingBranchWins", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		addRemote(t, gitClient, "origin")
+		addRemote(t, gitClient, "upstream")
+		gitClient.SetDefaultRemote("origin")
+		runGit(t, gitClient, "config", "branch.main.remote", "upstream")
+
+		remote, err := gitClient.ResolveRemote("main")
+		require.NoError(t, err)
+		assert.Equal(t, "upstream", remote)
+
+		// Branches without their own remote still use the default
+		remote, err = gitClient.ResolveRemote("feature")
+		require.NoError(t, err)
+		assert.Equal(t, "origin", remote)
+	})
+
+	t.Run("LocalTrackingBranchIgnored", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		addRemote(t, gitClient, "origin")
+		runGit(t, gitClient, "config", "branch.main.remote", ".")
+
+		remote, err := gitClient.ResolveRemote("main")
+		require.NoError(t, err)
+		assert.Equal(t, "origin", remote)
+	})
+
+	t.Run("PushAndDeleteUseResolvedRemote", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		originDir := addRemote(t, gitClient, "origin")
+		forkDir := addRemote(t, gitClient, "fork")
+		runGit(t, gitClient, "branch", "feature")
+		runGit(t, gitClient, "config", "branch.feature.remote", "fork")
+
+		require.NoError(t, gitClient.Push("feature", false))
+
+		heads, err := gitClient.LsRemoteHeads(forkDir, "feature")
+		require.NoError(t, err)
+		assert.Contains(t, heads, "feature")
+		heads, err = gitClient.LsRemoteHeads(originDir, "feature")
+		require.NoError(t, err)
+		assert.NotContains(t, heads, "feature")
+
+		require.NoError(t, gitClient.DeleteRemoteBranch("feature"))
+		heads, err = gitClient.LsRemoteHeads(forkDir, "feature")
+		require.NoError(t, err)
+		assert.NotContains(t, heads, "feature")
+	})
+
+	t.Run("EnvOverridesConfig", func(t *testing.T) {
+		gitClient := testutil.NewTestGitClient(t)
+		client, err := NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
+		require.NoError(t, client.saveRepositoryConfig(&RepositoryConfig{Remote: "upstream"}))
+
+		t.Setenv(config.RemoteEnvVar, "")
+		os.Unsetenv(config.RemoteEnvVar)
+		assert.Equal(t, "upstream", client.DefaultRemote())
+
+		// The config is loaded once, so the override applies to clients created after it's set
+		t.Setenv(config.RemoteEnvVar, "fork")
+		assert.Equal(t, "upstream", client.DefaultRemote())
+		client, err = NewClient(gitClient, &gh.MockGithubClient{})
+		require.NoError(t, err)
+		assert.Equal(t, "fork", client.DefaultRemote())
+	})
+}
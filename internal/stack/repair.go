@@ -0,0 +1,232 @@
+package stack
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// RepairAction describes a change RepairStack made (or had to skip) to a UUID branch
+type RepairAction struct {
+	Branch string
+	Action string // "created", "moved", "deleted" or "skipped"
+	From   string // Commit the branch pointed at before the repair ("" when created)
+	To     string // Commit the branch points at after the repair ("" when deleted or skipped)
+	Reason string // Why the branch was skipped
+}
+
+// UUIDReassignment describes a commit RepairStack gave a new PR-UUID because another commit
+// in the stack already carried its old one
+type UUIDReassignment struct {
+	Title   string
+	OldUUID string
+	NewUUID string
+}
+
+// RepairReport lists the actions taken by RepairStack
+type RepairReport struct {
+	Reassigned []UUIDReassignment
+	Actions    []RepairAction
+}
+
+// Count returns the number of actions with the given kind
+func (r RepairReport) Count(action string) int {
+	count := 0
+	for _, a := range r.Actions {
+		if a.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// RepairStack brings the stack's UUID branches back in line with the TOP branch after manual
+// git surgery: every active change gets a UUID branch at its commit, branches pointing at
+// the wrong commit are moved, and branches whose UUID is no longer in the stack are deleted.
+// The checked out branch is never moved or deleted.
+//
+// The TOP branch is only rewritten when several commits carry the same PR-UUID (e.g. after a
+// botched cherry-pick): one of them keeps it, preferring the commit its PR was pushed from,
+// and the others get new PR-UUIDs. That needs the TOP branch checked out and a clean worktree.
+func (c *Client) RepairStack(stackName string) (RepairReport, error) {
+	var report RepairReport
+
+	if c.git.IsRebaseInProgress() {
+		return report, fmt.Errorf("a rebase is in progress: finish or abort it before repairing the stack")
+	}
+	if c.InsertInProgress(stackName) {
+		return report, fmt.Errorf("an insert into stack '%s' is in progress: run 'stack insert --continue' or 'stack insert --abort' first", stackName)
+	}
+
+	// A normal load refuses stacks with duplicate PR-UUIDs, which this fixes
+	stackCtx, err := c.GetStackContextForRepair(stackName)
+	if err != nil {
+		return report, fmt.Errorf("failed to load stack: %w", err)
+	}
+	if len(stackCtx.DuplicateUUIDs) > 0 {
+		report.Reassigned, err = c.reassignDuplicateUUIDs(stackCtx)
+		if err != nil {
+			return report, err
+		}
+		stackCtx, err = c.GetStackContextByName(stackName)
+		if err != nil {
+			return report, fmt.Errorf("failed to reload stack: %w", err)
+		}
+	}
+
+	currentBranch, err := c.git.GetCurrentBranch()
+	if err != nil {
+		currentBranch = ""
+	}
+
+	existing, err := c.GetStackBranches(stackName)
+	if err != nil {
+		return report, err
+	}
+	existingSet := make(map[string]bool, len(existing))
+	for _, branch := range existing {
+		existingSet[branch] = true
+	}
+
+	for _, change := range stackCtx.ActiveChanges {
+		if change.UUID == "" {
+			continue
+		}
+		branch := stackCtx.FormatUUIDBranch(change.UUID)
+
+		if !existingSet[branch] {
+			if err := c.git.CreateBranchAt(branch, change.CommitHash); err != nil {
+				return report, fmt.Errorf("failed to create branch %s: %w", branch, err)
+			}
+			report.Actions = append(report.Actions, RepairAction{Branch: branch, Action: "created", To: change.CommitHash})
+			continue
+		}
+
+		hash, err := c.git.GetCommitHash(branch)
+		if err != nil {
+			return report, fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+		}
+		if hash == change.CommitHash {
+			continue
+		}
+		if branch == currentBranch {
+			report.Actions = append(report.Actions, RepairAction{Branch: branch, Action: "skipped", From: hash,
+				Reason: "branch is checked out; switch to the TOP branch and repair again"})
+			continue
+		}
+		if err := c.git.UpdateRef(branch, change.CommitHash); err != nil {
+			return report, fmt.Errorf("failed to update branch %s: %w", branch, err)
+		}
+		report.Actions = append(report.Actions, RepairAction{Branch: branch, Action: "moved", From: hash, To: change.CommitHash})
+	}
+
+	prefix := fmt.Sprintf("%s/stack-%s/", c.username, stackName)
+	for _, branch := range existing {
+		uuid := strings.TrimPrefix(branch, prefix)
+		if branch == stackCtx.Stack.Branch || !validUUID(uuid) || stackCtx.FindChange(uuid) != nil {
+			continue
+		}
+
+		hash, err := c.git.GetCommitHash(branch)
+		if err != nil {
+			return report, fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+		}
+		if branch == currentBranch {
+			report.Actions = append(report.Actions, RepairAction{Branch: branch, Action: "skipped", From: hash,
+				Reason: "branch is checked out; switch to the TOP branch and repair again"})
+			continue
+		}
+		if err := c.git.DeleteBranch(branch, true); err != nil {
+			return report, fmt.Errorf("failed to delete branch %s: %w", branch, err)
+		}
+		report.Actions = append(report.Actions, RepairAction{Branch: branch, Action: "deleted", From: hash})
+	}
+
+	return report, nil
+}
+
+// reassignDuplicateUUIDs gives new PR-UUIDs to the commits whose PR-UUID another commit in the
+// stack also carries, one commit at a time from the bottom of the stack. Of each group, the
+// commit the PR was pushed from keeps the PR-UUID, or the lowest one if none was.
+func (c *Client) reassignDuplicateUUIDs(stackCtx *StackContext) ([]UUIDReassignment, error) {
+	if !stackCtx.OnTopBranch() {
+		return nil, fmt.Errorf("commits in stack '%s' share a PR-UUID: check out its TOP branch to repair them", stackCtx.StackName)
+	}
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return nil, fmt.Errorf("cannot give commits new PR-UUIDs with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	var reassigned []UUIDReassignment
+	for {
+		change := firstDuplicateToReassign(stackCtx)
+		if change == nil {
+			return reassigned, nil
+		}
+
+		commit, err := c.git.GetCommit(change.CommitHash)
+		if err != nil {
+			return reassigned, err
+		}
+		newUUID := GenerateUUID()
+		msg := commit.Message
+		msg.Trailers = withStackTrailers(msg.Trailers, newUUID, stackCtx.StackName)
+
+		parent, err := c.git.GetParentCommit(change.CommitHash)
+		if err != nil {
+			return reassigned, err
+		}
+		tree, err := c.git.GetCommitTree(change.CommitHash)
+		if err != nil {
+			return reassigned, err
+		}
+		newHash, err := c.git.CommitTreeAs(tree, parent, msg.String(), commit.Meta)
+		if err != nil {
+			return reassigned, err
+		}
+
+		originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+		if err != nil {
+			return reassigned, fmt.Errorf("failed to get stack head: %w", err)
+		}
+		if _, err := c.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+			StackName:         stackCtx.StackName,
+			StackBranch:       stackCtx.Stack.Branch,
+			OldCommitHash:     change.CommitHash,
+			NewCommitHash:     newHash,
+			OriginalStackHead: originalHead,
+		}); err != nil {
+			return reassigned, err
+		}
+		reassigned = append(reassigned, UUIDReassignment{Title: change.Title, OldUUID: change.UUID, NewUUID: newUUID})
+
+		stackCtx, err = c.GetStackContextForRepair(stackCtx.StackName)
+		if err != nil {
+			return reassigned, fmt.Errorf("failed to reload stack: %w", err)
+		}
+	}
+}
+
+// firstDuplicateToReassign returns the lowest active change whose commit shares its PR-UUID
+// with another commit and isn't the one that keeps it, or nil if there is none
+func firstDuplicateToReassign(stackCtx *StackContext) *model.Change {
+	keep := make(map[string]string, len(stackCtx.DuplicateUUIDs))
+	for uuid, hashes := range stackCtx.DuplicateUUIDs {
+		keep[uuid] = hashes[0]
+		if change := stackCtx.FindChange(uuid); change != nil && change.PR != nil && slices.Contains(hashes, change.PR.CommitHash) {
+			keep[uuid] = change.PR.CommitHash
+		}
+	}
+
+	for _, change := range stackCtx.ActiveChanges {
+		if kept, ok := keep[change.UUID]; ok && change.CommitHash != kept {
+			return change
+		}
+	}
+	return nil
+}
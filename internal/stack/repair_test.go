@@ -0,0 +1,186 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestRepairStack(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+
+	// setup creates a three-change stack where every change has a UUID branch at its commit
+	setup := func(t *testing.T) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		for i, uuid := range uuids {
+			createCommit := testutil.CreateCommitWithTrailers
+			if i == 0 {
+				// A teammate's change, whose authorship giving it a new PR-UUID must keep
+				createCommit = createCommitByOtherAuthor
+			}
+			_ = createCommit(t, gitClient, fmt.Sprintf("Change %d", i+1), "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		for _, change := range stackCtx.ActiveChanges {
+			require.NoError(t, gitClient.CreateBranchAt(stackCtx.FormatUUIDBranch(change.UUID), change.CommitHash))
+		}
+		return stackClient, stackCtx
+	}
+
+	// assertInSync checks every active change's UUID branch points at its commit
+	assertInSync := func(t *testing.T, stackClient *Client, stackCtx *StackContext) {
+		for _, change := range stackCtx.ActiveChanges {
+			hash, err := stackClient.git.GetCommitHash(stackCtx.FormatUUIDBranch(change.UUID))
+			require.NoError(t, err)
+			assert.Equal(t, change.CommitHash, hash, "branch of %s", change.Title)
+		}
+	}
+
+	t.Run("InSync", func(t *testing.T) {
+		stackClient, _ := setup(t)
+
+		report, err := stackClient.RepairStack("test-stack")
+		require.NoError(t, err)
+		assert.Empty(t, report.Actions)
+	})
+
+	t.Run("MissingBranch", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		branch := stackCtx.FormatUUIDBranch(uuids[1])
+		require.NoError(t, stackClient.git.DeleteBranch(branch, true))
+
+		report, err := stackClient.RepairStack("test-stack")
+		require.NoError(t, err)
+
+		assert.Equal(t, []RepairAction{
+			{Branch: branch, Action: "created", To: stackCtx.ActiveChanges[1].CommitHash},
+		}, report.Actions)
+		assertInSync(t, stackClient, stackCtx)
+	})
+
+	t.Run("WrongCommit", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		branch := stackCtx.FormatUUIDBranch(uuids[2])
+		wrong := stackCtx.ActiveChanges[0].CommitHash
+		require.NoError(t, stackClient.git.UpdateRef(branch, wrong))
+
+		report, err := stackClient.RepairStack("test-stack")
+		require.NoError(t, err)
+
+		assert.Equal(t, []RepairAction{
+			{Branch: branch, Action: "moved", From: wrong, To: stackCtx.ActiveChanges[2].CommitHash},
+		}, report.Actions)
+		assertInSync(t, stackClient, stackCtx)
+	})
+
+	t.Run("OrphanedBranch", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		orphan := stackCtx.FormatUUIDBranch("9999999999999999")
+		require.NoError(t, stackClient.git.CreateBranchAt(orphan, stackCtx.ActiveChanges[0].CommitHash))
+
+		report, err := stackClient.RepairStack("test-stack")
+		require.NoError(t, err)
+
+		assert.Equal(t, []RepairAction{
+			{Branch: orphan, Action: "deleted", From: stackCtx.ActiveChanges[0].CommitHash},
+		}, report.Actions)
+		assert.False(t, stackClient.git.BranchExists(orphan))
+		assert.True(t, stackClient.git.BranchExists(stackCtx.Stack.Branch))
+		assertInSync(t, stackClient, stackCtx)
+	})
+
+	t.Run("DuplicateUUID", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			pushedAt int // Commit the PR of uuids[0] was pushed from (0 or 3), or -1 for no PR
+			keeper   string
+		}{
+			{name: "KeepsLowest", pushedAt: -1, keeper: "Change 1"},
+			{name: "KeepsPushedCommit", pushedAt: 3, keeper: "Cherry-pick of change 1"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				stackClient, stackCtx := setup(t)
+				dup := createCommitByOtherAuthor(t, stackClient.git.(*git.Client), "Cherry-pick of change 1", "", map[string]string{
+					"PR-UUID":  uuids[0],
+					"PR-Stack": "test-stack",
+				})
+				if tc.pushedAt == 3 {
+					require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{
+						Version: 1,
+						PRs:     map[string]*model.PR{uuids[0]: {PRNumber: 1, State: "open", CommitHash: dup}},
+					}))
+				}
+				_, err := stackClient.GetStackContextByName("test-stack")
+				var dupErr *DuplicateUUIDError
+				require.ErrorAs(t, err, &dupErr)
+
+				report, err := stackClient.RepairStack("test-stack")
+				require.NoError(t, err)
+				require.Len(t, report.Reassigned, 1)
+				assert.Equal(t, uuids[0], report.Reassigned[0].OldUUID)
+				assert.NotEqual(t, uuids[0], report.Reassigned[0].NewUUID)
+
+				stackCtx, err = stackClient.GetStackContextByName("test-stack")
+				require.NoError(t, err)
+				require.Len(t, stackCtx.ActiveChanges, 4)
+				assert.Equal(t, tc.keeper, stackCtx.FindChange(uuids[0]).Title)
+				assert.Equal(t, report.Reassigned[0].NewUUID, stackCtx.FindChange(report.Reassigned[0].NewUUID).UUID)
+				assertOtherAuthor(t, stackClient.git.(*git.Client), stackCtx.FindChange(report.Reassigned[0].NewUUID).CommitHash)
+				assertInSync(t, stackClient, stackCtx)
+			})
+		}
+	})
+
+	t.Run("DuplicateUUIDNeedsCleanWorktree", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		gitClient := stackClient.git.(*git.Client)
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, "Cherry-pick of change 1", "", map[string]string{
+			"PR-UUID":  uuids[0],
+			"PR-Stack": "test-stack",
+		})
+		require.NoError(t, os.WriteFile(filepath.Join(gitClient.GitRoot(), "dirty.txt"), []byte("dirty"), 0644))
+		cmd := exec.Command("git", "add", "dirty.txt")
+		cmd.Dir = gitClient.GitRoot()
+		require.NoError(t, cmd.Run())
+
+		_, err := stackClient.RepairStack("test-stack")
+		assert.ErrorIs(t, err, ErrUncommittedChanges)
+	})
+
+	t.Run("LeavesTOPBranchAlone", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		topBefore, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		require.NoError(t, stackClient.git.DeleteBranch(stackCtx.FormatUUIDBranch(uuids[0]), true))
+
+		_, err = stackClient.RepairStack("test-stack")
+		require.NoError(t, err)
+
+		topAfter, err := stackClient.git.GetCommitHash(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, topBefore, topAfter)
+	})
+}
@@ -0,0 +1,85 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpdateChangeMessage replaces the title and body of an active change's commit without
+// checking it out. The commit is recreated from the same tree and parent with the new message
+// (its PR-UUID and PR-Stack trailers are kept), later commits are rebased onto it and the UUID
+// branches are moved. The PR picks up the new title and description on the next push.
+func (c *Client) UpdateChangeMessage(stackCtx *StackContext, uuid, newTitle, newBody string) error {
+	if !stackCtx.IsStack() || stackCtx.OnUUIDBranch() {
+		return fmt.Errorf("%w to update a commit message", ErrNotOnTopBranch)
+	}
+
+	change := stackCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+	if change.PR.IsMerged() {
+		return fmt.Errorf("cannot update the message of merged change #%d", change.PR.PRNumber)
+	}
+
+	newTitle = strings.TrimSpace(newTitle)
+	if newTitle == "" {
+		return fmt.Errorf("commit title cannot be empty")
+	}
+	if strings.ContainsAny(newTitle, "\r\n") {
+		return fmt.Errorf("commit title must be a single line")
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("cannot update a commit message with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	commit, err := c.git.GetCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+	newBody = strings.TrimSpace(newBody)
+	if newTitle == commit.Message.Title && newBody == commit.Message.Body {
+		return nil
+	}
+	msg := commit.Message
+	msg.Title = newTitle
+	msg.Body = newBody
+
+	parent, err := c.git.GetParentCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+	tree, err := c.git.GetCommitTree(change.CommitHash)
+	if err != nil {
+		return err
+	}
+	newHash, err := c.git.CommitTreeAs(tree, parent, msg.String(), commit.Meta)
+	if err != nil {
+		return err
+	}
+
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+
+	if _, err := c.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+		StackName:         stackCtx.StackName,
+		StackBranch:       stackCtx.Stack.Branch,
+		OldCommitHash:     change.CommitHash,
+		NewCommitHash:     newHash,
+		OriginalStackHead: originalHead,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := c.UpdateUUIDBranches(stackCtx.StackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestUpdateChangeMessage(t *testing.T) {
+	uuids := []string{"1111111111111111", "2222222222222222", "3333333333333333"}
+
+	setup := func(t *testing.T) (*Client, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+
+		gitClient := stackClient.git.(*git.Client)
+		for i, uuid := range uuids {
+			createCommit := testutil.CreateCommitWithTrailers
+			if i == 1 {
+				// The middle change is a teammate's, whose authorship rewording must keep
+				createCommit = createCommitByOtherAuthor
+			}
+			hash := createCommit(t, gitClient, "Change "+uuid[:1], "Body "+uuid[:1], map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+			require.NoError(t, gitClient.CreateBranchAt("test-user/stack-test-stack/"+uuid, hash))
+		}
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		return stackClient, stackCtx
+	}
+
+	t.Run("MiddleChange", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		gitClient := stackClient.git.(*git.Client)
+		oldTopTree, err := gitClient.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		bottom := stackCtx.ActiveChanges[0]
+		middle := stackCtx.ActiveChanges[1]
+		middleTree, err := gitClient.GetCommitTree(middle.CommitHash)
+		require.NoError(t, err)
+
+		require.NoError(t, stackClient.UpdateChangeMessage(stackCtx, uuids[1], "  Reworded middle  ", "New body\n\nSecond paragraph"))
+
+		stackCtx, err = stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		require.Len(t, stackCtx.ActiveChanges, 3)
+		for i, change := range stackCtx.ActiveChanges {
+			assert.Equal(t, uuids[i], change.UUID, "stack order is unchanged")
+		}
+		assert.Equal(t, "Change 1", stackCtx.ActiveChanges[0].Title)
+		assert.Equal(t, "Change 3", stackCtx.ActiveChanges[2].Title)
+
+		reworded := stackCtx.ActiveChanges[1]
+		assert.Equal(t, "Reworded middle", reworded.Title)
+		assert.Equal(t, "New body\n\nSecond paragraph", reworded.Description)
+		assert.NotEqual(t, middle.CommitHash, reworded.CommitHash)
+
+		commit, err := gitClient.GetCommit(reworded.CommitHash)
+		require.NoError(t, err)
+		assert.Equal(t, uuids[1], commit.Message.Trailers["PR-UUID"])
+		assert.Equal(t, "test-stack", commit.Message.Trailers["PR-Stack"])
+		assertOtherAuthor(t, gitClient, reworded.CommitHash)
+
+		// Only the message changed: the commit's files and the stack's final tree are the same
+		tree, err := gitClient.GetCommitTree(reworded.CommitHash)
+		require.NoError(t, err)
+		assert.Equal(t, middleTree, tree)
+		topTree, err := gitClient.GetCommitTree(stackCtx.Stack.Branch)
+		require.NoError(t, err)
+		assert.Equal(t, oldTopTree, topTree)
+
+		// The bottom change is untouched; the changes above are rebased and their branches moved
+		assert.Equal(t, bottom.CommitHash, stackCtx.ActiveChanges[0].CommitHash)
+		for _, change := range stackCtx.ActiveChanges {
+			hash, err := gitClient.GetCommitHash(stackCtx.FormatUUIDBranch(change.UUID))
+			require.NoError(t, err)
+			assert.Equal(t, change.CommitHash, hash)
+		}
+
+		currentBranch, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, stackCtx.Stack.Branch, currentBranch)
+	})
+
+	t.Run("UnchangedMessageIsNoOp", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+		before := stackCtx.ActiveChanges[1].CommitHash
+
+		require.NoError(t, stackClient.UpdateChangeMessage(stackCtx, uuids[1], "Change 2", "Body 2"))
+
+		stackCtx, err := stackClient.GetStackContextByName("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, before, stackCtx.ActiveChanges[1].CommitHash)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		stackClient, stackCtx := setup(t)
+
+		assert.ErrorContains(t, stackClient.UpdateChangeMessage(stackCtx, uuids[0], "   ", ""), "commit title cannot be empty")
+		assert.ErrorContains(t, stackClient.UpdateChangeMessage(stackCtx, uuids[0], "Two\nlines", ""), "commit title must be a single line")
+		assert.ErrorContains(t, stackClient.UpdateChangeMessage(stackCtx, "9999999999999999", "Title", ""), "is not an active change")
+
+		stackCtx.ActiveChanges[0].PR = &model.PR{PRNumber: 101, State: "merged"}
+		assert.ErrorContains(t, stackClient.UpdateChangeMessage(stackCtx, uuids[0], "Title", ""), "cannot update the message of merged change #101")
+
+		testutil.WriteFile(t, stackClient.git.GitRoot(), "dirty.txt", "uncommitted")
+		assert.ErrorIs(t, stackClient.UpdateChangeMessage(stackCtx, uuids[1], "Title", ""), ErrUncommittedChanges)
+	})
+}
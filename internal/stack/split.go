@@ -0,0 +1,380 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// SplitState records an interactive split started by SplitChange. It is stored in the stacks
+// root rather than a stack directory so 'stack split --continue' and '--abort' find it
+// without having to work out the stack first.
+type SplitState struct {
+	StackName         string `json:"stack_name"`
+	UUID              string `json:"uuid"`                // Change being split
+	OriginalCommit    string `json:"original_commit"`     // Commit of the change before the split
+	OriginalStackHead string `json:"original_stack_head"` // TOP branch head before the split
+}
+
+func (c *Client) getSplitStatePath() string {
+	return filepath.Join(c.getStacksRootDir(), "split-state.json")
+}
+
+// LoadSplitState returns the in-progress split, or nil if no split is in progress
+func (c *Client) LoadSplitState() (*SplitState, error) {
+	data, err := os.ReadFile(c.getSplitStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read split state: %w", err)
+	}
+
+	var state SplitState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse split state: %w", err)
+	}
+	return &state, nil
+}
+
+// SplitInProgress reports whether SplitChange was called for the stack without a matching
+// ContinueSplit or AbortSplit
+func (c *Client) SplitInProgress(stackName string) bool {
+	state, err := c.LoadSplitState()
+	return err == nil && state != nil && state.StackName == stackName
+}
+
+func (c *Client) saveSplitState(state *SplitState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal split state: %w", err)
+	}
+	if err := os.WriteFile(c.getSplitStatePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write split state: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) clearSplitState() error {
+	if err := os.Remove(c.getSplitStatePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove split state: %w", err)
+	}
+	return nil
+}
+
+// validateSplit checks that the change with the given UUID can be split and returns it
+func (c *Client) validateSplit(stackCtx *StackContext, uuid string) (*model.Change, error) {
+	if !stackCtx.IsStack() || stackCtx.OnUUIDBranch() {
+		return nil, fmt.Errorf("%w to split a change", ErrNotOnTopBranch)
+	}
+
+	change := stackCtx.FindChangeInActive(uuid)
+	if change == nil {
+		return nil, fmt.Errorf("change %s is not an active change in stack '%s'", uuid, stackCtx.StackName)
+	}
+	if change.PR.IsMerged() {
+		return nil, fmt.Errorf("cannot split merged change #%d", change.PR.PRNumber)
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return nil, fmt.Errorf("cannot split with %w; commit or stash them first", ErrUncommittedChanges)
+	}
+
+	state, err := c.LoadSplitState()
+	if err != nil {
+		return nil, err
+	}
+	if state != nil {
+		return nil, fmt.Errorf("a split of stack '%s' is already in progress: run 'stack split --continue' or 'stack split --abort'", state.StackName)
+	}
+
+	return change, nil
+}
+
+// SplitChange starts an interactive split of the change with the given UUID. The change's
+// UUID branch is checked out at its commit and soft reset to its parent, leaving its changes
+// staged so the user can make two commits from them. The post-commit hook leaves the stack
+// alone while the split is in progress.
+//
+// ContinueSplit turns the two commits into changes and rebases the rest of the stack;
+// AbortSplit returns to the TOP branch without changing anything.
+func (c *Client) SplitChange(stackCtx *StackContext, uuid string) error {
+	change, err := c.validateSplit(stackCtx, uuid)
+	if err != nil {
+		return err
+	}
+
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+
+	if err := c.saveSplitState(&SplitState{
+		StackName:         stackCtx.StackName,
+		UUID:              uuid,
+		OriginalCommit:    change.CommitHash,
+		OriginalStackHead: originalHead,
+	}); err != nil {
+		return err
+	}
+
+	if err := c.git.EnsureBranchAt(stackCtx.FormatUUIDBranch(uuid), change.CommitHash); err != nil {
+		return err
+	}
+	if err := c.git.ResetSoft(change.CommitHash + "^"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ContinueSplit finishes a split started by SplitChange. HEAD must be exactly two commits
+// above the split change's parent. The first commit keeps the change's PR-UUID, so its PR
+// survives, and the second gets a new PR-UUID; the rest of the stack is rebased on top.
+func (c *Client) ContinueSplit() error {
+	state, err := c.LoadSplitState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("no split in progress")
+	}
+
+	hasChanges, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+	}
+	if hasChanges {
+		return fmt.Errorf("commit the remaining changes before continuing the split")
+	}
+
+	parent, err := c.git.GetParentCommit(state.OriginalCommit)
+	if err != nil {
+		return err
+	}
+	commits, err := c.git.GetCommits("HEAD", parent)
+	if err != nil {
+		return fmt.Errorf("failed to get split commits: %w", err)
+	}
+	if len(commits) != 2 {
+		return fmt.Errorf("expected 2 commits for the split, found %d", len(commits))
+	}
+
+	stackCtx, err := c.GetStackContextByName(state.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	change := stackCtx.FindChange(state.UUID)
+	if change == nil {
+		return fmt.Errorf("change %s is no longer in stack '%s'", state.UUID, state.StackName)
+	}
+
+	firstTree, err := c.git.GetCommitTree(commits[0].Hash)
+	if err != nil {
+		return err
+	}
+	secondTree, err := c.git.GetCommitTree(commits[1].Hash)
+	if err != nil {
+		return err
+	}
+
+	// Both halves are still the original change's work, whoever commits them during the split
+	author, err := c.git.GetCommitMeta(state.OriginalCommit)
+	if err != nil {
+		return err
+	}
+
+	if err := c.spliceSplit(stackCtx, change, state.OriginalStackHead, author,
+		commits[0].Message, firstTree, commits[1].Message, secondTree); err != nil {
+		return err
+	}
+
+	return c.clearSplitState()
+}
+
+// AbortSplit abandons a split started by SplitChange, moves the change's UUID branch back to
+// its original commit and checks out the TOP branch. The stack is left as it was before the
+// split.
+func (c *Client) AbortSplit() error {
+	state, err := c.LoadSplitState()
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("no split in progress")
+	}
+
+	stackCtx, err := c.GetStackContextByName(state.StackName)
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	// Drop the staged split so the TOP branch checks out cleanly
+	if err := c.git.ResetHard("HEAD"); err != nil {
+		return err
+	}
+	if err := c.git.CheckoutBranch(stackCtx.Stack.Branch); err != nil {
+		return err
+	}
+	branch := stackCtx.FormatUUIDBranch(state.UUID)
+	if c.git.BranchExists(branch) {
+		if err := c.git.UpdateRef(branch, state.OriginalCommit); err != nil {
+			return fmt.Errorf("failed to restore branch %s: %w", branch, err)
+		}
+	}
+
+	return c.clearSplitState()
+}
+
+// SplitChangeByPaths splits the change with the given UUID into two changes without user
+// interaction. The first change holds the change's edits to files under firstCommitPaths and
+// keeps the change's PR-UUID, description and PR; the second holds the remaining edits and
+// gets a new PR-UUID. titles gives the titles of the two changes. The rest of the stack is
+// rebased on top.
+func (c *Client) SplitChangeByPaths(stackCtx *StackContext, uuid string, firstCommitPaths []string, titles [2]string) error {
+	if len(firstCommitPaths) == 0 {
+		return fmt.Errorf("no paths given for the first change")
+	}
+	for _, title := range titles {
+		if strings.TrimSpace(title) == "" {
+			return fmt.Errorf("both changes need a title")
+		}
+	}
+
+	change, err := c.validateSplit(stackCtx, uuid)
+	if err != nil {
+		return err
+	}
+
+	originalHead, err := c.git.GetCommitHash(stackCtx.Stack.Branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack head: %w", err)
+	}
+	original, err := c.git.GetCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+	parent, err := c.git.GetParentCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+
+	parentTree, err := c.git.GetCommitTree(parent)
+	if err != nil {
+		return err
+	}
+	firstTree, err := c.git.TreeWithPaths(parent, change.CommitHash, firstCommitPaths)
+	if err != nil {
+		return fmt.Errorf("failed to build first change: %w", err)
+	}
+	secondTree, err := c.git.GetCommitTree(change.CommitHash)
+	if err != nil {
+		return err
+	}
+	if firstTree == parentTree {
+		return fmt.Errorf("the change doesn't modify any of the given paths")
+	}
+	if firstTree == secondTree {
+		return fmt.Errorf("the given paths cover the whole change; nothing is left for the second change")
+	}
+
+	firstMsg := original.Message
+	firstMsg.Title = titles[0]
+	secondMsg := git.CommitMessage{
+		Title:    titles[1],
+		Trailers: withPropagatedTrailers(nil, original.Message.Trailers, c.PropagateTrailers()),
+	}
+
+	return c.spliceSplit(stackCtx, change, originalHead, original.Meta, firstMsg, firstTree, secondMsg, secondTree)
+}
+
+// spliceSplit replaces change on the TOP branch with two commits built from the given
+// messages and trees, authored by author, sets their trailers, rebases the rest of the stack
+// onto the second commit and updates the UUID branches.
+func (c *Client) spliceSplit(stackCtx *StackContext, change *model.Change, originalHead string, author git.CommitMeta,
+	firstMsg git.CommitMessage, firstTree string, secondMsg git.CommitMessage, secondTree string) error {
+	parent, err := c.git.GetParentCommit(change.CommitHash)
+	if err != nil {
+		return err
+	}
+
+	firstMsg.Trailers = withStackTrailers(firstMsg.Trailers, change.UUID, stackCtx.StackName)
+	secondMsg.Trailers = withStackTrailers(secondMsg.Trailers, GenerateUUID(), stackCtx.StackName)
+
+	firstHash, err := c.git.CommitTreeAs(firstTree, parent, firstMsg.String(), author)
+	if err != nil {
+		return err
+	}
+	secondHash, err := c.git.CommitTreeAs(secondTree, firstHash, secondMsg.String(), author)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.RebaseSubsequentCommitsWithRecovery(RebaseParams{
+		StackName:         stackCtx.StackName,
+		StackBranch:       stackCtx.Stack.Branch,
+		OldCommitHash:     change.CommitHash,
+		NewCommitHash:     secondHash,
+		OriginalStackHead: originalHead,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := c.UpdateUUIDBranches(stackCtx.StackName); err != nil {
+		return fmt.Errorf("failed to update UUID branches: %w", err)
+	}
+	return nil
+}
+
+// withStackTrailers returns a copy of trailers with PR-UUID and PR-Stack set
+func withStackTrailers(trailers map[string]string, uuid string, stackName string) map[string]string {
+	result := make(map[string]string, len(trailers)+2)
+	for key, value := range trailers {
+		result[key] = value
+	}
+	result["PR-UUID"] = uuid
+	result["PR-Stack"] = stackName
+	return result
+}
+
+// withPropagatedTrailers returns a copy of trailers with each of the given keys copied from
+// source, unless trailers already has it
+func withPropagatedTrailers(trailers map[string]string, source map[string]string, keys []string) map[string]string {
+	result := make(map[string]string, len(trailers)+len(keys))
+	for key, value := range trailers {
+		result[key] = value
+	}
+	for _, key := range keys {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		if value, ok := source[key]; ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// customTrailers returns the trailers other than PR-UUID and PR-Stack, or nil if there are none
+func customTrailers(trailers map[string]string) map[string]string {
+	var result map[string]string
+	for key, value := range trailers {
+		if key == "PR-UUID" || key == "PR-Stack" {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[key] = value
+	}
+	return result
+}
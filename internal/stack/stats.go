@@ -0,0 +1,112 @@
+package stack
+
+import (
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/ui"
+)
+
+// ChangeStats holds per-change diff stats for a stack, keyed by change UUID
+type ChangeStats struct {
+	ByUUID map[string]git.DiffStat
+	Total  git.DiffStat
+}
+
+// GetChangeCommitStats computes additions/deletions for every change in the stack.
+// Stats come from the local commit when it is available; merged changes whose commits are
+// gone locally fall back to the PR's stats on GitHub. Changes without either are omitted.
+func (c *Client) GetChangeCommitStats(stackCtx *StackContext) (*ChangeStats, error) {
+	stats := &ChangeStats{ByUUID: make(map[string]git.DiffStat)}
+	byCommit := make(map[string]git.DiffStat)
+
+	for _, change := range stackCtx.AllChanges {
+		stat, ok, err := c.changeDiffStat(change.CommitHash, byCommit)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok && !change.IsLocal() {
+			prStat, err := c.ghFor(stackCtx.Stack).GetPRDiffStat(change.PR.PRNumber)
+			if err != nil {
+				ui.Warningf("Could not get diff stat for PR #%d: %v", change.PR.PRNumber, err)
+				continue
+			}
+			stat = git.DiffStat{
+				Files:     prStat.ChangedFiles,
+				Additions: prStat.Additions,
+				Deletions: prStat.Deletions,
+			}
+			ok = true
+		}
+
+		if !ok {
+			continue
+		}
+
+		stats.ByUUID[change.UUID] = stat
+		stats.Total = stats.Total.Add(stat)
+	}
+
+	return stats, nil
+}
+
+// changeDiffStat returns the diff stat of a local commit, reusing previously computed results.
+// Returns false if the commit is not available locally.
+func (c *Client) changeDiffStat(commitHash string, cache map[string]git.DiffStat) (git.DiffStat, bool, error) {
+	if commitHash == "" {
+		return git.DiffStat{}, false, nil
+	}
+	if stat, ok := cache[commitHash]; ok {
+		return stat, true, nil
+	}
+
+	if _, err := c.git.GetCommitHash(commitHash + "^{commit}"); err != nil {
+		return git.DiffStat{}, false, nil
+	}
+
+	stat, err := c.git.DiffStat(commitHash)
+	if err != nil {
+		return git.DiffStat{}, false, err
+	}
+
+	cache[commitHash] = stat
+	return stat, true, nil
+}
+
+// StackStats summarizes the size of a stack's active changes
+type StackStats struct {
+	Commits int                     // Commits between the stack's base and its TOP branch
+	Total   git.DiffStat            // Diff from the stack's base to its TOP branch
+	ByUUID  map[string]git.DiffStat // Per-change stats for active changes, keyed by UUID
+}
+
+// ComputeStackStats computes the total diff size and commit count of a stack along with
+// per-change breakdowns. It runs several git commands, so callers only compute it on request.
+func (c *Client) ComputeStackStats(stackCtx *StackContext) (StackStats, error) {
+	from, to, err := stackDiffRange(stackCtx)
+	if err != nil {
+		return StackStats{}, err
+	}
+
+	stats := StackStats{ByUUID: make(map[string]git.DiffStat)}
+	stats.Commits, err = c.git.CountCommitsBetween(from, to)
+	if err != nil {
+		return StackStats{}, err
+	}
+	stats.Total, err = c.git.DiffStatBetween(from, to)
+	if err != nil {
+		return StackStats{}, err
+	}
+
+	byCommit := make(map[string]git.DiffStat)
+	for _, change := range stackCtx.ActiveChanges {
+		stat, ok, err := c.changeDiffStat(change.CommitHash, byCommit)
+		if err != nil {
+			return StackStats{}, err
+		}
+		if ok {
+			stats.ByUUID[change.UUID] = stat
+		}
+	}
+
+	return stats, nil
+}
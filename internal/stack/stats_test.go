@@ -0,0 +1,105 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestGetChangeCommitStats(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+	mockGithubClient.On("GetPRDiffStat", 100).Return(&gh.PRDiffStat{Additions: 10, Deletions: 4, ChangedFiles: 3}, nil).Once()
+
+	stackClient := NewTestStack(t, mockGithubClient)
+
+	stack, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+	mergedUUID := "0000000000000001"
+
+	// Each commit adds a single two-line file
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "First change", "Body", map[string]string{
+		"PR-UUID":  uuid1,
+		"PR-Stack": "test-stack",
+	})
+	_ = testutil.CreateCommitWithTrailers(t, stackClient.git.(*git.Client), "Second change", "Body", map[string]string{
+		"PR-UUID":  uuid2,
+		"PR-Stack": "test-stack",
+	})
+
+	// A merged change whose commit no longer exists locally falls back to GitHub
+	stack.MergedChanges = []model.Change{
+		{
+			Title:      "Merged change",
+			UUID:       mergedUUID,
+			CommitHash: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PR:         &model.PR{PRNumber: 100, State: "merged"},
+		},
+	}
+	require.NoError(t, stackClient.SaveStack(stack))
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+	require.Len(t, stackCtx.AllChanges, 3)
+
+	stats, err := stackClient.GetChangeCommitStats(stackCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, git.DiffStat{Files: 3, Additions: 10, Deletions: 4}, stats.ByUUID[mergedUUID])
+	assert.Equal(t, git.DiffStat{Files: 1, Additions: 2, Deletions: 0}, stats.ByUUID[uuid1])
+	assert.Equal(t, git.DiffStat{Files: 1, Additions: 2, Deletions: 0}, stats.ByUUID[uuid2])
+	assert.Equal(t, git.DiffStat{Files: 5, Additions: 14, Deletions: 4}, stats.Total, "total should sum per-change stats")
+
+	mockGithubClient.AssertExpectations(t)
+}
+
+func TestComputeStackStats(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	gitClient := stackClient.git.(*git.Client)
+	gitRoot := gitClient.GitRoot()
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+
+	// Both changes edit shared.go; the first also adds a binary file, which numstat reports
+	// without line counts
+	testutil.WriteFile(t, gitRoot, "shared.go", "one\ntwo\nthree\n")
+	testutil.WriteFile(t, gitRoot, "logo.png", "\x00\x01\x02")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "First change", "Body", map[string]string{
+		"PR-UUID":  uuid1,
+		"PR-Stack": "test-stack",
+	})
+	testutil.WriteFile(t, gitRoot, "shared.go", "one\n2\nthree\nfour\n")
+	_ = testutil.CreateCommitWithTrailers(t, gitClient, "Second change", "Body", map[string]string{
+		"PR-UUID":  uuid2,
+		"PR-Stack": "test-stack",
+	})
+
+	stackCtx, err := stackClient.GetStackContextByName("test-stack")
+	require.NoError(t, err)
+
+	stats, err := stackClient.ComputeStackStats(stackCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, stats.Commits)
+	// file-First change.txt, logo.png, shared.go
+	assert.Equal(t, git.DiffStat{Files: 3, Additions: 5, Deletions: 0}, stats.ByUUID[uuid1])
+	// file-Second change.txt, shared.go
+	assert.Equal(t, git.DiffStat{Files: 2, Additions: 4, Deletions: 1}, stats.ByUUID[uuid2])
+	// shared.go counts once in the total and its rewritten line never existed in the base
+	assert.Equal(t, git.DiffStat{Files: 4, Additions: 8, Deletions: 0}, stats.Total)
+}
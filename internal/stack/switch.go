@@ -0,0 +1,84 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// ChangeSelector picks a change of a stack for SwitchToChange. Exactly one field is set.
+type ChangeSelector struct {
+	Position int    // 1-indexed position in the stack, as shown by 'stack status'
+	UUID     string // PR-UUID of the change
+	PRNumber int    // Number of the change's PR
+}
+
+// String describes the selector for error messages
+func (s ChangeSelector) String() string {
+	switch {
+	case s.Position > 0:
+		return fmt.Sprintf("position %d", s.Position)
+	case s.UUID != "":
+		return "UUID " + s.UUID
+	default:
+		return fmt.Sprintf("PR #%d", s.PRNumber)
+	}
+}
+
+// resolve looks up the selected change, returning nil if no change matches
+func (s ChangeSelector) resolve(stackCtx *StackContext) (*model.Change, error) {
+	set := 0
+	for _, isSet := range []bool{s.Position != 0, s.UUID != "", s.PRNumber != 0} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("a change selector needs exactly one of a position, a UUID or a PR number")
+	}
+
+	switch {
+	case s.Position != 0:
+		return stackCtx.ChangeByPosition(s.Position), nil
+	case s.UUID != "":
+		return stackCtx.FindChange(s.UUID), nil
+	default:
+		return stackCtx.ChangeByPRNumber(s.PRNumber), nil
+	}
+}
+
+// SwitchToChange checks out a change of the stack called stackName, whichever stack is
+// currently checked out, the same way 'stack edit' does: the change's UUID branch, or the
+// TOP branch for the topmost change. Returns the stack's context and the branch checked out.
+// Merged changes can't be switched to, and neither can any change with uncommitted changes
+// in the working tree.
+func (c *Client) SwitchToChange(stackName string, selector ChangeSelector) (*StackContext, string, error) {
+	name, err := c.ResolveStackName(stackName, false)
+	if err != nil {
+		return nil, "", err
+	}
+	stackCtx, err := c.GetStackContextByName(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load stack: %w", err)
+	}
+
+	change, err := selector.resolve(stackCtx)
+	if err != nil {
+		return nil, "", err
+	}
+	if change == nil {
+		return nil, "", fmt.Errorf("no change with %s in stack '%s'", selector, name)
+	}
+	if change.UUID == "" {
+		return nil, "", fmt.Errorf("cannot switch to change #%d (%s): commit missing PR-UUID trailer", change.Position, change.Title)
+	}
+	if stackCtx.FindChangeInActive(change.UUID) == nil {
+		return nil, "", fmt.Errorf("cannot switch to change #%d (%s): it has been merged", change.Position, change.Title)
+	}
+
+	branch, err := c.CheckoutChangeForEditing(stackCtx, change, EditOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	return stackCtx, branch, nil
+}
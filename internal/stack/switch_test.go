@@ -0,0 +1,102 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestSwitchToChange(t *testing.T) {
+	uuid1 := "1111111111111111"
+	uuid2 := "2222222222222222"
+	uuid3 := "3333333333333333"
+
+	// setup creates a three-change stack, with PRs for the lower two, and checks out main
+	setup := func(t *testing.T) (*Client, *git.Client) {
+		mockGithubClient := &gh.MockGithubClient{}
+		mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+		stackClient := NewTestStack(t, mockGithubClient)
+		gitClient := stackClient.git.(*git.Client)
+
+		_, err := stackClient.CreateStack("test-stack", "main")
+		require.NoError(t, err)
+		for _, uuid := range []string{uuid1, uuid2, uuid3} {
+			_ = testutil.CreateCommitWithTrailers(t, gitClient, "Change "+uuid[:1], "", map[string]string{
+				"PR-UUID":  uuid,
+				"PR-Stack": "test-stack",
+			})
+		}
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "open"},
+			uuid2: {PRNumber: 102, State: "open"},
+		}}))
+		require.NoError(t, gitClient.CheckoutBranch("main"))
+		return stackClient, gitClient
+	}
+
+	tests := []struct {
+		name         string
+		selector     ChangeSelector
+		expectUUID   string
+		expectBranch string
+	}{
+		{"ByPosition", ChangeSelector{Position: 1}, uuid1, "test-user/stack-test-stack/" + uuid1},
+		{"ByUUID", ChangeSelector{UUID: uuid2}, uuid2, "test-user/stack-test-stack/" + uuid2},
+		{"ByPRNumber", ChangeSelector{PRNumber: 102}, uuid2, "test-user/stack-test-stack/" + uuid2},
+		{"TopmostChange", ChangeSelector{Position: 3}, uuid3, "test-user/stack-test-stack/TOP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stackClient, gitClient := setup(t)
+
+			stackCtx, branch, err := stackClient.SwitchToChange("test-stack", tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, "test-stack", stackCtx.StackName)
+			assert.Equal(t, tt.expectBranch, branch)
+
+			currentBranch, err := gitClient.GetCurrentBranch()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectBranch, currentBranch)
+			head, err := gitClient.GetCommitHash("HEAD")
+			require.NoError(t, err)
+			assert.Equal(t, stackCtx.FindChange(tt.expectUUID).CommitHash, head)
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		stackClient, gitClient := setup(t)
+
+		_, _, err := stackClient.SwitchToChange("test-stack", ChangeSelector{Position: 4})
+		assert.ErrorContains(t, err, "no change with position 4 in stack 'test-stack'")
+		_, _, err = stackClient.SwitchToChange("test-stack", ChangeSelector{PRNumber: 999})
+		assert.ErrorContains(t, err, "no change with PR #999")
+		_, _, err = stackClient.SwitchToChange("test-stack", ChangeSelector{Position: 1, UUID: uuid1})
+		assert.ErrorContains(t, err, "exactly one of a position, a UUID or a PR number")
+		_, _, err = stackClient.SwitchToChange("test-stack", ChangeSelector{})
+		assert.ErrorContains(t, err, "exactly one of a position, a UUID or a PR number")
+		_, _, err = stackClient.SwitchToChange("missing-stack", ChangeSelector{Position: 1})
+		assert.ErrorContains(t, err, "failed to load stack")
+
+		// Nothing was checked out
+		currentBranch, err := gitClient.GetCurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "main", currentBranch)
+	})
+
+	t.Run("MergedChange", func(t *testing.T) {
+		stackClient, _ := setup(t)
+		require.NoError(t, stackClient.savePRs("test-stack", &model.PRData{Version: 1, PRs: map[string]*model.PR{
+			uuid1: {PRNumber: 101, State: "merged"},
+		}}))
+
+		_, _, err := stackClient.SwitchToChange("test-stack", ChangeSelector{PRNumber: 101})
+		assert.ErrorContains(t, err, "cannot switch to change #1 (Change 1): it has been merged")
+	})
+}
@@ -0,0 +1,93 @@
+package stack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/bjulian5/stack/internal/git"
+)
+
+// StackTemplate describes a sequence of placeholder changes used to scaffold a new stack.
+// Templates are stored in .git/stack/templates/<name>.json.
+type StackTemplate struct {
+	Changes []TemplateChange `json:"changes"`
+}
+
+// TemplateChange is a single placeholder change in a stack template
+type TemplateChange struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// GenerateUUID generates a 16-character hex UUID for PR identification
+func GenerateUUID() string {
+	u := uuid.New()
+	hexStr := strings.ReplaceAll(u.String(), "-", "")
+	return hexStr[:16]
+}
+
+func (c *Client) getTemplatePath(templateName string) string {
+	return filepath.Join(c.getStacksRootDir(), "templates", templateName+".json")
+}
+
+// LoadTemplate loads a stack template by name
+func (c *Client) LoadTemplate(templateName string) (*StackTemplate, error) {
+	data, err := os.ReadFile(c.getTemplatePath(templateName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("template '%s' not found in %s", templateName, filepath.Dir(c.getTemplatePath(templateName)))
+		}
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var template StackTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse template '%s': %w", templateName, err)
+	}
+
+	if len(template.Changes) == 0 {
+		return nil, fmt.Errorf("template '%s' has no changes", templateName)
+	}
+	for i, change := range template.Changes {
+		if strings.TrimSpace(change.Title) == "" {
+			return nil, fmt.Errorf("template '%s': change %d has no title", templateName, i+1)
+		}
+	}
+
+	return &template, nil
+}
+
+// CreateStackFromTemplate creates a new stack and adds an empty placeholder commit for each
+// change in the template. Each commit carries the PR-UUID and PR-Stack trailers so it shows up
+// as a change that can be edited and amended like any other.
+func (c *Client) CreateStackFromTemplate(stackName, base, templateName string) error {
+	template, err := c.LoadTemplate(templateName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.CreateStack(stackName, base); err != nil {
+		return err
+	}
+
+	for _, change := range template.Changes {
+		msg := git.CommitMessage{
+			Title:    change.Title,
+			Body:     change.Description,
+			Trailers: make(map[string]string),
+		}
+		msg.AddTrailer("PR-UUID", GenerateUUID())
+		msg.AddTrailer("PR-Stack", stackName)
+
+		if err := c.git.CommitEmpty(msg.String()); err != nil {
+			return fmt.Errorf("failed to create placeholder for '%s': %w", change.Title, err)
+		}
+	}
+
+	return nil
+}
@@ -3,28 +3,45 @@ package stack
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/bjulian5/stack/internal/config"
+	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/model"
 )
 
 func generateStackVisualization(stackCtx *StackContext, currentPRNumber int) string {
+	return generateStackVisualizationWithChecks(stackCtx, currentPRNumber, nil)
+}
+
+// generateStackVisualizationWithChecks renders the stack visualization with a CI checks
+// column built from checks (keyed by PR number). The column is omitted when checks is nil.
+func generateStackVisualizationWithChecks(stackCtx *StackContext, currentPRNumber int, checks map[int]*gh.ChecksSummary) string {
+	marker := formatVizCommentMarker(config.DefaultVizCommentMarker, stackCtx.StackName)
+	return renderStackVisualization(stackCtx, currentPRNumber, checks, marker)
+}
+
+// renderStackVisualization renders the stack visualization ending with the given hidden marker
+func renderStackVisualization(stackCtx *StackContext, currentPRNumber int, checks map[int]*gh.ChecksSummary, marker string) string {
 	var sb strings.Builder
 
 	totalPRs := len(stackCtx.AllChanges)
 	sb.WriteString(fmt.Sprintf("## 📚 Stack: %s (%d PRs)\n\n", stackCtx.StackName, totalPRs))
 
 	currentPosition := 0
-	for _, change := range stackCtx.AllChanges {
-		if !change.IsLocal() && change.PR.PRNumber == currentPRNumber {
-			currentPosition = change.Position
-			break
-		}
+	if current := stackCtx.ChangeByPRNumber(currentPRNumber); current != nil {
+		currentPosition = current.Position
 	}
 
-	sb.WriteString("| # | PR | Status | Title |\n")
-	sb.WriteString("|---|-----|---------|---------------------------------------|\n")
+	if checks != nil {
+		sb.WriteString("| # | PR | Status | Checks | Title |\n")
+		sb.WriteString("|---|-----|---------|--------|---------------------------------------|\n")
+	} else {
+		sb.WriteString("| # | PR | Status | Title |\n")
+		sb.WriteString("|---|-----|---------|---------------------------------------|\n")
+	}
 
 	for _, change := range stackCtx.AllChanges {
 		prLabel := "-"
@@ -37,9 +54,21 @@ func generateStackVisualization(stackCtx *StackContext, currentPRNumber int) str
 			status = change.PR.State
 		}
 		statusEmoji, statusText := getStatusDisplay(status)
+		if status == "open" || status == "draft" {
+			if glyph := getReviewGlyph(change.PR.ReviewDecision); glyph != "" {
+				statusText += " " + glyph
+			}
+		}
 
-		row := fmt.Sprintf("| %d | %s | %s %s | %s",
-			change.Position, prLabel, statusEmoji, statusText, change.Title)
+		row := fmt.Sprintf("| %d | %s | %s %s | ", change.Position, prLabel, statusEmoji, statusText)
+		if checks != nil {
+			var summary *gh.ChecksSummary
+			if !change.IsLocal() {
+				summary = checks[change.PR.PRNumber]
+			}
+			row += getChecksDisplay(summary) + " | "
+		}
+		row += change.Title
 
 		if change.Position == currentPosition {
 			row += " ← **YOU ARE HERE**"
@@ -65,7 +94,36 @@ func generateStackVisualization(stackCtx *StackContext, currentPRNumber int) str
 	}
 	sb.WriteString(") for full context\n\n")
 	sb.WriteString("🤖 Auto-updated by [stack](https://github.com/bjulian5/stack)\n\n")
-	sb.WriteString(fmt.Sprintf("<!-- stack-visualization: %s -->\n", stackCtx.StackName))
+	sb.WriteString(marker + "\n")
+
+	return sb.String()
+}
+
+// renderClosedPRNote renders the note that replaces the visualization comment of a merged or
+// closed PR, pointing at the lowest PR of the stack that is still open
+func renderClosedPRNote(stackCtx *StackContext, pr *model.PR, marker string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("## 📚 Stack: %s\n\n", stackCtx.StackName))
+
+	emoji, _ := getStatusDisplay(pr.State)
+	sb.WriteString(fmt.Sprintf("%s This PR was %s.", emoji, pr.State))
+
+	var next *model.PR
+	for _, change := range stackCtx.AllChanges {
+		if !change.IsLocal() && (change.PR.State == "open" || change.PR.State == "draft") {
+			next = change.PR
+			break
+		}
+	}
+	if next != nil {
+		sb.WriteString(fmt.Sprintf(" The rest of the stack continues in [#%d](%s).\n\n", next.PRNumber, next.URL))
+	} else {
+		sb.WriteString(" No PRs in this stack are open anymore.\n\n")
+	}
+
+	sb.WriteString("🤖 Auto-updated by [stack](https://github.com/bjulian5/stack)\n\n")
+	sb.WriteString(marker + "\n")
 
 	return sb.String()
 }
@@ -85,16 +143,167 @@ func getStatusDisplay(status string) (emoji, text string) {
 	}
 }
 
+// getReviewGlyph renders a review decision as a glyph shown next to the PR status, or "" when
+// the decision is unknown
+func getReviewGlyph(decision gh.ReviewState) string {
+	switch decision {
+	case gh.ReviewApproved:
+		return "👍"
+	case gh.ReviewChangesRequested:
+		return "✋"
+	case gh.ReviewRequired:
+		return "👀"
+	default:
+		return ""
+	}
+}
+
+// getChecksDisplay renders a checks summary as "✅ 5/5", "🔴 3/5" or "⏳ pending".
+// Changes without a PR or without checks render as "-".
+func getChecksDisplay(summary *gh.ChecksSummary) string {
+	switch {
+	case summary == nil || summary.Total == 0:
+		return "-"
+	case summary.Failed > 0:
+		return fmt.Sprintf("🔴 %d/%d", summary.Passed, summary.Total)
+	case summary.Pending > 0:
+		return "⏳ pending"
+	default:
+		return fmt.Sprintf("✅ %d/%d", summary.Passed, summary.Total)
+	}
+}
+
+// SyncVisualizationComments creates or updates the stack visualization comment on each of the
+// stack's PRs. It does nothing when visualization comments are disabled in the repository config.
+// The comments of merged and closed PRs are updated, replaced with a short note or deleted
+// depending on the closed_visualization_comments setting.
 func (c *Client) SyncVisualizationComments(stackCtx *StackContext) error {
+	if !c.VisualizationCommentsEnabled() {
+		return nil
+	}
+	return c.syncVisualizationComments(stackCtx, nil)
+}
+
+// SyncVisualizationCommentsWithChecks is like SyncVisualizationComments but also fetches
+// the CI checks of each open PR and adds a checks column to the visualization.
+// Fetching checks costs one GitHub call per PR, so it is opt-in.
+func (c *Client) SyncVisualizationCommentsWithChecks(stackCtx *StackContext) error {
+	if !c.VisualizationCommentsEnabled() {
+		return nil
+	}
+	checks, err := c.fetchPRChecks(stackCtx)
+	if err != nil {
+		return err
+	}
+	return c.syncVisualizationComments(stackCtx, checks)
+}
+
+// GetPRReviewStatus fetches the review decision of the stack's open and draft PRs and saves
+// it to each PR's ReviewDecision, which the visualization shows next to the PR status.
+// Fetching reviews costs one GitHub call per PR, so it is opt-in.
+func (c *Client) GetPRReviewStatus(stackCtx *StackContext) (map[int]gh.ReviewState, error) {
+	var mu sync.Mutex
+	decisions := make(map[int]gh.ReviewState)
+
+	github := c.ghFor(stackCtx.Stack)
+	g := errgroup.Group{}
+	for _, change := range stackCtx.AllChanges {
+		if change.IsLocal() || change.PR.IsMerged() || change.PR.State == "closed" {
+			continue
+		}
+
+		pr := change.PR
+		g.Go(func() error {
+			decision, err := github.GetPRReview(pr.PRNumber)
+			if err != nil {
+				return fmt.Errorf("failed to get reviews for PR #%d: %w", pr.PRNumber, err)
+			}
+			mu.Lock()
+			decisions[pr.PRNumber] = decision
+			pr.ReviewDecision = decision
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if err := stackCtx.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save review status: %w", err)
+	}
+	return decisions, nil
+}
+
+// fetchPRChecks queries the checks of the stack's open and draft PRs concurrently
+func (c *Client) fetchPRChecks(stackCtx *StackContext) (map[int]*gh.ChecksSummary, error) {
+	var mu sync.Mutex
+	checks := make(map[int]*gh.ChecksSummary)
+
+	github := c.ghFor(stackCtx.Stack)
+	g := errgroup.Group{}
+	for _, change := range stackCtx.AllChanges {
+		if change.IsLocal() || change.PR.IsMerged() || change.PR.State == "closed" {
+			continue
+		}
+
+		prNumber := change.PR.PRNumber
+		g.Go(func() error {
+			summary, err := github.GetPRChecks(prNumber)
+			if err != nil {
+				return fmt.Errorf("failed to get checks for PR #%d: %w", prNumber, err)
+			}
+			mu.Lock()
+			checks[prNumber] = summary
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+func (c *Client) syncVisualizationComments(stackCtx *StackContext, checks map[int]*gh.ChecksSummary) error {
+	marker := c.vizCommentMarker(stackCtx.StackName)
+	closedMode := c.closedVizCommentsMode()
+	github := c.ghFor(stackCtx.Stack)
+
 	g := errgroup.Group{}
 	for _, change := range stackCtx.AllChanges {
 		if change.IsLocal() {
 			continue
 		}
 
-		vizContent := generateStackVisualization(stackCtx, change.PR.PRNumber)
+		if closedMode != config.ClosedVizCommentsUpdate && (change.PR.IsMerged() || change.PR.State == "closed") {
+			// Only a comment stack posted earlier is touched: a finished PR never gets a new one
+			if change.PR.VizCommentID == "" {
+				continue
+			}
+			pr := change.PR
+			if closedMode == config.ClosedVizCommentsDelete {
+				g.Go(func() error {
+					c.deleteCommentForPR(github, pr)
+					return nil
+				})
+				continue
+			}
+			note := renderClosedPRNote(stackCtx, pr, marker)
+			g.Go(func() error {
+				if err := c.syncCommentForPR(github, pr, note, marker); err != nil {
+					return fmt.Errorf("failed to sync comment for PR #%d: %w", pr.PRNumber, err)
+				}
+				return nil
+			})
+			continue
+		}
+
+		vizContent := renderStackVisualization(stackCtx, change.PR.PRNumber, checks, marker)
 		g.Go(func() error {
-			if err := c.syncCommentForPR(change.PR, vizContent); err != nil {
+			if err := c.syncCommentForPR(github, change.PR, vizContent, marker); err != nil {
 				return fmt.Errorf("failed to sync comment for PR #%d: %w", change.PR.PRNumber, err)
 			}
 			return nil
@@ -113,35 +322,45 @@ func (c *Client) SyncVisualizationComments(stackCtx *StackContext) error {
 	return nil
 }
 
-func (c *Client) syncCommentForPR(pr *model.PR, vizContent string) error {
+// deleteCommentForPR deletes the visualization comment of a finished PR and forgets its ID so
+// later syncs leave the PR alone. A comment that can't be deleted (e.g. it was already removed
+// on GitHub) is forgotten too, with a warning.
+func (c *Client) deleteCommentForPR(github GithubClient, pr *model.PR) {
+	if err := github.DeletePRComment(pr.VizCommentID); err != nil {
+		fmt.Printf("Warning: Failed to delete the stack comment on PR #%d: %v\n", pr.PRNumber, err)
+	}
+	pr.VizCommentID = ""
+}
+
+// syncCommentForPR updates the PR's visualization comment, or creates one if there is none. A
+// comment is recognized by marker, the stack's rendered marker; failing that, by the marker
+// prefix shared by every stack, so the comment of a renamed stack is reused.
+func (c *Client) syncCommentForPR(github GithubClient, pr *model.PR, vizContent string, marker string) error {
 	if pr.VizCommentID != "" {
-		err := c.gh.UpdatePRComment(pr.VizCommentID, vizContent)
+		err := github.UpdatePRComment(pr.VizCommentID, vizContent)
 		if err == nil {
 			return nil
 		}
 		fmt.Printf("Warning: Failed to update cached comment for PR #%d, will search for it\n", pr.PRNumber)
 	}
 
-	comments, err := c.gh.ListPRComments(pr.PRNumber)
+	comments, err := github.ListPRComments(pr.PRNumber)
 	if err != nil {
 		return fmt.Errorf("failed to list comments: %w", err)
 	}
 
-	var existingCommentID string
-	for _, comment := range comments {
-		if strings.Contains(comment.Body, "<!-- stack-visualization:") {
-			existingCommentID = comment.ID
-			break
-		}
+	existingCommentID := findCommentWithMarker(comments, marker)
+	if prefix := c.vizCommentMarkerPrefix(); existingCommentID == "" && prefix != "" {
+		existingCommentID = findCommentWithMarker(comments, prefix)
 	}
 
 	if existingCommentID != "" {
-		if err := c.gh.UpdatePRComment(existingCommentID, vizContent); err != nil {
+		if err := github.UpdatePRComment(existingCommentID, vizContent); err != nil {
 			return fmt.Errorf("failed to update comment: %w", err)
 		}
 		pr.VizCommentID = existingCommentID
 	} else {
-		commentID, err := c.gh.CreatePRComment(pr.PRNumber, vizContent)
+		commentID, err := github.CreatePRComment(pr.PRNumber, vizContent)
 		if err != nil {
 			return fmt.Errorf("failed to create comment: %w", err)
 		}
@@ -150,3 +369,13 @@ func (c *Client) syncCommentForPR(pr *model.PR, vizContent string) error {
 
 	return nil
 }
+
+// findCommentWithMarker returns the ID of the first comment containing marker, or "" if none does
+func findCommentWithMarker(comments []gh.Comment, marker string) string {
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, marker) {
+			return comment.ID
+		}
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package stack
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"testing/synctest"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bjulian5/stack/internal/config"
 	"github.com/bjulian5/stack/internal/gh"
 	"github.com/bjulian5/stack/internal/model"
 )
@@ -347,6 +349,51 @@ func TestGenerateStackVisualization(t *testing.T) {
 	}
 }
 
+func TestGenerateStackVisualization_EnterpriseHost(t *testing.T) {
+	t.Setenv(config.HostEnvVar, "github.example.com")
+
+	changes := []*model.Change{
+		{
+			UUID:     "1111111111111111",
+			Title:    "First change",
+			Position: 1,
+			PR: &model.PR{
+				PRNumber: 101,
+				URL:      "https://github.example.com/test-owner/test-repo/pull/101",
+				State:    "open",
+			},
+		},
+		{
+			UUID:     "2222222222222222",
+			Title:    "Second change",
+			Position: 2,
+			PR: &model.PR{
+				PRNumber: 102,
+				URL:      "https://github.example.com/test-owner/test-repo/pull/102",
+				State:    "draft",
+			},
+		},
+	}
+	ctx := createTestStackContext(t, "test-stack", changes)
+	assert.Equal(t, "github.example.com", ctx.Stack.Host)
+
+	viz := generateStackVisualization(ctx, 102)
+	assert.Contains(t, viz, "| 1 | https://github.example.com/test-owner/test-repo/pull/101 |")
+	assert.Contains(t, viz, "| 2 | https://github.example.com/test-owner/test-repo/pull/102 |")
+	assert.Contains(t, viz, "([#101](https://github.example.com/test-owner/test-repo/pull/101))")
+	assert.NotContains(t, viz, "https://github.com/test-owner")
+
+	// The marker doesn't depend on the host, so an existing comment is found and updated
+	mockGithubClient := ctx.client.gh.(*gh.MockGithubClient)
+	mockGithubClient.On("ListPRComments", 101).Return([]gh.Comment{
+		{ID: "comment-101", Body: "old\n<!-- stack-visualization: test-stack -->", URL: "https://github.example.com/test-owner/test-repo/pull/101#issuecomment-1"},
+	}, nil)
+	mockGithubClient.On("UpdatePRComment", "comment-101", viz).Return(nil)
+	require.NoError(t, ctx.client.syncCommentForPR(ctx.client.gh, changes[0].PR, viz, "<!-- stack-visualization: test-stack -->"))
+	assert.Equal(t, "comment-101", changes[0].PR.VizCommentID)
+	mockGithubClient.AssertNotCalled(t, "CreatePRComment", mock.Anything, mock.Anything)
+}
+
 func TestGetStatusDisplay(t *testing.T) {
 	tests := []struct {
 		status        string
@@ -545,7 +592,7 @@ func TestSyncCommentForPR(t *testing.T) {
 
 			tt.setupMocks(mockGithubClient, tt.pr, tt.vizContent)
 
-			err := stackClient.syncCommentForPR(tt.pr, tt.vizContent)
+			err := stackClient.syncCommentForPR(stackClient.gh, tt.pr, tt.vizContent, "<!-- stack-visualization: test-stack -->")
 
 			if tt.expectError != nil {
 				assert.ErrorContains(t, err, tt.expectError.Error())
@@ -657,6 +704,129 @@ func TestSyncVisualizationComments_WithLocalChanges(t *testing.T) {
 	mockGithubClient.AssertExpectations(t)
 }
 
+func TestSyncVisualizationComments_Disabled(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{DisableVizComments: true}))
+
+	changes := []*model.Change{
+		{
+			UUID:     "1111111111111111",
+			Title:    "Pushed change",
+			Position: 1,
+			PR:       &model.PR{PRNumber: 101, State: "open"},
+		},
+	}
+
+	ctx := createTestStackContext(t, "test-stack", changes)
+	ctx.AllChanges = changes
+
+	assert.False(t, stackClient.VisualizationCommentsEnabled())
+	require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+	require.NoError(t, stackClient.SyncVisualizationCommentsWithChecks(ctx))
+
+	mockGithubClient.AssertNotCalled(t, "ListPRComments", mock.Anything)
+	mockGithubClient.AssertNotCalled(t, "CreatePRComment", mock.Anything, mock.Anything)
+	mockGithubClient.AssertNotCalled(t, "UpdatePRComment", mock.Anything, mock.Anything)
+	mockGithubClient.AssertNotCalled(t, "GetPRChecks", mock.Anything)
+	assert.Empty(t, changes[0].PR.VizCommentID)
+}
+
+func TestSyncVisualizationComments_CustomMarker(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{VizCommentMarker: "<!-- stacked-prs: {stack} -->"}))
+
+	changes := []*model.Change{
+		{
+			UUID:     "1111111111111111",
+			Title:    "Pushed change",
+			Position: 1,
+			PR:       &model.PR{PRNumber: 101, State: "open"},
+		},
+	}
+
+	ctx := createTestStackContext(t, "test-stack", changes)
+	ctx.AllChanges = changes
+
+	// Only the comment with the configured marker is the visualization comment
+	mockGithubClient.On("ListPRComments", 101).Return([]gh.Comment{
+		{ID: "comment-default", Body: "Old\n<!-- stack-visualization: test-stack -->"},
+		{ID: "comment-custom", Body: "Stack info\n<!-- stacked-prs: test-stack -->"},
+	}, nil)
+	mockGithubClient.On("UpdatePRComment", "comment-custom", mock.MatchedBy(func(body string) bool {
+		return strings.HasSuffix(body, "<!-- stacked-prs: test-stack -->\n") &&
+			!strings.Contains(body, "stack-visualization")
+	})).Return(nil)
+
+	require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+
+	assert.Equal(t, "comment-custom", changes[0].PR.VizCommentID)
+	mockGithubClient.AssertExpectations(t)
+}
+
+func TestSyncCommentForPR_MarkerMatching(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		comments []gh.Comment
+		expected string // ID of the updated comment, or "" if a new one is created
+	}{
+		{
+			name:     "PrefersThisStacksMarker",
+			template: config.DefaultVizCommentMarker,
+			comments: []gh.Comment{
+				{ID: "comment-old", Body: "<!-- stack-visualization: old-name -->"},
+				{ID: "comment-current", Body: "<!-- stack-visualization: test-stack -->"},
+			},
+			expected: "comment-current",
+		},
+		{
+			name:     "ReusesRenamedStacksComment",
+			template: config.DefaultVizCommentMarker,
+			comments: []gh.Comment{
+				{ID: "comment-old", Body: "<!-- stack-visualization: old-name -->"},
+			},
+			expected: "comment-old",
+		},
+		{
+			// The prefix is empty, so only the rendered marker identifies the comment
+			name:     "TemplateStartingWithStackName",
+			template: "{stack} stack comment",
+			comments: []gh.Comment{
+				{ID: "comment-review", Body: "Looks good"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGithubClient := &gh.MockGithubClient{}
+			stackClient := NewTestStack(t, mockGithubClient)
+			require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{VizCommentMarker: tt.template}))
+
+			pr := &model.PR{PRNumber: 101}
+			mockGithubClient.On("ListPRComments", 101).Return(tt.comments, nil)
+			if tt.expected != "" {
+				mockGithubClient.On("UpdatePRComment", tt.expected, "viz").Return(nil)
+			} else {
+				mockGithubClient.On("CreatePRComment", 101, "viz").Return("comment-new", nil)
+			}
+
+			require.NoError(t, stackClient.syncCommentForPR(stackClient.gh, pr, "viz", stackClient.vizCommentMarker("test-stack")))
+
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, pr.VizCommentID)
+			} else {
+				assert.Equal(t, "comment-new", pr.VizCommentID)
+			}
+			mockGithubClient.AssertExpectations(t)
+		})
+	}
+}
+
 func TestSyncVisualizationComments(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -828,3 +998,292 @@ func TestSyncVisualizationComments_Concurrent(t *testing.T) {
 		mockGithubClient.AssertExpectations(t)
 	})
 }
+
+func TestGenerateStackVisualizationWithChecks(t *testing.T) {
+	changes := []*model.Change{
+		{
+			UUID:     "1111111111111111",
+			Title:    "First change",
+			Position: 1,
+			PR: &model.PR{
+				PRNumber: 101,
+				URL:      "https://github.com/test-owner/test-repo/pull/101",
+				State:    "open",
+			},
+		},
+		{
+			UUID:     "2222222222222222",
+			Title:    "Second change",
+			Position: 2,
+			PR: &model.PR{
+				PRNumber: 102,
+				URL:      "https://github.com/test-owner/test-repo/pull/102",
+				State:    "open",
+			},
+		},
+		{
+			UUID:     "3333333333333333",
+			Title:    "Third change",
+			Position: 3,
+			PR: &model.PR{
+				PRNumber: 103,
+				URL:      "https://github.com/test-owner/test-repo/pull/103",
+				State:    "draft",
+			},
+		},
+		{
+			UUID:     "4444444444444444",
+			Title:    "Local change",
+			Position: 4,
+		},
+	}
+	checks := map[int]*gh.ChecksSummary{
+		101: {Passed: 5, Total: 5},
+		102: {Passed: 3, Failed: 1, Pending: 1, Total: 5},
+		103: {Passed: 2, Pending: 1, Total: 3},
+	}
+
+	ctx := createTestStackContext(t, "test-stack", changes)
+	viz := generateStackVisualizationWithChecks(ctx, 102, checks)
+
+	assert.Contains(t, viz, "| # | PR | Status | Checks | Title |\n")
+	assert.Contains(t, viz, "| 1 | https://github.com/test-owner/test-repo/pull/101 | ✅ Open   | ✅ 5/5 | First change |\n")
+	assert.Contains(t, viz, "| 2 | https://github.com/test-owner/test-repo/pull/102 | ✅ Open   | 🔴 3/5 | Second change ← **YOU ARE HERE** |\n")
+	assert.Contains(t, viz, "| 3 | https://github.com/test-owner/test-repo/pull/103 | 📝 Draft  | ⏳ pending | Third change |\n")
+	assert.Contains(t, viz, "| 4 | - | ⚪ Local  | - | Local change |\n")
+
+	// Without checks data the column is omitted
+	assert.Equal(t, generateStackVisualization(ctx, 102), generateStackVisualizationWithChecks(ctx, 102, nil))
+	assert.NotContains(t, generateStackVisualization(ctx, 102), "Checks")
+}
+
+func TestGetChecksDisplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		summary  *gh.ChecksSummary
+		expected string
+	}{
+		{"NotFetched", nil, "-"},
+		{"NoChecks", &gh.ChecksSummary{}, "-"},
+		{"AllPassing", &gh.ChecksSummary{Passed: 5, Total: 5}, "✅ 5/5"},
+		{"SomeFailing", &gh.ChecksSummary{Passed: 3, Failed: 2, Total: 5}, "🔴 3/5"},
+		{"FailingWinsOverPending", &gh.ChecksSummary{Passed: 1, Failed: 1, Pending: 1, Total: 3}, "🔴 1/3"},
+		{"Pending", &gh.ChecksSummary{Passed: 4, Pending: 1, Total: 5}, "⏳ pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getChecksDisplay(tt.summary))
+		})
+	}
+}
+
+func TestSyncVisualizationCommentsWithChecks(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		changes := []*model.Change{
+			{
+				UUID:     "1111111111111111",
+				Title:    "Merged change",
+				Position: 1,
+				PR:       &model.PR{PRNumber: 101, State: "merged", VizCommentID: "comment-101"},
+			},
+			{
+				UUID:     "2222222222222222",
+				Title:    "Open change",
+				Position: 2,
+				PR:       &model.PR{PRNumber: 102, State: "open", VizCommentID: "comment-102"},
+			},
+		}
+		ctx := createTestStackContext(t, "test-stack", changes)
+
+		// Only the open PR's checks are fetched
+		mockGithubClient.On("GetPRChecks", 102).Return(&gh.ChecksSummary{Passed: 2, Total: 2}, nil).Once()
+		mockGithubClient.On("UpdatePRComment", mock.AnythingOfType("string"), mock.MatchedBy(func(body string) bool {
+			return strings.Contains(body, "| Checks |") && strings.Contains(body, "✅ 2/2")
+		})).Return(nil).Twice()
+
+		err := stackClient.SyncVisualizationCommentsWithChecks(ctx)
+		require.NoError(t, err)
+
+		mockGithubClient.AssertExpectations(t)
+	})
+}
+
+func TestSyncVisualizationComments_ClosedPRs(t *testing.T) {
+	setup := func(t *testing.T, mode string) (*Client, *gh.MockGithubClient, []*model.Change, *StackContext) {
+		mockGithubClient := &gh.MockGithubClient{}
+		stackClient := NewTestStack(t, mockGithubClient)
+		require.NoError(t, stackClient.saveRepositoryConfig(&RepositoryConfig{ClosedVizComments: mode}))
+
+		changes := []*model.Change{
+			{
+				UUID:     "1111111111111111",
+				Title:    "Merged change",
+				Position: 1,
+				PR:       &model.PR{PRNumber: 101, State: "merged", VizCommentID: "comment-101"},
+			},
+			{
+				UUID:     "2222222222222222",
+				Title:    "Closed change",
+				Position: 2,
+				PR:       &model.PR{PRNumber: 102, State: "closed"},
+			},
+			{
+				UUID:     "3333333333333333",
+				Title:    "Open change",
+				Position: 3,
+				PR:       &model.PR{PRNumber: 103, State: "open", URL: "https://github.com/test-owner/test-repo/pull/103", VizCommentID: "comment-103"},
+			},
+		}
+		ctx := createTestStackContext(t, "test-stack", changes)
+		return stackClient, mockGithubClient, changes, ctx
+	}
+	isVisualization := func(body string) bool {
+		return strings.Contains(body, "**Merge order:**")
+	}
+
+	t.Run("UpdateByDefault", func(t *testing.T) {
+		stackClient, mockGithubClient, changes, ctx := setup(t, "")
+		assert.Equal(t, config.ClosedVizCommentsUpdate, stackClient.closedVizCommentsMode())
+
+		mockGithubClient.On("UpdatePRComment", "comment-101", mock.MatchedBy(isVisualization)).Return(nil).Once()
+		mockGithubClient.On("ListPRComments", 102).Return([]gh.Comment{}, nil).Once()
+		mockGithubClient.On("CreatePRComment", 102, mock.MatchedBy(isVisualization)).Return("comment-102", nil).Once()
+		mockGithubClient.On("UpdatePRComment", "comment-103", mock.MatchedBy(isVisualization)).Return(nil).Once()
+
+		require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+		assert.Equal(t, "comment-102", changes[1].PR.VizCommentID)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("Note", func(t *testing.T) {
+		stackClient, mockGithubClient, changes, ctx := setup(t, config.ClosedVizCommentsNote)
+
+		// The merged PR's comment becomes a note; the closed PR never had one, so it gets none
+		mockGithubClient.On("UpdatePRComment", "comment-101", mock.MatchedBy(func(body string) bool {
+			return !isVisualization(body) &&
+				strings.Contains(body, "🟣 This PR was merged. The rest of the stack continues in [#103](https://github.com/test-owner/test-repo/pull/103).") &&
+				strings.HasSuffix(body, "<!-- stack-visualization: test-stack -->\n")
+		})).Return(nil).Once()
+		mockGithubClient.On("UpdatePRComment", "comment-103", mock.MatchedBy(isVisualization)).Return(nil).Once()
+
+		require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+		assert.Equal(t, "comment-101", changes[0].PR.VizCommentID)
+		assert.Empty(t, changes[1].PR.VizCommentID)
+		mockGithubClient.AssertNotCalled(t, "CreatePRComment", mock.Anything, mock.Anything)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		stackClient, mockGithubClient, changes, ctx := setup(t, config.ClosedVizCommentsDelete)
+
+		mockGithubClient.On("DeletePRComment", "comment-101").Return(nil).Once()
+		mockGithubClient.On("UpdatePRComment", "comment-103", mock.MatchedBy(isVisualization)).Return(nil).Twice()
+
+		require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+		assert.Empty(t, changes[0].PR.VizCommentID)
+		assert.Equal(t, "comment-103", changes[2].PR.VizCommentID)
+
+		// The deleted comment is forgotten, so the next sync leaves the merged PR alone
+		require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+		mockGithubClient.AssertNotCalled(t, "CreatePRComment", mock.Anything, mock.Anything)
+		mockGithubClient.AssertExpectations(t)
+	})
+
+	t.Run("DeleteFailureForgetsComment", func(t *testing.T) {
+		stackClient, mockGithubClient, changes, ctx := setup(t, config.ClosedVizCommentsDelete)
+
+		mockGithubClient.On("DeletePRComment", "comment-101").Return(fmt.Errorf("not found")).Once()
+		mockGithubClient.On("UpdatePRComment", "comment-103", mock.MatchedBy(isVisualization)).Return(nil).Once()
+
+		require.NoError(t, stackClient.SyncVisualizationComments(ctx))
+		assert.Empty(t, changes[0].PR.VizCommentID)
+		mockGithubClient.AssertExpectations(t)
+	})
+}
+
+func TestRenderClosedPRNote(t *testing.T) {
+	changes := []*model.Change{
+		{UUID: "1111111111111111", Title: "First", Position: 1, PR: &model.PR{PRNumber: 101, State: "merged"}},
+		{UUID: "2222222222222222", Title: "Second", Position: 2, PR: &model.PR{PRNumber: 102, State: "closed"}},
+	}
+	ctx := createTestStackContext(t, "test-stack", changes)
+
+	note := renderClosedPRNote(ctx, changes[1].PR, "<!-- marker -->")
+	assert.Equal(t, "## 📚 Stack: test-stack\n\n"+
+		"❌ This PR was closed. No PRs in this stack are open anymore.\n\n"+
+		"🤖 Auto-updated by [stack](https://github.com/bjulian5/stack)\n\n"+
+		"<!-- marker -->\n", note)
+}
+
+func TestGenerateStackVisualization_ReviewDecision(t *testing.T) {
+	changes := []*model.Change{
+		{
+			UUID:     "1111111111111111",
+			Title:    "Approved change",
+			Position: 1,
+			PR:       &model.PR{PRNumber: 101, URL: "https://github.com/test-owner/test-repo/pull/101", State: "open", ReviewDecision: gh.ReviewApproved},
+		},
+		{
+			UUID:     "2222222222222222",
+			Title:    "Blocked change",
+			Position: 2,
+			PR:       &model.PR{PRNumber: 102, URL: "https://github.com/test-owner/test-repo/pull/102", State: "draft", ReviewDecision: gh.ReviewChangesRequested},
+		},
+		{
+			UUID:     "3333333333333333",
+			Title:    "Unreviewed change",
+			Position: 3,
+			PR:       &model.PR{PRNumber: 103, URL: "https://github.com/test-owner/test-repo/pull/103", State: "open"},
+		},
+	}
+
+	ctx := createTestStackContext(t, "test-stack", changes)
+	viz := generateStackVisualization(ctx, 0)
+
+	assert.Contains(t, viz, "| 1 | https://github.com/test-owner/test-repo/pull/101 | ✅ Open   👍 | Approved change |\n")
+	assert.Contains(t, viz, "| 2 | https://github.com/test-owner/test-repo/pull/102 | 📝 Draft  ✋ | Blocked change |\n")
+	assert.Contains(t, viz, "| 3 | https://github.com/test-owner/test-repo/pull/103 | ✅ Open   | Unreviewed change |\n")
+}
+
+func TestGetReviewGlyph(t *testing.T) {
+	assert.Equal(t, "👍", getReviewGlyph(gh.ReviewApproved))
+	assert.Equal(t, "✋", getReviewGlyph(gh.ReviewChangesRequested))
+	assert.Equal(t, "👀", getReviewGlyph(gh.ReviewRequired))
+	assert.Equal(t, "", getReviewGlyph(gh.ReviewNone))
+}
+
+func TestGetPRReviewStatus(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		mockGithubClient := &gh.MockGithubClient{}
+		stackClient := NewTestStack(t, mockGithubClient)
+
+		changes := []*model.Change{
+			{UUID: "1111111111111111", Title: "Merged change", Position: 1, PR: &model.PR{PRNumber: 101, State: "merged"}},
+			{UUID: "2222222222222222", Title: "Open change", Position: 2, PR: &model.PR{PRNumber: 102, State: "open"}},
+			{UUID: "3333333333333333", Title: "Draft change", Position: 3, PR: &model.PR{PRNumber: 103, State: "draft"}},
+		}
+		ctx := createTestStackContext(t, "test-stack", changes)
+
+		// Merged PRs are not queried
+		mockGithubClient.On("GetPRReview", 102).Return(gh.ReviewApproved, nil).Once()
+		mockGithubClient.On("GetPRReview", 103).Return(gh.ReviewRequired, nil).Once()
+
+		decisions, err := stackClient.GetPRReviewStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]gh.ReviewState{102: gh.ReviewApproved, 103: gh.ReviewRequired}, decisions)
+
+		assert.Equal(t, gh.ReviewApproved, changes[1].PR.ReviewDecision)
+		assert.Equal(t, gh.ReviewRequired, changes[2].PR.ReviewDecision)
+
+		// The decisions are persisted
+		prData, err := ctx.client.LoadPRs("test-stack")
+		require.NoError(t, err)
+		assert.Equal(t, gh.ReviewApproved, prData.PRs["2222222222222222"].ReviewDecision)
+
+		mockGithubClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,65 @@
+package stack
+
+import (
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// WorkTreeStatus describes uncommitted changes in the working tree and the change they
+// would belong to if committed.
+type WorkTreeStatus struct {
+	Dirty          bool
+	StagedFiles    int
+	UnstagedFiles  int
+	UntrackedFiles int
+
+	// Change is the change being edited when on one of the stack's UUID branches; amending
+	// the working tree updates it. Nil on the TOP branch, where a commit adds a new change.
+	Change *model.Change
+	// OnStack is true when the working tree is checked out on this stack
+	OnStack bool
+}
+
+// Describe returns a one-line explanation of what committing the working tree would do,
+// or an empty string if the tree is clean or belongs to a different stack.
+func (w *WorkTreeStatus) Describe() string {
+	if !w.Dirty || !w.OnStack {
+		return ""
+	}
+	if w.Change != nil {
+		return fmt.Sprintf("You have uncommitted changes on change #%d (amend to update it)", w.Change.Position)
+	}
+	return "You have uncommitted changes on TOP (commit to add a new change)"
+}
+
+// DescribeWorkingTree reports whether the working tree has uncommitted changes and which
+// change of the stack they are tied to.
+func (c *Client) DescribeWorkingTree(stackCtx *StackContext) (*WorkTreeStatus, error) {
+	dirty, err := c.git.HasUncommittedChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &WorkTreeStatus{
+		Dirty:   dirty,
+		OnStack: stackCtx.stackActive,
+	}
+	if stackCtx.stackActive && stackCtx.OnUUIDBranch() {
+		status.Change = stackCtx.CurrentChange()
+	}
+
+	if !dirty {
+		return status, nil
+	}
+
+	summary, err := c.git.Status()
+	if err != nil {
+		return nil, err
+	}
+	status.StagedFiles = summary.Staged
+	status.UnstagedFiles = summary.Unstaged
+	status.UntrackedFiles = summary.Untracked
+
+	return status, nil
+}
@@ -0,0 +1,73 @@
+package stack
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/gh"
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/testutil"
+)
+
+func TestDescribeWorkingTree(t *testing.T) {
+	mockGithubClient := &gh.MockGithubClient{}
+	mockGithubClient.On("GetRepoInfo").Return("test-owner", "test-repo", nil)
+
+	stackClient := NewTestStack(t, mockGithubClient)
+	gitClient := stackClient.git.(*git.Client)
+
+	_, err := stackClient.CreateStack("test-stack", "main")
+	require.NoError(t, err)
+
+	uuids := []string{"1111111111111111", "2222222222222222"}
+	for i, uuid := range uuids {
+		_ = testutil.CreateCommitWithTrailers(t, gitClient, fmt.Sprintf("Change %d", i+1), "", map[string]string{
+			"PR-UUID":  uuid,
+			"PR-Stack": "test-stack",
+		})
+	}
+
+	t.Run("CleanOnTop", func(t *testing.T) {
+		stackCtx, err := stackClient.GetStackContext()
+		require.NoError(t, err)
+
+		status, err := stackClient.DescribeWorkingTree(stackCtx)
+		require.NoError(t, err)
+		assert.False(t, status.Dirty)
+		assert.Nil(t, status.Change)
+		assert.Empty(t, status.Describe())
+	})
+
+	t.Run("DirtyOnUUIDBranch", func(t *testing.T) {
+		stackCtx, err := stackClient.GetStackContext()
+		require.NoError(t, err)
+		_, err = stackClient.CheckoutChangeForEditing(stackCtx, stackCtx.ActiveChanges[0], EditOptions{})
+		require.NoError(t, err)
+
+		// One staged file, one modified tracked file and one untracked file
+		testutil.WriteFile(t, gitClient.GitRoot(), "staged.txt", "staged")
+		cmd := exec.Command("git", "add", "staged.txt")
+		cmd.Dir = gitClient.GitRoot()
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git add failed: %s", string(output))
+		testutil.WriteFile(t, gitClient.GitRoot(), "file-Change 1.txt", "modified")
+		testutil.WriteFile(t, gitClient.GitRoot(), "untracked.txt", "untracked")
+
+		stackCtx, err = stackClient.GetStackContext()
+		require.NoError(t, err)
+
+		status, err := stackClient.DescribeWorkingTree(stackCtx)
+		require.NoError(t, err)
+		assert.True(t, status.Dirty)
+		assert.Equal(t, 1, status.StagedFiles)
+		assert.Equal(t, 1, status.UnstagedFiles)
+		assert.Equal(t, 1, status.UntrackedFiles)
+		require.NotNil(t, status.Change)
+		assert.Equal(t, uuids[0], status.Change.UUID)
+		assert.Equal(t, "You have uncommitted changes on change #1 (amend to update it)", status.Describe())
+	})
+}
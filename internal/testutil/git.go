@@ -26,6 +26,9 @@ func NewTestGitClient(t *testing.T) *git.Client {
 	cmd = exec.Command("git", "config", "user.email", "test@example.com")
 	cmd.Dir = tempDir
 	cmd.Run()
+	cmd = exec.Command("git", "config", "user.name", "Test User")
+	cmd.Dir = tempDir
+	cmd.Run()
 
 	// Create git client early so we can use createCommitWithTrailers
 	gitClient, err := git.NewClientAt(tempDir)
@@ -76,6 +79,24 @@ func CreateCommitWithTrailers(t *testing.T, gitClient *git.Client, title, body s
 	return strings.TrimSpace(string(output))
 }
 
+// AddBareRemote creates a bare repository in a temporary directory and adds it to the
+// client's repository as the "origin" remote. Returns the path to the bare repository.
+func AddBareRemote(t *testing.T, gitClient *git.Client) string {
+	remoteDir := t.TempDir()
+
+	cmd := exec.Command("git", "init", "--bare", "--initial-branch=main")
+	cmd.Dir = remoteDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git init --bare failed: %s", string(output))
+
+	cmd = exec.Command("git", "remote", "add", "origin", remoteDir)
+	cmd.Dir = gitClient.GitRoot()
+	output, err = cmd.CombinedOutput()
+	require.NoError(t, err, "git remote add failed: %s", string(output))
+
+	return remoteDir
+}
+
 // WriteFile writes a file to the git repository (for creating uncommitted changes)
 func WriteFile(t *testing.T, gitRoot, filename, content string) {
 	filePath := filepath.Join(gitRoot, filename)
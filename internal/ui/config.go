@@ -4,6 +4,7 @@ package ui
 type DisplayConfig struct {
 	// View settings
 	DefaultView ViewMode
+	ShowAuthor  bool // Add an AUTHOR column to the stack details table
 
 	// Truncation limits
 	MaxStackNameLength     int
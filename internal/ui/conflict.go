@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"strings"
+)
+
+// RenderConflictHelp renders a panel for an operation stopped on conflicts: what was being
+// done (e.g. "Restacking 'auth' onto main"), the conflicted files and the commands to resume
+// or abort it. With no files listed, it points at 'git status' instead.
+func RenderConflictHelp(operation string, conflictedFiles []string, resumeCmd string, abortCmd string) string {
+	var lines []string
+
+	if len(conflictedFiles) > 0 {
+		lines = append(lines, Bold("Conflicted files:"))
+		for _, file := range conflictedFiles {
+			lines = append(lines, "  "+ErrorStyle.Render("✗")+" "+file)
+		}
+	} else {
+		lines = append(lines, Dim("Run 'git status' to see the conflicted files."))
+	}
+
+	lines = append(lines,
+		"",
+		Bold("To resolve:"),
+		RenderNumberedList([]string{
+			"Fix the conflicts in the files above",
+			Highlight("git add <file>..."),
+			Highlight(resumeCmd),
+		}),
+		"",
+		Bold("To give up and keep the stack as it was:"),
+		"  "+Highlight(abortCmd),
+	)
+
+	return RenderBox(operation+" stopped on conflicts", strings.Join(lines, "\n"))
+}
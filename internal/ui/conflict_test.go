@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderConflictHelp(t *testing.T) {
+	t.Run("WithFiles", func(t *testing.T) {
+		panel := RenderConflictHelp("Restacking 'auth' onto main", []string{"internal/auth/login.go", "README.md"}, "stack restack --continue", "stack restack --abort")
+
+		expected := `╭──────────────────────────────────────────────────╮
+│ Restacking 'auth' onto main stopped on conflicts │
+│                                                  │
+│ Conflicted files:                                │
+│   ✗ internal/auth/login.go                       │
+│   ✗ README.md                                    │
+│                                                  │
+│ To resolve:                                      │
+│   1. Fix the conflicts in the files above        │
+│   2. git add <file>...                           │
+│   3. stack restack --continue                    │
+│                                                  │
+│ To give up and keep the stack as it was:         │
+│   stack restack --abort                          │
+╰──────────────────────────────────────────────────╯`
+		assert.Equal(t, expected, panel)
+	})
+
+	t.Run("WithoutFiles", func(t *testing.T) {
+		panel := RenderConflictHelp("Restacking 'auth' onto main", nil, "stack restack --continue", "stack restack --abort")
+		assert.Contains(t, panel, "Run 'git status' to see the conflicted files.")
+		assert.NotContains(t, panel, "Conflicted files:")
+	})
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// graphColors are the fill colors for each change state in stack graphs, matching GitHub's PR colors
+var graphColors = map[string]string{
+	"merged": "#8250df",
+	"open":   "#1a7f37",
+	"draft":  "#6e7781",
+	"closed": "#cf222e",
+	"local":  "#d0d7de",
+}
+
+// graphStates lists the states in graphColors in a fixed order for stable output
+var graphStates = []string{"merged", "open", "draft", "closed", "local"}
+
+// graphNode is a change in a stack graph
+type graphNode struct {
+	ID     string // Node identifier: c<position>
+	Parent string // ID of the node the change is based on ("base" for the stack base)
+	Label  string // "#123 Title", or just the title for local changes
+	State  string // One of graphStates
+}
+
+// graphChangeState returns the state a change is drawn with. Unlike GetChangeStatus, merged
+// wins over the local draft flag.
+func graphChangeState(change *model.Change) string {
+	switch {
+	case change.PR.IsMerged():
+		return "merged"
+	case change.IsLocal():
+		return "local"
+	case change.GetDraftStatus():
+		return "draft"
+	case change.PR.State == "closed":
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// buildGraphNodes resolves each change's parent from its DesiredBase: the stack base, or the
+// change whose UUID branch it names. Changes without a DesiredBase (merged changes) follow the
+// previous change, or the base if they come first.
+func buildGraphNodes(s *model.Stack, changes []*model.Change) []graphNode {
+	byUUID := make(map[string]string, len(changes))
+	nodes := make([]graphNode, len(changes))
+	for i, change := range changes {
+		nodes[i].ID = fmt.Sprintf("c%d", i+1)
+		if change.UUID != "" {
+			byUUID[change.UUID] = nodes[i].ID
+		}
+	}
+
+	for i, change := range changes {
+		parent := "base"
+		if i > 0 {
+			parent = nodes[i-1].ID
+		}
+		if change.DesiredBase == s.Base {
+			parent = "base"
+		} else if change.DesiredBase != "" {
+			uuid := change.DesiredBase[strings.LastIndex(change.DesiredBase, "/")+1:]
+			if id, ok := byUUID[uuid]; ok {
+				parent = id
+			}
+		}
+
+		label := change.Title
+		if !change.IsLocal() {
+			label = fmt.Sprintf("#%d %s", change.PR.PRNumber, change.Title)
+		}
+
+		nodes[i].Parent = parent
+		nodes[i].Label = label
+		nodes[i].State = graphChangeState(change)
+	}
+	return nodes
+}
+
+// RenderStackMermaid renders the stack's dependency chain as a Mermaid flowchart, from the
+// base branch up to the top change, colored by change state.
+// Example output:
+//
+//	flowchart BT
+//	    base["main"]
+//	    c1["#123 Add JWT auth"]:::merged
+//	    base --> c1
+func RenderStackMermaid(s *model.Stack, changes []*model.Change) string {
+	escape := strings.NewReplacer(`"`, "#quot;").Replace
+
+	var b strings.Builder
+	b.WriteString("flowchart BT\n")
+	fmt.Fprintf(&b, "    base[\"%s\"]\n", escape(s.Base))
+
+	nodes := buildGraphNodes(s, changes)
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "    %s[\"%s\"]:::%s\n", node.ID, escape(node.Label), node.State)
+	}
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "    %s --> %s\n", node.Parent, node.ID)
+	}
+	for _, state := range graphStates {
+		fmt.Fprintf(&b, "    classDef %s fill:%s,color:%s\n", state, graphColors[state], graphTextColor(state))
+	}
+	return b.String()
+}
+
+// RenderStackDOT renders the stack's dependency chain as a Graphviz DOT digraph, from the
+// base branch up to the top change, colored by change state.
+// Example output:
+//
+//	digraph "auth-refactor" {
+//	    rankdir=BT;
+//	    node [shape=box, style="rounded,filled"];
+//	    base [label="main", shape=ellipse, style=solid];
+//	    c1 [label="#123 Add JWT auth", fillcolor="#8250df", fontcolor="#ffffff"];
+//	    base -> c1;
+//	}
+func RenderStackDOT(s *model.Stack, changes []*model.Change) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph \"%s\" {\n", escape(s.Name))
+	b.WriteString("    rankdir=BT;\n")
+	b.WriteString("    node [shape=box, style=\"rounded,filled\"];\n")
+	fmt.Fprintf(&b, "    base [label=\"%s\", shape=ellipse, style=solid];\n", escape(s.Base))
+
+	nodes := buildGraphNodes(s, changes)
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "    %s [label=\"%s\", fillcolor=\"%s\", fontcolor=\"%s\"];\n",
+			node.ID, escape(node.Label), graphColors[node.State], graphTextColor(node.State))
+	}
+	for _, node := range nodes {
+		fmt.Fprintf(&b, "    %s -> %s;\n", node.Parent, node.ID)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// graphTextColor returns a text color readable on the state's fill color
+func graphTextColor(state string) string {
+	if state == "local" {
+		return "#1f2328"
+	}
+	return "#ffffff"
+}
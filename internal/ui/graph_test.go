@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+func graphTestStack() (*model.Stack, []*model.Change) {
+	s := &model.Stack{Name: "auth-refactor", Base: "main"}
+	changes := []*model.Change{
+		{
+			Title: "Add JWT auth",
+			UUID:  "1111111111111111",
+			PR:    &model.PR{PRNumber: 123, State: "merged"},
+		},
+		{
+			Title:       `Refresh "tokens"`,
+			UUID:        "2222222222222222",
+			PR:          &model.PR{PRNumber: 124, State: "open"},
+			DesiredBase: "main",
+		},
+		{
+			Title:       "Unit tests",
+			UUID:        "3333333333333333",
+			DesiredBase: "user/stack-auth-refactor/2222222222222222",
+		},
+	}
+	return s, changes
+}
+
+func TestRenderStackMermaid(t *testing.T) {
+	s, changes := graphTestStack()
+
+	expected := `flowchart BT
+    base["main"]
+    c1["#123 Add JWT auth"]:::merged
+    c2["#124 Refresh #quot;tokens#quot;"]:::open
+    c3["Unit tests"]:::local
+    base --> c1
+    base --> c2
+    c2 --> c3
+    classDef merged fill:#8250df,color:#ffffff
+    classDef open fill:#1a7f37,color:#ffffff
+    classDef draft fill:#6e7781,color:#ffffff
+    classDef closed fill:#cf222e,color:#ffffff
+    classDef local fill:#d0d7de,color:#1f2328
+`
+	assert.Equal(t, expected, RenderStackMermaid(s, changes))
+}
+
+func TestRenderStackDOT(t *testing.T) {
+	s, changes := graphTestStack()
+
+	expected := `digraph "auth-refactor" {
+    rankdir=BT;
+    node [shape=box, style="rounded,filled"];
+    base [label="main", shape=ellipse, style=solid];
+    c1 [label="#123 Add JWT auth", fillcolor="#8250df", fontcolor="#ffffff"];
+    c2 [label="#124 Refresh \"tokens\"", fillcolor="#1a7f37", fontcolor="#ffffff"];
+    c3 [label="Unit tests", fillcolor="#d0d7de", fontcolor="#1f2328"];
+    base -> c1;
+    base -> c2;
+    c2 -> c3;
+}
+`
+	assert.Equal(t, expected, RenderStackDOT(s, changes))
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+// StackJSON is the machine-readable form of a stack printed by --json. Every field is always
+// present so scripts can rely on the shape; add fields rather than renaming them.
+type StackJSON struct {
+	Name    string       `json:"name"`
+	Base    string       `json:"base"`
+	Branch  string       `json:"branch"`
+	Current bool         `json:"current"` // Whether this is the checked out stack (list) or always false (details)
+	Changes []ChangeJSON `json:"changes"`
+}
+
+// ChangeJSON is the machine-readable form of a change in a stack
+type ChangeJSON struct {
+	Position int    `json:"position"`
+	UUID     string `json:"uuid"`
+	PRNumber int    `json:"pr_number"` // 0 for changes that haven't been pushed
+	URL      string `json:"url"`       // "" for changes that haven't been pushed
+	State    string `json:"state"`     // open, draft, merged, closed or local
+	Title    string `json:"title"`
+	Commit   string `json:"commit"`
+	Current  bool   `json:"current"` // Whether this is the change being edited
+}
+
+func newStackJSON(s *model.Stack, changes []*model.Change, currentUUID string) StackJSON {
+	result := StackJSON{
+		Name:    s.Name,
+		Base:    s.Base,
+		Branch:  s.Branch,
+		Changes: make([]ChangeJSON, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		c := ChangeJSON{
+			Position: change.Position,
+			UUID:     change.UUID,
+			State:    GetChangeStatus(change).State,
+			Title:    change.Title,
+			Commit:   change.CommitHash,
+			Current:  currentUUID != "" && change.UUID == currentUUID,
+		}
+		if !change.IsLocal() {
+			c.PRNumber = change.PR.PRNumber
+			c.URL = change.PR.URL
+		}
+		result.Changes = append(result.Changes, c)
+	}
+	return result
+}
+
+// RenderStackListJSON renders every stack and its changes as a JSON array
+func RenderStackListJSON(stacks []*model.Stack, allChanges map[string][]*model.Change, currentStackName string) (string, error) {
+	result := make([]StackJSON, 0, len(stacks))
+	for _, s := range stacks {
+		stackJSON := newStackJSON(s, allChanges[s.Name], "")
+		stackJSON.Current = s.Name == currentStackName
+		result = append(result, stackJSON)
+	}
+	return marshalJSON(result)
+}
+
+// RenderStackDetailsJSON renders a single stack and its changes as a JSON object
+func RenderStackDetailsJSON(s *model.Stack, changes []*model.Change, currentUUID string) (string, error) {
+	return marshalJSON(newStackJSON(s, changes, currentUUID))
+}
+
+func marshalJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return string(data), nil
+}
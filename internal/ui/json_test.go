@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjulian5/stack/internal/model"
+)
+
+func jsonTestStack() (*model.Stack, []*model.Change) {
+	s := &model.Stack{Name: "auth-refactor", Base: "main", Branch: "user/stack-auth-refactor/TOP"}
+	changes := []*model.Change{
+		{
+			Position:   1,
+			Title:      "Add JWT auth",
+			UUID:       "1111111111111111",
+			CommitHash: "aaaa",
+			PR:         &model.PR{PRNumber: 123, URL: "https://github.com/o/r/pull/123", State: "merged"},
+		},
+		{
+			Position:   2,
+			Title:      "Refresh tokens",
+			UUID:       "2222222222222222",
+			CommitHash: "bbbb",
+			PR:         &model.PR{PRNumber: 124, URL: "https://github.com/o/r/pull/124", State: "open"},
+		},
+		{
+			Position:   3,
+			Title:      "Unit tests",
+			UUID:       "3333333333333333",
+			CommitHash: "cccc",
+		},
+	}
+	return s, changes
+}
+
+func TestRenderStackDetailsJSON(t *testing.T) {
+	s, changes := jsonTestStack()
+
+	output, err := RenderStackDetailsJSON(s, changes, "2222222222222222")
+	require.NoError(t, err)
+
+	expected := `{
+  "name": "auth-refactor",
+  "base": "main",
+  "branch": "user/stack-auth-refactor/TOP",
+  "current": false,
+  "changes": [
+    {
+      "position": 1,
+      "uuid": "1111111111111111",
+      "pr_number": 123,
+      "url": "https://github.com/o/r/pull/123",
+      "state": "merged",
+      "title": "Add JWT auth",
+      "commit": "aaaa",
+      "current": false
+    },
+    {
+      "position": 2,
+      "uuid": "2222222222222222",
+      "pr_number": 124,
+      "url": "https://github.com/o/r/pull/124",
+      "state": "open",
+      "title": "Refresh tokens",
+      "commit": "bbbb",
+      "current": true
+    },
+    {
+      "position": 3,
+      "uuid": "3333333333333333",
+      "pr_number": 0,
+      "url": "",
+      "state": "draft",
+      "title": "Unit tests",
+      "commit": "cccc",
+      "current": false
+    }
+  ]
+}`
+	assert.Equal(t, expected, output)
+
+	var decoded StackJSON
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, s.Name, decoded.Name)
+	assert.Equal(t, s.Base, decoded.Base)
+	require.Len(t, decoded.Changes, len(changes))
+	for i, change := range changes {
+		assert.Equal(t, change.Position, decoded.Changes[i].Position)
+		assert.Equal(t, change.UUID, decoded.Changes[i].UUID)
+		assert.Equal(t, change.Title, decoded.Changes[i].Title)
+		assert.Equal(t, change.CommitHash, decoded.Changes[i].Commit)
+		if !change.IsLocal() {
+			assert.Equal(t, change.PR.PRNumber, decoded.Changes[i].PRNumber)
+			assert.Equal(t, change.PR.URL, decoded.Changes[i].URL)
+		}
+	}
+}
+
+func TestRenderStackListJSON(t *testing.T) {
+	s, changes := jsonTestStack()
+	empty := &model.Stack{Name: "empty", Base: "develop", Branch: "user/stack-empty/TOP"}
+
+	output, err := RenderStackListJSON([]*model.Stack{s, empty}, map[string][]*model.Change{s.Name: changes}, "empty")
+	require.NoError(t, err)
+
+	var decoded []StackJSON
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	require.Len(t, decoded, 2)
+
+	assert.Equal(t, "auth-refactor", decoded[0].Name)
+	assert.False(t, decoded[0].Current)
+	assert.Len(t, decoded[0].Changes, 3)
+
+	assert.Equal(t, "empty", decoded[1].Name)
+	assert.Equal(t, "develop", decoded[1].Base)
+	assert.True(t, decoded[1].Current)
+	assert.NotNil(t, decoded[1].Changes, "changes should be an empty array, not null")
+	assert.Contains(t, output, `"changes": []`)
+}
+
+func TestRenderStackListJSON_NoStacks(t *testing.T) {
+	output, err := RenderStackListJSON(nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, "[]", output)
+}
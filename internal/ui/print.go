@@ -21,8 +21,10 @@ func Errorf(format string, args ...interface{}) {
 	Error(fmt.Sprintf(format, args...))
 }
 
+// Warning prints to stderr, like Error, so warnings raised while producing machine-readable
+// output (e.g. --json) don't end up in it
 func Warning(msg string) {
-	fmt.Fprintln(os.Stdout, WarningStyle.Render("⚠ "+msg))
+	fmt.Fprintln(os.Stderr, WarningStyle.Render("⚠ "+msg))
 }
 
 func Warningf(format string, args ...interface{}) {
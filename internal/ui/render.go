@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bjulian5/stack/internal/git"
 	"github.com/bjulian5/stack/internal/model"
 )
 
@@ -16,19 +17,35 @@ func RenderStackList(stacks []*model.Stack, currentStackName string, stackChange
 
 // RenderStackDetails renders detailed information about a stack
 // Now uses tree visualization by default via RenderStackTree
-// Accepts currentUUID to show current position indicator
-func RenderStackDetails(s *model.Stack, changes []*model.Change, currentUUID string) string {
+// Accepts currentUUID to show current position indicator, and onTop when the TOP branch is
+// checked out
+func RenderStackDetails(s *model.Stack, changes []*model.Change, currentUUID string, onTop bool) string {
+	return renderStackDetails(s, changes, currentUUID, onTop, "")
+}
+
+// RenderStackDetailsWithSize renders RenderStackDetails with an extra line giving the stack's
+// commit count and total additions/deletions across the files it changes
+func RenderStackDetailsWithSize(s *model.Stack, changes []*model.Change, currentUUID string, onTop bool, commits int, total git.DiffStat) string {
+	return renderStackDetails(s, changes, currentUUID, onTop, formatStackSize(commits, total))
+}
+
+func renderStackDetails(s *model.Stack, changes []*model.Change, currentUUID string, onTop bool, sizeLine string) string {
 	var output strings.Builder
 
 	// Render the tree visualization with current position
-	treeView := RenderStackTree(s, changes, currentUUID)
+	treeView := RenderStackTree(s, changes, currentUUID, onTop)
 	output.WriteString(treeView)
 	output.WriteString("\n\n")
 
 	// Add summary statistics
 	if len(changes) > 0 {
 		output.WriteString(buildSummaryLine(changes))
-		output.WriteString("\n\n")
+		output.WriteString("\n")
+		if sizeLine != "" {
+			output.WriteString(sizeLine)
+			output.WriteString("\n")
+		}
+		output.WriteString("\n")
 	}
 
 	legend := buildLegendPanel()
@@ -61,6 +78,7 @@ type NavigationSuccess struct {
 	Changes     []*model.Change
 	CurrentUUID string
 	IsEditing   bool
+	OnTop       bool // Navigation ended on the TOP branch rather than a UUID branch
 }
 
 // RenderNavigationSuccess renders a success message with compact stack tree after navigation
@@ -72,7 +90,7 @@ func RenderNavigationSuccess(data NavigationSuccess) string {
 	output.WriteString("\n\n")
 
 	// Compact stack tree
-	treeView := RenderStackTree(data.Stack, data.Changes, data.CurrentUUID)
+	treeView := RenderStackTree(data.Stack, data.Changes, data.CurrentUUID, data.OnTop)
 	output.WriteString(treeView)
 
 	// Add summary line
@@ -219,16 +237,48 @@ func RenderMergedPRsTable(mergedChanges []*model.Change) string {
 // RenderStackDetailsTable renders a detailed table view of a single stack
 // Accepts currentUUID to highlight the current row
 func RenderStackDetailsTable(s *model.Stack, changes []*model.Change, currentUUID string) string {
+	return renderStackDetailsTable(s, changes, currentUUID, nil, git.DiffStat{}, "")
+}
+
+// RenderStackDetailsTableWithStats renders the detailed table view with additions/deletions
+// for each change (keyed by UUID) and a totals row
+func RenderStackDetailsTableWithStats(s *model.Stack, changes []*model.Change, currentUUID string, stats map[string]git.DiffStat, total git.DiffStat) string {
+	if stats == nil {
+		stats = map[string]git.DiffStat{}
+	}
+	return renderStackDetailsTable(s, changes, currentUUID, stats, total, "")
+}
+
+// RenderStackDetailsTableWithSize renders the detailed table view with a line giving the
+// stack's commit count and total additions/deletions under the summary. Per-change stats are
+// shown as in RenderStackDetailsTableWithStats when stats is non-nil.
+func RenderStackDetailsTableWithSize(s *model.Stack, changes []*model.Change, currentUUID string, stats map[string]git.DiffStat, total git.DiffStat, commits int, size git.DiffStat) string {
+	return renderStackDetailsTable(s, changes, currentUUID, stats, total, formatStackSize(commits, size))
+}
+
+func renderStackDetailsTable(s *model.Stack, changes []*model.Change, currentUUID string, stats map[string]git.DiffStat, total git.DiffStat, sizeLine string) string {
 	if len(changes) == 0 {
 		return RenderPanel(Dim("No changes in this stack"))
 	}
 
 	var output strings.Builder
 
-	output.WriteString(Bold(s.Name) + "  " + Dim("→") + "  " + Muted(s.Base) + "\n\n")
+	output.WriteString(Bold(s.Name) + "  " + Dim("→") + "  " + Muted(s.Base) + "\n")
+	if s.Description != "" {
+		output.WriteString(Dim(s.Description) + "\n")
+	}
+	output.WriteString("\n")
 
-	rows := make([][]string, len(changes))
-	for i, change := range changes {
+	showRemote := false
+	for _, change := range changes {
+		if change.RemoteState != "" {
+			showRemote = true
+			break
+		}
+	}
+
+	rows := make([][]string, 0, len(changes)+1)
+	for _, change := range changes {
 		position := fmt.Sprintf("%d", change.Position)
 		statusText := GetChangeStatus(change).Render()
 
@@ -257,20 +307,119 @@ func RenderStackDetailsTable(s *model.Stack, changes []*model.Change, currentUUI
 			url = BoldStyle.Render(url)
 		}
 
-		rows[i] = []string{position, statusText, prLabel, change.Title, commit, url}
+		row := []string{position, statusText, prLabel, change.Title, commit}
+		if Display.ShowAuthor {
+			author := change.Author
+			if author == "" {
+				author = "-"
+			}
+			row = append(row, author)
+		}
+		if stats != nil {
+			diff := "-"
+			if stat, ok := stats[change.UUID]; ok {
+				diff = formatDiffStat(stat)
+			}
+			row = append(row, diff)
+		}
+		if showRemote {
+			row = append(row, formatRemoteState(change.RemoteState))
+		}
+		rows = append(rows, append(row, url))
+	}
+
+	headers := []string{"#", "STATUS", "PR", "TITLE", "COMMIT"}
+	if Display.ShowAuthor {
+		headers = append(headers, "AUTHOR")
+	}
+	if stats != nil {
+		headers = append(headers, "DIFF")
+	}
+	if showRemote {
+		headers = append(headers, "REMOTE")
+	}
+	headers = append(headers, "URL")
+
+	if stats != nil {
+		totalRow := []string{"", "", "", Bold("Total"), ""}
+		if Display.ShowAuthor {
+			totalRow = append(totalRow, "")
+		}
+		totalRow = append(totalRow, Bold(formatDiffStat(total)))
+		if showRemote {
+			totalRow = append(totalRow, "")
+		}
+		rows = append(rows, append(totalRow, ""))
 	}
 
 	t := NewStackTable().
-		Headers("#", "STATUS", "PR", "TITLE", "COMMIT", "URL").
+		Headers(headers...).
 		Rows(rows...)
 
 	output.WriteString(t.String() + "\n\n")
-	output.WriteString(buildSummaryLine(changes) + "\n\n")
+	output.WriteString(buildSummaryLine(changes) + "\n")
+	if sizeLine != "" {
+		output.WriteString(sizeLine + "\n")
+	}
+	output.WriteString("\n")
 	output.WriteString(Dim("Legend:") + "\n" + buildLegendPanel())
 
 	return output.String()
 }
 
+// formatBaseColumn renders a stack's base, marked with "⤓ N" when it is behind its upstream
+func formatBaseColumn(base string, behind int) string {
+	if behind == 0 {
+		return base
+	}
+	return base + " " + StatusModifiedStyle.Render(fmt.Sprintf("⤓ %d", behind))
+}
+
+func formatSyncIndicator(needsRefresh bool) string {
+	if needsRefresh {
+		return StatusModifiedStyle.Render("⟳ needs refresh")
+	}
+	return StatusOpenStyle.Render("✓")
+}
+
+// FormatBaseBehind renders how far a stack's base is behind its upstream, e.g. "⤓ 4 behind main"
+func FormatBaseBehind(count int, base string) string {
+	return fmt.Sprintf("⤓ %d behind %s", count, base)
+}
+
+func formatRemoteState(state model.RemoteState) string {
+	switch state {
+	case model.RemoteInSync:
+		return StatusOpenStyle.Render(string(state))
+	case model.RemoteAhead, model.RemoteMissing:
+		return StatusModifiedStyle.Render(string(state))
+	case model.RemoteBehind, model.RemoteDiverged:
+		return StatusClosedStyle.Render(string(state))
+	case "":
+		return "-"
+	default:
+		return string(state)
+	}
+}
+
+func formatDiffStat(stat git.DiffStat) string {
+	return StatusOpenStyle.Render(fmt.Sprintf("+%d", stat.Additions)) + " " + StatusClosedStyle.Render(fmt.Sprintf("-%d", stat.Deletions))
+}
+
+// formatStackSize formats a stack's size, e.g. "4 commits, +1200 -340 across 18 files"
+func formatStackSize(commits int, total git.DiffStat) string {
+	commitWord := "commits"
+	if commits == 1 {
+		commitWord = "commit"
+	}
+	fileWord := "files"
+	if total.Files == 1 {
+		fileWord = "file"
+	}
+	return Bold(fmt.Sprintf("%d %s", commits, commitWord)) + Dim(", ") + formatDiffStat(total) +
+		Dim(fmt.Sprintf(" across %d %s", total.Files, fileWord))
+}
+
 func buildSummaryLine(changes []*model.Change) string {
 	open, draft, merged, closed, local, needsPush := CountPRsByState(changes)
 	totalPRs := len(changes)
@@ -316,12 +465,23 @@ func buildLegendPanel() string {
 	return RenderPanel(content)
 }
 
-// RenderStackListTable renders a table comparing multiple stacks
-func RenderStackListTable(stacks []*model.Stack, allChanges map[string][]*model.Change, currentStackName string) string {
+// RenderStackListTable renders a table comparing multiple stacks. Stacks in needsRefresh are
+// marked as needing a 'stack refresh', and behind maps stacks to how many commits their base
+// is behind its upstream.
+func RenderStackListTable(stacks []*model.Stack, allChanges map[string][]*model.Change, currentStackName string, needsRefresh map[string]bool, behind map[string]int) string {
 	if len(stacks) == 0 {
 		return RenderNoStacksMessage()
 	}
 
+	// Only show descriptions when a stack has one, so the table stays narrow otherwise
+	showDescription := false
+	for _, s := range stacks {
+		if s.Description != "" {
+			showDescription = true
+			break
+		}
+	}
+
 	rows := make([][]string, len(stacks))
 	for i, s := range stacks {
 		changes := allChanges[s.Name]
@@ -332,19 +492,33 @@ func RenderStackListTable(stacks []*model.Stack, allChanges map[string][]*model.
 			name = "● " + name
 		}
 
-		rows[i] = []string{
-			Truncate(name, 20),
+		row := []string{Truncate(name, 20)}
+		if showDescription {
+			description := "-"
+			if s.Description != "" {
+				description = Truncate(s.Description, 30)
+			}
+			row = append(row, description)
+		}
+		rows[i] = append(row,
 			fmt.Sprintf("%d", open),
 			fmt.Sprintf("%d", draft),
 			fmt.Sprintf("%d", merged),
 			fmt.Sprintf("%d", local),
-			s.Base,
+			formatBaseColumn(s.Base, behind[s.Name]),
 			Truncate(s.Branch, 27),
-		}
+			formatSyncIndicator(needsRefresh[s.Name]),
+		)
+	}
+
+	headers := []string{"STACK"}
+	if showDescription {
+		headers = append(headers, "DESCRIPTION")
 	}
+	headers = append(headers, "OPEN", "DRAFT", "MERGED", "LOCAL", "BASE", "BRANCH", "SYNC")
 
 	t := NewStackTable().
-		Headers("STACK", "OPEN", "DRAFT", "MERGED", "LOCAL", "BASE", "BRANCH").
+		Headers(headers...).
 		Rows(rows...)
 
 	plural := ""
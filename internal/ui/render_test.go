@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjulian5/stack/internal/git"
+	"github.com/bjulian5/stack/internal/model"
+)
+
+func TestRenderStackDescription(t *testing.T) {
+	s, changes := jsonTestStack()
+	allChanges := map[string][]*model.Change{s.Name: changes}
+
+	t.Run("WithDescription", func(t *testing.T) {
+		described := *s
+		described.Description = "Auth refactor epic that replaces sessions with JWTs"
+
+		assert.Contains(t, RenderStackDetailsTable(&described, changes, ""), "Auth refactor epic")
+		assert.Contains(t, RenderStackTree(&described, changes, "", false), "Auth refactor epic")
+		assert.Contains(t, RenderStackListTree([]*model.Stack{&described}, allChanges, ""), "Auth refactor epic")
+
+		table := RenderStackListTable([]*model.Stack{&described}, allChanges, "", nil, nil)
+		assert.Contains(t, table, "DESCRIPTION")
+		assert.Contains(t, table, Truncate(described.Description, 30))
+		assert.NotContains(t, table, described.Description, "long descriptions are truncated in the table")
+	})
+
+	t.Run("EmptyDescription", func(t *testing.T) {
+		root, _, _ := strings.Cut(RenderStackTree(s, changes, "", false), "\n")
+		assert.Equal(t, TreeRootStyle.Render(s.Name), root)
+		assert.NotContains(t, RenderStackListTable([]*model.Stack{s}, allChanges, "", nil, nil), "DESCRIPTION")
+
+		// Only stacks with a description fill the column
+		described := &model.Stack{Name: "other", Base: "main", Branch: "user/stack-other/TOP", Description: "Other work"}
+		table := RenderStackListTable([]*model.Stack{s, described}, allChanges, "", nil, nil)
+		assert.Contains(t, table, "DESCRIPTION")
+		assert.Contains(t, table, "Other work")
+	})
+}
+
+func TestRenderStackDetailsWithSize(t *testing.T) {
+	s, changes := jsonTestStack()
+
+	output := RenderStackDetailsWithSize(s, changes, "", false, 4, git.DiffStat{Files: 18, Additions: 1200, Deletions: 340})
+	assert.Contains(t, output, "4 commits")
+	assert.Contains(t, output, "+1200")
+	assert.Contains(t, output, "-340")
+	assert.Contains(t, output, "across 18 files")
+
+	assert.Contains(t, RenderStackDetailsWithSize(s, changes, "", false, 1, git.DiffStat{Files: 1, Additions: 2}), "1 commit, ")
+	assert.NotContains(t, RenderStackDetails(s, changes, "", false), "across", "size is only rendered on request")
+
+	// The table views can show the size line too
+	table := RenderStackDetailsTableWithSize(s, changes, "", nil, git.DiffStat{}, 4, git.DiffStat{Files: 18, Additions: 1200, Deletions: 340})
+	assert.Contains(t, table, "across 18 files")
+	assert.NotContains(t, table, "DIFF")
+	withStats := RenderStackDetailsTableWithSize(s, changes, "", map[string]git.DiffStat{}, git.DiffStat{}, 4, git.DiffStat{Files: 18, Additions: 1200, Deletions: 340})
+	assert.Contains(t, withStats, "across 18 files")
+	assert.Contains(t, withStats, "DIFF")
+	assert.NotContains(t, RenderStackDetailsTable(s, changes, ""), "across")
+}
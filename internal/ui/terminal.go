@@ -26,3 +26,9 @@ func GetTerminalWidth() int {
 
 	return width
 }
+
+// IsInteractive returns true if stdin is a terminal, so prompts can be answered. Commands
+// that prompt fall back to a non-interactive default otherwise (CI, pipes, scripts).
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
@@ -17,13 +17,21 @@ import (
 //	  ├─● #123 Add JWT auth (a1b2c3d)
 //	  ├─◐ #124 Refresh tokens (b2c3d4e)
 //	  ╰─◯ Unit tests (c3d4e5f) [local]
-func RenderStackTree(s *model.Stack, changes []*model.Change, currentUUID string) string {
+//
+// onTop adds a banner under the tree saying the TOP branch is checked out, so the arrow on the
+// last change isn't mistaken for editing that change alone.
+func RenderStackTree(s *model.Stack, changes []*model.Change, currentUUID string, onTop bool) string {
+	banner := ""
+	if onTop {
+		banner = "\n" + formatTopBanner()
+	}
+
 	if len(changes) == 0 {
-		return TreeRootStyle.Render(s.Name) + "\n" + Dim("  No changes yet")
+		return formatStackRootForTree(s) + "\n" + Dim("  No changes yet") + banner
 	}
 
 	// Create root with stack name
-	t := tree.Root(TreeRootStyle.Render(s.Name))
+	t := tree.Root(formatStackRootForTree(s))
 
 	// Add base branch as intermediate node
 	baseNode := tree.Root(Muted(s.Base))
@@ -42,7 +50,7 @@ func RenderStackTree(s *model.Stack, changes []*model.Change, currentUUID string
 		EnumeratorStyle(TreeEnumeratorStyle).
 		Indenter(RenderTreeIndenter())
 
-	return t.String()
+	return t.String() + banner
 }
 
 // RenderStackTreeCompact renders a stack tree in compact form (no base branch node)
@@ -101,6 +109,10 @@ func RenderStackListTree(stacks []*model.Stack, allChanges map[string][]*model.C
 		stackLabel := formatStackNameForTree(s.Name, currentStackName)
 		stackNode := tree.Root(stackLabel)
 
+		if s.Description != "" {
+			stackNode.Child(s.Description)
+		}
+
 		// Get changes for this stack
 		changes, ok := allChanges[s.Name]
 		if !ok {
@@ -204,6 +216,11 @@ func formatChangeForTree(change *model.Change, currentUUID string) string {
 	return line
 }
 
+// formatTopBanner formats the line shown under a stack tree when its TOP branch is checked out
+func formatTopBanner() string {
+	return CurrentPositionArrowStyle.Render("► On TOP") + Dim(" (editing whole stack)")
+}
+
 // formatStackNameForTree formats a stack name with current marker
 func formatStackNameForTree(stackName string, currentStackName string) string {
 	if stackName == currentStackName {
@@ -212,6 +229,16 @@ func formatStackNameForTree(stackName string, currentStackName string) string {
 	return TreeItemStyle.Render(stackName)
 }
 
+// formatStackRootForTree formats the root of a stack tree: the stack name, followed by its
+// description when it has one
+func formatStackRootForTree(s *model.Stack) string {
+	root := TreeRootStyle.Render(s.Name)
+	if s.Description != "" {
+		root += "  " + Dim(s.Description)
+	}
+	return root
+}
+
 // formatStackSummary creates a summary line for a stack
 func formatStackSummary(changes []*model.Change) string {
 	if len(changes) == 0 {
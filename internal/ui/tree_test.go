@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderStackTree_CurrentPosition(t *testing.T) {
+	s, changes := jsonTestStack()
+
+	t.Run("OnTop", func(t *testing.T) {
+		expected := `auth-refactor
+╰─ main
+   ├─ ◆ #123 Add JWT auth (aaaa)
+   ├─ ● #124 Refresh tokens (bbbb) [needs push]
+   ╰─ ◐ [local] Unit tests (cccc) ←
+► On TOP (editing whole stack)`
+		assert.Equal(t, expected, RenderStackTree(s, changes, "3333333333333333", true))
+	})
+
+	t.Run("EditingChange", func(t *testing.T) {
+		expected := `auth-refactor
+╰─ main
+   ├─ ◆ #123 Add JWT auth (aaaa)
+   ├─ ● #124 Refresh tokens (bbbb) [needs push] ←
+   ╰─ ◐ [local] Unit tests (cccc)`
+		assert.Equal(t, expected, RenderStackTree(s, changes, "2222222222222222", false))
+	})
+
+	t.Run("EditingTopChange", func(t *testing.T) {
+		// Editing the last change on its UUID branch highlights the same change, without the banner
+		assert.NotContains(t, RenderStackTree(s, changes, "3333333333333333", false), "On TOP")
+	})
+
+	t.Run("EmptyStackOnTop", func(t *testing.T) {
+		expected := `auth-refactor
+  No changes yet
+► On TOP (editing whole stack)`
+		assert.Equal(t, expected, RenderStackTree(s, nil, "", true))
+	})
+}